@@ -0,0 +1,96 @@
+// Command advice-eval scores the stored corpus of survey answers with the scoring engine, runs each
+// result through the configured LLM provider and checks the patient advice with rule-based checks,
+// so a prompt or model change can be compared against the current one before it is rolled out.
+//
+//	go run ./cmd/advice-eval -provider stub
+//	go run ./cmd/advice-eval -prompt draft_prompt.txt -min-score 0.9
+//
+// The provider is read by config.LoadLLM from the same environment variables as the API
+// (LLM_PROVIDER, YANDEX_*, OPENAI_*); no database or API secrets are needed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/medical-app/backend/config"
+	"github.com/medical-app/backend/internal/service"
+)
+
+func main() {
+	corpus := flag.String("corpus", "evals/advice_cases.json", "path to the JSON corpus of survey cases")
+	templatesFile := flag.String("templates", "evals/survey_templates.json", "path to the JSON snapshot of the survey templates the cases are scored with")
+	promptFile := flag.String("prompt", "", "file with the system prompt to evaluate (default: built-in patient advice prompt)")
+	provider := flag.String("provider", "", "LLM provider: yandex, openai or stub (default: LLM_PROVIDER)")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	minScore := flag.Float64("min-score", 0, "exit with status 1 when the overall score is below this value")
+	timeout := flag.Duration("timeout", 10*time.Minute, "time limit for the whole run")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cases, err := service.LoadAdviceEvalCases(*corpus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	templates, err := service.LoadAdviceEvalTemplates(*templatesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	systemPrompt := ""
+	if *promptFile != "" {
+		data, err := os.ReadFile(*promptFile)
+		if err != nil {
+			log.Fatalf("read prompt: %v", err)
+		}
+		systemPrompt = string(data)
+	}
+
+	cfg, err := config.LoadLLM()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *provider != "" {
+		cfg.LLMProvider = *provider
+	}
+	llm := service.NewLLMProvider(service.Deps{
+		LLMProvider:     cfg.LLMProvider,
+		YandexGPTApiKey: cfg.YandexGPTApiKey,
+		YandexIAMToken:  cfg.YandexIAMToken,
+		YandexFolderID:  cfg.YandexFolderID,
+		YandexGPTModel:  cfg.YandexGPTModel,
+		OpenAIBaseURL:   cfg.OpenAIBaseURL,
+		OpenAIAPIKey:    cfg.OpenAIAPIKey,
+		OpenAIModel:     cfg.OpenAIModel,
+	})
+	if llm == nil {
+		log.Fatal("no LLM provider configured (set LLM_PROVIDER or pass -provider stub)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report := service.RunAdviceEval(ctx, llm, systemPrompt, templates, cases)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if report.Score < *minScore {
+		log.Printf("score %.3f is below the required %.3f", report.Score, *minScore)
+		os.Exit(1)
+	}
+}
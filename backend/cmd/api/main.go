@@ -16,6 +16,7 @@ import (
 
 	"github.com/medical-app/backend/config"
 	"github.com/medical-app/backend/internal/app"
+	"github.com/medical-app/backend/internal/external"
 	"github.com/medical-app/backend/internal/handler/middleware"
 	v1 "github.com/medical-app/backend/internal/handler/v1"
 	"github.com/medical-app/backend/internal/infrastructure/postgres"
@@ -70,8 +71,24 @@ func main() {
 		YandexIAMToken:   cfg.YandexIAMToken,
 		YandexFolderID:   cfg.YandexFolderID,
 		YandexGPTModel:   cfg.YandexGPTModel,
+		LLMProvider:      cfg.LLMProvider,
+		OpenAIBaseURL:    cfg.OpenAIBaseURL,
+		OpenAIAPIKey:     cfg.OpenAIAPIKey,
+		OpenAIModel:      cfg.OpenAIModel,
+		AIWorkers:        cfg.AIWorkers,
+		AdviceLiterature: cfg.AdviceLiterature,
+		AdviceCacheTTL:   cfg.AdviceCacheTTL,
 	})
 
+	// Background AI generation workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	services.AIJobs.Start(workerCtx)
+	if err := services.Rescore.FailInterrupted(context.Background()); err != nil {
+		log.Printf("Failed to clean up interrupted rescore jobs: %v", err)
+	}
+	services.Rescore.StartReaper(workerCtx)
+
 	// Initialize Fiber app
 	fiberApp := fiber.New(fiber.Config{
 		AppName:      "GIBP Medical API",
@@ -93,19 +110,29 @@ func main() {
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret)
 	auditMiddleware := middleware.NewAuditMiddleware(repos.AuditLog)
+	permissionMiddleware := middleware.NewPermissionMiddleware(repos.User)
 
 	// Setup routes
 	v1.SetupRoutes(fiberApp, v1.RouterDeps{
-		Services:        services,
-		AuthMiddleware:  authMiddleware,
-		AuditMiddleware: auditMiddleware,
+		Services:             services,
+		AuthMiddleware:       authMiddleware,
+		AuditMiddleware:      auditMiddleware,
+		PermissionMiddleware: permissionMiddleware,
 	})
 
-	// Health check
+	// Health check; an open circuit breaker on an upstream API marks the service degraded
 	fiberApp.Get("/health", func(c *fiber.Ctx) error {
+		status := "ok"
+		breakers := external.SharedTransport.BreakerStates()
+		for _, b := range breakers {
+			if b.State != external.BreakerClosed {
+				status = "degraded"
+			}
+		}
 		return c.JSON(fiber.Map{
-			"status":    "ok",
-			"timestamp": time.Now().UTC(),
+			"status":           status,
+			"timestamp":        time.Now().UTC(),
+			"circuit_breakers": breakers,
 		})
 	})
 
@@ -121,6 +148,7 @@ func main() {
 	<-quit
 
 	zapLogger.Info("Shutting down server...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -33,6 +33,21 @@ type Config struct {
 	YandexFolderID  string
 	YandexGPTModel  string
 
+	// LLM provider: "yandex", "openai" or "stub" (empty = yandex when configured)
+	LLMProvider   string
+	OpenAIBaseURL string
+	OpenAIAPIKey  string
+	OpenAIModel   string
+
+	// Background AI generation workers
+	AIWorkers int
+
+	// Ground patient advice in PubMed abstracts (cached locally)
+	AdviceLiterature bool
+
+	// Reuse generated patient advice for identical answers (0 = off)
+	AdviceCacheTTL time.Duration
+
 	// CORS
 	CORSOrigins string
 }
@@ -88,12 +103,57 @@ func Load() (*Config, error) {
 
 	// External APIs (optional for MVP)
 	cfg.NCBIApiKey = os.Getenv("NCBI_API_KEY")
+	if err := loadLLM(cfg); err != nil {
+		return nil, err
+	}
+
+	aiWorkers, err := strconv.Atoi(getEnv("AI_WORKERS", "2"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_WORKERS: %w", err)
+	}
+	cfg.AIWorkers = aiWorkers
+
+	adviceLiterature, err := strconv.ParseBool(getEnv("ADVICE_LITERATURE", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADVICE_LITERATURE: %w", err)
+	}
+	cfg.AdviceLiterature = adviceLiterature
+
+	adviceCacheHours, err := strconv.Atoi(getEnv("ADVICE_CACHE_TTL_HOURS", "168"))
+	if err != nil || adviceCacheHours < 0 {
+		return nil, fmt.Errorf("invalid ADVICE_CACHE_TTL_HOURS: %q", os.Getenv("ADVICE_CACHE_TTL_HOURS"))
+	}
+	cfg.AdviceCacheTTL = time.Duration(adviceCacheHours) * time.Hour
+
+	return cfg, nil
+}
+
+// LoadLLM reads only the LLM provider settings, for tools that generate text without the API
+// server's database and secrets.
+func LoadLLM() (*Config, error) {
+	cfg := &Config{}
+	if err := loadLLM(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadLLM(cfg *Config) error {
 	cfg.YandexGPTApiKey = os.Getenv("YANDEX_GPT_API_KEY")
 	cfg.YandexIAMToken = os.Getenv("YANDEX_IAM_TOKEN")
 	cfg.YandexFolderID = os.Getenv("YANDEX_FOLDER_ID")
 	cfg.YandexGPTModel = getEnv("YANDEX_GPT_MODEL", "yandexgpt-lite")
 
-	return cfg, nil
+	cfg.LLMProvider = os.Getenv("LLM_PROVIDER")
+	switch cfg.LLMProvider {
+	case "", "yandex", "openai", "stub":
+	default:
+		return fmt.Errorf("invalid LLM_PROVIDER %q (expected yandex, openai or stub)", cfg.LLMProvider)
+	}
+	cfg.OpenAIBaseURL = os.Getenv("OPENAI_BASE_URL")
+	cfg.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
+	cfg.OpenAIModel = os.Getenv("OPENAI_MODEL")
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
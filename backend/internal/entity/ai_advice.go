@@ -16,7 +16,26 @@ type AIAdvice struct {
 	Category   string          `json:"category,omitempty"`
 	Details    json.RawMessage `json:"details,omitempty"`
 	AdviceText string          `json:"advice_text"`
-	CreatedAt  time.Time       `json:"created_at"`
+	AIStatus   string          `json:"ai_status,omitempty"`
+	// Sections holds the structured answer; nil for fallback or unstructured text.
+	Sections *AdviceSections `json:"sections,omitempty"`
+	// Citations are the PMIDs of the PubMed abstracts given to the model as evidence.
+	Citations []string `json:"citations,omitempty"`
+	// Prompt version the advice was generated with; nil for the built-in prompt.
+	PromptTemplateID *uuid.UUID `json:"prompt_template_id,omitempty"`
+	PromptVersion    int        `json:"prompt_version,omitempty"`
+	// Model that generated the text; empty for the rule-based fallback.
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdviceSections are the five parts of patient advice requested by the prompt.
+type AdviceSections struct {
+	Summary     string `json:"summary"`
+	RiskLevel   string `json:"risk_level"`
+	Preparation string `json:"preparation"`
+	Tests       string `json:"tests"`
+	Questions   string `json:"questions"`
 }
 
 type AIAdviceCreate struct {
@@ -28,3 +47,88 @@ type AIAdviceCreate struct {
 	AdviceText string         `json:"advice_text"`
 	PatientID  uuid.UUID      `json:"-"`
 }
+
+// AdviceMessage is one turn of a follow-up conversation about an advice record.
+type AdviceMessage struct {
+	ID       uuid.UUID `json:"id"`
+	AdviceID uuid.UUID `json:"advice_id"`
+	Role     string    `json:"role"`
+	Content  string    `json:"content"`
+	// AIStatus is set on assistant replies; failed means the standard fallback reply was stored.
+	AIStatus  string    `json:"ai_status,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Advice message roles
+const (
+	AdviceMessageUser      = "user"
+	AdviceMessageAssistant = "assistant"
+)
+
+// AdviceFeedback is a patient rating or a clinician flag on an advice record.
+type AdviceFeedback struct {
+	ID        uuid.UUID `json:"id"`
+	AdviceID  uuid.UUID `json:"advice_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Rating    *int      `json:"rating,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Advice feedback kinds
+const (
+	AdviceFeedbackRating = "rating"
+	AdviceFeedbackFlag   = "flag"
+)
+
+// Reasons a clinician may flag advice for
+const (
+	AdviceFlagInaccurate = "inaccurate"
+	AdviceFlagUnsafe     = "unsafe"
+)
+
+// AdviceRatingCreate is a patient's rating of their advice (1 = useless, 5 = very helpful).
+type AdviceRatingCreate struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// AdviceFlagCreate is a clinician's report that advice is inaccurate or unsafe.
+type AdviceFlagCreate struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+// AdviceQualityRow aggregates feedback for advice generated with one prompt version and model.
+// An empty model groups the rule-based fallback texts.
+type AdviceQualityRow struct {
+	PromptVersion     int      `json:"prompt_version"`
+	Model             string   `json:"model"`
+	Advice            int      `json:"advice"`
+	Ratings           int      `json:"ratings"`
+	AvgRating         *float64 `json:"avg_rating,omitempty"`
+	FlaggedInaccurate int      `json:"flagged_inaccurate"`
+	FlaggedUnsafe     int      `json:"flagged_unsafe"`
+	Flagged           int      `json:"flagged"`
+	// FlagRate is the share of advice records flagged for any reason.
+	FlagRate float64 `json:"flag_rate"`
+}
+
+// AdviceCacheEntry is generated advice stored for reuse by requests with the same cache key.
+type AdviceCacheEntry struct {
+	Key              string          `json:"cache_key"`
+	SurveyCode       string          `json:"survey_code"`
+	TemplateVersion  int             `json:"template_version"`
+	PromptTemplateID *uuid.UUID      `json:"prompt_template_id,omitempty"`
+	PromptVersion    int             `json:"prompt_version"`
+	Model            string          `json:"model"`
+	AdviceText       string          `json:"advice_text"`
+	Sections         *AdviceSections `json:"sections,omitempty"`
+	Citations        []string        `json:"citations,omitempty"`
+	Hits             int             `json:"hits"`
+	CreatedAt        time.Time       `json:"created_at"`
+	ExpiresAt        time.Time       `json:"expires_at"`
+}
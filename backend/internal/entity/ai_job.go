@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AIJob is a queued LLM generation task processed by background workers
+type AIJob struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	Kind              string          `json:"kind" db:"kind"`
+	Status            string          `json:"status" db:"status"`
+	UserID            *uuid.UUID      `json:"user_id,omitempty" db:"user_id"`
+	SurveyResponseID  *uuid.UUID      `json:"survey_response_id,omitempty" db:"survey_response_id"`
+	AIAdviceID        *uuid.UUID      `json:"ai_advice_id,omitempty" db:"ai_advice_id"`
+	ClinicalInsightID *uuid.UUID      `json:"clinical_insight_id,omitempty" db:"clinical_insight_id"`
+	Payload           json.RawMessage `json:"-" db:"payload"`
+	Result            string          `json:"result,omitempty" db:"result"`
+	Error             string          `json:"error,omitempty" db:"error"`
+	Attempts          int             `json:"attempts" db:"attempts"`
+	MaxAttempts       int             `json:"max_attempts" db:"max_attempts"`
+	RunAfter          time.Time       `json:"run_after" db:"run_after"`
+	LockedAt          *time.Time      `json:"-" db:"locked_at"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	FinishedAt        *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// AI job kinds
+const (
+	AIJobSurveyInterpretation = "survey_interpretation"
+	AIJobPatientAdvice        = "patient_advice"
+	AIJobClinicalInsight      = "clinical_insight"
+)
+
+// AI job status constants
+const (
+	AIJobStatusPending = "pending"
+	AIJobStatusRunning = "running"
+	AIJobStatusDone    = "done"
+	AIJobStatusFailed  = "failed"
+)
+
+// AI enrichment status of a survey response or advice record
+const (
+	AIStatusPending = "pending"
+	AIStatusDone    = "done"
+	AIStatusFailed  = "failed"
+)
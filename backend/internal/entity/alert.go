@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertRule defines when a new medical index measurement should raise an alert
+type AlertRule struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	IndexType     string          `json:"index_type" db:"index_type"`
+	RuleType      string          `json:"rule_type" db:"rule_type"`
+	Direction     string          `json:"direction" db:"direction"`
+	Threshold     *float64        `json:"threshold,omitempty" db:"threshold"`
+	CategoryOrder json.RawMessage `json:"category_order,omitempty" db:"category_order"`
+	Severity      string          `json:"severity" db:"severity"`
+	Description   string          `json:"description,omitempty" db:"description"`
+	IsActive      bool            `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Alert rule types
+const (
+	AlertRuleThreshold      = "threshold"
+	AlertRuleDelta          = "delta"
+	AlertRuleCategoryChange = "category_change"
+)
+
+// Alert rule directions
+const (
+	AlertDirectionRise = "rise"
+	AlertDirectionFall = "fall"
+	AlertDirectionAny  = "any"
+)
+
+// Alert severities
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// AlertRuleUpsert represents data for creating or updating an alert rule
+type AlertRuleUpsert struct {
+	IndexType     string   `json:"index_type"`
+	RuleType      string   `json:"rule_type"`
+	Direction     string   `json:"direction,omitempty"`
+	Threshold     *float64 `json:"threshold,omitempty"`
+	CategoryOrder []string `json:"category_order,omitempty"`
+	Severity      string   `json:"severity,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	IsActive      *bool    `json:"is_active,omitempty"`
+}
+
+// GetCategoryOrder parses the ordered category list of a category_change rule
+func (r *AlertRule) GetCategoryOrder() ([]string, error) {
+	if len(r.CategoryOrder) == 0 {
+		return nil, nil
+	}
+	var order []string
+	if err := json.Unmarshal(r.CategoryOrder, &order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Alert is a notification raised for the attending doctor
+type Alert struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	RuleID           uuid.UUID  `json:"rule_id" db:"rule_id"`
+	PatientID        uuid.UUID  `json:"patient_id" db:"patient_id"`
+	DoctorID         *uuid.UUID `json:"doctor_id,omitempty" db:"doctor_id"`
+	MedicalIndexID   *uuid.UUID `json:"medical_index_id,omitempty" db:"medical_index_id"`
+	IndexType        string     `json:"index_type" db:"index_type"`
+	PreviousValue    *float64   `json:"previous_value,omitempty" db:"previous_value"`
+	CurrentValue     float64    `json:"current_value" db:"current_value"`
+	PreviousCategory string     `json:"previous_category,omitempty" db:"previous_category"`
+	CurrentCategory  string     `json:"current_category,omitempty" db:"current_category"`
+	Severity         string     `json:"severity" db:"severity"`
+	Message          string     `json:"message" db:"message"`
+	Status           string     `json:"status" db:"status"`
+	AcknowledgedBy   *uuid.UUID `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	ResolvedBy       *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolutionNote   string     `json:"resolution_note,omitempty" db:"resolution_note"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Alert status constants
+const (
+	AlertStatusOpen         = "open"
+	AlertStatusAcknowledged = "acknowledged"
+	AlertStatusResolved     = "resolved"
+)
@@ -0,0 +1,46 @@
+package entity
+
+// ATCClass is a node of the ATC (Anatomical Therapeutic Chemical) classification: level 1 is the
+// anatomical main group (L), level 5 the chemical substance (L04AB04).
+type ATCClass struct {
+	Code       string `json:"code" db:"code"`
+	Level      int    `json:"level" db:"level"`
+	ParentCode string `json:"parent_code,omitempty" db:"parent_code"`
+	NameRU     string `json:"name_ru" db:"name_ru"`
+	NameEN     string `json:"name_en" db:"name_en"`
+	// DrugCount is the number of active catalogue drugs in the class, including its subclasses.
+	DrugCount int `json:"drug_count" db:"drug_count"`
+}
+
+// ATCClassDetail is a class with its ancestors (from level 1 down) and direct subclasses.
+type ATCClassDetail struct {
+	ATCClass
+	Path     []*ATCClass `json:"path"`
+	Children []*ATCClass `json:"children"`
+}
+
+// atcLevelLengths are the code lengths of ATC levels 1–5.
+var atcLevelLengths = [...]int{1, 3, 4, 5, 7}
+
+// ATCLevel returns the level (1–5) of a well-formed ATC code, or 0 for any other length.
+func ATCLevel(code string) int {
+	for i, n := range atcLevelLengths {
+		if len(code) == n {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ATCAncestors returns the codes of the classes above code, from level 1 down. A class contains
+// every code that starts with it, so ancestors are the prefixes at the shorter level lengths.
+func ATCAncestors(code string) []string {
+	var out []string
+	for _, n := range atcLevelLengths {
+		if n >= len(code) {
+			break
+		}
+		out = append(out, code[:n])
+	}
+	return out
+}
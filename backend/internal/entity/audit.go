@@ -31,15 +31,24 @@ const (
 	AuditActionDelete = "DELETE"
 	AuditActionLogin  = "LOGIN"
 	AuditActionLogout = "LOGOUT"
+	AuditActionReview = "REVIEW"
+	AuditActionRedact = "REDACT"
 )
 
 // Resource type constants
 const (
-	ResourceUser    = "user"
-	ResourcePatient = "patient"
-	ResourceSurvey  = "survey"
-	ResourceTherapy = "therapy"
-	ResourceDrug    = "drug"
+	ResourceUser              = "user"
+	ResourcePatient           = "patient"
+	ResourceSurvey            = "survey"
+	ResourceTherapy           = "therapy"
+	ResourceDrug              = "drug"
+	ResourceDrugInteraction   = "drug_interaction_rule"
+	ResourceDrugPerioperative = "drug_perioperative_rule"
+	ResourceAlert             = "alert"
+	ResourcePrompt            = "prompt_template"
+	ResourceAdvice            = "ai_advice"
+	ResourceQuota             = "llm_quota"
+	ResourceInsight           = "clinical_insight"
 )
 
 // AuditLogCreate represents data for creating an audit log entry
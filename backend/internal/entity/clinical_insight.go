@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClinicalInsight is clinician-facing AI output about one patient: an interpretation of the latest
+// scores or biologic therapy considerations. It is never shown to the patient.
+type ClinicalInsight struct {
+	ID          uuid.UUID  `json:"id"`
+	PatientID   uuid.UUID  `json:"patient_id"`
+	RequestedBy *uuid.UUID `json:"requested_by,omitempty"`
+	Kind        string     `json:"kind"`
+	Text        string     `json:"text"`
+	// InteractionText reviews interactions between the drugs in the therapy history (therapy insights only).
+	InteractionText string `json:"interaction_text,omitempty"`
+	// Context is the patient data the text was generated from.
+	Context  *ClinicalContext `json:"context"`
+	AIStatus string           `json:"ai_status,omitempty"`
+	// Prompt version the text was generated with; nil for the built-in prompt or the rule-based text.
+	PromptTemplateID *uuid.UUID `json:"prompt_template_id,omitempty"`
+	PromptVersion    int        `json:"prompt_version,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Clinical insight kinds
+const (
+	ClinicalInsightInterpretation = "interpretation"
+	ClinicalInsightTherapy        = "therapy"
+)
+
+// ClinicalContext is a snapshot of the diagnosis, latest indices and therapy history of a patient.
+type ClinicalContext struct {
+	Diagnosis     string            `json:"diagnosis,omitempty"`
+	DiagnosisDate *time.Time        `json:"diagnosis_date,omitempty"`
+	Indices       []ClinicalIndex   `json:"indices"`
+	Therapy       []ClinicalTherapy `json:"therapy"`
+}
+
+// ClinicalIndex is the latest measurement of one index type.
+type ClinicalIndex struct {
+	Type       string    `json:"type"`
+	Value      float64   `json:"value"`
+	Category   string    `json:"category,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+	// ActiveTemplate marks indices of a survey template that is still in use.
+	ActiveTemplate bool `json:"active_template,omitempty"`
+}
+
+// ClinicalTherapy is one therapy log entry as given to the model.
+type ClinicalTherapy struct {
+	Drug             string     `json:"drug"`
+	Status           string     `json:"status"`
+	AdministeredAt   *time.Time `json:"administered_at,omitempty"`
+	NextScheduled    *time.Time `json:"next_scheduled,omitempty"`
+	CycleNumber      int        `json:"cycle_number,omitempty"`
+	AdverseReactions string     `json:"adverse_reactions,omitempty"`
+}
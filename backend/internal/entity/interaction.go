@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interaction severities, most severe first.
+const (
+	InteractionContraindicated = "contraindicated"
+	InteractionMajor           = "major"
+	InteractionModerate        = "moderate"
+	InteractionMinor           = "minor"
+)
+
+// InteractionSeverityRank orders severities; a higher rank is more severe, unknown values rank 0.
+func InteractionSeverityRank(severity string) int {
+	switch severity {
+	case InteractionContraindicated:
+		return 4
+	case InteractionMajor:
+		return 3
+	case InteractionModerate:
+		return 2
+	case InteractionMinor:
+		return 1
+	}
+	return 0
+}
+
+// DrugInteractionRule describes an interaction between two sides, each either one catalogue drug
+// or an ATC class (every drug whose atc_code starts with the class code).
+type DrugInteractionRule struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	DrugAID    *uuid.UUID `json:"drug_a_id,omitempty" db:"drug_a_id"`
+	ATCCodeA   string     `json:"atc_code_a,omitempty" db:"atc_code_a"`
+	DrugBID    *uuid.UUID `json:"drug_b_id,omitempty" db:"drug_b_id"`
+	ATCCodeB   string     `json:"atc_code_b,omitempty" db:"atc_code_b"`
+	Severity   string     `json:"severity" db:"severity"`
+	Mechanism  string     `json:"mechanism" db:"mechanism"`
+	Management string     `json:"management" db:"management"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DrugInteractionRuleCreate is the request body for a new rule.
+type DrugInteractionRuleCreate struct {
+	DrugAID    *uuid.UUID `json:"drug_a_id,omitempty"`
+	ATCCodeA   string     `json:"atc_code_a,omitempty"`
+	DrugBID    *uuid.UUID `json:"drug_b_id,omitempty"`
+	ATCCodeB   string     `json:"atc_code_b,omitempty"`
+	Severity   string     `json:"severity"`
+	Mechanism  string     `json:"mechanism"`
+	Management string     `json:"management"`
+}
+
+// InteractionDrug identifies one drug of a detected interaction.
+type InteractionDrug struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	ATCCode string    `json:"atc_code,omitempty"`
+}
+
+// DrugInteraction is a rule that matched a pair of drugs.
+type DrugInteraction struct {
+	RuleID     uuid.UUID       `json:"rule_id"`
+	Severity   string          `json:"severity"`
+	DrugA      InteractionDrug `json:"drug_a"`
+	DrugB      InteractionDrug `json:"drug_b"`
+	Mechanism  string          `json:"mechanism"`
+	Management string          `json:"management"`
+}
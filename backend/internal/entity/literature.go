@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// PubMedAbstract is a cached PubMed article used as evidence for generated advice.
+type PubMedAbstract struct {
+	PMID      string    `json:"pmid" db:"pmid"`
+	Title     string    `json:"title" db:"title"`
+	Abstract  string    `json:"abstract" db:"abstract"`
+	Journal   string    `json:"journal,omitempty" db:"journal"`
+	PubYear   string    `json:"pub_year,omitempty" db:"pub_year"`
+	FetchedAt time.Time `json:"fetched_at" db:"fetched_at"`
+}
+
+// PubMedQuery is a cached ESearch result: the PMIDs found for a query term, in relevance order.
+type PubMedQuery struct {
+	Term      string    `json:"term" db:"term"`
+	PMIDs     []string  `json:"pmids" db:"pmids"`
+	FetchedAt time.Time `json:"fetched_at" db:"fetched_at"`
+}
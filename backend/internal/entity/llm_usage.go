@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LLMUsage is one completion call: who triggered it, for which survey, and what it cost.
+type LLMUsage struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	UserID       *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	SurveyCode   string     `json:"survey_code,omitempty" db:"survey_code"`
+	Purpose      string     `json:"purpose,omitempty" db:"purpose"`
+	Model        string     `json:"model" db:"model"`
+	InputTokens  int        `json:"input_tokens" db:"input_tokens"`
+	OutputTokens int        `json:"output_tokens" db:"output_tokens"`
+	LatencyMS    int        `json:"latency_ms" db:"latency_ms"`
+	Status       string     `json:"status" db:"status"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LLM usage statuses
+const (
+	LLMUsageOK    = "ok"
+	LLMUsageError = "error"
+)
+
+// LLMUsageTotals is a user's consumption within a period.
+type LLMUsageTotals struct {
+	Requests int `json:"requests"`
+	Tokens   int `json:"tokens"`
+}
+
+// LLMUsageReportRow aggregates consumption for one day, survey code and model.
+type LLMUsageReportRow struct {
+	Day          string `json:"day"`
+	SurveyCode   string `json:"survey_code,omitempty"`
+	Model        string `json:"model"`
+	Requests     int    `json:"requests"`
+	Errors       int    `json:"errors"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	AvgLatencyMS int    `json:"avg_latency_ms"`
+}
+
+// LLMQuota limits daily LLM consumption for a role or, as an override, for one user.
+// A nil limit means unlimited.
+type LLMQuota struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	RoleID        *uuid.UUID `json:"role_id,omitempty" db:"role_id"`
+	RoleName      string     `json:"role,omitempty" db:"-"`
+	UserID        *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	DailyTokens   *int       `json:"daily_tokens" db:"daily_tokens"`
+	DailyRequests *int       `json:"daily_requests" db:"daily_requests"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// LLMQuotaUpsert sets the limits for either a role (by name) or a user.
+type LLMQuotaUpsert struct {
+	Role          string     `json:"role"`
+	UserID        *uuid.UUID `json:"user_id"`
+	DailyTokens   *int       `json:"daily_tokens"`
+	DailyRequests *int       `json:"daily_requests"`
+}
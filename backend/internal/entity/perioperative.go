@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Perioperative actions for one drug.
+const (
+	PerioperativeHold     = "hold"
+	PerioperativeContinue = "continue"
+	// PerioperativeNoRule marks a current therapy without a rule; the surgeon decides.
+	PerioperativeNoRule = "no_rule"
+)
+
+// Sources of the dosing interval a hold period is computed from.
+const (
+	IntervalSourceRule     = "rule"
+	IntervalSourceObserved = "observed"
+)
+
+// Kinds of perioperative timeline events.
+const (
+	PerioperativeEventLastDose      = "last_dose"
+	PerioperativeEventLastDoseBy    = "last_dose_by"
+	PerioperativeEventScheduledDose = "scheduled_dose"
+	PerioperativeEventSurgery       = "surgery"
+	PerioperativeEventResume        = "resume"
+)
+
+// DrugPerioperativeRule tells how long a drug is held before surgery: the last dose must be at
+// least HoldIntervals dosing intervals before the operation (0 means the drug is continued), and
+// it is restarted ResumeAfterDays after surgery once the wound has healed. A rule names either one
+// catalogue drug or an ATC class; a drug rule wins over a class, a longer class code over a shorter one.
+type DrugPerioperativeRule struct {
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	DrugID             *uuid.UUID `json:"drug_id,omitempty" db:"drug_id"`
+	ATCCode            string     `json:"atc_code,omitempty" db:"atc_code"`
+	DosingIntervalDays int        `json:"dosing_interval_days" db:"dosing_interval_days"`
+	HoldIntervals      float64    `json:"hold_intervals" db:"hold_intervals"`
+	ResumeAfterDays    int        `json:"resume_after_days" db:"resume_after_days"`
+	Guidance           string     `json:"guidance" db:"guidance"`
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DrugPerioperativeRuleSet is the request body that creates or replaces the rule of a drug or class.
+type DrugPerioperativeRuleSet struct {
+	DrugID             *uuid.UUID `json:"drug_id,omitempty"`
+	ATCCode            string     `json:"atc_code,omitempty"`
+	DosingIntervalDays int        `json:"dosing_interval_days"`
+	HoldIntervals      float64    `json:"hold_intervals"`
+	ResumeAfterDays    int        `json:"resume_after_days"`
+	Guidance           string     `json:"guidance"`
+}
+
+// PerioperativeDose is a planned administration of a current therapy before the drug is resumed.
+type PerioperativeDose struct {
+	LogID        uuid.UUID `json:"log_id"`
+	DrugID       uuid.UUID `json:"drug_id"`
+	DrugName     string    `json:"drug_name"`
+	Date         time.Time `json:"date"`
+	InHoldWindow bool      `json:"in_hold_window"`
+}
+
+// PerioperativeDrugPlan is the hold/resume schedule of one current therapy. Dates are calendar days
+// (midnight UTC). For a held drug the last dose is due by LastDoseBy and the drug restarts on
+// ResumeOn; a dose between the two falls inside the hold window. EarliestSurgeryDate is set when the
+// last recorded dose is already too close to the planned operation.
+type PerioperativeDrugPlan struct {
+	Drug                InteractionDrug     `json:"drug"`
+	Action              string              `json:"action"`
+	RuleID              *uuid.UUID          `json:"rule_id,omitempty"`
+	Guidance            string              `json:"guidance,omitempty"`
+	DosingIntervalDays  int                 `json:"dosing_interval_days,omitempty"`
+	IntervalSource      string              `json:"interval_source,omitempty"`
+	HoldDays            int                 `json:"hold_days"`
+	LastDoseAt          *time.Time          `json:"last_dose_at,omitempty"`
+	LastDoseBy          *time.Time          `json:"last_dose_by,omitempty"`
+	ResumeOn            *time.Time          `json:"resume_on,omitempty"`
+	EarliestSurgeryDate *time.Time          `json:"earliest_surgery_date,omitempty"`
+	ScheduledDoses      []PerioperativeDose `json:"scheduled_doses"`
+}
+
+// PerioperativeEvent is one dated entry of the combined timeline.
+type PerioperativeEvent struct {
+	Date         time.Time  `json:"date"`
+	Kind         string     `json:"kind"`
+	DrugID       *uuid.UUID `json:"drug_id,omitempty"`
+	DrugName     string     `json:"drug_name,omitempty"`
+	LogID        *uuid.UUID `json:"log_id,omitempty"`
+	InHoldWindow bool       `json:"in_hold_window,omitempty"`
+}
+
+// PerioperativePlan is the hold/resume guidance for a patient's current therapies around a planned
+// operation. FlaggedDoses lists the scheduled doses that fall inside a hold window.
+type PerioperativePlan struct {
+	PatientID    uuid.UUID               `json:"patient_id"`
+	SurgeryDate  time.Time               `json:"surgery_date"`
+	Drugs        []PerioperativeDrugPlan `json:"drugs"`
+	Timeline     []PerioperativeEvent    `json:"timeline"`
+	FlaggedDoses []PerioperativeDose     `json:"flagged_doses"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptTemplate is one version of an LLM system prompt. A version is used for generation only
+// after a clinical reviewer approves it; approving a version retires the previously approved one.
+type PromptTemplate struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Key           string     `json:"key" db:"key"`
+	SurveyCode    string     `json:"survey_code,omitempty" db:"survey_code"`
+	Version       int        `json:"version" db:"version"`
+	SystemPrompt  string     `json:"system_prompt" db:"system_prompt"`
+	Status        string     `json:"status" db:"status"`
+	Notes         string     `json:"notes,omitempty" db:"notes"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	ReviewedBy    *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewComment string     `json:"review_comment,omitempty" db:"review_comment"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// Prompt keys
+const (
+	PromptPatientAdvice         = "patient_advice"
+	PromptSurveyInterpretation  = "survey_interpretation"
+	PromptTherapyRecommendation = "therapy_recommendation"
+	PromptDrugInteraction       = "drug_interaction"
+)
+
+// Prompt template status constants
+const (
+	PromptStatusDraft    = "draft"
+	PromptStatusApproved = "approved"
+	PromptStatusRejected = "rejected"
+	PromptStatusRetired  = "retired"
+)
+
+// PromptTemplateCreate is a request to add a new draft version.
+type PromptTemplateCreate struct {
+	Key          string `json:"key"`
+	SurveyCode   string `json:"survey_code,omitempty"`
+	SystemPrompt string `json:"system_prompt"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// PromptTemplateReview approves or rejects a draft version.
+type PromptTemplateReview struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment,omitempty"`
+}
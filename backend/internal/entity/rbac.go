@@ -25,10 +25,11 @@ type Permission struct {
 
 // Common role names
 const (
-	RoleAdmin   = "admin"
-	RoleDoctor  = "doctor"
-	RoleNurse   = "nurse"
-	RolePatient = "patient"
+	RoleAdmin      = "admin"
+	RoleDoctor     = "doctor"
+	RoleNurse      = "nurse"
+	RolePatient    = "patient"
+	RolePharmacist = "pharmacist"
 )
 
 // Common permission names
@@ -60,16 +61,20 @@ const (
 	PermDrugsRead  = "drugs:read"
 	PermDrugsWrite = "drugs:write"
 
+	// Clinician-facing AI output
+	PermAIClinical = "ai:clinical"
+
 	// Admin
 	PermAdminFull = "admin:full"
 )
 
 // Default role UUIDs (matching migration seed data)
 var (
-	AdminRoleID   = uuid.MustParse("11111111-1111-1111-1111-111111111111")
-	DoctorRoleID  = uuid.MustParse("22222222-2222-2222-2222-222222222222")
-	NurseRoleID   = uuid.MustParse("33333333-3333-3333-3333-333333333333")
-	PatientRoleID = uuid.MustParse("44444444-4444-4444-4444-444444444444")
+	AdminRoleID      = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	DoctorRoleID     = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	NurseRoleID      = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	PatientRoleID    = uuid.MustParse("44444444-4444-4444-4444-444444444444")
+	PharmacistRoleID = uuid.MustParse("55555555-5555-5555-5555-555555555555")
 )
 
 // GetRoleIDByName returns the UUID for a role name
@@ -83,6 +88,8 @@ func GetRoleIDByName(name string) uuid.UUID {
 		return NurseRoleID
 	case RolePatient:
 		return PatientRoleID
+	case RolePharmacist:
+		return PharmacistRoleID
 	default:
 		return uuid.Nil
 	}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RescoreJob re-runs the scoring engine over stored responses of one template.
+// Results are only written back after explicit confirmation.
+type RescoreJob struct {
+	ID                   uuid.UUID       `json:"id" db:"id"`
+	TemplateID           uuid.UUID       `json:"template_id" db:"template_id"`
+	TemplateVersion      int             `json:"template_version" db:"template_version"`
+	RequestedBy          *uuid.UUID      `json:"requested_by,omitempty" db:"requested_by"`
+	Status               string          `json:"status" db:"status"`
+	TotalCount           int             `json:"total_count" db:"total_count"`
+	ChangedCount         int             `json:"changed_count" db:"changed_count"`
+	CategoryChangedCount int             `json:"category_changed_count" db:"category_changed_count"`
+	Report               json.RawMessage `json:"report,omitempty" db:"report"`
+	Error                string          `json:"error,omitempty" db:"error"`
+	ConfirmedBy          *uuid.UUID      `json:"confirmed_by,omitempty" db:"confirmed_by"`
+	ConfirmedAt          *time.Time      `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
+	FinishedAt           *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+	AppliedAt            *time.Time      `json:"applied_at,omitempty" db:"applied_at"`
+	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Rescore job status constants
+const (
+	RescoreStatusPending  = "pending"
+	RescoreStatusRunning  = "running"
+	RescoreStatusReady    = "ready"
+	RescoreStatusApplying = "applying"
+	RescoreStatusApplied  = "applied"
+	RescoreStatusFailed   = "failed"
+)
+
+// RescoreJobItem is a response whose score or category differs from the stored value
+type RescoreJobItem struct {
+	ID               uuid.UUID       `json:"id" db:"id"`
+	JobID            uuid.UUID       `json:"job_id" db:"job_id"`
+	SurveyResponseID uuid.UUID       `json:"survey_response_id" db:"survey_response_id"`
+	OldScore         *float64        `json:"old_score,omitempty" db:"old_score"`
+	NewScore         float64         `json:"new_score" db:"new_score"`
+	OldCategory      string          `json:"old_category,omitempty" db:"old_category"`
+	NewCategory      string          `json:"new_category" db:"new_category"`
+	OldBreakdown     json.RawMessage `json:"old_breakdown,omitempty" db:"old_breakdown"`
+	NewBreakdown     json.RawMessage `json:"new_breakdown,omitempty" db:"new_breakdown"`
+	CategoryChanged  bool            `json:"category_changed" db:"category_changed"`
+}
+
+// RescoreReport summarises category transitions found by a rescore job
+type RescoreReport struct {
+	Transitions map[string]int `json:"transitions"` // "old_category -> new_category": count
+}
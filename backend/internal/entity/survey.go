@@ -105,25 +105,36 @@ type InterpretationRules struct {
 
 // SurveyResponse represents a patient's survey submission
 type SurveyResponse struct {
-	ID              uuid.UUID       `json:"id" db:"id"`
-	TemplateID      uuid.UUID       `json:"template_id" db:"template_id"`
-	PatientID       uuid.UUID       `json:"patient_id" db:"patient_id"`
-	Responses       json.RawMessage `json:"responses" db:"responses"`
-	CalculatedScore *float64        `json:"calculated_score,omitempty" db:"calculated_score"`
-	ScoreBreakdown  json.RawMessage `json:"score_breakdown,omitempty" db:"score_breakdown"`
-	Interpretation  string          `json:"interpretation,omitempty" db:"interpretation"`
-	AISummary       string          `json:"ai_summary,omitempty" db:"ai_summary"`
-	Status          string          `json:"status" db:"status"`
-	SubmittedAt     time.Time       `json:"submitted_at" db:"submitted_at"`
-	ReviewedBy      *uuid.UUID      `json:"reviewed_by,omitempty" db:"reviewed_by"`
-	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
-	Notes           string          `json:"notes,omitempty" db:"notes"`
-	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	ID               uuid.UUID       `json:"id" db:"id"`
+	TemplateID       uuid.UUID       `json:"template_id" db:"template_id"`
+	PatientID        uuid.UUID       `json:"patient_id" db:"patient_id"`
+	Responses        json.RawMessage `json:"responses" db:"responses"`
+	CalculatedScore  *float64        `json:"calculated_score,omitempty" db:"calculated_score"`
+	ScoreBreakdown   json.RawMessage `json:"score_breakdown,omitempty" db:"score_breakdown"`
+	Interpretation   string          `json:"interpretation,omitempty" db:"interpretation"`
+	Category         string          `json:"category,omitempty" db:"category"`
+	CategoryOverride string          `json:"category_override,omitempty" db:"category_override"`
+	OverrideReason   string          `json:"override_reason,omitempty" db:"override_reason"`
+	AISummary        string          `json:"ai_summary,omitempty" db:"ai_summary"`
+	AIStatus         string          `json:"ai_status,omitempty" db:"ai_status"`
+	Status           string          `json:"status" db:"status"`
+	Revision         int             `json:"revision" db:"revision"`
+	SubmittedAt      time.Time       `json:"submitted_at" db:"submitted_at"`
+	ReviewedBy       *uuid.UUID      `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt       *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	Notes            string          `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 
 	// Joined fields
 	Template *SurveyTemplate `json:"template,omitempty"`
 	Patient  *Patient        `json:"patient,omitempty"`
 	Reviewer *User           `json:"reviewer,omitempty"`
+
+	// History, only loaded on request
+	Revisions []*SurveyResponseRevision `json:"revisions,omitempty"`
+
+	// Set when AI interpretation was queued by this request
+	AIJobID *uuid.UUID `json:"ai_job_id,omitempty"`
 }
 
 // Response status constants
@@ -139,6 +150,40 @@ type SurveyResponseCreate struct {
 	PatientID  uuid.UUID              `json:"patient_id" validate:"required"`
 	Responses  map[string]interface{} `json:"responses" validate:"required"`
 	Status     string                 `json:"status,omitempty"`
+
+	SubmittedBy uuid.UUID `json:"-"` // authenticated user, set by the handler
+}
+
+// SurveyReviewRequest represents a clinician's review of a submitted response.
+// OverrideCategory replaces the engine category and requires a Justification.
+type SurveyReviewRequest struct {
+	Notes            string `json:"notes,omitempty"`
+	OverrideCategory string `json:"override_category,omitempty"`
+	Justification    string `json:"justification,omitempty"`
+}
+
+// SurveyResponseAmendRequest replaces the answers of a stored response. Reason is mandatory.
+// ExpectedRevision, when set, rejects the amendment if someone else amended the response first.
+type SurveyResponseAmendRequest struct {
+	Responses        map[string]interface{} `json:"responses" validate:"required"`
+	Reason           string                 `json:"reason" validate:"required"`
+	ExpectedRevision int                    `json:"expected_revision,omitempty"`
+}
+
+// SurveyResponseRevision is an immutable record of one amendment to a survey response
+type SurveyResponseRevision struct {
+	ID               uuid.UUID       `json:"id" db:"id"`
+	SurveyResponseID uuid.UUID       `json:"survey_response_id" db:"survey_response_id"`
+	Revision         int             `json:"revision" db:"revision"`
+	AmendedBy        uuid.UUID       `json:"amended_by" db:"amended_by"`
+	Reason           string          `json:"reason" db:"reason"`
+	OldResponses     json.RawMessage `json:"old_responses" db:"old_responses"`
+	NewResponses     json.RawMessage `json:"new_responses" db:"new_responses"`
+	OldScore         *float64        `json:"old_score,omitempty" db:"old_score"`
+	NewScore         float64         `json:"new_score" db:"new_score"`
+	OldCategory      string          `json:"old_category,omitempty" db:"old_category"`
+	NewCategory      string          `json:"new_category" db:"new_category"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 }
 
 // SurveyResponseFilter represents filter options for survey responses
@@ -152,6 +197,14 @@ type SurveyResponseFilter struct {
 	PerPage    int        `query:"per_page"`
 }
 
+// EffectiveCategory returns the clinician override if present, otherwise the engine category.
+func (r *SurveyResponse) EffectiveCategory() string {
+	if r.CategoryOverride != "" {
+		return r.CategoryOverride
+	}
+	return r.Category
+}
+
 // GetSections parses the questions JSON into sections
 func (t *SurveyTemplate) GetSections() ([]SurveySection, error) {
 	var sections []SurveySection
@@ -58,6 +58,9 @@ type TherapyLog struct {
 	Patient        *Patient `json:"patient,omitempty"`
 	Drug           *Drug    `json:"drug,omitempty"`
 	AdministeredBy *User    `json:"administered_by_user,omitempty"`
+
+	// Interactions with the patient's active therapies, reported when the log is created.
+	Interactions []DrugInteraction `json:"interactions,omitempty"`
 }
 
 // TherapyLog status constants
@@ -156,3 +159,34 @@ type CalendarEvent struct {
 	ScheduledAt time.Time `json:"scheduled_at"`
 	Status      string    `json:"status"`
 }
+
+// IndexTrendPoint is a single measurement in an index time series.
+type IndexTrendPoint struct {
+	MedicalIndexID    uuid.UUID  `json:"medical_index_id"`
+	SurveyResponseID  *uuid.UUID `json:"survey_response_id,omitempty"`
+	RecordedAt        time.Time  `json:"recorded_at"`
+	Value             float64    `json:"value"`
+	Category          string     `json:"category,omitempty"`
+	Delta             *float64   `json:"delta,omitempty"`     // change vs. previous point
+	DeltaFromBaseline float64    `json:"delta_from_baseline"` // change vs. first point
+	CategoryChanged   bool       `json:"category_changed,omitempty"`
+}
+
+// CategoryTransition records a change of index category between two consecutive measurements.
+type CategoryTransition struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	ValueDelta float64   `json:"value_delta"`
+}
+
+// IndexTrend is the time series of one index type for a patient.
+type IndexTrend struct {
+	PatientID   uuid.UUID            `json:"patient_id"`
+	IndexType   string               `json:"index_type"`
+	Points      []IndexTrendPoint    `json:"points"`
+	Transitions []CategoryTransition `json:"transitions"`
+	Baseline    *IndexTrendPoint     `json:"baseline,omitempty"`
+	Latest      *IndexTrendPoint     `json:"latest,omitempty"`
+	TotalDelta  float64              `json:"total_delta"`
+}
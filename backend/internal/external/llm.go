@@ -0,0 +1,98 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LLMProvider is a text-generation backend (YandexGPT, an OpenAI-compatible server or the local stub).
+type LLMProvider interface {
+	// Complete runs a chat completion over the given messages.
+	Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error)
+	// GenerateText is a convenience wrapper for a single system + user prompt.
+	GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+	// Model returns the provider-qualified model name, e.g. "yandexgpt/yandexgpt-lite".
+	Model() string
+}
+
+// StreamingLLMProvider is implemented by providers that can relay text while it is generated.
+type StreamingLLMProvider interface {
+	LLMProvider
+	// Stream calls onDelta with each new piece of text and returns the full completion at the end.
+	// An error returned by onDelta aborts the stream.
+	Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error)
+}
+
+// StructuredLLMProvider is implemented by providers that can constrain the answer to a JSON schema.
+type StructuredLLMProvider interface {
+	LLMProvider
+	// CompleteJSON runs a chat completion whose text is a JSON document matching schema.
+	CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error)
+}
+
+// Completion is the provider-neutral result of a completion request.
+type Completion struct {
+	Text         string `json:"text"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// Default generation parameters used by GenerateText and streaming callers.
+const (
+	DefaultTemperature = 0.3
+	DefaultMaxTokens   = 2000
+)
+
+// generateText implements GenerateText on top of Complete for any provider.
+func generateText(ctx context.Context, p LLMProvider, systemPrompt, userPrompt string) (string, error) {
+	messages := []Message{
+		{Role: "system", Text: systemPrompt},
+		{Role: "user", Text: userPrompt},
+	}
+
+	resp, err := p.Complete(ctx, messages, DefaultTemperature, DefaultMaxTokens)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// InterpretSurvey generates AI interpretation of survey results.
+// systemPrompt is usually MedicalSummaryPrompts.SurveyInterpretation or an approved registry version of it.
+func InterpretSurvey(ctx context.Context, p LLMProvider, systemPrompt string, surveyType string, score float64, breakdown map[string]any) (string, error) {
+	userPrompt := fmt.Sprintf(
+		"Опросник: %s\nИтоговый балл: %.2f\nДетали: %v\n\nДай краткую интерпретацию результатов для врача.",
+		surveyType, score, breakdown,
+	)
+	return p.GenerateText(ctx, systemPrompt, userPrompt)
+}
+
+// InterpretPatientScores generates a clinician-level interpretation of a patient's latest indices.
+// systemPrompt is usually MedicalSummaryPrompts.SurveyInterpretation or an approved registry version of it.
+func InterpretPatientScores(ctx context.Context, p LLMProvider, systemPrompt string, diagnosis string, scores []string, therapy []string) (string, error) {
+	userPrompt := fmt.Sprintf(
+		"Диагноз: %s\nПоследние индексы:\n%s\nТерапия: %s\n\nДай интерпретацию динамики и активности заболевания для врача.",
+		diagnosis, strings.Join(scores, "\n"), strings.Join(therapy, "; "),
+	)
+	return p.GenerateText(ctx, systemPrompt, userPrompt)
+}
+
+// RecommendTherapy generates therapy recommendations based on patient data.
+// systemPrompt is usually MedicalSummaryPrompts.TherapyRecommendation or an approved registry version of it.
+func RecommendTherapy(ctx context.Context, p LLMProvider, systemPrompt string, diagnosis string, activityIndex string, activityScore float64, previousTherapy []string) (string, error) {
+	userPrompt := fmt.Sprintf(
+		"Диагноз: %s\nТекущий индекс (%s): %.2f\nПредыдущая терапия: %s\n\nПредложи варианты ГИБП-терапии.",
+		diagnosis, activityIndex, activityScore, strings.Join(previousTherapy, "; "),
+	)
+	return p.GenerateText(ctx, systemPrompt, userPrompt)
+}
+
+// CheckDrugInteractions reviews clinically significant interactions between the given drugs.
+// systemPrompt is usually MedicalSummaryPrompts.DrugInteraction or an approved registry version of it.
+func CheckDrugInteractions(ctx context.Context, p LLMProvider, systemPrompt string, drugs []string) (string, error) {
+	userPrompt := fmt.Sprintf("Препараты: %s\n\nОцени возможные взаимодействия.", strings.Join(drugs, ", "))
+	return p.GenerateText(ctx, systemPrompt, userPrompt)
+}
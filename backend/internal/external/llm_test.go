@@ -0,0 +1,147 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStubLLMDeterministic(t *testing.T) {
+	stub := NewStubLLM()
+	ctx := context.Background()
+
+	a, err := stub.GenerateText(ctx, "system", "Опросник: RCRI\nБалл: 2")
+	if err != nil {
+		t.Fatalf("GenerateText() error = %v", err)
+	}
+	b, _ := stub.GenerateText(ctx, "system", "Опросник: RCRI\nБалл: 2")
+	c, _ := stub.GenerateText(ctx, "system", "Опросник: ASA\nБалл: 3")
+
+	if a != b {
+		t.Errorf("same prompt produced different text:\n%s\n---\n%s", a, b)
+	}
+	if a == c {
+		t.Errorf("different prompts produced identical text")
+	}
+	if !strings.Contains(a, "Опросник: RCRI") {
+		t.Errorf("stub text does not echo the prompt subject: %s", a)
+	}
+}
+
+func TestOpenAICompatibleClientComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Model != "llama" || len(req.Messages) != 2 || req.Messages[1].Content != "hello" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		_, _ = w.Write([]byte(`{"model":"llama","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":7,"completion_tokens":2}}`))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAICompatibleClient(srv.URL+"/v1/", "secret", "llama")
+	resp, err := client.Complete(context.Background(), []Message{{Role: "system", Text: "sys"}, {Role: "user", Text: "hello"}}, 0.2, 100)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Text != "hi" || resp.InputTokens != 7 || resp.OutputTokens != 2 || resp.Model != "openai/llama" {
+		t.Errorf("Complete() = %+v", resp)
+	}
+}
+
+func TestOpenAICompatibleClientStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("stream not requested: %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Здрав\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"ствуйте\"}}]}\n\n" +
+			": keep-alive\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":3}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAICompatibleClient(srv.URL, "", "llama")
+	var deltas []string
+	resp, err := client.Stream(context.Background(), []Message{{Role: "user", Text: "hello"}}, 0.2, 100, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(deltas) != 2 || resp.Text != "Здравствуйте" || resp.InputTokens != 5 || resp.OutputTokens != 3 {
+		t.Errorf("Stream() = %+v, deltas %q", resp, deltas)
+	}
+}
+
+func TestWithUsageRecorder(t *testing.T) {
+	var got []Usage
+	metered := WithUsageRecorder(NewStubLLM(), func(ctx context.Context, u Usage) { got = append(got, u) })
+
+	if _, ok := metered.(StreamingLLMProvider); !ok {
+		t.Fatalf("metered stub lost streaming support")
+	}
+	if _, ok := metered.(StructuredLLMProvider); !ok {
+		t.Fatalf("metered stub lost structured output support")
+	}
+	if _, err := metered.GenerateText(context.Background(), "system", "Опросник: ASA"); err != nil {
+		t.Fatalf("GenerateText() error = %v", err)
+	}
+	_, err := metered.(StreamingLLMProvider).Stream(context.Background(), []Message{{Role: "user", Text: "hi"}}, 0.3, 100, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("recorded %d completions, want 2", len(got))
+	}
+	for _, u := range got {
+		if u.Model != "stub/deterministic" || u.InputTokens == 0 || u.OutputTokens == 0 || u.Err != nil {
+			t.Errorf("usage = %+v", u)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := metered.Complete(ctx, nil, 0.3, 100); err == nil {
+		t.Fatalf("Complete() with cancelled context succeeded")
+	}
+	if last := got[len(got)-1]; last.Err == nil || last.Model != "stub/deterministic" {
+		t.Errorf("failed call usage = %+v", last)
+	}
+
+	plain := WithUsageRecorder(plainProvider{}, nil)
+	if _, ok := plain.(StreamingLLMProvider); ok {
+		t.Errorf("metered provider claims streaming the wrapped provider lacks")
+	}
+}
+
+type plainProvider struct{}
+
+func (plainProvider) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error) {
+	return &Completion{Text: "ok"}, nil
+}
+
+func (p plainProvider) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return generateText(ctx, p, systemPrompt, userPrompt)
+}
+
+func (plainProvider) Model() string { return "plain" }
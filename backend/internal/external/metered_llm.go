@@ -0,0 +1,109 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Usage describes one completion call for accounting.
+type Usage struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration
+	// Err is the call's error; token counts are zero for failed calls.
+	Err error
+}
+
+// UsageRecorder receives every completion made through a metered provider.
+type UsageRecorder func(ctx context.Context, u Usage)
+
+// WithUsageRecorder wraps a provider so every completion, streamed or not, is reported to record.
+// The wrapper supports streaming and structured output exactly when the wrapped provider does.
+func WithUsageRecorder(p LLMProvider, record UsageRecorder) LLMProvider {
+	m := &meteredLLM{p: p, record: record}
+	_, streams := p.(StreamingLLMProvider)
+	_, structured := p.(StructuredLLMProvider)
+	switch {
+	case streams && structured:
+		return meteredFullLLM{m}
+	case streams:
+		return meteredStreamingLLM{m}
+	case structured:
+		return meteredStructuredLLM{m}
+	default:
+		return m
+	}
+}
+
+type meteredLLM struct {
+	p      LLMProvider
+	record UsageRecorder
+}
+
+func (m *meteredLLM) Model() string {
+	return m.p.Model()
+}
+
+func (m *meteredLLM) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error) {
+	start := time.Now()
+	resp, err := m.p.Complete(ctx, messages, temperature, maxTokens)
+	m.report(ctx, start, resp, err)
+	return resp, err
+}
+
+func (m *meteredLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return generateText(ctx, m, systemPrompt, userPrompt)
+}
+
+func (m *meteredLLM) stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	start := time.Now()
+	resp, err := m.p.(StreamingLLMProvider).Stream(ctx, messages, temperature, maxTokens, onDelta)
+	m.report(ctx, start, resp, err)
+	return resp, err
+}
+
+func (m *meteredLLM) completeJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	start := time.Now()
+	resp, err := m.p.(StructuredLLMProvider).CompleteJSON(ctx, messages, schema, temperature, maxTokens)
+	m.report(ctx, start, resp, err)
+	return resp, err
+}
+
+func (m *meteredLLM) report(ctx context.Context, start time.Time, resp *Completion, err error) {
+	if m.record == nil {
+		return
+	}
+	u := Usage{Model: m.p.Model(), Latency: time.Since(start), Err: err}
+	if resp != nil {
+		if resp.Model != "" {
+			u.Model = resp.Model
+		}
+		u.InputTokens = resp.InputTokens
+		u.OutputTokens = resp.OutputTokens
+	}
+	m.record(ctx, u)
+}
+
+type meteredStreamingLLM struct{ *meteredLLM }
+
+func (m meteredStreamingLLM) Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	return m.stream(ctx, messages, temperature, maxTokens, onDelta)
+}
+
+type meteredStructuredLLM struct{ *meteredLLM }
+
+func (m meteredStructuredLLM) CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	return m.completeJSON(ctx, messages, schema, temperature, maxTokens)
+}
+
+type meteredFullLLM struct{ *meteredLLM }
+
+func (m meteredFullLLM) Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	return m.stream(ctx, messages, temperature, maxTokens, onDelta)
+}
+
+func (m meteredFullLLM) CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	return m.completeJSON(ctx, messages, schema, temperature, maxTokens)
+}
@@ -3,9 +3,12 @@ package external
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"html"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -28,7 +31,7 @@ func NewNCBIClient(apiKey string) *NCBIClient {
 		rps = 10.0
 	}
 	return &NCBIClient{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: NewHTTPClient(30 * time.Second),
 		apiKey:     apiKey,
 		limiter:    rate.NewLimiter(rate.Limit(rps), 1),
 		baseURL:    "https://eutils.ncbi.nlm.nih.gov/entrez/eutils",
@@ -263,3 +266,111 @@ func (c *NCBIClient) ESummary(ctx context.Context, db string, ids []string) ([]P
 
 	return articles, nil
 }
+
+// PubMedAbstract is an article with its abstract text, as returned by EFetch.
+type PubMedAbstract struct {
+	PMID     string `json:"pmid"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	Journal  string `json:"journal"`
+	PubYear  string `json:"pub_year,omitempty"`
+}
+
+type eFetchArticleSet struct {
+	Articles []struct {
+		PMID    string `xml:"MedlineCitation>PMID"`
+		Article struct {
+			Title struct {
+				Text string `xml:",innerxml"`
+			} `xml:"ArticleTitle"`
+			Journal struct {
+				Title   string `xml:"Title"`
+				PubDate struct {
+					Year        string `xml:"Year"`
+					MedlineDate string `xml:"MedlineDate"`
+				} `xml:"JournalIssue>PubDate"`
+			} `xml:"Journal"`
+			AbstractText []struct {
+				Label string `xml:"Label,attr"`
+				Text  string `xml:",innerxml"`
+			} `xml:"Abstract>AbstractText"`
+		} `xml:"MedlineCitation>Article"`
+	} `xml:"PubmedArticle"`
+}
+
+var eFetchTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// eFetchText flattens element content that may contain inline markup such as <i> or <sup>.
+func eFetchText(inner string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(eFetchTagPattern.ReplaceAllString(inner, ""))), " ")
+}
+
+// EFetchAbstracts fetches titles and abstracts for PMIDs. Structured abstracts keep their section
+// labels ("CONCLUSIONS: ..."), one section per line. Articles without an abstract are returned with an empty one.
+func (c *NCBIClient) EFetchAbstracts(ctx context.Context, pmids []string) ([]PubMedAbstract, error) {
+	if len(pmids) == 0 {
+		return nil, nil
+	}
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("db", "pubmed")
+	params.Set("id", strings.Join(pmids, ","))
+	params.Set("rettype", "abstract")
+	params.Set("retmode", "xml")
+	if c.apiKey != "" {
+		params.Set("api_key", c.apiKey)
+	}
+
+	reqURL := fmt.Sprintf("%s/efetch.fcgi?%s", c.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("efetch: status %d", resp.StatusCode)
+	}
+
+	var set eFetchArticleSet
+	if err := xml.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("efetch: decode: %w", err)
+	}
+
+	out := make([]PubMedAbstract, 0, len(set.Articles))
+	for _, a := range set.Articles {
+		parts := make([]string, 0, len(a.Article.AbstractText))
+		for _, p := range a.Article.AbstractText {
+			text := eFetchText(p.Text)
+			if text == "" {
+				continue
+			}
+			if p.Label != "" {
+				text = p.Label + ": " + text
+			}
+			parts = append(parts, text)
+		}
+		year := a.Article.Journal.PubDate.Year
+		if year == "" && len(a.Article.Journal.PubDate.MedlineDate) >= 4 {
+			year = a.Article.Journal.PubDate.MedlineDate[:4]
+		}
+		out = append(out, PubMedAbstract{
+			PMID:     strings.TrimSpace(a.PMID),
+			Title:    eFetchText(a.Article.Title.Text),
+			Abstract: strings.Join(parts, "\n"),
+			Journal:  strings.TrimSpace(a.Article.Journal.Title),
+			PubYear:  year,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,81 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const eFetchFixture = `<?xml version="1.0" ?>
+<!DOCTYPE PubmedArticleSet PUBLIC "-//NLM//DTD PubMedArticle, 1st January 2024//EN" "https://dtd.nlm.nih.gov/ncbi/pubmed/out/pubmed_240101.dtd">
+<PubmedArticleSet>
+<PubmedArticle>
+  <MedlineCitation Status="MEDLINE" Owner="NLM">
+    <PMID Version="1">10487994</PMID>
+    <Article PubModel="Print">
+      <Journal>
+        <JournalIssue CitedMedium="Print"><PubDate><Year>1999</Year><Month>Sep</Month></PubDate></JournalIssue>
+        <Title>Circulation</Title>
+      </Journal>
+      <ArticleTitle>Derivation of a simple index for <i>cardiac</i> risk.</ArticleTitle>
+      <Abstract>
+        <AbstractText Label="BACKGROUND" NlmCategory="BACKGROUND">Risk &amp; outcome.</AbstractText>
+        <AbstractText Label="CONCLUSIONS" NlmCategory="CONCLUSIONS">Six factors predict risk (P&lt;0.001).</AbstractText>
+      </Abstract>
+    </Article>
+  </MedlineCitation>
+</PubmedArticle>
+<PubmedArticle>
+  <MedlineCitation Status="MEDLINE" Owner="NLM">
+    <PMID Version="1">123</PMID>
+    <Article PubModel="Print">
+      <Journal>
+        <JournalIssue CitedMedium="Print"><PubDate><MedlineDate>2001 Jan-Feb</MedlineDate></PubDate></JournalIssue>
+        <Title>Anesthesiology</Title>
+      </Journal>
+      <ArticleTitle>No abstract.</ArticleTitle>
+    </Article>
+  </MedlineCitation>
+</PubmedArticle>
+</PubmedArticleSet>`
+
+func TestNCBIClientEFetchAbstracts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/efetch.fcgi" {
+			t.Errorf("path = %s, want /efetch.fcgi", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("db") != "pubmed" || q.Get("id") != "10487994,123" || q.Get("rettype") != "abstract" || q.Get("retmode") != "xml" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(eFetchFixture))
+	}))
+	defer srv.Close()
+
+	client := NewNCBIClient("")
+	client.baseURL = srv.URL
+
+	got, err := client.EFetchAbstracts(context.Background(), []string{"10487994", "123"})
+	if err != nil {
+		t.Fatalf("EFetchAbstracts() error = %v", err)
+	}
+	want := []PubMedAbstract{
+		{
+			PMID:     "10487994",
+			Title:    "Derivation of a simple index for cardiac risk.",
+			Abstract: "BACKGROUND: Risk & outcome.\nCONCLUSIONS: Six factors predict risk (P<0.001).",
+			Journal:  "Circulation",
+			PubYear:  "1999",
+		},
+		{PMID: "123", Title: "No abstract.", Journal: "Anesthesiology", PubYear: "2001"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("EFetchAbstracts() returned %d articles, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("article %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
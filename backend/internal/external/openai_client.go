@@ -0,0 +1,231 @@
+package external
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// OpenAICompatibleClient talks to any server exposing the OpenAI chat completions API
+// (vLLM, llama.cpp server, Ollama, LM Studio, ...). It implements LLMProvider.
+type OpenAICompatibleClient struct {
+	httpClient *http.Client
+	apiKey     string
+	limiter    *rate.Limiter
+	baseURL    string
+	model      string
+}
+
+// NewOpenAICompatibleClient creates a client for baseURL (e.g. http://localhost:8000/v1).
+// apiKey is optional; self-hosted servers often run without authentication.
+func NewOpenAICompatibleClient(baseURL, apiKey, model string) *OpenAICompatibleClient {
+	return &OpenAICompatibleClient{
+		httpClient: NewHTTPClient(60 * time.Second),
+		apiKey:     apiKey,
+		limiter:    rate.NewLimiter(rate.Limit(5), 1),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+	}
+}
+
+// Model returns the configured model name.
+func (c *OpenAICompatibleClient) Model() string {
+	return "openai/" + c.model
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens"`
+	Stream      bool            `json:"stream"`
+	// Asks the server to append token usage to the last stream chunk.
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema openAIJSONSchema `json:"json_schema"`
+}
+
+type openAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends a chat completion request.
+func (c *OpenAICompatibleClient) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error) {
+	return c.complete(ctx, messages, nil, temperature, maxTokens)
+}
+
+// CompleteJSON sends a chat completion request with a json_schema response format.
+func (c *OpenAICompatibleClient) CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	return c.complete(ctx, messages, schema, temperature, maxTokens)
+}
+
+func (c *OpenAICompatibleClient) complete(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	resp, err := c.send(ctx, messages, schema, temperature, maxTokens, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("openai: no choices returned")
+	}
+
+	return &Completion{
+		Text:         result.Choices[0].Message.Content,
+		Model:        c.Model(),
+		InputTokens:  result.Usage.PromptTokens,
+		OutputTokens: result.Usage.CompletionTokens,
+	}, nil
+}
+
+// Stream sends a streaming chat completion request and relays "data:" chunks until [DONE].
+func (c *OpenAICompatibleClient) Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	resp, err := c.send(ctx, messages, nil, temperature, maxTokens, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	out := &Completion{Model: c.Model()}
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("openai: decode stream chunk: %w", err)
+		}
+		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
+			out.InputTokens = chunk.Usage.PromptTokens
+			out.OutputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	out.Text = text.String()
+	return out, nil
+}
+
+// send posts a chat completion request. The caller owns the response body.
+func (c *OpenAICompatibleClient) send(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int, stream bool) (*http.Response, error) {
+	if c.baseURL == "" {
+		return nil, fmt.Errorf("openai: base_url required")
+	}
+	if c.model == "" {
+		return nil, fmt.Errorf("openai: model required")
+	}
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	reqBody := openAIChatRequest{
+		Model:       c.model,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+		Stream:      stream,
+	}
+	if stream {
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	if schema != nil {
+		reqBody.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: openAIJSONSchema{Name: "response", Schema: schema, Strict: true},
+		}
+	}
+	for _, m := range messages {
+		reqBody.Messages = append(reqBody.Messages, openAIMessage{Role: m.Role, Content: m.Text})
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var errBody map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&errBody)
+		return nil, fmt.Errorf("openai: status %d: %v", resp.StatusCode, errBody)
+	}
+	return resp, nil
+}
+
+// GenerateText is a convenience method for simple text generation.
+func (c *OpenAICompatibleClient) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return generateText(ctx, c, systemPrompt, userPrompt)
+}
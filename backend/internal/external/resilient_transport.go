@@ -0,0 +1,321 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the host while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// RetryPolicy controls retries and the per-host circuit breaker of a ResilientTransport.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries per request, including the first.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the exponential backoff; each wait is drawn uniformly from
+	// [0, min(MaxDelay, BaseDelay*2^retry)] so clients retrying together spread out.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxRetryAfter caps how long a Retry-After header may make us wait; longer waits are not retried.
+	MaxRetryAfter time.Duration
+	// FailureThreshold consecutive failures (5xx or transport errors) open the breaker for OpenTimeout.
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// DefaultRetryPolicy suits the NCBI and LLM APIs: a few quick retries, and a host that keeps
+// failing is left alone for half a minute instead of holding up every request.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:      3,
+	BaseDelay:        200 * time.Millisecond,
+	MaxDelay:         5 * time.Second,
+	MaxRetryAfter:    30 * time.Second,
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+}
+
+// ResilientTransport is an http.RoundTripper that retries 429 and 5xx responses and transport errors
+// with jittered exponential backoff, honours Retry-After, and keeps a circuit breaker per host.
+// Requests with a body are retried only when the body can be replayed (http.NewRequest sets GetBody
+// for in-memory readers). Responses are returned as soon as headers arrive, so streams are never retried.
+//
+// Non-idempotent requests (POST, PATCH) may already have been processed when they fail, and an LLM
+// completion is billed even when its response is lost, so they are retried only when the server cannot
+// have acted on them: the connection was never established, or a 429/503 came with Retry-After. A
+// request carrying an Idempotency-Key header opts in to the full retry policy.
+type ResilientTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilientTransport wraps base (http.DefaultTransport when nil).
+func NewResilientTransport(base http.RoundTripper, policy RetryPolicy) *ResilientTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &ResilientTransport{
+		base:     base,
+		policy:   policy,
+		breakers: make(map[string]*circuitBreaker),
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// SharedTransport is used by every outbound integration so breaker state is kept per host
+// across clients and can be reported by the health check.
+var SharedTransport = NewResilientTransport(nil, DefaultRetryPolicy)
+
+// NewHTTPClient returns a client on SharedTransport. timeout bounds the whole call, retries included.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: SharedTransport}
+}
+
+// BreakerStatus is the state of one host's circuit breaker.
+type BreakerStatus struct {
+	Host     string     `json:"host"`
+	State    string     `json:"state"`
+	Failures int        `json:"consecutive_failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// BreakerStates reports the breakers of all hosts contacted so far, sorted by host.
+func (t *ResilientTransport) BreakerStates() []BreakerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	out := make([]BreakerStatus, 0, len(t.breakers))
+	for host, b := range t.breakers {
+		st := BreakerStatus{Host: host, State: b.state(now, t.policy.OpenTimeout), Failures: b.failures}
+		if !b.openedAt.IsZero() {
+			openedAt := b.openedAt
+			st.OpenedAt = &openedAt
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ResilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	idempotent := idempotentRequest(req)
+
+	for attempt := 1; ; attempt++ {
+		if !t.allow(host) {
+			return nil, fmt.Errorf("%s: %w", host, ErrCircuitOpen)
+		}
+
+		try := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			try = req.Clone(ctx)
+			try.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(try)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled by the caller: says nothing about the host's health.
+			t.release(host)
+			return nil, err
+		}
+		failed := err != nil || resp.StatusCode >= 500
+		t.record(host, failed)
+
+		if !failed && resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+		if attempt >= t.policy.MaxAttempts || !replayable || !(idempotent || notProcessed(resp, err)) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+				if after > t.policy.MaxRetryAfter {
+					return resp, nil
+				}
+				wait = after
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && t.now().Add(wait).After(deadline) {
+			// The caller would time out while waiting; report what we have now.
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// idempotentRequest reports whether repeating req cannot have a different effect than sending it once.
+func idempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// notProcessed reports whether a failed attempt certainly did not reach the application: the
+// connection could not be established, or the server asked to come back later with Retry-After.
+func notProcessed(resp *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		var dnsErr *net.DNSError
+		return (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// backoff returns the jittered wait before retry number attempt (1-based).
+func (t *ResilientTransport) backoff(attempt int) time.Duration {
+	ceiling := t.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > t.policy.MaxDelay {
+		ceiling = t.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// circuitBreaker counts consecutive failures of one host. Once open, it lets a single probe through
+// after the open timeout (half-open); the probe's outcome closes or re-opens it.
+type circuitBreaker struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *circuitBreaker) state(now time.Time, openTimeout time.Duration) string {
+	switch {
+	case b.openedAt.IsZero():
+		return BreakerClosed
+	case now.Sub(b.openedAt) < openTimeout:
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+func (t *ResilientTransport) breaker(host string) *circuitBreaker {
+	b, ok := t.breakers[host]
+	if !ok {
+		b = &circuitBreaker{}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+// allow reports whether a request to host may be sent now.
+func (t *ResilientTransport) allow(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.breaker(host)
+	switch b.state(t.now(), t.policy.OpenTimeout) {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// release gives up a half-open probe slot without judging the host.
+func (t *ResilientTransport) release(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.breaker(host).probing = false
+}
+
+func (t *ResilientTransport) record(host string, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.breaker(host)
+	probe := b.probing
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+	b.failures++
+	if probe || (t.policy.FailureThreshold > 0 && b.failures >= t.policy.FailureThreshold) {
+		b.openedAt = t.now()
+	}
+}
@@ -0,0 +1,206 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTransport returns a transport with a controllable clock whose waits are recorded instead of slept.
+func testTransport(policy RetryPolicy) (*ResilientTransport, *time.Time, *[]time.Duration) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	tr := NewResilientTransport(nil, policy)
+	tr.now = func() time.Time { return now }
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return tr, &now, &waits
+}
+
+func TestResilientTransportRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, body)
+		}
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	policy := DefaultRetryPolicy
+	tr, _, waits := testTransport(policy)
+	client := &http.Client{Transport: tr}
+
+	// An Idempotency-Key opts a POST in to retries of any 5xx.
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader([]byte("payload")))
+	req.Header.Set("Idempotency-Key", "k1")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+	if len(*waits) != 2 || (*waits)[0] > policy.BaseDelay || (*waits)[1] != 7*time.Second {
+		t.Fatalf("waits = %v, want jittered backoff then Retry-After 7s", *waits)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestResilientTransportNonIdempotent(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	status := func(code int, retryAfter string) func() (*http.Response, error) {
+		return func() (*http.Response, error) {
+			resp := &http.Response{StatusCode: code, Header: http.Header{}, Body: http.NoBody}
+			if retryAfter != "" {
+				resp.Header.Set("Retry-After", retryAfter)
+			}
+			return resp, nil
+		}
+	}
+	fail := func(err error) func() (*http.Response, error) {
+		return func() (*http.Response, error) { return nil, err }
+	}
+
+	tests := []struct {
+		name      string
+		first     func() (*http.Response, error)
+		wantCalls int32
+	}{
+		{"server error", status(http.StatusInternalServerError, ""), 1},
+		{"unavailable without Retry-After", status(http.StatusServiceUnavailable, ""), 1},
+		{"unavailable with Retry-After", status(http.StatusServiceUnavailable, "1"), 2},
+		{"rate limited with Retry-After", status(http.StatusTooManyRequests, "1"), 2},
+		{"connection refused", fail(dialErr), 2},
+		{"connection lost after sending", fail(readErr), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			tr, _, _ := testTransport(DefaultRetryPolicy)
+			tr.base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if calls.Add(1) == 1 {
+					return tt.first()
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "http://llm.test/completion", bytes.NewReader([]byte("prompt")))
+			resp, err := tr.RoundTrip(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Fatalf("POST made %d calls, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResilientTransportGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	tr, _, _ := testTransport(DefaultRetryPolicy)
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("status %d after %d calls; a Retry-After beyond the cap must not be waited for", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestResilientTransportCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	policy := RetryPolicy{MaxAttempts: 1, FailureThreshold: 2, OpenTimeout: time.Minute}
+	tr, now, _ := testTransport(policy)
+	client := &http.Client{Transport: tr}
+	get := func() error {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	_ = get()
+	_ = get()
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("third call: err = %v after %d calls, want ErrCircuitOpen after 2", err, calls.Load())
+	}
+	if st := tr.BreakerStates(); len(st) != 1 || st[0].State != BreakerOpen || st[0].Failures != 2 {
+		t.Fatalf("BreakerStates() = %+v", st)
+	}
+
+	// After the open timeout one probe is let through; a failed probe re-opens the breaker at once.
+	*now = now.Add(time.Minute)
+	if st := tr.BreakerStates(); st[0].State != BreakerHalfOpen {
+		t.Fatalf("state after timeout = %s, want half_open", st[0].State)
+	}
+	_ = get()
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after failed probe: err = %v, want ErrCircuitOpen", err)
+	}
+
+	*now = now.Add(time.Minute)
+	healthy.Store(true)
+	if err := get(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if st := tr.BreakerStates(); st[0].State != BreakerClosed || st[0].Failures != 0 {
+		t.Fatalf("after successful probe: %+v", st[0])
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if d, ok := retryAfter("120", now); !ok || d != 2*time.Minute {
+		t.Errorf("retryAfter(120) = %v, %v", d, ok)
+	}
+	if d, ok := retryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now); !ok || d != 90*time.Second {
+		t.Errorf("retryAfter(date) = %v, %v", d, ok)
+	}
+	if _, ok := retryAfter("soon", now); ok {
+		t.Errorf("retryAfter(soon) parsed")
+	}
+}
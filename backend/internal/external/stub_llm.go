@@ -0,0 +1,119 @@
+package external
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StubLLM is an offline, deterministic LLMProvider for development and integration tests.
+// The same messages always produce the same text, so assertions can be exact.
+type StubLLM struct{}
+
+// NewStubLLM creates the local stub provider.
+func NewStubLLM() *StubLLM {
+	return &StubLLM{}
+}
+
+// Model returns the stub model name.
+func (c *StubLLM) Model() string {
+	return "stub/deterministic"
+}
+
+// Complete returns a canned five-part answer that echoes the first line of the last user message.
+func (c *StubLLM) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	a := stubAnswerFor(messages)
+	text := fmt.Sprintf(
+		"1) Резюме: %s\n2) Уровень риска: %s\n3) Подготовка: %s\n4) Обследования: %s\n5) Вопросы: %s\n[stub:%s]",
+		a.Summary, a.RiskLevel, a.Preparation, a.Tests, a.Questions, a.hash,
+	)
+	return c.completion(a, text), nil
+}
+
+// CompleteJSON returns the same canned answer as a JSON object with the five patient advice sections.
+// The schema is not interpreted; the stub only serves the patient advice contract.
+func (c *StubLLM) CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	a := stubAnswerFor(messages)
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return c.completion(a, string(raw)), nil
+}
+
+type stubAnswer struct {
+	Summary     string `json:"summary"`
+	RiskLevel   string `json:"risk_level"`
+	Preparation string `json:"preparation"`
+	Tests       string `json:"tests"`
+	Questions   string `json:"questions"`
+
+	input string
+	hash  string
+}
+
+func stubAnswerFor(messages []Message) stubAnswer {
+	var input strings.Builder
+	lastUser := ""
+	for _, m := range messages {
+		input.WriteString(m.Role)
+		input.WriteString(":")
+		input.WriteString(m.Text)
+		input.WriteString("\n")
+		if m.Role == "user" {
+			lastUser = m.Text
+		}
+	}
+	subject := strings.TrimSpace(strings.SplitN(strings.TrimSpace(lastUser), "\n", 2)[0])
+	sum := sha256.Sum256([]byte(input.String()))
+
+	return stubAnswer{
+		Summary:     subject + ".",
+		RiskLevel:   "оценка рассчитана по шкале, значение обсудите с врачом.",
+		Preparation: "следуйте общим рекомендациям лечащего врача.",
+		Tests:       "врач определит необходимый объём обследования.",
+		Questions:   "уточните у анестезиолога и хирурга, что означает результат для вас.",
+		input:       input.String(),
+		hash:        hex.EncodeToString(sum[:4]),
+	}
+}
+
+func (c *StubLLM) completion(a stubAnswer, text string) *Completion {
+	return &Completion{
+		Text:         text,
+		Model:        c.Model(),
+		InputTokens:  len(strings.Fields(a.input)),
+		OutputTokens: len(strings.Fields(text)),
+	}
+}
+
+// Stream relays the Complete text word by word.
+func (c *StubLLM) Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	resp, err := c.Complete(ctx, messages, temperature, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.SplitAfter(resp.Text, " ")
+	for _, w := range words {
+		if err := onDelta(w); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// GenerateText is a convenience method for simple text generation.
+func (c *StubLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return generateText(ctx, c, systemPrompt, userPrompt)
+}
@@ -1,17 +1,20 @@
 package external
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
 )
 
-// YandexGPTClient provides access to YandexGPT API for text generation.
+// YandexGPTClient provides access to YandexGPT API for text generation. It implements LLMProvider.
 type YandexGPTClient struct {
 	httpClient *http.Client
 	apiKey     string
@@ -25,7 +28,7 @@ type YandexGPTClient struct {
 // NewYandexGPTClient creates a new YandexGPT client.
 func NewYandexGPTClient(apiKey, folderID string) *YandexGPTClient {
 	return &YandexGPTClient{
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: NewHTTPClient(60 * time.Second),
 		apiKey:     apiKey,
 		folderID:   folderID,
 		limiter:    rate.NewLimiter(rate.Limit(1), 1), // 1 req/s (conservative)
@@ -37,7 +40,7 @@ func NewYandexGPTClient(apiKey, folderID string) *YandexGPTClient {
 // NewYandexGPTClientWithIAMToken creates a new YandexGPT client using an IAM token (Bearer).
 func NewYandexGPTClientWithIAMToken(iamToken, folderID string) *YandexGPTClient {
 	return &YandexGPTClient{
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		httpClient: NewHTTPClient(60 * time.Second),
 		iamToken:   iamToken,
 		folderID:   folderID,
 		limiter:    rate.NewLimiter(rate.Limit(1), 1), // 1 req/s (conservative)
@@ -62,6 +65,12 @@ type CompletionRequest struct {
 	ModelURI          string            `json:"modelUri"`
 	CompletionOptions CompletionOptions `json:"completionOptions"`
 	Messages          []Message         `json:"messages"`
+	JSONSchema        *JSONSchema       `json:"jsonSchema,omitempty"`
+}
+
+// JSONSchema constrains the completion to structured output.
+type JSONSchema struct {
+	Schema json.RawMessage `json:"schema"`
 }
 
 // CompletionOptions controls generation parameters.
@@ -81,12 +90,90 @@ type CompletionResponse struct {
 			} `json:"message"`
 			Status string `json:"status"`
 		} `json:"alternatives"`
-		Usage map[string]any `json:"usage"`
+		Usage struct {
+			InputTextTokens  string `json:"inputTextTokens"`
+			CompletionTokens string `json:"completionTokens"`
+			TotalTokens      string `json:"totalTokens"`
+		} `json:"usage"`
+		ModelVersion string `json:"modelVersion"`
 	} `json:"result"`
 }
 
+// Model returns the configured model name.
+func (c *YandexGPTClient) Model() string {
+	return "yandexgpt/" + c.model
+}
+
 // Complete sends a completion request to YandexGPT.
-func (c *YandexGPTClient) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*CompletionResponse, error) {
+func (c *YandexGPTClient) Complete(ctx context.Context, messages []Message, temperature float64, maxTokens int) (*Completion, error) {
+	return c.complete(ctx, messages, nil, temperature, maxTokens)
+}
+
+// CompleteJSON sends a completion request with a JSON schema for the answer.
+func (c *YandexGPTClient) CompleteJSON(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	return c.complete(ctx, messages, schema, temperature, maxTokens)
+}
+
+func (c *YandexGPTClient) complete(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int) (*Completion, error) {
+	resp, err := c.send(ctx, messages, schema, temperature, maxTokens, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result CompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return c.toCompletion(&result)
+}
+
+// Stream sends a streaming completion request. YandexGPT answers with newline-delimited
+// JSON objects, each carrying the text generated so far; onDelta receives only the new part.
+func (c *YandexGPTClient) Stream(ctx context.Context, messages []Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*Completion, error) {
+	resp, err := c.send(ctx, messages, nil, temperature, maxTokens, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var last CompletionResponse
+	text := ""
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk CompletionResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("yandexgpt: decode stream chunk: %w", err)
+		}
+		if len(chunk.Result.Alternatives) == 0 {
+			continue
+		}
+		last = chunk
+
+		current := chunk.Result.Alternatives[0].Message.Text
+		delta := strings.TrimPrefix(current, text)
+		text = current
+		if delta == "" {
+			continue
+		}
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c.toCompletion(&last)
+}
+
+// send validates configuration, waits for the rate limiter and posts a completion request.
+// The caller owns the response body.
+func (c *YandexGPTClient) send(ctx context.Context, messages []Message, schema json.RawMessage, temperature float64, maxTokens int, stream bool) (*http.Response, error) {
 	if c.folderID == "" {
 		return nil, fmt.Errorf("yandexgpt: folder_id required")
 	}
@@ -103,12 +190,15 @@ func (c *YandexGPTClient) Complete(ctx context.Context, messages []Message, temp
 	reqBody := CompletionRequest{
 		ModelURI: modelURI,
 		CompletionOptions: CompletionOptions{
-			Stream:      false,
+			Stream:      stream,
 			Temperature: temperature,
 			MaxTokens:   maxTokens,
 		},
 		Messages: messages,
 	}
+	if schema != nil {
+		reqBody.JSONSchema = &JSONSchema{Schema: schema}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
@@ -132,39 +222,35 @@ func (c *YandexGPTClient) Complete(ctx context.Context, messages []Message, temp
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		var errBody map[string]any
 		_ = json.NewDecoder(resp.Body).Decode(&errBody)
 		return nil, fmt.Errorf("yandexgpt: status %d: %v", resp.StatusCode, errBody)
 	}
+	return resp, nil
+}
 
-	var result CompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+func (c *YandexGPTClient) toCompletion(result *CompletionResponse) (*Completion, error) {
+	if len(result.Result.Alternatives) == 0 {
+		return nil, fmt.Errorf("yandexgpt: no alternatives returned")
 	}
 
-	return &result, nil
+	// Yandex reports token counts as strings.
+	input, _ := strconv.Atoi(result.Result.Usage.InputTextTokens)
+	output, _ := strconv.Atoi(result.Result.Usage.CompletionTokens)
+	return &Completion{
+		Text:         result.Result.Alternatives[0].Message.Text,
+		Model:        c.Model(),
+		InputTokens:  input,
+		OutputTokens: output,
+	}, nil
 }
 
 // GenerateText is a convenience method for simple text generation.
 func (c *YandexGPTClient) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	messages := []Message{
-		{Role: "system", Text: systemPrompt},
-		{Role: "user", Text: userPrompt},
-	}
-
-	resp, err := c.Complete(ctx, messages, 0.3, 2000)
-	if err != nil {
-		return "", err
-	}
-
-	if len(resp.Result.Alternatives) == 0 {
-		return "", fmt.Errorf("yandexgpt: no alternatives returned")
-	}
-
-	return resp.Result.Alternatives[0].Message.Text, nil
+	return generateText(ctx, c, systemPrompt, userPrompt)
 }
 
 // MedicalSummaryPrompts contains prompts for medical use cases.
@@ -183,21 +269,3 @@ var MedicalSummaryPrompts = struct {
 	DrugInteraction: `Ты — фармацевт-консультант. Проанализируй возможные лекарственные взаимодействия между указанными препаратами.
 Укажи клинически значимые взаимодействия и рекомендации по их предотвращению.`,
 }
-
-// InterpretSurvey generates AI interpretation of survey results.
-func (c *YandexGPTClient) InterpretSurvey(ctx context.Context, surveyType string, score float64, breakdown map[string]any) (string, error) {
-	userPrompt := fmt.Sprintf(
-		"Опросник: %s\nИтоговый балл: %.2f\nДетали: %v\n\nДай краткую интерпретацию результатов для врача.",
-		surveyType, score, breakdown,
-	)
-	return c.GenerateText(ctx, MedicalSummaryPrompts.SurveyInterpretation, userPrompt)
-}
-
-// RecommendTherapy generates therapy recommendations based on patient data.
-func (c *YandexGPTClient) RecommendTherapy(ctx context.Context, diagnosis string, activityScore float64, previousTherapy []string) (string, error) {
-	userPrompt := fmt.Sprintf(
-		"Диагноз: %s\nИндекс активности: %.2f\nПредыдущая терапия: %v\n\nПредложи варианты ГИБП-терапии.",
-		diagnosis, activityScore, previousTherapy,
-	)
-	return c.GenerateText(ctx, MedicalSummaryPrompts.TherapyRecommendation, userPrompt)
-}
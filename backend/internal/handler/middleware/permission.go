@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type PermissionMiddleware struct {
+	userRepo repository.UserRepository
+}
+
+func NewPermissionMiddleware(userRepo repository.UserRepository) *PermissionMiddleware {
+	return &PermissionMiddleware{userRepo: userRepo}
+}
+
+// Require allows the request through if the authenticated user has any of the given permissions.
+// Must be mounted after RequireAuth. The resolved permission list is stored in Locals("permissions").
+func (m *PermissionMiddleware) Require(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, ok := GetUserID(c)
+		if !ok {
+			return response.Unauthorized(c, "Unauthorized")
+		}
+
+		perms, err := m.userRepo.ListPermissions(c.Context(), uid)
+		if err != nil {
+			return err
+		}
+
+		user := &entity.User{ID: uid, Permissions: perms}
+		if !user.HasAnyPermission(permissions...) {
+			return response.Forbidden(c, "Insufficient permissions")
+		}
+
+		c.Locals("permissions", perms)
+		return c.Next()
+	}
+}
+
+// HasPermission reports whether permissions resolved by Require include the given one.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	perms, ok := c.Locals("permissions").([]string)
+	if !ok {
+		return false
+	}
+	user := &entity.User{Permissions: perms}
+	return user.HasPermission(permission)
+}
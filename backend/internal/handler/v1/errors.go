@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/medical-app/backend/internal/service"
 	"github.com/medical-app/backend/pkg/response"
 	"github.com/medical-app/backend/pkg/validator"
 )
@@ -22,5 +23,11 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 		return response.ValidationError(c, ve.Error())
 	}
 
+	// Daily LLM quota used up
+	var qe *service.QuotaExceededError
+	if errors.As(err, &qe) {
+		return response.TooManyRequests(c, "Daily AI quota exceeded", qe.RetryAfter)
+	}
+
 	return response.InternalError(c, "Internal server error")
 }
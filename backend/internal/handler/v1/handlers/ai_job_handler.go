@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type AIJobHandler struct {
+	svc *service.AIJobService
+}
+
+func NewAIJobHandler(svc *service.AIJobService) *AIJobHandler {
+	return &AIJobHandler{svc: svc}
+}
+
+// Get lets clients poll a queued AI generation job until its status is done or failed.
+func (h *AIJobHandler) Get(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	staff := middleware.HasPermission(c, entity.PermPatientsRead)
+	job, err := h.svc.Get(c.Context(), userID, staff, id)
+	if err != nil {
+		if errors.Is(err, service.ErrAIJobNotFound) {
+			return response.NotFound(c, "AI job not found")
+		}
+		if errors.Is(err, service.ErrAIJobAccessDenied) {
+			return response.Forbidden(c, "Access to this AI job is not allowed")
+		}
+		return err
+	}
+	return response.Success(c, job)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type AlertHandler struct {
+	svc   *service.AlertService
+	audit *middleware.AuditMiddleware
+}
+
+func NewAlertHandler(svc *service.AlertService, audit *middleware.AuditMiddleware) *AlertHandler {
+	return &AlertHandler{svc: svc, audit: audit}
+}
+
+// List returns the authenticated doctor's alerts, and for admins also the alerts of patients without
+// an attending doctor. Without ?status only unresolved alerts are returned.
+func (h *AlertHandler) List(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	limit := c.QueryInt("limit", 50)
+	offset := c.QueryInt("offset", 0)
+
+	items, err := h.svc.ListForDoctor(c.Context(), doctorID, middleware.HasPermission(c, entity.PermAdminFull), c.Query("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+func (h *AlertHandler) Acknowledge(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	updated, err := h.svc.Acknowledge(c.Context(), doctorID, middleware.HasPermission(c, entity.PermAdminFull), id)
+	if err != nil {
+		return alertError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceAlert, &updated.ID, map[string]any{"status": entity.AlertStatusOpen}, map[string]any{"status": updated.Status})
+	return response.Success(c, updated)
+}
+
+type alertResolveRequest struct {
+	Note string `json:"note"`
+}
+
+func (h *AlertHandler) Resolve(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req alertResolveRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	updated, err := h.svc.Resolve(c.Context(), doctorID, middleware.HasPermission(c, entity.PermAdminFull), id, req.Note)
+	if err != nil {
+		return alertError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceAlert, &updated.ID, nil, map[string]any{"status": updated.Status, "note": updated.ResolutionNote})
+	return response.Success(c, updated)
+}
+
+func (h *AlertHandler) ListRules(c *fiber.Ctx) error {
+	items, err := h.svc.ListRules(c.Context(), c.Query("index_type"))
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+func (h *AlertHandler) CreateRule(c *fiber.Ctx) error {
+	var req entity.AlertRuleUpsert
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	created, err := h.svc.CreateRule(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourceAlert, &created.ID, nil, created)
+	return response.Created(c, created)
+}
+
+func (h *AlertHandler) UpdateRule(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req entity.AlertRuleUpsert
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	updated, previous, err := h.svc.UpdateRule(c.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, service.ErrAlertRuleNotFound) {
+			return response.NotFound(c, "Alert rule not found")
+		}
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceAlert, &updated.ID, previous, updated)
+	return response.Success(c, updated)
+}
+
+func alertError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrAlertNotFound) {
+		return response.NotFound(c, "Alert not found")
+	}
+	if errors.Is(err, service.ErrAlertNotAssigned) {
+		return response.Forbidden(c, "Alert is assigned to another doctor")
+	}
+	return err
+}
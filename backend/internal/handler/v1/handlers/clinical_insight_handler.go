@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type ClinicalInsightHandler struct {
+	svc   *service.ClinicalInsightService
+	audit *middleware.AuditMiddleware
+}
+
+func NewClinicalInsightHandler(svc *service.ClinicalInsightService, audit *middleware.AuditMiddleware) *ClinicalInsightHandler {
+	return &ClinicalInsightHandler{svc: svc, audit: audit}
+}
+
+// Interpretation produces a clinician-level interpretation of the patient's latest indices.
+func (h *ClinicalInsightHandler) Interpretation(c *fiber.Ctx) error {
+	return h.create(c, entity.ClinicalInsightInterpretation)
+}
+
+// Therapy produces biologic therapy considerations from the diagnosis, current index and therapy history.
+func (h *ClinicalInsightHandler) Therapy(c *fiber.Ctx) error {
+	return h.create(c, entity.ClinicalInsightTherapy)
+}
+
+func (h *ClinicalInsightHandler) create(c *fiber.Ctx, kind string) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	patientID, ok := parseUUIDParam(c, "patientId")
+	if !ok {
+		return response.BadRequest(c, "Invalid patientId")
+	}
+
+	item, err := h.svc.Create(c.Context(), userID, patientID, kind)
+	if err != nil {
+		if errors.Is(err, service.ErrPatientNotFound) {
+			return response.NotFound(c, "Patient not found")
+		}
+		if errors.Is(err, service.ErrNotAttendingDoctor) {
+			return response.Forbidden(c, "Not the attending doctor of this patient")
+		}
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourceInsight, &item.ID, nil, map[string]any{"patient_id": item.PatientID, "kind": item.Kind, "prompt_version": item.PromptVersion})
+	return response.Created(c, item)
+}
+
+// List returns stored insights for a patient, newest first. ?kind= filters by interpretation or therapy.
+func (h *ClinicalInsightHandler) List(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	patientID, ok := parseUUIDParam(c, "patientId")
+	if !ok {
+		return response.BadRequest(c, "Invalid patientId")
+	}
+	kind := c.Query("kind")
+	if kind != "" && kind != entity.ClinicalInsightInterpretation && kind != entity.ClinicalInsightTherapy {
+		return response.BadRequest(c, "Invalid kind (expected interpretation or therapy)")
+	}
+
+	items, err := h.svc.List(c.Context(), userID, patientID, kind, c.QueryInt("limit", 50), c.QueryInt("offset", 0))
+	if err != nil {
+		if errors.Is(err, service.ErrPatientNotFound) {
+			return response.NotFound(c, "Patient not found")
+		}
+		if errors.Is(err, service.ErrNotAttendingDoctor) {
+			return response.Forbidden(c, "Not the attending doctor of this patient")
+		}
+		return err
+	}
+	return response.Success(c, items)
+}
@@ -1,33 +1,78 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
 	"github.com/medical-app/backend/internal/service"
 	"github.com/medical-app/backend/pkg/response"
 )
 
 type DrugHandler struct {
-	svc *service.DrugService
+	svc   *service.DrugService
+	audit *middleware.AuditMiddleware
 }
 
-func NewDrugHandler(svc *service.DrugService, _ any) *DrugHandler {
-	return &DrugHandler{svc: svc}
+func NewDrugHandler(svc *service.DrugService, audit *middleware.AuditMiddleware) *DrugHandler {
+	return &DrugHandler{svc: svc, audit: audit}
 }
 
+// List searches the catalogue: ?search= (typos and either script), ?atc_code= prefix,
+// ?is_active= (true by default), ?page= and ?per_page=.
 func (h *DrugHandler) List(c *fiber.Ctx) error {
-	search := c.Query("search")
-	items, err := h.svc.List(c.Context(), search, 50)
+	filter, ok := drugFilterFromQuery(c)
+	if !ok {
+		return response.BadRequest(c, "Invalid is_active")
+	}
+
+	page, err := h.svc.Search(c.Context(), filter)
 	if err != nil {
 		return err
 	}
+	return response.SuccessWithMeta(c, page.Items, drugPageMeta(page))
+}
+
+// ATCClasses lists the subclasses of ?parent=, or the anatomical main groups.
+func (h *DrugHandler) ATCClasses(c *fiber.Ctx) error {
+	items, err := h.svc.ATCChildren(c.Context(), c.Query("parent"))
+	if err != nil {
+		return drugError(c, err)
+	}
 	return response.Success(c, items)
 }
 
-func (h *DrugHandler) Get(c *fiber.Ctx) error {
-	id, err := uuid.Parse(c.Params("id"))
+// ATCClass returns an ATC class with its path from the main group and its direct subclasses.
+func (h *DrugHandler) ATCClass(c *fiber.Ctx) error {
+	class, err := h.svc.ATCClass(c.Context(), c.Params("code"))
+	if err != nil {
+		return drugError(c, err)
+	}
+	return response.Success(c, class)
+}
+
+// ATCDrugs lists catalogue drugs in an ATC class and its subclasses; it takes the same query
+// parameters as List except atc_code.
+func (h *DrugHandler) ATCDrugs(c *fiber.Ctx) error {
+	filter, ok := drugFilterFromQuery(c)
+	if !ok {
+		return response.BadRequest(c, "Invalid is_active")
+	}
+
+	class, page, err := h.svc.ATCDrugs(c.Context(), c.Params("code"), filter)
 	if err != nil {
+		return drugError(c, err)
+	}
+	return response.SuccessWithMeta(c, map[string]any{"class": class, "drugs": page.Items}, drugPageMeta(page))
+}
+
+func (h *DrugHandler) Get(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
 		return response.BadRequest(c, "Invalid id")
 	}
 	item, err := h.svc.Get(c.Context(), id)
@@ -40,6 +85,168 @@ func (h *DrugHandler) Get(c *fiber.Ctx) error {
 	return response.Success(c, item)
 }
 
+// Create adds a catalogue entry (drugs:write).
+func (h *DrugHandler) Create(c *fiber.Ctx) error {
+	var req entity.DrugCreate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	d, err := h.svc.Create(c.Context(), req)
+	if err != nil {
+		return drugError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourceDrug, &d.ID, nil, d)
+	return response.Created(c, d)
+}
+
+// Update changes the given fields of a catalogue entry (drugs:write).
+func (h *DrugHandler) Update(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req entity.DrugUpdate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	old, d, err := h.svc.Update(c.Context(), id, req)
+	if err != nil {
+		return drugError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceDrug, &d.ID, old, d)
+	return response.Success(c, d)
+}
+
+// Deactivate hides a drug from the catalogue; the row is kept for existing therapy logs (drugs:write).
+func (h *DrugHandler) Deactivate(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	old, err := h.svc.Deactivate(c.Context(), id)
+	if err != nil {
+		return drugError(c, err)
+	}
+
+	if old.IsActive {
+		h.audit.Log(c, entity.AuditActionDelete, entity.ResourceDrug, &old.ID, map[string]any{"is_active": true}, map[string]any{"is_active": false})
+	}
+	return response.Success(c, map[string]any{"id": old.ID, "is_active": false})
+}
+
+// BulkUpsert creates or updates drugs matched by name; each created or changed entry is audited (drugs:write).
+func (h *DrugHandler) BulkUpsert(c *fiber.Ctx) error {
+	var req struct {
+		Items []entity.DrugCreate `json:"items"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	results, err := h.svc.BulkUpsert(c.Context(), req.Items)
+	if err != nil {
+		return drugError(c, err)
+	}
+	for _, r := range results {
+		switch r.Action {
+		case service.DrugBulkCreated:
+			h.audit.Log(c, entity.AuditActionCreate, entity.ResourceDrug, &r.Drug.ID, nil, r.Drug)
+		case service.DrugBulkUpdated:
+			h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceDrug, &r.Drug.ID, r.Old, r.Drug)
+		}
+	}
+
+	summary := map[string]int{service.DrugBulkCreated: 0, service.DrugBulkUpdated: 0, service.DrugBulkUnchanged: 0}
+	for _, r := range results {
+		summary[r.Action]++
+	}
+	return response.Success(c, map[string]any{"summary": summary, "items": results})
+}
+
+// CheckInteractions matches the interaction rules against every pair of {"drug_ids": [...]}.
+func (h *DrugHandler) CheckInteractions(c *fiber.Ctx) error {
+	var req struct {
+		DrugIDs []uuid.UUID `json:"drug_ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	items, err := h.svc.CheckInteractions(c.Context(), req.DrugIDs)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, map[string]any{"interactions": items})
+}
+
+func (h *DrugHandler) InteractionRules(c *fiber.Ctx) error {
+	items, err := h.svc.ListInteractionRules(c.Context())
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+// CreateInteractionRule adds a rule between two drugs or ATC classes (drugs:write).
+func (h *DrugHandler) CreateInteractionRule(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	var req entity.DrugInteractionRuleCreate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	rule, err := h.svc.CreateInteractionRule(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourceDrugInteraction, &rule.ID, nil, rule)
+	return response.Created(c, rule)
+}
+
+func (h *DrugHandler) PerioperativeRules(c *fiber.Ctx) error {
+	items, err := h.svc.ListPerioperativeRules(c.Context())
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+// SetPerioperativeRule creates or replaces the hold/resume rule of a drug or ATC class (drugs:write).
+func (h *DrugHandler) SetPerioperativeRule(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	var req entity.DrugPerioperativeRuleSet
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	old, rule, err := h.svc.SetPerioperativeRule(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	if old == nil {
+		h.audit.Log(c, entity.AuditActionCreate, entity.ResourceDrugPerioperative, &rule.ID, nil, rule)
+	} else {
+		h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceDrugPerioperative, &rule.ID, old, rule)
+	}
+	return response.Success(c, rule)
+}
+
 // SearchPubChem searches for drugs in NCBI PubChem.
 func (h *DrugHandler) SearchPubChem(c *fiber.Ctx) error {
 	query := c.Query("q")
@@ -84,3 +291,44 @@ func (h *DrugHandler) SearchPubMed(c *fiber.Ctx) error {
 	}
 	return response.Success(c, articles)
 }
+
+func drugError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrDrugNotFound):
+		return response.NotFound(c, "Drug not found")
+	case errors.Is(err, service.ErrDrugNameTaken):
+		return response.Conflict(c, "A drug with this name already exists")
+	case errors.Is(err, service.ErrATCClassNotFound):
+		return response.NotFound(c, "ATC class not found")
+	}
+	return err
+}
+
+// drugFilterFromQuery reads the catalogue search parameters; only active drugs are listed unless
+// ?is_active= says otherwise. It reports false when is_active is not a boolean.
+func drugFilterFromQuery(c *fiber.Ctx) (entity.DrugFilter, bool) {
+	active := true
+	if raw := c.Query("is_active"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return entity.DrugFilter{}, false
+		}
+		active = v
+	}
+	return entity.DrugFilter{
+		Search:   c.Query("search"),
+		ATCCode:  c.Query("atc_code"),
+		IsActive: &active,
+		Page:     c.QueryInt("page", 1),
+		PerPage:  c.QueryInt("per_page", 0),
+	}, true
+}
+
+func drugPageMeta(page *service.DrugPage) *response.Meta {
+	return &response.Meta{
+		Page:       page.Page,
+		PerPage:    page.PerPage,
+		Total:      page.Total,
+		TotalPages: int((page.Total + int64(page.PerPage) - 1) / int64(page.PerPage)),
+	}
+}
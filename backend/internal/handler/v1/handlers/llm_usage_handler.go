@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+const llmUsageDefaultDays = 30
+
+type LLMUsageHandler struct {
+	svc   *service.LLMUsageService
+	audit *middleware.AuditMiddleware
+}
+
+func NewLLMUsageHandler(svc *service.LLMUsageService, audit *middleware.AuditMiddleware) *LLMUsageHandler {
+	return &LLMUsageHandler{svc: svc, audit: audit}
+}
+
+// Report returns token consumption by day, survey code and model.
+// ?from= and ?to= are dates (YYYY-MM-DD, inclusive); the default is the last 30 days.
+func (h *LLMUsageHandler) Report(c *fiber.Ctx) error {
+	from, to, invalid := reportDates(c)
+	if invalid != "" {
+		return response.BadRequest(c, invalid)
+	}
+
+	rows, err := h.svc.Report(c.Context(), from, to)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, rows)
+}
+
+// reportDates reads the ?from= and ?to= report dates, defaulting to the last 30 days.
+// A malformed date is reported as a message for a 400 response.
+func reportDates(c *fiber.Ctx) (from time.Time, to time.Time, invalid string) {
+	to = time.Now().UTC()
+	from = to.AddDate(0, 0, -(llmUsageDefaultDays - 1))
+	if raw := c.Query("from"); raw != "" {
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return from, to, "Invalid from date (expected YYYY-MM-DD)"
+		}
+		from = t
+	}
+	if raw := c.Query("to"); raw != "" {
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return from, to, "Invalid to date (expected YYYY-MM-DD)"
+		}
+		to = t
+	}
+	return from, to, ""
+}
+
+func (h *LLMUsageHandler) ListQuotas(c *fiber.Ctx) error {
+	items, err := h.svc.ListQuotas(c.Context())
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+// SetQuota creates or replaces the daily limits of a role or user.
+func (h *LLMUsageHandler) SetQuota(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	var req entity.LLMQuotaUpsert
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	quota, err := h.svc.SetQuota(c.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotFound) {
+			return response.NotFound(c, "Role not found")
+		}
+		if errors.Is(err, service.ErrUserNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceQuota, &quota.ID, nil, quota)
+	return response.Success(c, quota)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type MedicalIndexHandler struct {
+	svc *service.MedicalIndexService
+}
+
+func NewMedicalIndexHandler(svc *service.MedicalIndexService) *MedicalIndexHandler {
+	return &MedicalIndexHandler{svc: svc}
+}
+
+// Trend returns an index time series for a patient. Patients may only see their own data.
+func (h *MedicalIndexHandler) Trend(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "patientId")
+	if !ok {
+		return response.BadRequest(c, "Invalid patientId")
+	}
+
+	patient, err := h.svc.ResolvePatient(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	if patient == nil {
+		return response.NotFound(c, "Patient not found")
+	}
+	if patient.UserID != userID && !middleware.HasPermission(c, entity.PermPatientsRead) {
+		return response.Forbidden(c, "Access to this patient is not allowed")
+	}
+
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return response.BadRequest(c, "Invalid 'from' (expected RFC3339)")
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return response.BadRequest(c, "Invalid 'to' (expected RFC3339)")
+	}
+
+	trend, err := h.svc.GetTrend(c.Context(), patient.ID, c.Params("type"), from, to)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, trend)
+}
+
+// parseTimeQuery parses an optional RFC3339 query parameter.
+func parseTimeQuery(c *fiber.Ctx, key string) (*time.Time, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type PromptHandler struct {
+	svc   *service.PromptService
+	audit *middleware.AuditMiddleware
+}
+
+func NewPromptHandler(svc *service.PromptService, audit *middleware.AuditMiddleware) *PromptHandler {
+	return &PromptHandler{svc: svc, audit: audit}
+}
+
+// List returns prompt versions, optionally filtered by ?key= and ?survey_code=.
+func (h *PromptHandler) List(c *fiber.Ctx) error {
+	items, err := h.svc.List(c.Context(), c.Query("key"), c.Query("survey_code"))
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+func (h *PromptHandler) Get(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	p, err := h.svc.Get(c.Context(), id)
+	if err != nil {
+		return promptError(c, err)
+	}
+	return response.Success(c, p)
+}
+
+// Create adds a draft version. It is not used for generation until a reviewer approves it.
+func (h *PromptHandler) Create(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	var req entity.PromptTemplateCreate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	p, err := h.svc.Create(c.Context(), userID, req)
+	if err != nil {
+		return promptError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourcePrompt, &p.ID, nil, map[string]any{"key": p.Key, "survey_code": p.SurveyCode, "version": p.Version})
+	return response.Created(c, p)
+}
+
+// Review approves or rejects a draft version.
+func (h *PromptHandler) Review(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req entity.PromptTemplateReview
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	p, err := h.svc.Review(c.Context(), userID, id, req)
+	if err != nil {
+		return promptError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionReview, entity.ResourcePrompt, &p.ID, map[string]any{"status": entity.PromptStatusDraft}, map[string]any{"status": p.Status, "comment": p.ReviewComment})
+	return response.Success(c, p)
+}
+
+func promptError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPromptTemplateNotFound):
+		return response.NotFound(c, "Prompt template not found")
+	case errors.Is(err, service.ErrSurveyTemplateNotFound):
+		return response.NotFound(c, "Survey template not found")
+	case errors.Is(err, service.ErrPromptTemplateNotDraft):
+		return response.Conflict(c, err.Error())
+	case errors.Is(err, service.ErrPromptSelfApproval):
+		return response.Forbidden(c, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/handler/middleware"
+	"github.com/medical-app/backend/internal/service"
+	"github.com/medical-app/backend/pkg/response"
+)
+
+type RescoreHandler struct {
+	svc   *service.RescoreService
+	audit *middleware.AuditMiddleware
+}
+
+func NewRescoreHandler(svc *service.RescoreService, audit *middleware.AuditMiddleware) *RescoreHandler {
+	return &RescoreHandler{svc: svc, audit: audit}
+}
+
+// Start launches a rescore job for a template. The job only computes a diff; nothing is written back yet.
+func (h *RescoreHandler) Start(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+
+	job, err := h.svc.Start(c.Context(), userID, c.Params("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrRescoreJobActive) {
+			return response.Conflict(c, err.Error())
+		}
+		if errors.Is(err, service.ErrSurveyTemplateNotFound) {
+			return response.NotFound(c, "Survey template not found")
+		}
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionCreate, entity.ResourceSurvey, &job.ID, nil, map[string]any{"rescore_job": job.ID, "template_id": job.TemplateID})
+	return response.Accepted(c, job)
+}
+
+func (h *RescoreHandler) Get(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	job, err := h.svc.Get(c.Context(), id)
+	if err != nil {
+		return rescoreError(c, err)
+	}
+	return response.Success(c, job)
+}
+
+// Items lists per-response differences. ?category_changed=true narrows to category transitions.
+func (h *RescoreHandler) Items(c *fiber.Ctx) error {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+	limit := c.QueryInt("limit", 100)
+	offset := c.QueryInt("offset", 0)
+
+	items, err := h.svc.ListItems(c.Context(), id, c.QueryBool("category_changed", false), limit, offset)
+	if err != nil {
+		return rescoreError(c, err)
+	}
+	return response.Success(c, items)
+}
+
+// Confirm applies a ready job's new scores to the stored responses.
+func (h *RescoreHandler) Confirm(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	job, err := h.svc.Confirm(c.Context(), userID, id)
+	if err != nil {
+		return rescoreError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceSurvey, &job.ID, map[string]any{"status": entity.RescoreStatusReady}, map[string]any{"status": job.Status})
+	return response.Accepted(c, job)
+}
+
+func rescoreError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrRescoreJobNotFound) {
+		return response.NotFound(c, "Rescore job not found")
+	}
+	if errors.Is(err, service.ErrRescoreJobNotReady) {
+		return response.Conflict(c, err.Error())
+	}
+	return err
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -9,6 +16,7 @@ import (
 	"github.com/medical-app/backend/internal/handler/middleware"
 	"github.com/medical-app/backend/internal/service"
 	"github.com/medical-app/backend/pkg/response"
+	"github.com/medical-app/backend/pkg/validator"
 )
 
 type SurveyHandler struct {
@@ -46,6 +54,7 @@ func (h *SurveyHandler) SubmitResponse(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
+	req.SubmittedBy, _ = middleware.GetUserID(c)
 
 	created, err := h.svc.SubmitResponse(c.Context(), req)
 	if err != nil {
@@ -117,6 +126,23 @@ type surveyAdviceRequest struct {
 	Text string `json:"text"`
 }
 
+const (
+	adviceStreamTimeout      = 2 * time.Minute
+	adviceStreamWriteTimeout = 30 * time.Second
+)
+
+func (r *surveyAdviceRequest) answerMap() map[string]any {
+	respMap := make(map[string]any, len(r.Answers))
+	for _, a := range r.Answers {
+		qid := strings.TrimSpace(a.QuestionID)
+		if qid == "" {
+			continue
+		}
+		respMap[qid] = a.Value
+	}
+	return respMap
+}
+
 func (h *SurveyHandler) CreateAdvice(c *fiber.Ctx) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
@@ -135,22 +161,77 @@ func (h *SurveyHandler) CreateAdvice(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
-	respMap := make(map[string]any, len(req.Answers))
-	for _, a := range req.Answers {
-		qid := strings.TrimSpace(a.QuestionID)
-		if qid == "" {
-			continue
-		}
-		respMap[qid] = a.Value
-	}
-
-	created, err := h.ai.CreateForUser(c.Context(), userID, code, respMap, req.Text)
+	created, err := h.ai.CreateForUser(c.Context(), userID, code, req.answerMap(), req.Text)
 	if err != nil {
 		return err
 	}
 	return response.Created(c, created)
 }
 
+// CreateAdviceStream generates advice like CreateAdvice but relays the text as Server-Sent Events:
+// "delta" events carry text chunks, a final "done" event the stored advice, "error" a failure.
+func (h *SurveyHandler) CreateAdviceStream(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	code := strings.TrimSpace(c.Params("code"))
+	if code == "" {
+		return response.BadRequest(c, "Invalid code")
+	}
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+
+	var req surveyAdviceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	answers := req.answerMap()
+	// Checked before the stream starts so an exhausted quota is a plain 429, not an event.
+	if err := h.ai.CheckQuota(c.Context(), userID); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The server write timeout would cut long generations short, so each event extends the deadline.
+	conn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), adviceStreamTimeout)
+		defer cancel()
+
+		emit := func(event string, data any) error {
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(adviceStreamWriteTimeout))
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+
+		if _, err := h.ai.StreamForUser(ctx, userID, code, answers, req.Text, emit); err != nil {
+			log.Printf("[AIAdvice] advice stream for user %s ended: %v", userID, err)
+			message := "Internal server error"
+			var ve validator.ValidationErrors
+			var qe *service.QuotaExceededError
+			if errors.As(err, &ve) {
+				message = ve.Error()
+			} else if errors.As(err, &qe) {
+				message = "Daily AI quota exceeded"
+			}
+			_ = emit("error", fiber.Map{"message": message})
+		}
+	})
+	return nil
+}
+
 func (h *SurveyHandler) ListAdvice(c *fiber.Ctx) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
@@ -168,3 +249,295 @@ func (h *SurveyHandler) ListAdvice(c *fiber.Ctx) error {
 	}
 	return response.Success(c, items)
 }
+
+// GetAdviceThread returns the follow-up conversation about one of the user's advice records.
+func (h *SurveyHandler) GetAdviceThread(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+
+	thread, err := h.ai.GetThread(c.Context(), userID, id)
+	if err != nil {
+		return adviceThreadError(c, err)
+	}
+	return response.Success(c, thread)
+}
+
+type adviceMessageRequest struct {
+	Text string `json:"text"`
+}
+
+// PostAdviceMessage asks a follow-up question about an advice record and returns the updated thread.
+func (h *SurveyHandler) PostAdviceMessage(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+
+	var req adviceMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	thread, err := h.ai.AskInThread(c.Context(), userID, id, req.Text)
+	if err != nil {
+		return adviceThreadError(c, err)
+	}
+	return response.Created(c, thread)
+}
+
+func adviceThreadError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrAdviceNotFound):
+		return response.NotFound(c, "Advice not found")
+	case errors.Is(err, service.ErrAdviceAccessDenied):
+		return response.Forbidden(c, "Access to this advice is not allowed")
+	case errors.Is(err, service.ErrNotAttendingDoctor):
+		return response.Forbidden(c, "Not the attending doctor of this patient")
+	case errors.Is(err, service.ErrAdviceThreadClosed):
+		return response.Conflict(c, "No more questions can be asked about this advice")
+	}
+	return err
+}
+
+// RateAdvice stores the patient's 1-5 rating of one of their advice records.
+func (h *SurveyHandler) RateAdvice(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+
+	var req entity.AdviceRatingCreate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	feedback, err := h.ai.Rate(c.Context(), userID, id, req)
+	if err != nil {
+		return adviceThreadError(c, err)
+	}
+	return response.Success(c, feedback)
+}
+
+// FlagAdvice lets a clinician mark advice as inaccurate or unsafe with a reason.
+func (h *SurveyHandler) FlagAdvice(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+
+	var req entity.AdviceFlagCreate
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	feedback, err := h.ai.Flag(c.Context(), doctorID, id, req)
+	if err != nil {
+		return adviceThreadError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceAdvice, &id, nil, feedback)
+	return response.Success(c, feedback)
+}
+
+// ClearAdviceCache drops cached advice of ?survey_code=, or all cached advice without it.
+func (h *SurveyHandler) ClearAdviceCache(c *fiber.Ctx) error {
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+	surveyCode := c.Query("survey_code")
+
+	deleted, err := h.ai.InvalidateCache(c.Context(), surveyCode)
+	if err != nil {
+		return err
+	}
+
+	result := fiber.Map{"survey_code": surveyCode, "deleted": deleted}
+	h.audit.Log(c, entity.AuditActionDelete, entity.ResourceAdvice, nil, nil, result)
+	return response.Success(c, result)
+}
+
+// AdviceQuality returns ratings and flags aggregated per prompt version and model.
+// ?from= and ?to= are dates (YYYY-MM-DD, inclusive); the default is the last 30 days.
+func (h *SurveyHandler) AdviceQuality(c *fiber.Ctx) error {
+	if h.ai == nil {
+		return response.BadRequest(c, "AI service not configured")
+	}
+	from, to, invalid := reportDates(c)
+	if invalid != "" {
+		return response.BadRequest(c, invalid)
+	}
+
+	rows, err := h.ai.QualityReport(c.Context(), from, to)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, rows)
+}
+
+// ReviewQueue lists survey responses of the doctor's patients awaiting review.
+func (h *SurveyHandler) ReviewQueue(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	limit := c.QueryInt("limit", 50)
+	offset := c.QueryInt("offset", 0)
+
+	items, err := h.svc.ListReviewQueue(c.Context(), doctorID, c.Query("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, items)
+}
+
+// ReviewResponse marks a response reviewed, optionally overriding its category with a justification.
+func (h *SurveyHandler) ReviewResponse(c *fiber.Ctx) error {
+	doctorID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req entity.SurveyReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	updated, previous, err := h.svc.ReviewResponse(c.Context(), doctorID, id, req)
+	if err != nil {
+		if errors.Is(err, service.ErrSurveyResponseNotFound) {
+			return response.NotFound(c, "Survey response not found")
+		}
+		if errors.Is(err, service.ErrNotAttendingDoctor) {
+			return response.Forbidden(c, "Not the attending doctor of this patient")
+		}
+		return err
+	}
+
+	h.audit.Log(c, entity.AuditActionReview, entity.ResourceSurvey, &updated.ID,
+		map[string]any{
+			"status":            previous.Status,
+			"category":          previous.Category,
+			"category_override": previous.CategoryOverride,
+			"notes":             previous.Notes,
+		},
+		map[string]any{
+			"status":            updated.Status,
+			"category":          updated.Category,
+			"category_override": updated.CategoryOverride,
+			"override_reason":   updated.OverrideReason,
+			"notes":             updated.Notes,
+		},
+	)
+	return response.Success(c, updated)
+}
+
+// GetResponse returns the latest revision of a response; ?history=true adds the amendment chain.
+func (h *SurveyHandler) GetResponse(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	staff := middleware.HasPermission(c, entity.PermPatientsRead)
+	sr, err := h.svc.GetResponse(c.Context(), userID, staff, id, c.QueryBool("history", false))
+	if err != nil {
+		return surveyResponseError(c, err)
+	}
+	return response.Success(c, sr)
+}
+
+// AmendResponse corrects the answers of a stored response and records a new revision.
+func (h *SurveyHandler) AmendResponse(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return response.BadRequest(c, "Invalid id")
+	}
+
+	var req entity.SurveyResponseAmendRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	// Reading patients is not enough to change their answers: other people's responses are
+	// amended by reviewers, and only for the patients they attend.
+	reviewer := middleware.HasPermission(c, entity.PermSurveysReview)
+	updated, rev, err := h.svc.AmendResponse(c.Context(), userID, reviewer, id, req)
+	if err != nil {
+		return surveyResponseError(c, err)
+	}
+
+	h.audit.Log(c, entity.AuditActionUpdate, entity.ResourceSurvey, &updated.ID,
+		map[string]any{
+			"revision":  rev.Revision - 1,
+			"responses": rev.OldResponses,
+			"score":     rev.OldScore,
+			"category":  rev.OldCategory,
+		},
+		map[string]any{
+			"revision":  rev.Revision,
+			"responses": rev.NewResponses,
+			"score":     rev.NewScore,
+			"category":  rev.NewCategory,
+			"reason":    rev.Reason,
+		},
+	)
+	return response.Success(c, updated)
+}
+
+func surveyResponseError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrSurveyResponseNotFound) {
+		return response.NotFound(c, "Survey response not found")
+	}
+	if errors.Is(err, service.ErrSurveyAccessDenied) {
+		return response.Forbidden(c, "Access to this survey response is not allowed")
+	}
+	if errors.Is(err, service.ErrNotAttendingDoctor) {
+		return response.Forbidden(c, "Not the attending doctor of this patient")
+	}
+	if errors.Is(err, service.ErrSurveyRevisionConflict) {
+		return response.Conflict(c, "Survey response was amended by someone else; reload and retry")
+	}
+	return err
+}
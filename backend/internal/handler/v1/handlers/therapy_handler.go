@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
@@ -56,6 +58,46 @@ func (h *TherapyHandler) ListByPatient(c *fiber.Ctx) error {
 	return response.Success(c, items)
 }
 
+// Perioperative returns the hold/resume plan of the patient's current therapies for the operation
+// planned on ?surgery_date=YYYY-MM-DD.
+func (h *TherapyHandler) Perioperative(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return response.Unauthorized(c, "Unauthorized")
+	}
+	id, ok := parseUUIDParam(c, "patientId")
+	if !ok {
+		return response.BadRequest(c, "Invalid patientId")
+	}
+	surgeryDate, err := time.Parse(time.DateOnly, c.Query("surgery_date"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid 'surgery_date' (expected YYYY-MM-DD)")
+	}
+
+	patientID, ok, err := h.svc.ResolvePatientID(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return response.NotFound(c, "Patient not found")
+	}
+	if !middleware.HasPermission(c, entity.PermPatientsRead) {
+		own, ok, err := h.svc.ResolvePatientID(c.Context(), userID)
+		if err != nil {
+			return err
+		}
+		if !ok || own != patientID {
+			return response.Forbidden(c, "Access to this patient is not allowed")
+		}
+	}
+
+	plan, err := h.svc.PerioperativePlan(c.Context(), patientID, surgeryDate)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, plan)
+}
+
 func (h *TherapyHandler) DeleteLog(c *fiber.Ctx) error {
 	logID, err := uuid.Parse(c.Params("logId"))
 	if err != nil {
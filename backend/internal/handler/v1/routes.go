@@ -3,6 +3,7 @@ package v1
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/medical-app/backend/internal/entity"
 	"github.com/medical-app/backend/internal/handler/middleware"
 	"github.com/medical-app/backend/internal/handler/v1/handlers"
 	"github.com/medical-app/backend/internal/service"
@@ -11,8 +12,9 @@ import (
 type RouterDeps struct {
 	Services *service.Services
 
-	AuthMiddleware  *middleware.AuthMiddleware
-	AuditMiddleware *middleware.AuditMiddleware
+	AuthMiddleware       *middleware.AuthMiddleware
+	AuditMiddleware      *middleware.AuditMiddleware
+	PermissionMiddleware *middleware.PermissionMiddleware
 }
 
 func SetupRoutes(app *fiber.App, deps RouterDeps) {
@@ -21,8 +23,15 @@ func SetupRoutes(app *fiber.App, deps RouterDeps) {
 
 	authHandler := handlers.NewAuthHandler(deps.Services.Auth, deps.AuditMiddleware)
 	surveyHandler := handlers.NewSurveyHandler(deps.Services.Survey, deps.Services.AIAdvice, deps.AuthMiddleware, deps.AuditMiddleware)
-	drugHandler := handlers.NewDrugHandler(deps.Services.Drug, deps.AuthMiddleware)
+	drugHandler := handlers.NewDrugHandler(deps.Services.Drug, deps.AuditMiddleware)
 	therapyHandler := handlers.NewTherapyHandler(deps.Services.Therapy, deps.AuthMiddleware)
+	indexHandler := handlers.NewMedicalIndexHandler(deps.Services.Indices)
+	alertHandler := handlers.NewAlertHandler(deps.Services.Alerts, deps.AuditMiddleware)
+	rescoreHandler := handlers.NewRescoreHandler(deps.Services.Rescore, deps.AuditMiddleware)
+	aiJobHandler := handlers.NewAIJobHandler(deps.Services.AIJobs)
+	promptHandler := handlers.NewPromptHandler(deps.Services.Prompts, deps.AuditMiddleware)
+	llmUsageHandler := handlers.NewLLMUsageHandler(deps.Services.LLMUsage, deps.AuditMiddleware)
+	insightHandler := handlers.NewClinicalInsightHandler(deps.Services.Insights, deps.AuditMiddleware)
 
 	// Auth
 	v1.Post("/auth/register", authHandler.Register)
@@ -36,8 +45,52 @@ func SetupRoutes(app *fiber.App, deps RouterDeps) {
 	v1.Get("/surveys/templates/:code", deps.AuthMiddleware.OptionalAuth(), surveyHandler.GetTemplateByCode)
 	v1.Post("/surveys/:code/calculate", deps.AuthMiddleware.OptionalAuth(), surveyHandler.Calculate)
 	v1.Post("/surveys/responses", deps.AuthMiddleware.RequireAuth(), surveyHandler.SubmitResponse)
+	requirePatientAccess := deps.PermissionMiddleware.Require(entity.PermPatientsRead, entity.PermPatientsReadOwn)
+	v1.Get("/surveys/responses/:id", deps.AuthMiddleware.RequireAuth(), requirePatientAccess, surveyHandler.GetResponse)
+	v1.Post("/surveys/responses/:id/amend", deps.AuthMiddleware.RequireAuth(), requirePatientAccess, surveyHandler.AmendResponse)
 	v1.Post("/surveys/:code/advice", deps.AuthMiddleware.RequireAuth(), surveyHandler.CreateAdvice)
+	v1.Post("/surveys/:code/advice/stream", deps.AuthMiddleware.RequireAuth(), surveyHandler.CreateAdviceStream)
 	v1.Get("/ai/advice", deps.AuthMiddleware.RequireAuth(), surveyHandler.ListAdvice)
+	v1.Get("/ai/advice/:id/messages", deps.AuthMiddleware.RequireAuth(), surveyHandler.GetAdviceThread)
+	v1.Post("/ai/advice/:id/messages", deps.AuthMiddleware.RequireAuth(), surveyHandler.PostAdviceMessage)
+	v1.Post("/ai/advice/:id/rating", deps.AuthMiddleware.RequireAuth(), surveyHandler.RateAdvice)
+	v1.Get("/ai/jobs/:id", deps.AuthMiddleware.RequireAuth(), requirePatientAccess, aiJobHandler.Get)
+
+	// Survey review (clinicians)
+	requireReview := deps.PermissionMiddleware.Require(entity.PermSurveysReview)
+	v1.Get("/surveys/reviews", deps.AuthMiddleware.RequireAuth(), requireReview, surveyHandler.ReviewQueue)
+	v1.Post("/surveys/responses/:id/review", deps.AuthMiddleware.RequireAuth(), requireReview, surveyHandler.ReviewResponse)
+	v1.Post("/ai/advice/:id/flag", deps.AuthMiddleware.RequireAuth(), requireReview, surveyHandler.FlagAdvice)
+
+	// Clinical alerts
+	requireAdmin := deps.PermissionMiddleware.Require(entity.PermAdminFull)
+	v1.Get("/alerts", deps.AuthMiddleware.RequireAuth(), requireReview, alertHandler.List)
+	v1.Post("/alerts/:id/acknowledge", deps.AuthMiddleware.RequireAuth(), requireReview, alertHandler.Acknowledge)
+	v1.Post("/alerts/:id/resolve", deps.AuthMiddleware.RequireAuth(), requireReview, alertHandler.Resolve)
+	v1.Get("/alert-rules", deps.AuthMiddleware.RequireAuth(), requireReview, alertHandler.ListRules)
+	v1.Post("/alert-rules", deps.AuthMiddleware.RequireAuth(), requireAdmin, alertHandler.CreateRule)
+	v1.Put("/alert-rules/:id", deps.AuthMiddleware.RequireAuth(), requireAdmin, alertHandler.UpdateRule)
+
+	// Survey rescoring (admin)
+	requireManage := deps.PermissionMiddleware.Require(entity.PermSurveysManage)
+	v1.Post("/admin/surveys/templates/:code/rescore", deps.AuthMiddleware.RequireAuth(), requireManage, rescoreHandler.Start)
+	v1.Get("/admin/rescore-jobs/:id", deps.AuthMiddleware.RequireAuth(), requireManage, rescoreHandler.Get)
+	v1.Get("/admin/rescore-jobs/:id/items", deps.AuthMiddleware.RequireAuth(), requireManage, rescoreHandler.Items)
+	v1.Post("/admin/rescore-jobs/:id/confirm", deps.AuthMiddleware.RequireAuth(), requireManage, rescoreHandler.Confirm)
+
+	// Prompt registry: authored by survey managers, approved by clinical reviewers
+	requirePromptAccess := deps.PermissionMiddleware.Require(entity.PermSurveysManage, entity.PermSurveysReview)
+	v1.Get("/admin/prompts", deps.AuthMiddleware.RequireAuth(), requirePromptAccess, promptHandler.List)
+	v1.Get("/admin/prompts/:id", deps.AuthMiddleware.RequireAuth(), requirePromptAccess, promptHandler.Get)
+	v1.Post("/admin/prompts", deps.AuthMiddleware.RequireAuth(), requireManage, promptHandler.Create)
+	v1.Post("/admin/prompts/:id/review", deps.AuthMiddleware.RequireAuth(), requireReview, promptHandler.Review)
+	v1.Get("/admin/ai/quality", deps.AuthMiddleware.RequireAuth(), requirePromptAccess, surveyHandler.AdviceQuality)
+	v1.Delete("/admin/ai/advice-cache", deps.AuthMiddleware.RequireAuth(), requireManage, surveyHandler.ClearAdviceCache)
+
+	// LLM consumption and daily quotas (admin)
+	v1.Get("/admin/llm/usage", deps.AuthMiddleware.RequireAuth(), requireAdmin, llmUsageHandler.Report)
+	v1.Get("/admin/llm/quotas", deps.AuthMiddleware.RequireAuth(), requireAdmin, llmUsageHandler.ListQuotas)
+	v1.Put("/admin/llm/quotas", deps.AuthMiddleware.RequireAuth(), requireAdmin, llmUsageHandler.SetQuota)
 
 	// Drugs
 	v1.Get("/drugs", deps.AuthMiddleware.OptionalAuth(), drugHandler.List)
@@ -45,9 +98,38 @@ func SetupRoutes(app *fiber.App, deps RouterDeps) {
 	v1.Get("/drugs/pubchem/search", deps.AuthMiddleware.RequireAuth(), drugHandler.SearchPubChem)
 	v1.Get("/drugs/pubchem/verify", deps.AuthMiddleware.RequireAuth(), drugHandler.VerifyPubChem)
 	v1.Get("/drugs/pubmed/search", deps.AuthMiddleware.RequireAuth(), drugHandler.SearchPubMed)
+	v1.Get("/atc", deps.AuthMiddleware.OptionalAuth(), drugHandler.ATCClasses)
+	v1.Get("/atc/:code", deps.AuthMiddleware.OptionalAuth(), drugHandler.ATCClass)
+	v1.Get("/atc/:code/drugs", deps.AuthMiddleware.OptionalAuth(), drugHandler.ATCDrugs)
+
+	// Drug catalogue administration (pharmacists and admins)
+	requireDrugsWrite := deps.PermissionMiddleware.Require(entity.PermDrugsWrite)
+	v1.Post("/drugs", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.Create)
+	v1.Post("/drugs/bulk", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.BulkUpsert)
+	v1.Put("/drugs/:id", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.Update)
+	v1.Delete("/drugs/:id", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.Deactivate)
+
+	// Drug interactions
+	v1.Post("/drugs/interactions/check", deps.AuthMiddleware.RequireAuth(), drugHandler.CheckInteractions)
+	v1.Get("/drugs/interactions/rules", deps.AuthMiddleware.RequireAuth(), drugHandler.InteractionRules)
+	v1.Post("/drugs/interactions/rules", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.CreateInteractionRule)
+
+	// Perioperative hold/resume rules
+	v1.Get("/drugs/perioperative/rules", deps.AuthMiddleware.RequireAuth(), drugHandler.PerioperativeRules)
+	v1.Put("/drugs/perioperative/rules", deps.AuthMiddleware.RequireAuth(), requireDrugsWrite, drugHandler.SetPerioperativeRule)
 
 	// Therapy
 	v1.Post("/therapy/logs", deps.AuthMiddleware.RequireAuth(), therapyHandler.CreateLog)
 	v1.Delete("/therapy/logs/:logId", deps.AuthMiddleware.RequireAuth(), therapyHandler.DeleteLog)
 	v1.Get("/patients/:patientId/therapy", deps.AuthMiddleware.RequireAuth(), therapyHandler.ListByPatient)
+	v1.Get("/patients/:patientId/perioperative", deps.AuthMiddleware.RequireAuth(), requirePatientAccess, therapyHandler.Perioperative)
+
+	// Medical indices
+	v1.Get("/patients/:patientId/indices/:type/trend", deps.AuthMiddleware.RequireAuth(), requirePatientAccess, indexHandler.Trend)
+
+	// Clinician-facing AI insights (stored apart from patient advice)
+	requireClinicalAI := deps.PermissionMiddleware.Require(entity.PermAIClinical)
+	v1.Post("/patients/:patientId/ai/interpretation", deps.AuthMiddleware.RequireAuth(), requireClinicalAI, insightHandler.Interpretation)
+	v1.Post("/patients/:patientId/ai/therapy", deps.AuthMiddleware.RequireAuth(), requireClinicalAI, insightHandler.Therapy)
+	v1.Get("/patients/:patientId/ai/insights", deps.AuthMiddleware.RequireAuth(), requireClinicalAI, insightHandler.List)
 }
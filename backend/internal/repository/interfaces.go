@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -44,17 +45,85 @@ type SurveyTemplateRepository interface {
 }
 
 type SurveyResponseRepository interface {
-	Create(ctx context.Context, resp *entity.SurveyResponse) error
+	// Create also stores idx, the response's index history entry, when it is not nil.
+	Create(ctx context.Context, resp *entity.SurveyResponse, idx *entity.MedicalIndex) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.SurveyResponse, error)
 	ListByPatient(ctx context.Context, patientID uuid.UUID, limit int) ([]*entity.SurveyResponse, error)
 	UpdateCalculated(ctx context.Context, id uuid.UUID, score float64, category string, interpretation string, aiSummary string, breakdown any) error
+	ListForDoctor(ctx context.Context, doctorID uuid.UUID, status string, limit int, offset int) ([]*entity.SurveyResponse, error)
+	// UpdateReview also sets the category of the response's index entry when indexCategory is not empty.
+	UpdateReview(ctx context.Context, id uuid.UUID, reviewedBy uuid.UUID, reviewedAt time.Time, notes string, categoryOverride string, overrideReason string, indexCategory string) error
+	ListByTemplate(ctx context.Context, templateID uuid.UUID, afterID uuid.UUID, limit int) ([]*entity.SurveyResponse, error)
+	UpdateScore(ctx context.Context, id uuid.UUID, score float64, category string, interpretation string, breakdown json.RawMessage) error
+	Amend(ctx context.Context, rev *entity.SurveyResponseRevision, status string, interpretation string, aiStatus string, breakdown json.RawMessage, clearOverride bool) (bool, error)
+	UpdateAIResult(ctx context.Context, id uuid.UUID, revision int, interpretation string, aiStatus string) (bool, error)
+	ListRevisions(ctx context.Context, responseID uuid.UUID) ([]*entity.SurveyResponseRevision, error)
+}
+
+type MedicalIndexRepository interface {
+	Create(ctx context.Context, idx *entity.MedicalIndex) error
+	ListByPatientAndType(ctx context.Context, patientID uuid.UUID, indexType string, from *time.Time, to *time.Time) ([]*entity.MedicalIndex, error)
+	GetPrevious(ctx context.Context, patientID uuid.UUID, indexType string, before time.Time, excludeID uuid.UUID) (*entity.MedicalIndex, error)
+	UpdateValueBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID, value float64, category string) error
+	GetBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID) (*entity.MedicalIndex, error)
+	ListLatestByPatient(ctx context.Context, patientID uuid.UUID) ([]*entity.MedicalIndex, error)
+}
+
+type RescoreJobRepository interface {
+	Create(ctx context.Context, job *entity.RescoreJob) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.RescoreJob, error)
+	HasActive(ctx context.Context, templateID uuid.UUID) (bool, error)
+	Update(ctx context.Context, job *entity.RescoreJob) error
+	TransitionStatus(ctx context.Context, id uuid.UUID, from string, to string) (bool, error)
+	FailStale(ctx context.Context, updatedBefore time.Time, reason string) (int64, error)
+	AddItems(ctx context.Context, items []*entity.RescoreJobItem) error
+	ListItems(ctx context.Context, jobID uuid.UUID, categoryChangedOnly bool, limit int, offset int) ([]*entity.RescoreJobItem, error)
+}
+
+type AlertRuleRepository interface {
+	List(ctx context.Context, indexType string, activeOnly bool) ([]*entity.AlertRule, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertRule, error)
+	Create(ctx context.Context, rule *entity.AlertRule) error
+	Update(ctx context.Context, rule *entity.AlertRule) error
+}
+
+type AlertRepository interface {
+	Create(ctx context.Context, alert *entity.Alert) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Alert, error)
+	// ListByDoctor also returns unassigned alerts (no attending doctor) when includeUnassigned is set.
+	ListByDoctor(ctx context.Context, doctorID uuid.UUID, includeUnassigned bool, status string, limit int, offset int) ([]*entity.Alert, error)
+	Acknowledge(ctx context.Context, id uuid.UUID, userID uuid.UUID, at time.Time) error
+	Resolve(ctx context.Context, id uuid.UUID, userID *uuid.UUID, at time.Time, note string) error
+	ListUnresolvedByMedicalIndex(ctx context.Context, medicalIndexID uuid.UUID) ([]*entity.Alert, error)
 }
 
 type DrugRepository interface {
 	List(ctx context.Context, search string, limit int) ([]*entity.Drug, error)
+	Search(ctx context.Context, filter entity.DrugFilter) ([]*entity.Drug, int64, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Drug, error)
-	Create(ctx context.Context, drug *entity.Drug) error
-	Update(ctx context.Context, drug *entity.Drug) error
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Drug, error)
+	// Create and Update report false, without writing, when another drug has the same name.
+	Create(ctx context.Context, drug *entity.Drug) (bool, error)
+	Update(ctx context.Context, drug *entity.Drug) (bool, error)
+	// BulkUpsert matches the drugs by name and writes them in one transaction; it returns the stored
+	// entries and the matched ones before the write (nil for new drugs), in the order given.
+	BulkUpsert(ctx context.Context, drugs []*entity.Drug) (stored, old []*entity.Drug, err error)
+}
+
+type DrugInteractionRepository interface {
+	Create(ctx context.Context, rule *entity.DrugInteractionRule) error
+	ListActive(ctx context.Context) ([]*entity.DrugInteractionRule, error)
+}
+
+type DrugPerioperativeRepository interface {
+	// Upsert returns the rule it replaced, nil when the rule is new.
+	Upsert(ctx context.Context, rule *entity.DrugPerioperativeRule) (*entity.DrugPerioperativeRule, error)
+	List(ctx context.Context) ([]*entity.DrugPerioperativeRule, error)
+}
+
+type ATCRepository interface {
+	ListChildren(ctx context.Context, parentCode string) ([]*entity.ATCClass, error)
+	GetByCodes(ctx context.Context, codes []string) ([]*entity.ATCClass, error)
 }
 
 type TherapyLogRepository interface {
@@ -72,4 +141,69 @@ type PatientRepository interface {
 type AIAdviceRepository interface {
 	Create(ctx context.Context, item *entity.AIAdvice) error
 	ListByPatient(ctx context.Context, patientID uuid.UUID, limit int, offset int) ([]*entity.AIAdvice, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AIAdvice, error)
+	UpdateAdviceText(ctx context.Context, id uuid.UUID, text string, sections *entity.AdviceSections, citations []string, aiStatus string) error
+}
+
+// AdviceCacheRepository stores generated advice by cache key. Get ignores expired entries.
+type AdviceCacheRepository interface {
+	Get(ctx context.Context, key string) (*entity.AdviceCacheEntry, error)
+	Put(ctx context.Context, e *entity.AdviceCacheEntry) error
+	// DeleteBySurvey removes the entries of one survey code, or all entries when surveyCode is empty.
+	DeleteBySurvey(ctx context.Context, surveyCode string) (int64, error)
+	// DeleteExpired removes the entries that expired before now.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type AdviceMessageRepository interface {
+	Create(ctx context.Context, m *entity.AdviceMessage) error
+	CreateUserTurn(ctx context.Context, m *entity.AdviceMessage, maxTurns int) (bool, error)
+	ListByAdvice(ctx context.Context, adviceID uuid.UUID) ([]*entity.AdviceMessage, error)
+}
+
+type AdviceFeedbackRepository interface {
+	Upsert(ctx context.Context, f *entity.AdviceFeedback) error
+	QualityReport(ctx context.Context, from time.Time, to time.Time) ([]*entity.AdviceQualityRow, error)
+}
+
+type ClinicalInsightRepository interface {
+	Create(ctx context.Context, item *entity.ClinicalInsight) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ClinicalInsight, error)
+	ListByPatient(ctx context.Context, patientID uuid.UUID, kind string, limit int, offset int) ([]*entity.ClinicalInsight, error)
+	UpdateText(ctx context.Context, id uuid.UUID, text string, interactionText string, aiStatus string) error
+}
+
+type LLMUsageRepository interface {
+	Create(ctx context.Context, u *entity.LLMUsage) error
+	Totals(ctx context.Context, userID uuid.UUID, since time.Time) (*entity.LLMUsageTotals, error)
+	Report(ctx context.Context, from time.Time, to time.Time) ([]*entity.LLMUsageReportRow, error)
+	GetEffectiveQuota(ctx context.Context, userID uuid.UUID) (*entity.LLMQuota, error)
+	ListQuotas(ctx context.Context) ([]*entity.LLMQuota, error)
+	UpsertQuota(ctx context.Context, q *entity.LLMQuota) error
+}
+
+type LiteratureRepository interface {
+	GetQuery(ctx context.Context, term string) (*entity.PubMedQuery, error)
+	SaveQuery(ctx context.Context, q *entity.PubMedQuery) error
+	GetAbstracts(ctx context.Context, pmids []string) ([]*entity.PubMedAbstract, error)
+	UpsertAbstracts(ctx context.Context, items []*entity.PubMedAbstract) error
+}
+
+type PromptTemplateRepository interface {
+	Create(ctx context.Context, p *entity.PromptTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error)
+	GetActive(ctx context.Context, key string, surveyCode string) (*entity.PromptTemplate, error)
+	List(ctx context.Context, key string, surveyCode string) ([]*entity.PromptTemplate, error)
+	Review(ctx context.Context, p *entity.PromptTemplate, status string, reviewedBy uuid.UUID, comment string, at time.Time) (bool, error)
+}
+
+type AIJobRepository interface {
+	Create(ctx context.Context, job *entity.AIJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AIJob, error)
+	ClaimNext(ctx context.Context, now time.Time) (*entity.AIJob, error)
+	Complete(ctx context.Context, id uuid.UUID, result string, at time.Time) error
+	Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time, at time.Time) error
+	RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	// CountActiveByUser counts the user's pending and running jobs.
+	CountActiveByUser(ctx context.Context, userID uuid.UUID) (int, error)
 }
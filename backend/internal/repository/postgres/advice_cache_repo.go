@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type adviceCacheRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAdviceCacheRepository(db *pgxpool.Pool) *adviceCacheRepository {
+	return &adviceCacheRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+// Get returns a live entry and counts the hit; expired entries are treated as missing.
+func (r *adviceCacheRepository) Get(ctx context.Context, key string) (*entity.AdviceCacheEntry, error) {
+	const sql = `
+		UPDATE ai_advice_cache SET hits = hits + 1
+		WHERE cache_key = $1 AND expires_at > NOW()
+		RETURNING cache_key, survey_code, template_version, prompt_template_id, prompt_version, model,
+			advice_text, sections, citations, hits, created_at, expires_at`
+
+	var e entity.AdviceCacheEntry
+	err := r.db.QueryRow(ctx, sql, key).Scan(
+		&e.Key, &e.SurveyCode, &e.TemplateVersion, &e.PromptTemplateID, &e.PromptVersion, &e.Model,
+		&e.AdviceText, &e.Sections, &e.Citations, &e.Hits, &e.CreatedAt, &e.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get advice cache entry: %w", err)
+	}
+	return &e, nil
+}
+
+// Put stores an entry, replacing an expired (or concurrently written) one with the same key.
+func (r *adviceCacheRepository) Put(ctx context.Context, e *entity.AdviceCacheEntry) error {
+	const sql = `
+		INSERT INTO ai_advice_cache (cache_key, survey_code, template_version, prompt_template_id, prompt_version, model,
+			advice_text, sections, citations, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET advice_text = EXCLUDED.advice_text, sections = EXCLUDED.sections, citations = EXCLUDED.citations,
+			hits = 0, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at`
+
+	if _, err := r.db.Exec(ctx, sql,
+		e.Key, e.SurveyCode, e.TemplateVersion, e.PromptTemplateID, e.PromptVersion, e.Model,
+		e.AdviceText, e.Sections, e.Citations, e.CreatedAt, e.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("put advice cache entry: %w", err)
+	}
+	return nil
+}
+
+func (r *adviceCacheRepository) DeleteBySurvey(ctx context.Context, surveyCode string) (int64, error) {
+	q := r.sb.Delete("ai_advice_cache")
+	if surveyCode != "" {
+		q = q.Where(squirrel.Eq{"survey_code": surveyCode})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete advice cache entries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *adviceCacheRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	q := r.sb.Delete("ai_advice_cache").Where(squirrel.Lt{"expires_at": now})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired advice cache entries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
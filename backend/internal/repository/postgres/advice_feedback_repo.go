@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type adviceFeedbackRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAdviceFeedbackRepository(db *pgxpool.Pool) *adviceFeedbackRepository {
+	return &adviceFeedbackRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+// Upsert stores a rating or flag, replacing the one the same user left earlier on the same advice.
+// The stored id and created_at are written back to f.
+func (r *adviceFeedbackRepository) Upsert(ctx context.Context, f *entity.AdviceFeedback) error {
+	const sql = `
+		INSERT INTO ai_advice_feedback (id, advice_id, user_id, kind, rating, reason, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (advice_id, user_id, kind) DO UPDATE
+		SET rating = EXCLUDED.rating, reason = EXCLUDED.reason, comment = EXCLUDED.comment, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	if err := r.db.QueryRow(ctx, sql,
+		f.ID, f.AdviceID, f.UserID, f.Kind, f.Rating, nullIfEmpty(f.Reason), nullIfEmpty(f.Comment), f.UpdatedAt,
+	).Scan(&f.ID, &f.CreatedAt); err != nil {
+		return fmt.Errorf("upsert advice feedback: %w", err)
+	}
+	return nil
+}
+
+// QualityReport aggregates feedback on advice created in [from, to) by prompt version and model.
+// Advice whose generation failed is counted under the rule-based group (empty model).
+func (r *adviceFeedbackRepository) QualityReport(ctx context.Context, from time.Time, to time.Time) ([]*entity.AdviceQualityRow, error) {
+	q := r.sb.Select(
+		"CASE WHEN a.ai_status = 'failed' THEN 0 ELSE COALESCE(a.prompt_version, 0) END AS prompt_version",
+		"CASE WHEN a.ai_status = 'failed' THEN '' ELSE COALESCE(a.model, '') END AS model",
+		"COUNT(DISTINCT a.id)",
+		"COUNT(f.id) FILTER (WHERE f.kind = 'rating')",
+		"(AVG(f.rating) FILTER (WHERE f.kind = 'rating'))::float8",
+		"COUNT(DISTINCT f.advice_id) FILTER (WHERE f.kind = 'flag' AND f.reason = 'inaccurate')",
+		"COUNT(DISTINCT f.advice_id) FILTER (WHERE f.kind = 'flag' AND f.reason = 'unsafe')",
+		"COUNT(DISTINCT f.advice_id) FILTER (WHERE f.kind = 'flag')",
+	).
+		From("ai_advice a").
+		LeftJoin("ai_advice_feedback f ON f.advice_id = a.id").
+		Where(squirrel.GtOrEq{"a.created_at": from}).
+		Where(squirrel.Lt{"a.created_at": to}).
+		GroupBy("1", "2").
+		OrderBy("1 DESC", "2")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query advice quality report: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.AdviceQualityRow, 0)
+	for rows.Next() {
+		var row entity.AdviceQualityRow
+		if err := rows.Scan(&row.PromptVersion, &row.Model, &row.Advice, &row.Ratings, &row.AvgRating, &row.FlaggedInaccurate, &row.FlaggedUnsafe, &row.Flagged); err != nil {
+			return nil, fmt.Errorf("scan advice quality report: %w", err)
+		}
+		out = append(out, &row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type adviceMessageRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAdviceMessageRepository(db *pgxpool.Pool) *adviceMessageRepository {
+	return &adviceMessageRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+func (r *adviceMessageRepository) Create(ctx context.Context, m *entity.AdviceMessage) error {
+	q := r.sb.Insert("ai_advice_messages").
+		Columns("id", "advice_id", "role", "content", "ai_status", "created_at").
+		Values(m.ID, m.AdviceID, m.Role, m.Content, nullIfEmpty(m.AIStatus), m.CreatedAt)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert advice message: %w", err)
+	}
+	return nil
+}
+
+// CreateUserTurn stores a patient question unless the thread already has maxTurns of them, and
+// reports whether it was stored. The advice row is locked for the check, so concurrent questions
+// about the same advice are counted one after another.
+func (r *adviceMessageRepository) CreateUserTurn(ctx context.Context, m *entity.AdviceMessage, maxTurns int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin advice question: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	lock := r.sb.Select("id").From("ai_advice").Where(squirrel.Eq{"id": m.AdviceID}).Suffix("FOR UPDATE")
+	sql, args, err := lock.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	var adviceID uuid.UUID
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&adviceID); err != nil {
+		return false, fmt.Errorf("lock advice: %w", err)
+	}
+
+	count := r.sb.Select("COUNT(*)").From("ai_advice_messages").
+		Where(squirrel.Eq{"advice_id": m.AdviceID, "role": entity.AdviceMessageUser})
+	sql, args, err = count.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	var n int
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&n); err != nil {
+		return false, fmt.Errorf("count advice questions: %w", err)
+	}
+	if n >= maxTurns {
+		return false, nil
+	}
+
+	ins := r.sb.Insert("ai_advice_messages").
+		Columns("id", "advice_id", "role", "content", "ai_status", "created_at").
+		Values(m.ID, m.AdviceID, m.Role, m.Content, nullIfEmpty(m.AIStatus), m.CreatedAt)
+	sql, args, err = ins.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return false, fmt.Errorf("insert advice message: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit advice question: %w", err)
+	}
+	return true, nil
+}
+
+// ListByAdvice returns the whole thread in chronological order.
+func (r *adviceMessageRepository) ListByAdvice(ctx context.Context, adviceID uuid.UUID) ([]*entity.AdviceMessage, error) {
+	q := r.sb.Select("id", "advice_id", "role", "content", "COALESCE(ai_status, '')", "created_at").
+		From("ai_advice_messages").
+		Where(squirrel.Eq{"advice_id": adviceID}).
+		OrderBy("created_at ASC", "id ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query advice messages: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.AdviceMessage, 0)
+	for rows.Next() {
+		var m entity.AdviceMessage
+		if err := rows.Scan(&m.ID, &m.AdviceID, &m.Role, &m.Content, &m.AIStatus, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan advice message: %w", err)
+		}
+		out = append(out, &m)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows advice messages: %w", rows.Err())
+	}
+	return out, nil
+}
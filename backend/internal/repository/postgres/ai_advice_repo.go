@@ -6,6 +6,7 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/medical-app/backend/internal/entity"
@@ -20,10 +21,39 @@ func NewAIAdviceRepository(db *pgxpool.Pool) *aiAdviceRepository {
 	return &aiAdviceRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
 }
 
+var aiAdviceColumns = []string{
+	"id", "patient_id", "survey_code", "COALESCE(user_text, '')", "score", "COALESCE(category, '')",
+	"details", "advice_text", "sections", "citations", "COALESCE(ai_status, '')", "prompt_template_id", "COALESCE(prompt_version, 0)", "COALESCE(model, '')", "created_at",
+}
+
+func scanAIAdvice(row pgx.Row) (*entity.AIAdvice, error) {
+	var item entity.AIAdvice
+	if err := row.Scan(
+		&item.ID,
+		&item.PatientID,
+		&item.SurveyCode,
+		&item.UserText,
+		&item.Score,
+		&item.Category,
+		&item.Details,
+		&item.AdviceText,
+		&item.Sections,
+		&item.Citations,
+		&item.AIStatus,
+		&item.PromptTemplateID,
+		&item.PromptVersion,
+		&item.Model,
+		&item.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
 func (r *aiAdviceRepository) Create(ctx context.Context, item *entity.AIAdvice) error {
 	q := r.sb.Insert("ai_advice").
-		Columns("id", "patient_id", "survey_code", "user_text", "score", "category", "details", "advice_text", "created_at").
-		Values(item.ID, item.PatientID, item.SurveyCode, item.UserText, item.Score, item.Category, item.Details, item.AdviceText, item.CreatedAt)
+		Columns("id", "patient_id", "survey_code", "user_text", "score", "category", "details", "advice_text", "sections", "citations", "ai_status", "prompt_template_id", "prompt_version", "model", "created_at").
+		Values(item.ID, item.PatientID, item.SurveyCode, item.UserText, item.Score, item.Category, item.Details, item.AdviceText, item.Sections, item.Citations, nullIfEmpty(item.AIStatus), item.PromptTemplateID, nullIfZero(item.PromptVersion), nullIfEmpty(item.Model), item.CreatedAt)
 
 	sql, args, err := q.ToSql()
 	if err != nil {
@@ -46,7 +76,7 @@ func (r *aiAdviceRepository) ListByPatient(ctx context.Context, patientID uuid.U
 	}
 
 	q := r.sb.Select(
-		"id", "patient_id", "survey_code", "COALESCE(user_text, '')", "score", "COALESCE(category, '')", "details", "advice_text", "created_at",
+		aiAdviceColumns...,
 	).
 		From("ai_advice").
 		Where(squirrel.Eq{"patient_id": patientID}).
@@ -67,24 +97,56 @@ func (r *aiAdviceRepository) ListByPatient(ctx context.Context, patientID uuid.U
 
 	out := make([]*entity.AIAdvice, 0)
 	for rows.Next() {
-		var item entity.AIAdvice
-		if err := rows.Scan(
-			&item.ID,
-			&item.PatientID,
-			&item.SurveyCode,
-			&item.UserText,
-			&item.Score,
-			&item.Category,
-			&item.Details,
-			&item.AdviceText,
-			&item.CreatedAt,
-		); err != nil {
+		item, err := scanAIAdvice(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan ai_advice: %w", err)
 		}
-		out = append(out, &item)
+		out = append(out, item)
 	}
 	if rows.Err() != nil {
 		return nil, fmt.Errorf("rows ai_advice: %w", rows.Err())
 	}
 	return out, nil
 }
+
+func (r *aiAdviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AIAdvice, error) {
+	q := r.sb.Select(aiAdviceColumns...).
+		From("ai_advice").
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	item, err := scanAIAdvice(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select ai_advice: %w", err)
+	}
+	return item, nil
+}
+
+// UpdateAdviceText replaces the advice text, its sections and citations once generation finished.
+// An empty text keeps the current text, sections and citations.
+func (r *aiAdviceRepository) UpdateAdviceText(ctx context.Context, id uuid.UUID, text string, sections *entity.AdviceSections, citations []string, aiStatus string) error {
+	q := r.sb.Update("ai_advice").
+		Set("ai_status", aiStatus).
+		Where(squirrel.Eq{"id": id})
+	if text != "" {
+		q = q.Set("advice_text", text).Set("sections", sections).Set("citations", citations)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update ai_advice text: %w", err)
+	}
+	return nil
+}
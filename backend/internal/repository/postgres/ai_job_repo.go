@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type aiJobRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAIJobRepository(db *pgxpool.Pool) *aiJobRepository {
+	return &aiJobRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+const aiJobColumns = `id, kind, status, user_id, survey_response_id, ai_advice_id, clinical_insight_id, payload,
+	COALESCE(result, ''), COALESCE(error, ''), attempts, max_attempts, run_after, locked_at, created_at, finished_at`
+
+func scanAIJob(row pgx.Row) (*entity.AIJob, error) {
+	var j entity.AIJob
+	if err := row.Scan(
+		&j.ID, &j.Kind, &j.Status, &j.UserID, &j.SurveyResponseID, &j.AIAdviceID, &j.ClinicalInsightID, &j.Payload,
+		&j.Result, &j.Error, &j.Attempts, &j.MaxAttempts, &j.RunAfter, &j.LockedAt, &j.CreatedAt, &j.FinishedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *aiJobRepository) Create(ctx context.Context, job *entity.AIJob) error {
+	q := r.sb.Insert("ai_jobs").
+		Columns(
+			"id", "kind", "status", "user_id", "survey_response_id", "ai_advice_id", "clinical_insight_id",
+			"payload", "max_attempts", "run_after", "created_at",
+		).
+		Values(
+			job.ID, job.Kind, job.Status, job.UserID, job.SurveyResponseID, job.AIAdviceID, job.ClinicalInsightID,
+			job.Payload, job.MaxAttempts, job.RunAfter, job.CreatedAt,
+		)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insert ai job: %w", err)
+	}
+	return nil
+}
+
+func (r *aiJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AIJob, error) {
+	job, err := scanAIJob(r.db.QueryRow(ctx, `SELECT `+aiJobColumns+` FROM ai_jobs WHERE id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select ai job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimNext locks the oldest runnable job for this worker and marks it running.
+// SKIP LOCKED lets several workers (and several API instances) poll the same table.
+func (r *aiJobRepository) ClaimNext(ctx context.Context, now time.Time) (*entity.AIJob, error) {
+	job, err := scanAIJob(r.db.QueryRow(ctx, `
+		UPDATE ai_jobs SET status = 'running', attempts = attempts + 1, locked_at = $1
+		WHERE id = (
+			SELECT id FROM ai_jobs
+			WHERE status = 'pending' AND run_after <= $1
+			ORDER BY run_after
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+aiJobColumns, now))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("claim ai job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *aiJobRepository) Complete(ctx context.Context, id uuid.UUID, result string, at time.Time) error {
+	q := r.sb.Update("ai_jobs").
+		Set("status", entity.AIJobStatusDone).
+		Set("result", result).
+		Set("error", nil).
+		Set("finished_at", at).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("complete ai job: %w", err)
+	}
+	return nil
+}
+
+// Fail records an attempt error. With retryAt the job goes back to the queue, otherwise it is failed for good.
+func (r *aiJobRepository) Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time, at time.Time) error {
+	q := r.sb.Update("ai_jobs").
+		Set("error", errMsg).
+		Set("locked_at", nil).
+		Where(squirrel.Eq{"id": id})
+	if retryAt != nil {
+		q = q.Set("status", entity.AIJobStatusPending).Set("run_after", *retryAt)
+	} else {
+		q = q.Set("status", entity.AIJobStatusFailed).Set("finished_at", at)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("fail ai job: %w", err)
+	}
+	return nil
+}
+
+// RequeueStale returns jobs whose worker died (locked before lockedBefore) to the queue.
+func (r *aiJobRepository) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	q := r.sb.Update("ai_jobs").
+		Set("status", entity.AIJobStatusPending).
+		Set("locked_at", nil).
+		Where(squirrel.Eq{"status": entity.AIJobStatusRunning}).
+		Where(squirrel.Lt{"locked_at": lockedBefore})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("requeue stale ai jobs: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (r *aiJobRepository) CountActiveByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	q := r.sb.Select("COUNT(*)").From("ai_jobs").
+		Where(squirrel.Eq{"user_id": userID, "status": []string{entity.AIJobStatusPending, entity.AIJobStatusRunning}})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	var n int
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count active ai jobs: %w", err)
+	}
+	return n, nil
+}
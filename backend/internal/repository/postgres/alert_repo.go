@@ -0,0 +1,325 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type alertRuleRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAlertRuleRepository(db *pgxpool.Pool) *alertRuleRepository {
+	return &alertRuleRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+var alertRuleColumns = []string{
+	"id", "index_type", "rule_type", "direction", "threshold", "category_order",
+	"severity", "COALESCE(description, '')", "is_active", "created_at", "updated_at",
+}
+
+func scanAlertRule(row pgx.Row) (*entity.AlertRule, error) {
+	var r entity.AlertRule
+	if err := row.Scan(
+		&r.ID, &r.IndexType, &r.RuleType, &r.Direction, &r.Threshold, &r.CategoryOrder,
+		&r.Severity, &r.Description, &r.IsActive, &r.CreatedAt, &r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// List returns rules, optionally restricted to one index type and/or active rules only.
+func (r *alertRuleRepository) List(ctx context.Context, indexType string, activeOnly bool) ([]*entity.AlertRule, error) {
+	q := r.sb.Select(alertRuleColumns...).From("alert_rules")
+	if indexType != "" {
+		q = q.Where(squirrel.Eq{"index_type": indexType})
+	}
+	if activeOnly {
+		q = q.Where(squirrel.Eq{"is_active": true})
+	}
+	q = q.OrderBy("index_type ASC", "created_at ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.AlertRule, 0)
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert rule: %w", err)
+		}
+		out = append(out, rule)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows alert rules: %w", rows.Err())
+	}
+	return out, nil
+}
+
+func (r *alertRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertRule, error) {
+	q := r.sb.Select(alertRuleColumns...).From("alert_rules").Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rule, err := scanAlertRule(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *alertRuleRepository) Create(ctx context.Context, rule *entity.AlertRule) error {
+	q := r.sb.Insert("alert_rules").
+		Columns("id", "index_type", "rule_type", "direction", "threshold", "category_order", "severity", "description", "is_active", "created_at", "updated_at").
+		Values(rule.ID, rule.IndexType, rule.RuleType, rule.Direction, rule.Threshold, rule.CategoryOrder, rule.Severity, rule.Description, rule.IsActive, rule.CreatedAt, rule.UpdatedAt)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insert alert rule: %w", err)
+	}
+	return nil
+}
+
+func (r *alertRuleRepository) Update(ctx context.Context, rule *entity.AlertRule) error {
+	q := r.sb.Update("alert_rules").
+		Set("index_type", rule.IndexType).
+		Set("rule_type", rule.RuleType).
+		Set("direction", rule.Direction).
+		Set("threshold", rule.Threshold).
+		Set("category_order", rule.CategoryOrder).
+		Set("severity", rule.Severity).
+		Set("description", rule.Description).
+		Set("is_active", rule.IsActive).
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": rule.ID})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update alert rule: %w", err)
+	}
+	return nil
+}
+
+type alertRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewAlertRepository(db *pgxpool.Pool) *alertRepository {
+	return &alertRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+var alertColumns = []string{
+	"id", "rule_id", "patient_id", "doctor_id", "medical_index_id", "index_type",
+	"previous_value", "current_value", "COALESCE(previous_category, '')", "COALESCE(current_category, '')",
+	"severity", "message", "status", "acknowledged_by", "acknowledged_at",
+	"resolved_by", "resolved_at", "COALESCE(resolution_note, '')", "created_at",
+}
+
+func scanAlert(row pgx.Row) (*entity.Alert, error) {
+	var a entity.Alert
+	if err := row.Scan(
+		&a.ID, &a.RuleID, &a.PatientID, &a.DoctorID, &a.MedicalIndexID, &a.IndexType,
+		&a.PreviousValue, &a.CurrentValue, &a.PreviousCategory, &a.CurrentCategory,
+		&a.Severity, &a.Message, &a.Status, &a.AcknowledgedBy, &a.AcknowledgedAt,
+		&a.ResolvedBy, &a.ResolvedAt, &a.ResolutionNote, &a.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *alertRepository) Create(ctx context.Context, a *entity.Alert) error {
+	q := r.sb.Insert("alerts").
+		Columns(
+			"id", "rule_id", "patient_id", "doctor_id", "medical_index_id", "index_type",
+			"previous_value", "current_value", "previous_category", "current_category",
+			"severity", "message", "status", "created_at",
+		).
+		Values(
+			a.ID, a.RuleID, a.PatientID, a.DoctorID, a.MedicalIndexID, a.IndexType,
+			a.PreviousValue, a.CurrentValue, a.PreviousCategory, a.CurrentCategory,
+			a.Severity, a.Message, a.Status, a.CreatedAt,
+		)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insert alert: %w", err)
+	}
+	return nil
+}
+
+func (r *alertRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Alert, error) {
+	q := r.sb.Select(alertColumns...).From("alerts").Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	a, err := scanAlert(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select alert: %w", err)
+	}
+	return a, nil
+}
+
+// ListByDoctor returns the doctor's alerts, newest first. An empty status means "not resolved".
+func (r *alertRepository) ListByDoctor(ctx context.Context, doctorID uuid.UUID, includeUnassigned bool, status string, limit int, offset int) ([]*entity.Alert, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var assigned squirrel.Sqlizer = squirrel.Eq{"doctor_id": doctorID}
+	if includeUnassigned {
+		assigned = squirrel.Or{assigned, squirrel.Eq{"doctor_id": nil}}
+	}
+	q := r.sb.Select(alertColumns...).From("alerts").Where(assigned)
+	if status != "" {
+		q = q.Where(squirrel.Eq{"status": status})
+	} else {
+		q = q.Where(squirrel.NotEq{"status": entity.AlertStatusResolved})
+	}
+	q = q.OrderBy("created_at DESC").Limit(uint64(limit)).Offset(uint64(offset))
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.Alert, 0)
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		out = append(out, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows alerts: %w", rows.Err())
+	}
+	return out, nil
+}
+
+func (r *alertRepository) Acknowledge(ctx context.Context, id uuid.UUID, userID uuid.UUID, at time.Time) error {
+	q := r.sb.Update("alerts").
+		Set("status", entity.AlertStatusAcknowledged).
+		Set("acknowledged_by", userID).
+		Set("acknowledged_at", at).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("acknowledge alert: %w", err)
+	}
+	return nil
+}
+
+// Resolve closes an alert; a nil userID records a resolution by the system (e.g. after re-scoring).
+func (r *alertRepository) Resolve(ctx context.Context, id uuid.UUID, userID *uuid.UUID, at time.Time, note string) error {
+	q := r.sb.Update("alerts").
+		Set("status", entity.AlertStatusResolved).
+		Set("resolved_by", userID).
+		Set("resolved_at", at).
+		Set("resolution_note", note).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("resolve alert: %w", err)
+	}
+	return nil
+}
+
+// ListUnresolvedByMedicalIndex returns the open and acknowledged alerts raised for one measurement.
+func (r *alertRepository) ListUnresolvedByMedicalIndex(ctx context.Context, medicalIndexID uuid.UUID) ([]*entity.Alert, error) {
+	q := r.sb.Select(alertColumns...).From("alerts").
+		Where(squirrel.Eq{"medical_index_id": medicalIndexID}).
+		Where(squirrel.NotEq{"status": entity.AlertStatusResolved}).
+		OrderBy("created_at ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.Alert, 0)
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		out = append(out, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows alerts: %w", rows.Err())
+	}
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type atcRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewATCRepository(db *pgxpool.Pool) *atcRepository {
+	return &atcRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+// atcClassColumns include the number of active drugs whose code starts with the class code.
+var atcClassColumns = []string{
+	"a.code", "a.level", "COALESCE(a.parent_code, '')", "a.name_ru", "a.name_en",
+	"(SELECT COUNT(*) FROM drugs d WHERE d.is_active AND d.atc_code LIKE a.code || '%')",
+}
+
+// ListChildren returns the direct subclasses of parentCode ordered by code; an empty parentCode
+// lists the anatomical main groups.
+func (r *atcRepository) ListChildren(ctx context.Context, parentCode string) ([]*entity.ATCClass, error) {
+	q := r.sb.Select(atcClassColumns...).From("atc_classes a").OrderBy("a.code ASC")
+	if parentCode == "" {
+		q = q.Where(squirrel.Eq{"a.parent_code": nil})
+	} else {
+		q = q.Where(squirrel.Eq{"a.parent_code": parentCode})
+	}
+	return r.list(ctx, q)
+}
+
+// GetByCodes returns the known classes among codes ordered by code (and so by level along one branch).
+func (r *atcRepository) GetByCodes(ctx context.Context, codes []string) ([]*entity.ATCClass, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	q := r.sb.Select(atcClassColumns...).From("atc_classes a").
+		Where(squirrel.Eq{"a.code": codes}).
+		OrderBy("a.code ASC")
+	return r.list(ctx, q)
+}
+
+func (r *atcRepository) list(ctx context.Context, q squirrel.SelectBuilder) ([]*entity.ATCClass, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query atc_classes: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.ATCClass, 0)
+	for rows.Next() {
+		var c entity.ATCClass
+		if err := rows.Scan(&c.Code, &c.Level, &c.ParentCode, &c.NameRU, &c.NameEN, &c.DrugCount); err != nil {
+			return nil, fmt.Errorf("scan atc_class: %w", err)
+		}
+		out = append(out, &c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type clinicalInsightRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewClinicalInsightRepository(db *pgxpool.Pool) *clinicalInsightRepository {
+	return &clinicalInsightRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+var clinicalInsightColumns = []string{
+	"id", "patient_id", "requested_by", "kind", "insight_text", "COALESCE(interaction_text, '')", "context",
+	"COALESCE(ai_status, '')", "prompt_template_id", "COALESCE(prompt_version, 0)", "created_at",
+}
+
+func scanClinicalInsight(row pgx.Row) (*entity.ClinicalInsight, error) {
+	var item entity.ClinicalInsight
+	if err := row.Scan(
+		&item.ID, &item.PatientID, &item.RequestedBy, &item.Kind, &item.Text, &item.InteractionText, &item.Context,
+		&item.AIStatus, &item.PromptTemplateID, &item.PromptVersion, &item.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *clinicalInsightRepository) Create(ctx context.Context, item *entity.ClinicalInsight) error {
+	q := r.sb.Insert("clinical_insights").
+		Columns("id", "patient_id", "requested_by", "kind", "insight_text", "interaction_text", "context", "ai_status", "prompt_template_id", "prompt_version", "created_at").
+		Values(item.ID, item.PatientID, item.RequestedBy, item.Kind, item.Text, nullIfEmpty(item.InteractionText), item.Context, nullIfEmpty(item.AIStatus), item.PromptTemplateID, nullIfZero(item.PromptVersion), item.CreatedAt)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert clinical insight: %w", err)
+	}
+	return nil
+}
+
+func (r *clinicalInsightRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ClinicalInsight, error) {
+	q := r.sb.Select(clinicalInsightColumns...).From("clinical_insights").Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	item, err := scanClinicalInsight(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select clinical insight: %w", err)
+	}
+	return item, nil
+}
+
+// ListByPatient returns insights newest first; an empty kind matches every kind.
+func (r *clinicalInsightRepository) ListByPatient(ctx context.Context, patientID uuid.UUID, kind string, limit int, offset int) ([]*entity.ClinicalInsight, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	q := r.sb.Select(clinicalInsightColumns...).
+		From("clinical_insights").
+		Where(squirrel.Eq{"patient_id": patientID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+	if kind != "" {
+		q = q.Where(squirrel.Eq{"kind": kind})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query clinical insights: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.ClinicalInsight, 0)
+	for rows.Next() {
+		item, err := scanClinicalInsight(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan clinical insight: %w", err)
+		}
+		out = append(out, item)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows clinical insights: %w", rows.Err())
+	}
+	return out, nil
+}
+
+// UpdateText replaces the rule-based text once generation finished. An empty text keeps the current text.
+func (r *clinicalInsightRepository) UpdateText(ctx context.Context, id uuid.UUID, text string, interactionText string, aiStatus string) error {
+	q := r.sb.Update("clinical_insights").
+		Set("ai_status", aiStatus).
+		Where(squirrel.Eq{"id": id})
+	if text != "" {
+		q = q.Set("insight_text", text).Set("interaction_text", nullIfEmpty(interactionText))
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("update clinical insight text: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type drugInteractionRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewDrugInteractionRepository(db *pgxpool.Pool) *drugInteractionRepository {
+	return &drugInteractionRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+func (r *drugInteractionRepository) Create(ctx context.Context, rule *entity.DrugInteractionRule) error {
+	q := r.sb.Insert("drug_interaction_rules").
+		Columns("id", "drug_a_id", "atc_code_a", "drug_b_id", "atc_code_b", "severity", "mechanism", "management", "is_active", "created_by", "created_at").
+		Values(rule.ID, rule.DrugAID, nullIfEmpty(rule.ATCCodeA), rule.DrugBID, nullIfEmpty(rule.ATCCodeB),
+			rule.Severity, rule.Mechanism, rule.Management, rule.IsActive, rule.CreatedBy, rule.CreatedAt)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert drug interaction rule: %w", err)
+	}
+	return nil
+}
+
+// ListActive returns every active rule; the table is small enough to match in memory.
+func (r *drugInteractionRepository) ListActive(ctx context.Context) ([]*entity.DrugInteractionRule, error) {
+	q := r.sb.Select(
+		"id", "drug_a_id", "COALESCE(atc_code_a, '')", "drug_b_id", "COALESCE(atc_code_b, '')",
+		"severity", "mechanism", "management", "is_active", "created_by", "created_at",
+	).From("drug_interaction_rules").
+		Where(squirrel.Eq{"is_active": true}).
+		OrderBy("created_at ASC", "id ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query drug interaction rules: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.DrugInteractionRule, 0)
+	for rows.Next() {
+		var rule entity.DrugInteractionRule
+		if err := rows.Scan(
+			&rule.ID, &rule.DrugAID, &rule.ATCCodeA, &rule.DrugBID, &rule.ATCCodeB,
+			&rule.Severity, &rule.Mechanism, &rule.Management, &rule.IsActive, &rule.CreatedBy, &rule.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan drug interaction rule: %w", err)
+		}
+		out = append(out, &rule)
+	}
+	return out, rows.Err()
+}
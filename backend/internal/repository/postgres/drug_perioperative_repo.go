@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type drugPerioperativeRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewDrugPerioperativeRepository(db *pgxpool.Pool) *drugPerioperativeRepository {
+	return &drugPerioperativeRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+const perioperativeRuleColumns = "id, drug_id, COALESCE(atc_code, ''), dosing_interval_days, hold_intervals, resume_after_days, guidance, created_by, created_at"
+
+// Upsert stores a rule, replacing the one already set for the same drug or ATC class, and returns
+// the replaced rule (nil when none existed); rule.ID and rule.CreatedAt are updated to the stored row.
+func (r *drugPerioperativeRepository) Upsert(ctx context.Context, rule *entity.DrugPerioperativeRule) (*entity.DrugPerioperativeRule, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin perioperative rule: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	target := squirrel.Eq{"atc_code": rule.ATCCode}
+	conflict := "ON CONFLICT (atc_code) WHERE atc_code IS NOT NULL"
+	if rule.DrugID != nil {
+		target = squirrel.Eq{"drug_id": *rule.DrugID}
+		conflict = "ON CONFLICT (drug_id) WHERE drug_id IS NOT NULL"
+	}
+
+	sql, args, err := r.sb.Select(perioperativeRuleColumns).From("drug_perioperative_rules").
+		Where(target).Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+	previous, err := scanPerioperativeRule(tx.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		previous = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("select perioperative rule: %w", err)
+	}
+
+	q := r.sb.Insert("drug_perioperative_rules").
+		Columns("id", "drug_id", "atc_code", "dosing_interval_days", "hold_intervals", "resume_after_days", "guidance", "created_by", "created_at").
+		Values(rule.ID, rule.DrugID, nullIfEmpty(rule.ATCCode), rule.DosingIntervalDays, rule.HoldIntervals,
+			rule.ResumeAfterDays, rule.Guidance, rule.CreatedBy, rule.CreatedAt).
+		Suffix(conflict + ` DO UPDATE SET
+			dosing_interval_days = EXCLUDED.dosing_interval_days,
+			hold_intervals = EXCLUDED.hold_intervals,
+			resume_after_days = EXCLUDED.resume_after_days,
+			guidance = EXCLUDED.guidance,
+			created_by = EXCLUDED.created_by,
+			created_at = EXCLUDED.created_at
+			RETURNING id, created_at`)
+
+	sql, args, err = q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&rule.ID, &rule.CreatedAt); err != nil {
+		return nil, fmt.Errorf("upsert perioperative rule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit perioperative rule: %w", err)
+	}
+	return previous, nil
+}
+
+// List returns every rule; the table is small enough to match in memory.
+func (r *drugPerioperativeRepository) List(ctx context.Context) ([]*entity.DrugPerioperativeRule, error) {
+	q := r.sb.Select(perioperativeRuleColumns).
+		From("drug_perioperative_rules").
+		OrderBy("COALESCE(atc_code, '') ASC", "created_at ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query perioperative rules: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.DrugPerioperativeRule, 0)
+	for rows.Next() {
+		rule, err := scanPerioperativeRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan perioperative rule: %w", err)
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+func scanPerioperativeRule(row pgx.Row) (*entity.DrugPerioperativeRule, error) {
+	var rule entity.DrugPerioperativeRule
+	if err := row.Scan(
+		&rule.ID, &rule.DrugID, &rule.ATCCode, &rule.DosingIntervalDays, &rule.HoldIntervals,
+		&rule.ResumeAfterDays, &rule.Guidance, &rule.CreatedBy, &rule.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/translit"
 )
 
 type drugRepository struct {
@@ -19,6 +20,32 @@ type drugRepository struct {
 	sb squirrel.StatementBuilderType
 }
 
+var drugColumns = []string{
+	"id", "name",
+	"COALESCE(international_name, '')", "COALESCE(trade_name, '')",
+	"COALESCE(ncbi_pubchem_id, '')", "COALESCE(atc_code, '')",
+	"COALESCE(dosage_form, '')", "COALESCE(manufacturer, '')",
+	"COALESCE(description, '')", "COALESCE(contraindications, '')",
+	"is_active", "created_at", "updated_at",
+}
+
+// drugSearchDocument is the text fuzzy search matches against; migration 020 indexes the same expression.
+const drugSearchDocument = "LOWER(name || ' ' || COALESCE(international_name, '') || ' ' || COALESCE(trade_name, ''))"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func scanDrug(row pgx.Row) (*entity.Drug, error) {
+	var d entity.Drug
+	if err := row.Scan(
+		&d.ID, &d.Name, &d.InternationalName, &d.TradeName, &d.NCBIPubchemID, &d.ATCCode,
+		&d.DosageForm, &d.Manufacturer, &d.Description, &d.Contraindications,
+		&d.IsActive, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
 func NewDrugRepository(db *pgxpool.Pool) *drugRepository {
 	return &drugRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
 }
@@ -28,14 +55,7 @@ func (r *drugRepository) List(ctx context.Context, search string, limit int) ([]
 		limit = 50
 	}
 
-	q := r.sb.Select(
-		"id", "name",
-		"COALESCE(international_name, '')", "COALESCE(trade_name, '')",
-		"COALESCE(ncbi_pubchem_id, '')", "COALESCE(atc_code, '')",
-		"COALESCE(dosage_form, '')", "COALESCE(manufacturer, '')",
-		"COALESCE(description, '')", "COALESCE(contraindications, '')",
-		"is_active", "created_at", "updated_at",
-	).From("drugs")
+	q := r.sb.Select(drugColumns...).From("drugs")
 
 	if strings.TrimSpace(search) != "" {
 		like := "%" + strings.ToLower(search) + "%"
@@ -61,51 +81,134 @@ func (r *drugRepository) List(ctx context.Context, search string, limit int) ([]
 
 	var out []*entity.Drug
 	for rows.Next() {
-		var d entity.Drug
-		if err := rows.Scan(
-			&d.ID, &d.Name, &d.InternationalName, &d.TradeName, &d.NCBIPubchemID, &d.ATCCode,
-			&d.DosageForm, &d.Manufacturer, &d.Description, &d.Contraindications,
-			&d.IsActive, &d.CreatedAt, &d.UpdatedAt,
-		); err != nil {
+		d, err := scanDrug(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan drug: %w", err)
 		}
-		out = append(out, &d)
+		out = append(out, d)
 	}
 	return out, nil
 }
 
+// Search pages through drugs matching the filter. The query is tried as typed and transliterated
+// into the other script; a drug matches when any variant is a substring of its names or is close to
+// one of their words (pg_trgm word similarity, which tolerates typos). Results are ordered by the best
+// word similarity, then by name.
+func (r *drugRepository) Search(ctx context.Context, f entity.DrugFilter) ([]*entity.Drug, int64, error) {
+	where := squirrel.And{}
+	if f.ATCCode != "" {
+		where = append(where, squirrel.Like{"atc_code": likeEscaper.Replace(f.ATCCode) + "%"})
+	}
+	if f.IsActive != nil {
+		where = append(where, squirrel.Eq{"is_active": *f.IsActive})
+	}
+
+	variants := translit.Variants(f.Search)
+	rank := squirrel.Expr("0")
+	if len(variants) > 0 {
+		match := squirrel.Or{}
+		sims := make([]string, 0, len(variants))
+		args := make([]any, 0, len(variants))
+		for _, v := range variants {
+			match = append(match,
+				squirrel.Expr(drugSearchDocument+" LIKE ?", "%"+likeEscaper.Replace(v)+"%"),
+				squirrel.Expr("? <% "+drugSearchDocument, v),
+			)
+			sims = append(sims, "word_similarity(?, "+drugSearchDocument+")")
+			args = append(args, v)
+		}
+		where = append(where, match)
+		rank = squirrel.Expr("GREATEST("+strings.Join(sims, ", ")+")", args...)
+	}
+
+	countSQL, countArgs, err := r.sb.Select("COUNT(*)").From("drugs").Where(where).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build sql: %w", err)
+	}
+	var total int64
+	if err := r.db.QueryRow(ctx, countSQL, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count drugs: %w", err)
+	}
+
+	rankSQL, rankArgs, err := rank.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build sql: %w", err)
+	}
+	q := r.sb.Select(drugColumns...).From("drugs").Where(where).
+		OrderByClause(rankSQL+" DESC", rankArgs...).
+		OrderBy("name ASC").
+		Limit(uint64(f.PerPage)).
+		Offset(uint64((f.Page - 1) * f.PerPage))
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query drugs: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.Drug, 0, f.PerPage)
+	for rows.Next() {
+		d, err := scanDrug(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan drug: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, total, rows.Err()
+}
+
 func (r *drugRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Drug, error) {
-	q := r.sb.Select(
-		"id", "name",
-		"COALESCE(international_name, '')", "COALESCE(trade_name, '')",
-		"COALESCE(ncbi_pubchem_id, '')", "COALESCE(atc_code, '')",
-		"COALESCE(dosage_form, '')", "COALESCE(manufacturer, '')",
-		"COALESCE(description, '')", "COALESCE(contraindications, '')",
-		"is_active", "created_at", "updated_at",
-	).From("drugs").Where(squirrel.Eq{"id": id})
+	q := r.sb.Select(drugColumns...).From("drugs").Where(squirrel.Eq{"id": id})
 
 	sql, args, err := q.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build sql: %w", err)
 	}
 
-	row := r.db.QueryRow(ctx, sql, args...)
-	var d entity.Drug
-	if err := row.Scan(
-		&d.ID, &d.Name, &d.InternationalName, &d.TradeName, &d.NCBIPubchemID, &d.ATCCode,
-		&d.DosageForm, &d.Manufacturer, &d.Description, &d.Contraindications,
-		&d.IsActive, &d.CreatedAt, &d.UpdatedAt,
-	); err != nil {
+	d, err := scanDrug(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select drug: %w", err)
 	}
-	return &d, nil
+	return d, nil
+}
+
+// GetByIDs returns the drugs with the given IDs that exist, in no particular order.
+func (r *drugRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Drug, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sql, args, err := r.sb.Select(drugColumns...).From("drugs").Where(squirrel.Eq{"id": ids}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query drugs: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.Drug, 0, len(ids))
+	for rows.Next() {
+		d, err := scanDrug(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan drug: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
 }
 
-func (r *drugRepository) Create(ctx context.Context, drug *entity.Drug) error {
-	q := r.sb.Insert("drugs").
+func (r *drugRepository) insertQuery(drug *entity.Drug) squirrel.InsertBuilder {
+	return r.sb.Insert("drugs").
 		Columns(
 			"id", "name", "international_name", "trade_name", "ncbi_pubchem_id", "atc_code",
 			"dosage_form", "manufacturer", "description", "contraindications", "is_active",
@@ -116,20 +219,10 @@ func (r *drugRepository) Create(ctx context.Context, drug *entity.Drug) error {
 			drug.DosageForm, drug.Manufacturer, drug.Description, drug.Contraindications, drug.IsActive,
 			time.Now().UTC(), time.Now().UTC(),
 		)
-
-	sql, args, err := q.ToSql()
-	if err != nil {
-		return fmt.Errorf("build sql: %w", err)
-	}
-	_, err = r.db.Exec(ctx, sql, args...)
-	if err != nil {
-		return fmt.Errorf("insert drug: %w", err)
-	}
-	return nil
 }
 
-func (r *drugRepository) Update(ctx context.Context, drug *entity.Drug) error {
-	q := r.sb.Update("drugs").
+func (r *drugRepository) updateQuery(drug *entity.Drug) squirrel.UpdateBuilder {
+	return r.sb.Update("drugs").
 		Set("name", drug.Name).
 		Set("international_name", drug.InternationalName).
 		Set("trade_name", drug.TradeName).
@@ -142,14 +235,121 @@ func (r *drugRepository) Update(ctx context.Context, drug *entity.Drug) error {
 		Set("is_active", drug.IsActive).
 		Set("updated_at", time.Now().UTC()).
 		Where(squirrel.Eq{"id": drug.ID})
+}
+
+// drugNameConflict is the conflict target of the unique index on LOWER(name) (migration 028).
+const drugNameConflict = "ON CONFLICT ((LOWER(name)))"
+
+// Create inserts a drug; it reports false, without inserting, if another drug has the same name.
+func (r *drugRepository) Create(ctx context.Context, drug *entity.Drug) (bool, error) {
+	sql, args, err := r.insertQuery(drug).Suffix(drugNameConflict + " DO NOTHING").ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("insert drug: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
 
+// Update saves a drug; it reports false, without saving, if another drug already has the new name.
+func (r *drugRepository) Update(ctx context.Context, drug *entity.Drug) (bool, error) {
+	q := r.updateQuery(drug).Where(squirrel.Expr(
+		"NOT EXISTS (SELECT 1 FROM drugs other WHERE LOWER(other.name) = LOWER(?) AND other.id <> ?)", drug.Name, drug.ID))
 	sql, args, err := q.ToSql()
 	if err != nil {
-		return fmt.Errorf("build sql: %w", err)
+		return false, fmt.Errorf("build sql: %w", err)
 	}
-	_, err = r.db.Exec(ctx, sql, args...)
+	tag, err := r.db.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("update drug: %w", err)
+		return false, fmt.Errorf("update drug: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// BulkUpsert writes drugs matched by name in one transaction: either all rows are written or none.
+// The matched entries are read and locked in the same transaction, then every drug is inserted or,
+// when its name is taken, written over the existing entry; an entry that already holds the values is
+// left untouched. It returns, per drug, the stored entry and the matched entry before the write
+// (nil for a new drug).
+func (r *drugRepository) BulkUpsert(ctx context.Context, drugs []*entity.Drug) (stored, old []*entity.Drug, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin drug import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	names := make([]string, 0, len(drugs))
+	for _, drug := range drugs {
+		names = append(names, strings.ToLower(drug.Name))
+	}
+	sql, args, err := r.sb.Select(drugColumns...).From("drugs").
+		Where(squirrel.Expr("LOWER(name) = ANY(?)", names)).
+		Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("build sql: %w", err)
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query drugs: %w", err)
+	}
+	existing := make(map[string]*entity.Drug, len(drugs))
+	for rows.Next() {
+		d, err := scanDrug(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scan drug: %w", err)
+		}
+		existing[strings.ToLower(d.Name)] = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("query drugs: %w", err)
+	}
+
+	stored = make([]*entity.Drug, 0, len(drugs))
+	old = make([]*entity.Drug, 0, len(drugs))
+	for _, drug := range drugs {
+		prev := existing[strings.ToLower(drug.Name)]
+		sql, args, err := r.insertQuery(drug).Suffix(drugUpsertSuffix + " RETURNING " + strings.Join(drugColumns, ", ")).ToSql()
+		if err != nil {
+			return nil, nil, fmt.Errorf("build sql: %w", err)
+		}
+		d, err := scanDrug(tx.QueryRow(ctx, sql, args...))
+		switch {
+		case err == pgx.ErrNoRows && prev != nil:
+			// The entry already holds these values.
+			d = prev
+		case err != nil:
+			return nil, nil, fmt.Errorf("upsert drug %q: %w", drug.Name, err)
+		}
+		stored = append(stored, d)
+		old = append(old, prev)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit drug import: %w", err)
+	}
+	return stored, old, nil
+}
+
+// drugUpsertSuffix overwrites the entry with the same name, unless it already holds the values.
+var drugUpsertSuffix = drugNameConflict + ` DO UPDATE SET
+	name = EXCLUDED.name, international_name = EXCLUDED.international_name, trade_name = EXCLUDED.trade_name,
+	ncbi_pubchem_id = EXCLUDED.ncbi_pubchem_id, atc_code = EXCLUDED.atc_code, dosage_form = EXCLUDED.dosage_form,
+	manufacturer = EXCLUDED.manufacturer, description = EXCLUDED.description,
+	contraindications = EXCLUDED.contraindications, is_active = EXCLUDED.is_active, updated_at = EXCLUDED.updated_at
+	WHERE (` + drugCatalogueColumns("drugs") + `) IS DISTINCT FROM (` + drugCatalogueColumns("EXCLUDED") + `)`
+
+// drugCatalogueColumns lists the editable columns of a drug row as they are read (NULL as empty).
+func drugCatalogueColumns(table string) string {
+	cols := []string{table + ".name", table + ".is_active"}
+	for _, c := range []string{
+		"international_name", "trade_name", "ncbi_pubchem_id", "atc_code",
+		"dosage_form", "manufacturer", "description", "contraindications",
+	} {
+		cols = append(cols, "COALESCE("+table+"."+c+", '')")
 	}
-	return nil
+	return strings.Join(cols, ", ")
 }
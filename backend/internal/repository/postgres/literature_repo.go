@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type literatureRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewLiteratureRepository(db *pgxpool.Pool) *literatureRepository {
+	return &literatureRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+func (r *literatureRepository) GetQuery(ctx context.Context, term string) (*entity.PubMedQuery, error) {
+	q := r.sb.Select("term", "pmids", "fetched_at").From("pubmed_queries").Where(squirrel.Eq{"term": term})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	var out entity.PubMedQuery
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&out.Term, &out.PMIDs, &out.FetchedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select pubmed query: %w", err)
+	}
+	return &out, nil
+}
+
+func (r *literatureRepository) SaveQuery(ctx context.Context, pq *entity.PubMedQuery) error {
+	pmids := pq.PMIDs
+	if pmids == nil {
+		pmids = []string{}
+	}
+	q := r.sb.Insert("pubmed_queries").
+		Columns("term", "pmids", "fetched_at").
+		Values(pq.Term, pmids, pq.FetchedAt).
+		Suffix("ON CONFLICT (term) DO UPDATE SET pmids = EXCLUDED.pmids, fetched_at = EXCLUDED.fetched_at")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("upsert pubmed query: %w", err)
+	}
+	return nil
+}
+
+// GetAbstracts returns the cached abstracts among pmids, in no particular order.
+func (r *literatureRepository) GetAbstracts(ctx context.Context, pmids []string) ([]*entity.PubMedAbstract, error) {
+	if len(pmids) == 0 {
+		return nil, nil
+	}
+	q := r.sb.Select("pmid", "title", "abstract", "COALESCE(journal, '')", "COALESCE(pub_year, '')", "fetched_at").
+		From("pubmed_abstracts").
+		Where(squirrel.Eq{"pmid": pmids})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select pubmed abstracts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*entity.PubMedAbstract
+	for rows.Next() {
+		var a entity.PubMedAbstract
+		if err := rows.Scan(&a.PMID, &a.Title, &a.Abstract, &a.Journal, &a.PubYear, &a.FetchedAt); err != nil {
+			return nil, fmt.Errorf("scan pubmed abstract: %w", err)
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
+
+func (r *literatureRepository) UpsertAbstracts(ctx context.Context, items []*entity.PubMedAbstract) error {
+	if len(items) == 0 {
+		return nil
+	}
+	q := r.sb.Insert("pubmed_abstracts").
+		Columns("pmid", "title", "abstract", "journal", "pub_year", "fetched_at").
+		Suffix("ON CONFLICT (pmid) DO UPDATE SET title = EXCLUDED.title, abstract = EXCLUDED.abstract, " +
+			"journal = EXCLUDED.journal, pub_year = EXCLUDED.pub_year, fetched_at = EXCLUDED.fetched_at")
+	for _, a := range items {
+		q = q.Values(a.PMID, a.Title, a.Abstract, nullIfEmpty(a.Journal), nullIfEmpty(a.PubYear), a.FetchedAt)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("upsert pubmed abstracts: %w", err)
+	}
+	return nil
+}
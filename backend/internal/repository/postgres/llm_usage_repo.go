@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type llmUsageRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewLLMUsageRepository(db *pgxpool.Pool) *llmUsageRepository {
+	return &llmUsageRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+func (r *llmUsageRepository) Create(ctx context.Context, u *entity.LLMUsage) error {
+	q := r.sb.Insert("llm_usage").
+		Columns("id", "user_id", "survey_code", "purpose", "model", "input_tokens", "output_tokens", "latency_ms", "status", "created_at").
+		Values(u.ID, u.UserID, nullIfEmpty(u.SurveyCode), nullIfEmpty(u.Purpose), u.Model, u.InputTokens, u.OutputTokens, u.LatencyMS, u.Status, u.CreatedAt)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert llm_usage: %w", err)
+	}
+	return nil
+}
+
+// Totals counts the user's calls and tokens since the given time.
+func (r *llmUsageRepository) Totals(ctx context.Context, userID uuid.UUID, since time.Time) (*entity.LLMUsageTotals, error) {
+	q := r.sb.Select("COUNT(*)", "COALESCE(SUM(input_tokens + output_tokens), 0)").
+		From("llm_usage").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.GtOrEq{"created_at": since})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	var t entity.LLMUsageTotals
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&t.Requests, &t.Tokens); err != nil {
+		return nil, fmt.Errorf("sum llm_usage: %w", err)
+	}
+	return &t, nil
+}
+
+// Report aggregates usage in [from, to) by UTC day, survey code and model.
+func (r *llmUsageRepository) Report(ctx context.Context, from time.Time, to time.Time) ([]*entity.LLMUsageReportRow, error) {
+	q := r.sb.Select(
+		"to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day",
+		"COALESCE(survey_code, '')",
+		"model",
+		"COUNT(*)",
+		"COUNT(*) FILTER (WHERE status = 'error')",
+		"COALESCE(SUM(input_tokens), 0)",
+		"COALESCE(SUM(output_tokens), 0)",
+		"COALESCE(AVG(latency_ms), 0)::int",
+	).
+		From("llm_usage").
+		Where(squirrel.GtOrEq{"created_at": from}).
+		Where(squirrel.Lt{"created_at": to}).
+		GroupBy("day", "survey_code", "model").
+		OrderBy("day DESC", "survey_code", "model")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query llm_usage report: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.LLMUsageReportRow, 0)
+	for rows.Next() {
+		var row entity.LLMUsageReportRow
+		if err := rows.Scan(&row.Day, &row.SurveyCode, &row.Model, &row.Requests, &row.Errors, &row.InputTokens, &row.OutputTokens, &row.AvgLatencyMS); err != nil {
+			return nil, fmt.Errorf("scan llm_usage report: %w", err)
+		}
+		out = append(out, &row)
+	}
+	return out, rows.Err()
+}
+
+var llmQuotaColumns = []string{
+	"q.id", "q.role_id", "COALESCE(r.name, '')", "q.user_id", "q.daily_tokens", "q.daily_requests", "q.updated_by", "q.updated_at",
+}
+
+func scanLLMQuota(row pgx.Row) (*entity.LLMQuota, error) {
+	var q entity.LLMQuota
+	if err := row.Scan(&q.ID, &q.RoleID, &q.RoleName, &q.UserID, &q.DailyTokens, &q.DailyRequests, &q.UpdatedBy, &q.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// GetEffectiveQuota returns the user's own quota, or else the quota of the user's role; nil when neither exists.
+func (r *llmUsageRepository) GetEffectiveQuota(ctx context.Context, userID uuid.UUID) (*entity.LLMQuota, error) {
+	q := r.sb.Select(llmQuotaColumns...).
+		From("llm_quotas q").
+		LeftJoin("roles r ON r.id = q.role_id").
+		Where(squirrel.Or{
+			squirrel.Eq{"q.user_id": userID},
+			squirrel.Expr("q.role_id = (SELECT role_id FROM users WHERE id = ?)", userID),
+		}).
+		OrderBy("q.user_id IS NULL").
+		Limit(1)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	quota, err := scanLLMQuota(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select llm quota: %w", err)
+	}
+	return quota, nil
+}
+
+func (r *llmUsageRepository) ListQuotas(ctx context.Context) ([]*entity.LLMQuota, error) {
+	q := r.sb.Select(llmQuotaColumns...).
+		From("llm_quotas q").
+		LeftJoin("roles r ON r.id = q.role_id").
+		OrderBy("q.user_id IS NOT NULL", "r.name", "q.updated_at")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select llm quotas: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.LLMQuota, 0)
+	for rows.Next() {
+		quota, err := scanLLMQuota(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan llm quota: %w", err)
+		}
+		out = append(out, quota)
+	}
+	return out, rows.Err()
+}
+
+// UpsertQuota creates or replaces the quota of a role or user; q.ID is set to the stored row.
+func (r *llmUsageRepository) UpsertQuota(ctx context.Context, quota *entity.LLMQuota) error {
+	conflict := "ON CONFLICT (user_id) WHERE user_id IS NOT NULL"
+	if quota.RoleID != nil {
+		conflict = "ON CONFLICT (role_id) WHERE role_id IS NOT NULL"
+	}
+	q := r.sb.Insert("llm_quotas").
+		Columns("id", "role_id", "user_id", "daily_tokens", "daily_requests", "updated_by", "updated_at").
+		Values(quota.ID, quota.RoleID, quota.UserID, quota.DailyTokens, quota.DailyRequests, quota.UpdatedBy, quota.UpdatedAt).
+		Suffix(conflict + " DO UPDATE SET daily_tokens = EXCLUDED.daily_tokens, daily_requests = EXCLUDED.daily_requests, " +
+			"updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at RETURNING id")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&quota.ID); err != nil {
+		return fmt.Errorf("upsert llm quota: %w", err)
+	}
+	return nil
+}
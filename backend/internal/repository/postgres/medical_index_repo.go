@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type medicalIndexRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewMedicalIndexRepository(db *pgxpool.Pool) *medicalIndexRepository {
+	return &medicalIndexRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+// insertMedicalIndex builds the insert of one measurement; survey submission reuses it in its transaction.
+func insertMedicalIndex(sb squirrel.StatementBuilderType, idx *entity.MedicalIndex) squirrel.InsertBuilder {
+	return sb.Insert("medical_indices").
+		Columns("id", "patient_id", "index_type", "value", "category", "survey_response_id", "notes", "recorded_by", "recorded_at").
+		Values(idx.ID, idx.PatientID, idx.IndexType, idx.Value, idx.Category, idx.SurveyResponseID, idx.Notes, idx.RecordedByID, idx.RecordedAt)
+}
+
+func (r *medicalIndexRepository) Create(ctx context.Context, idx *entity.MedicalIndex) error {
+	sql, args, err := insertMedicalIndex(r.sb, idx).ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insert medical index: %w", err)
+	}
+	return nil
+}
+
+// ListByPatientAndType returns measurements in chronological order, optionally bounded by [from, to].
+func (r *medicalIndexRepository) ListByPatientAndType(ctx context.Context, patientID uuid.UUID, indexType string, from *time.Time, to *time.Time) ([]*entity.MedicalIndex, error) {
+	q := r.sb.Select(
+		"id", "patient_id", "index_type", "value", "COALESCE(category, '')",
+		"survey_response_id", "COALESCE(notes, '')", "recorded_by", "recorded_at",
+	).
+		From("medical_indices").
+		Where(squirrel.Eq{"patient_id": patientID, "index_type": indexType})
+	if from != nil {
+		q = q.Where(squirrel.GtOrEq{"recorded_at": *from})
+	}
+	if to != nil {
+		q = q.Where(squirrel.LtOrEq{"recorded_at": *to})
+	}
+	q = q.OrderBy("recorded_at ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query medical indices: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.MedicalIndex, 0)
+	for rows.Next() {
+		var idx entity.MedicalIndex
+		if err := rows.Scan(
+			&idx.ID, &idx.PatientID, &idx.IndexType, &idx.Value, &idx.Category,
+			&idx.SurveyResponseID, &idx.Notes, &idx.RecordedByID, &idx.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan medical index: %w", err)
+		}
+		out = append(out, &idx)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows medical indices: %w", rows.Err())
+	}
+	return out, nil
+}
+
+// GetPrevious returns the latest measurement of the same type recorded before the given one, or nil.
+func (r *medicalIndexRepository) GetPrevious(ctx context.Context, patientID uuid.UUID, indexType string, before time.Time, excludeID uuid.UUID) (*entity.MedicalIndex, error) {
+	q := r.sb.Select(
+		"id", "patient_id", "index_type", "value", "COALESCE(category, '')",
+		"survey_response_id", "COALESCE(notes, '')", "recorded_by", "recorded_at",
+	).
+		From("medical_indices").
+		Where(squirrel.Eq{"patient_id": patientID, "index_type": indexType}).
+		Where(squirrel.LtOrEq{"recorded_at": before}).
+		Where(squirrel.NotEq{"id": excludeID}).
+		OrderBy("recorded_at DESC").
+		Limit(1)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	var idx entity.MedicalIndex
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(
+		&idx.ID, &idx.PatientID, &idx.IndexType, &idx.Value, &idx.Category,
+		&idx.SurveyResponseID, &idx.Notes, &idx.RecordedByID, &idx.RecordedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select previous medical index: %w", err)
+	}
+	return &idx, nil
+}
+
+// UpdateValueBySurveyResponse rewrites the measurement derived from a re-scored response.
+func (r *medicalIndexRepository) UpdateValueBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID, value float64, category string) error {
+	q := r.sb.Update("medical_indices").
+		Set("value", value).
+		Set("category", category).
+		Where(squirrel.Eq{"survey_response_id": surveyResponseID})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update medical index value: %w", err)
+	}
+	return nil
+}
+
+// GetBySurveyResponse returns the measurement derived from a survey response, or nil.
+func (r *medicalIndexRepository) GetBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID) (*entity.MedicalIndex, error) {
+	q := r.sb.Select(
+		"id", "patient_id", "index_type", "value", "COALESCE(category, '')",
+		"survey_response_id", "COALESCE(notes, '')", "recorded_by", "recorded_at",
+	).
+		From("medical_indices").
+		Where(squirrel.Eq{"survey_response_id": surveyResponseID}).
+		OrderBy("recorded_at DESC").
+		Limit(1)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	var idx entity.MedicalIndex
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(
+		&idx.ID, &idx.PatientID, &idx.IndexType, &idx.Value, &idx.Category,
+		&idx.SurveyResponseID, &idx.Notes, &idx.RecordedByID, &idx.RecordedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select medical index by survey response: %w", err)
+	}
+	return &idx, nil
+}
+
+// ListLatestByPatient returns the most recent measurement of every index type the patient has.
+func (r *medicalIndexRepository) ListLatestByPatient(ctx context.Context, patientID uuid.UUID) ([]*entity.MedicalIndex, error) {
+	q := r.sb.Select(
+		"DISTINCT ON (index_type) id", "patient_id", "index_type", "value", "COALESCE(category, '')",
+		"survey_response_id", "COALESCE(notes, '')", "recorded_by", "recorded_at",
+	).
+		From("medical_indices").
+		Where(squirrel.Eq{"patient_id": patientID}).
+		OrderBy("index_type", "recorded_at DESC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query latest medical indices: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.MedicalIndex, 0)
+	for rows.Next() {
+		var idx entity.MedicalIndex
+		if err := rows.Scan(
+			&idx.ID, &idx.PatientID, &idx.IndexType, &idx.Value, &idx.Category,
+			&idx.SurveyResponseID, &idx.Notes, &idx.RecordedByID, &idx.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan medical index: %w", err)
+		}
+		out = append(out, &idx)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows medical indices: %w", rows.Err())
+	}
+	return out, nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type promptTemplateRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewPromptTemplateRepository(db *pgxpool.Pool) *promptTemplateRepository {
+	return &promptTemplateRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+var promptTemplateColumns = []string{
+	"id", "key", "COALESCE(survey_code, '')", "version", "system_prompt", "status", "COALESCE(notes, '')",
+	"created_by", "reviewed_by", "COALESCE(review_comment, '')", "created_at", "reviewed_at",
+}
+
+func scanPromptTemplate(row pgx.Row) (*entity.PromptTemplate, error) {
+	var p entity.PromptTemplate
+	if err := row.Scan(
+		&p.ID, &p.Key, &p.SurveyCode, &p.Version, &p.SystemPrompt, &p.Status, &p.Notes,
+		&p.CreatedBy, &p.ReviewedBy, &p.ReviewComment, &p.CreatedAt, &p.ReviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Create inserts a new version; the version number is the next one within the key and survey scope.
+func (r *promptTemplateRepository) Create(ctx context.Context, p *entity.PromptTemplate) error {
+	const sql = `
+		INSERT INTO prompt_templates (id, key, survey_code, version, system_prompt, status, notes, created_by, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(version), 0) + 1, $4, $5, $6, $7, $8
+		FROM prompt_templates
+		WHERE key = $2 AND COALESCE(survey_code, '') = COALESCE($3, '')
+		RETURNING version`
+
+	if err := r.db.QueryRow(ctx, sql,
+		p.ID, p.Key, nullIfEmpty(p.SurveyCode), p.SystemPrompt, p.Status, nullIfEmpty(p.Notes), p.CreatedBy, p.CreatedAt,
+	).Scan(&p.Version); err != nil {
+		return fmt.Errorf("insert prompt template: %w", err)
+	}
+	return nil
+}
+
+func (r *promptTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	q := r.sb.Select(promptTemplateColumns...).From("prompt_templates").Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	p, err := scanPromptTemplate(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select prompt template: %w", err)
+	}
+	return p, nil
+}
+
+// GetActive returns the approved version for a survey code, falling back to the approved default of the key.
+func (r *promptTemplateRepository) GetActive(ctx context.Context, key string, surveyCode string) (*entity.PromptTemplate, error) {
+	q := r.sb.Select(promptTemplateColumns...).From("prompt_templates").
+		Where(squirrel.Eq{"key": key, "status": entity.PromptStatusApproved}).
+		Where(squirrel.Or{squirrel.Eq{"survey_code": nil}, squirrel.Eq{"survey_code": surveyCode}}).
+		OrderBy("survey_code IS NULL").
+		Limit(1)
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	p, err := scanPromptTemplate(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select active prompt template: %w", err)
+	}
+	return p, nil
+}
+
+// List returns versions, newest first within each key and survey scope. Empty filters match everything.
+func (r *promptTemplateRepository) List(ctx context.Context, key string, surveyCode string) ([]*entity.PromptTemplate, error) {
+	q := r.sb.Select(promptTemplateColumns...).From("prompt_templates").
+		OrderBy("key", "survey_code NULLS FIRST", "version DESC")
+	if key != "" {
+		q = q.Where(squirrel.Eq{"key": key})
+	}
+	if surveyCode != "" {
+		q = q.Where(squirrel.Eq{"survey_code": surveyCode})
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*entity.PromptTemplate
+	for rows.Next() {
+		p, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan prompt template: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// Review moves a draft to approved or rejected. Approving retires the previously approved version
+// of the same key and survey scope in the same transaction. It reports false if the version is no longer a draft.
+func (r *promptTemplateRepository) Review(ctx context.Context, p *entity.PromptTemplate, status string, reviewedBy uuid.UUID, comment string, at time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin prompt review: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if status == entity.PromptStatusApproved {
+		retire := r.sb.Update("prompt_templates").
+			Set("status", entity.PromptStatusRetired).
+			Where(squirrel.Eq{"key": p.Key, "status": entity.PromptStatusApproved}).
+			Where("COALESCE(survey_code, '') = ?", p.SurveyCode)
+
+		sql, args, err := retire.ToSql()
+		if err != nil {
+			return false, fmt.Errorf("build sql: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return false, fmt.Errorf("retire approved prompt template: %w", err)
+		}
+	}
+
+	upd := r.sb.Update("prompt_templates").
+		Set("status", status).
+		Set("reviewed_by", reviewedBy).
+		Set("review_comment", nullIfEmpty(comment)).
+		Set("reviewed_at", at).
+		Where(squirrel.Eq{"id": p.ID, "status": entity.PromptStatusDraft})
+
+	sql, args, err := upd.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	ct, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("update prompt template: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit prompt review: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type rescoreJobRepository struct {
+	db *pgxpool.Pool
+	sb squirrel.StatementBuilderType
+}
+
+func NewRescoreJobRepository(db *pgxpool.Pool) *rescoreJobRepository {
+	return &rescoreJobRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
+}
+
+// rescoreActiveStatuses are the non-terminal statuses; a template has at most one job in them
+// (idx_rescore_jobs_one_active).
+var rescoreActiveStatuses = []string{entity.RescoreStatusPending, entity.RescoreStatusRunning, entity.RescoreStatusReady, entity.RescoreStatusApplying}
+
+// Create inserts a job; it reports false, without inserting, if the template already has an active job.
+func (r *rescoreJobRepository) Create(ctx context.Context, job *entity.RescoreJob) (bool, error) {
+	q := r.sb.Insert("rescore_jobs").
+		Columns("id", "template_id", "template_version", "requested_by", "status", "created_at").
+		Values(job.ID, job.TemplateID, job.TemplateVersion, job.RequestedBy, job.Status, job.CreatedAt).
+		Suffix("ON CONFLICT (template_id) WHERE status IN ('pending', 'running', 'ready', 'applying') DO NOTHING")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("insert rescore job: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+func (r *rescoreJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.RescoreJob, error) {
+	q := r.sb.Select(
+		"id", "template_id", "COALESCE(template_version, 0)", "requested_by", "status",
+		"total_count", "changed_count", "category_changed_count", "report", "COALESCE(error, '')",
+		"confirmed_by", "confirmed_at", "created_at", "finished_at", "applied_at", "updated_at",
+	).From("rescore_jobs").Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	var j entity.RescoreJob
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(
+		&j.ID, &j.TemplateID, &j.TemplateVersion, &j.RequestedBy, &j.Status,
+		&j.TotalCount, &j.ChangedCount, &j.CategoryChangedCount, &j.Report, &j.Error,
+		&j.ConfirmedBy, &j.ConfirmedAt, &j.CreatedAt, &j.FinishedAt, &j.AppliedAt, &j.UpdatedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select rescore job: %w", err)
+	}
+	return &j, nil
+}
+
+// HasActive reports whether the template already has a job that is running or awaiting confirmation.
+func (r *rescoreJobRepository) HasActive(ctx context.Context, templateID uuid.UUID) (bool, error) {
+	q := r.sb.Select("COUNT(*)").From("rescore_jobs").
+		Where(squirrel.Eq{"template_id": templateID}).
+		Where(squirrel.Eq{"status": rescoreActiveStatuses})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+
+	var n int
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&n); err != nil {
+		return false, fmt.Errorf("count active rescore jobs: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Update persists the mutable fields of a job (status, counters, report and timestamps).
+func (r *rescoreJobRepository) Update(ctx context.Context, job *entity.RescoreJob) error {
+	q := r.sb.Update("rescore_jobs").
+		Set("status", job.Status).
+		Set("total_count", job.TotalCount).
+		Set("changed_count", job.ChangedCount).
+		Set("category_changed_count", job.CategoryChangedCount).
+		Set("report", job.Report).
+		Set("error", nullIfEmpty(job.Error)).
+		Set("confirmed_by", job.ConfirmedBy).
+		Set("confirmed_at", job.ConfirmedAt).
+		Set("finished_at", job.FinishedAt).
+		Set("applied_at", job.AppliedAt).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": job.ID})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update rescore job: %w", err)
+	}
+	return nil
+}
+
+// TransitionStatus atomically moves a job from one status to another; it reports false if the job was not in "from".
+func (r *rescoreJobRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from string, to string) (bool, error) {
+	q := r.sb.Update("rescore_jobs").
+		Set("status", to).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": id, "status": from})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("transition rescore job: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// FailStale marks jobs that are pending, running or applying but were last updated before
+// updatedBefore as failed with the given reason, and returns how many were failed.
+func (r *rescoreJobRepository) FailStale(ctx context.Context, updatedBefore time.Time, reason string) (int64, error) {
+	q := r.sb.Update("rescore_jobs").
+		Set("status", entity.RescoreStatusFailed).
+		Set("error", reason).
+		Set("finished_at", squirrel.Expr("NOW()")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"status": []string{entity.RescoreStatusPending, entity.RescoreStatusRunning, entity.RescoreStatusApplying}}).
+		Where(squirrel.Lt{"updated_at": updatedBefore})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql: %w", err)
+	}
+
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("fail stale rescore jobs: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+func (r *rescoreJobRepository) AddItems(ctx context.Context, items []*entity.RescoreJobItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	q := r.sb.Insert("rescore_job_items").
+		Columns(
+			"id", "job_id", "survey_response_id", "old_score", "new_score",
+			"old_category", "new_category", "old_breakdown", "new_breakdown", "category_changed",
+		)
+	for _, it := range items {
+		q = q.Values(
+			it.ID, it.JobID, it.SurveyResponseID, it.OldScore, it.NewScore,
+			it.OldCategory, it.NewCategory, it.OldBreakdown, it.NewBreakdown, it.CategoryChanged,
+		)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("insert rescore job items: %w", err)
+	}
+	return nil
+}
+
+func (r *rescoreJobRepository) ListItems(ctx context.Context, jobID uuid.UUID, categoryChangedOnly bool, limit int, offset int) ([]*entity.RescoreJobItem, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	q := r.sb.Select(
+		"id", "job_id", "survey_response_id", "old_score", "new_score",
+		"COALESCE(old_category, '')", "new_category", "old_breakdown", "new_breakdown", "category_changed",
+	).From("rescore_job_items").Where(squirrel.Eq{"job_id": jobID})
+	if categoryChangedOnly {
+		q = q.Where(squirrel.Eq{"category_changed": true})
+	}
+	q = q.OrderBy("id ASC").Limit(uint64(limit)).Offset(uint64(offset))
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query rescore job items: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.RescoreJobItem, 0)
+	for rows.Next() {
+		var it entity.RescoreJobItem
+		if err := rows.Scan(
+			&it.ID, &it.JobID, &it.SurveyResponseID, &it.OldScore, &it.NewScore,
+			&it.OldCategory, &it.NewCategory, &it.OldBreakdown, &it.NewBreakdown, &it.CategoryChanged,
+		); err != nil {
+			return nil, fmt.Errorf("scan rescore job item: %w", err)
+		}
+		out = append(out, &it)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows rescore job items: %w", rows.Err())
+	}
+	return out, nil
+}
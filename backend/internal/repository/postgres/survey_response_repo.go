@@ -23,16 +23,46 @@ func NewSurveyResponseRepository(db *pgxpool.Pool) *surveyResponseRepository {
 	return &surveyResponseRepository{db: db, sb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)}
 }
 
-func (r *surveyResponseRepository) Create(ctx context.Context, resp *entity.SurveyResponse) error {
+var surveyResponseColumns = []string{
+	"sr.id", "sr.template_id", "sr.patient_id", "sr.responses", "sr.calculated_score",
+	"sr.score_breakdown", "COALESCE(sr.interpretation, '')", "COALESCE(sr.category, '')",
+	"COALESCE(sr.category_override, '')", "COALESCE(sr.override_reason, '')",
+	"COALESCE(sr.ai_summary, '')", "COALESCE(sr.ai_status, '')", "sr.status", "sr.revision",
+	"sr.submitted_at", "sr.reviewed_by", "sr.reviewed_at", "COALESCE(sr.notes, '')", "sr.created_at",
+}
+
+func scanSurveyResponse(row pgx.Row, dest ...any) (*entity.SurveyResponse, error) {
+	var sr entity.SurveyResponse
+	fields := []any{
+		&sr.ID, &sr.TemplateID, &sr.PatientID, &sr.Responses, &sr.CalculatedScore,
+		&sr.ScoreBreakdown, &sr.Interpretation, &sr.Category,
+		&sr.CategoryOverride, &sr.OverrideReason,
+		&sr.AISummary, &sr.AIStatus, &sr.Status, &sr.Revision,
+		&sr.SubmittedAt, &sr.ReviewedBy, &sr.ReviewedAt, &sr.Notes, &sr.CreatedAt,
+	}
+	if err := row.Scan(append(fields, dest...)...); err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
+
+// Create stores a response and, when idx is not nil, its index history entry in one transaction.
+func (r *surveyResponseRepository) Create(ctx context.Context, resp *entity.SurveyResponse, idx *entity.MedicalIndex) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin survey response: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	q := r.sb.Insert("survey_responses").
 		Columns(
 			"id", "template_id", "patient_id", "responses", "calculated_score",
-			"score_breakdown", "interpretation", "ai_summary", "status",
+			"score_breakdown", "interpretation", "category", "ai_summary", "ai_status", "status",
 			"submitted_at", "reviewed_by", "reviewed_at", "notes", "created_at",
 		).
 		Values(
 			resp.ID, resp.TemplateID, resp.PatientID, resp.Responses, resp.CalculatedScore,
-			resp.ScoreBreakdown, resp.Interpretation, resp.AISummary, resp.Status,
+			resp.ScoreBreakdown, resp.Interpretation, resp.Category, resp.AISummary, nullIfEmpty(resp.AIStatus), resp.Status,
 			resp.SubmittedAt, resp.ReviewedBy, resp.ReviewedAt, resp.Notes, resp.CreatedAt,
 		)
 
@@ -40,39 +70,44 @@ func (r *surveyResponseRepository) Create(ctx context.Context, resp *entity.Surv
 	if err != nil {
 		return fmt.Errorf("build sql: %w", err)
 	}
-
-	_, err = r.db.Exec(ctx, sql, args...)
-	if err != nil {
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
 		return fmt.Errorf("insert survey response: %w", err)
 	}
+
+	if idx != nil {
+		sql, args, err = insertMedicalIndex(r.sb, idx).ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("insert medical index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit survey response: %w", err)
+	}
 	return nil
 }
 
 func (r *surveyResponseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.SurveyResponse, error) {
-	q := r.sb.Select(
-		"id", "template_id", "patient_id", "responses", "calculated_score",
-		"score_breakdown", "interpretation", "ai_summary", "status",
-		"submitted_at", "reviewed_by", "reviewed_at", "notes", "created_at",
-	).From("survey_responses").Where(squirrel.Eq{"id": id})
+	q := r.sb.Select(surveyResponseColumns...).
+		From("survey_responses sr").
+		Where(squirrel.Eq{"sr.id": id})
 
 	sql, args, err := q.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build sql: %w", err)
 	}
 
-	row := r.db.QueryRow(ctx, sql, args...)
-	var sr entity.SurveyResponse
-	if err := row.Scan(
-		&sr.ID, &sr.TemplateID, &sr.PatientID, &sr.Responses, &sr.CalculatedScore,
-		&sr.ScoreBreakdown, &sr.Interpretation, &sr.AISummary, &sr.Status,
-		&sr.SubmittedAt, &sr.ReviewedBy, &sr.ReviewedAt, &sr.Notes, &sr.CreatedAt,
-	); err != nil {
+	sr, err := scanSurveyResponse(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select response: %w", err)
 	}
-	return &sr, nil
+	return sr, nil
 }
 
 func (r *surveyResponseRepository) ListByPatient(ctx context.Context, patientID uuid.UUID, limit int) ([]*entity.SurveyResponse, error) {
@@ -80,13 +115,10 @@ func (r *surveyResponseRepository) ListByPatient(ctx context.Context, patientID
 		limit = 50
 	}
 
-	q := r.sb.Select(
-		"id", "template_id", "patient_id", "responses", "calculated_score",
-		"score_breakdown", "interpretation", "ai_summary", "status",
-		"submitted_at", "reviewed_by", "reviewed_at", "notes", "created_at",
-	).From("survey_responses").
-		Where(squirrel.Eq{"patient_id": patientID}).
-		OrderBy("submitted_at DESC").
+	q := r.sb.Select(surveyResponseColumns...).
+		From("survey_responses sr").
+		Where(squirrel.Eq{"sr.patient_id": patientID}).
+		OrderBy("sr.submitted_at DESC").
 		Limit(uint64(limit))
 
 	sql, args, err := q.ToSql()
@@ -102,15 +134,59 @@ func (r *surveyResponseRepository) ListByPatient(ctx context.Context, patientID
 
 	var out []*entity.SurveyResponse
 	for rows.Next() {
-		var sr entity.SurveyResponse
-		if err := rows.Scan(
-			&sr.ID, &sr.TemplateID, &sr.PatientID, &sr.Responses, &sr.CalculatedScore,
-			&sr.ScoreBreakdown, &sr.Interpretation, &sr.AISummary, &sr.Status,
-			&sr.SubmittedAt, &sr.ReviewedBy, &sr.ReviewedAt, &sr.Notes, &sr.CreatedAt,
-		); err != nil {
+		sr, err := scanSurveyResponse(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan response: %w", err)
 		}
-		out = append(out, &sr)
+		out = append(out, sr)
+	}
+	return out, nil
+}
+
+// ListForDoctor returns responses of patients attended by doctorID, oldest first, with the template joined.
+func (r *surveyResponseRepository) ListForDoctor(ctx context.Context, doctorID uuid.UUID, status string, limit int, offset int) ([]*entity.SurveyResponse, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	q := r.sb.Select(append(surveyResponseColumns, "st.code", "st.name")...).
+		From("survey_responses sr").
+		Join("patients p ON p.id = sr.patient_id").
+		Join("survey_templates st ON st.id = sr.template_id").
+		Where(squirrel.Eq{"p.attending_doctor_id": doctorID})
+	if status != "" {
+		q = q.Where(squirrel.Eq{"sr.status": status})
+	}
+	q = q.OrderBy("sr.submitted_at ASC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.SurveyResponse, 0)
+	for rows.Next() {
+		var code, name string
+		sr, err := scanSurveyResponse(rows, &code, &name)
+		if err != nil {
+			return nil, fmt.Errorf("scan response: %w", err)
+		}
+		sr.Template = &entity.SurveyTemplate{ID: sr.TemplateID, Code: code, Name: name}
+		out = append(out, sr)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows review queue: %w", rows.Err())
 	}
 	return out, nil
 }
@@ -121,6 +197,7 @@ func (r *surveyResponseRepository) UpdateCalculated(ctx context.Context, id uuid
 	q := r.sb.Update("survey_responses").
 		Set("calculated_score", score).
 		Set("score_breakdown", breakdownJSON).
+		Set("category", category).
 		Set("interpretation", interpretation).
 		Set("ai_summary", aiSummary).
 		Set("status", entity.SurveyStatusSubmitted).
@@ -135,7 +212,247 @@ func (r *surveyResponseRepository) UpdateCalculated(ctx context.Context, id uuid
 	if err != nil {
 		return fmt.Errorf("update calculated: %w", err)
 	}
-	_ = category
-	_ = time.Now()
 	return nil
 }
+
+// UpdateReview marks a response reviewed. An empty categoryOverride clears any previous override.
+// A non-empty indexCategory is written to the response's medical index entry in the same transaction.
+func (r *surveyResponseRepository) UpdateReview(ctx context.Context, id uuid.UUID, reviewedBy uuid.UUID, reviewedAt time.Time, notes string, categoryOverride string, overrideReason string, indexCategory string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin review: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.sb.Update("survey_responses").
+		Set("status", entity.SurveyStatusReviewed).
+		Set("reviewed_by", reviewedBy).
+		Set("reviewed_at", reviewedAt).
+		Set("notes", notes).
+		Set("category_override", nullIfEmpty(categoryOverride)).
+		Set("override_reason", nullIfEmpty(overrideReason)).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("update review: %w", err)
+	}
+
+	if indexCategory != "" {
+		idx := r.sb.Update("medical_indices").
+			Set("category", indexCategory).
+			Where(squirrel.Eq{"survey_response_id": id})
+		sql, args, err = idx.ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql: %w", err)
+		}
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("update medical index category: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit review: %w", err)
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullIfZero(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// ListByTemplate pages through all responses of a template in id order (keyset pagination after afterID).
+func (r *surveyResponseRepository) ListByTemplate(ctx context.Context, templateID uuid.UUID, afterID uuid.UUID, limit int) ([]*entity.SurveyResponse, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+
+	q := r.sb.Select(surveyResponseColumns...).
+		From("survey_responses sr").
+		Where(squirrel.Eq{"sr.template_id": templateID}).
+		Where(squirrel.Gt{"sr.id": afterID}).
+		OrderBy("sr.id ASC").
+		Limit(uint64(limit))
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query responses by template: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*entity.SurveyResponse
+	for rows.Next() {
+		sr, err := scanSurveyResponse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan response: %w", err)
+		}
+		out = append(out, sr)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows responses by template: %w", rows.Err())
+	}
+	return out, nil
+}
+
+// UpdateScore rewrites the engine result of a response without touching its review state.
+func (r *surveyResponseRepository) UpdateScore(ctx context.Context, id uuid.UUID, score float64, category string, interpretation string, breakdown json.RawMessage) error {
+	q := r.sb.Update("survey_responses").
+		Set("calculated_score", score).
+		Set("category", category).
+		Set("interpretation", interpretation).
+		Set("score_breakdown", breakdown).
+		Where(squirrel.Eq{"id": id})
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql: %w", err)
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update score: %w", err)
+	}
+	return nil
+}
+
+// Amend replaces the answers and engine result of a response and appends the revision record in one
+// transaction, dropping the category override when clearOverride is set. It reports false when the
+// response is no longer at rev.Revision-1 (concurrent amendment).
+func (r *surveyResponseRepository) Amend(ctx context.Context, rev *entity.SurveyResponseRevision, status string, interpretation string, aiStatus string, breakdown json.RawMessage, clearOverride bool) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin amend: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	upd := r.sb.Update("survey_responses").
+		Set("responses", rev.NewResponses).
+		Set("calculated_score", rev.NewScore).
+		Set("category", rev.NewCategory).
+		Set("score_breakdown", breakdown).
+		Set("interpretation", interpretation).
+		Set("ai_status", nullIfEmpty(aiStatus)).
+		Set("status", status).
+		Set("revision", rev.Revision).
+		Where(squirrel.Eq{"id": rev.SurveyResponseID, "revision": rev.Revision - 1})
+	if clearOverride {
+		upd = upd.Set("category_override", nil).Set("override_reason", nil)
+	}
+
+	sql, args, err := upd.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	ct, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("update amended response: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	ins := r.sb.Insert("survey_response_revisions").
+		Columns(
+			"id", "survey_response_id", "revision", "amended_by", "reason",
+			"old_responses", "new_responses", "old_score", "new_score",
+			"old_category", "new_category", "created_at",
+		).
+		Values(
+			rev.ID, rev.SurveyResponseID, rev.Revision, rev.AmendedBy, rev.Reason,
+			rev.OldResponses, rev.NewResponses, rev.OldScore, rev.NewScore,
+			nullIfEmpty(rev.OldCategory), rev.NewCategory, rev.CreatedAt,
+		)
+
+	sql, args, err = ins.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return false, fmt.Errorf("insert response revision: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit amend: %w", err)
+	}
+	return true, nil
+}
+
+// ListRevisions returns the amendment chain of a response, oldest first.
+func (r *surveyResponseRepository) ListRevisions(ctx context.Context, responseID uuid.UUID) ([]*entity.SurveyResponseRevision, error) {
+	q := r.sb.Select(
+		"id", "survey_response_id", "revision", "amended_by", "reason",
+		"old_responses", "new_responses", "old_score", "new_score",
+		"COALESCE(old_category, '')", "new_category", "created_at",
+	).
+		From("survey_response_revisions").
+		Where(squirrel.Eq{"survey_response_id": responseID}).
+		OrderBy("revision ASC")
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query response revisions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]*entity.SurveyResponseRevision, 0)
+	for rows.Next() {
+		var rev entity.SurveyResponseRevision
+		if err := rows.Scan(
+			&rev.ID, &rev.SurveyResponseID, &rev.Revision, &rev.AmendedBy, &rev.Reason,
+			&rev.OldResponses, &rev.NewResponses, &rev.OldScore, &rev.NewScore,
+			&rev.OldCategory, &rev.NewCategory, &rev.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan response revision: %w", err)
+		}
+		out = append(out, &rev)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("rows response revisions: %w", rows.Err())
+	}
+	return out, nil
+}
+
+// UpdateAIResult stores the outcome of an AI interpretation job. It only applies while the response
+// is still at the given revision, so a late job cannot overwrite a newer amendment.
+func (r *surveyResponseRepository) UpdateAIResult(ctx context.Context, id uuid.UUID, revision int, interpretation string, aiStatus string) (bool, error) {
+	q := r.sb.Update("survey_responses").
+		Set("ai_status", aiStatus).
+		Where(squirrel.Eq{"id": id, "revision": revision})
+	if interpretation != "" {
+		q = q.Set("interpretation", interpretation)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql: %w", err)
+	}
+
+	ct, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("update ai result: %w", err)
+	}
+	return ct.RowsAffected() > 0, nil
+}
@@ -14,11 +14,26 @@ type Repositories struct {
 
 	SurveyTemplate SurveyTemplateRepository
 	SurveyResponse SurveyResponseRepository
+	MedicalIndex   MedicalIndexRepository
+	AlertRule      AlertRuleRepository
+	Alert          AlertRepository
+	RescoreJob     RescoreJobRepository
+	AIJob          AIJobRepository
+	PromptTemplate PromptTemplateRepository
 
-	Drug       DrugRepository
-	TherapyLog TherapyLogRepository
-	Patient    PatientRepository
-	AIAdvice   AIAdviceRepository
+	Drug            DrugRepository
+	ATC             ATCRepository
+	DrugInteraction DrugInteractionRepository
+	Perioperative   DrugPerioperativeRepository
+	TherapyLog      TherapyLogRepository
+	Patient         PatientRepository
+	AIAdvice        AIAdviceRepository
+	AdviceMessage   AdviceMessageRepository
+	AdviceFeedback  AdviceFeedbackRepository
+	AdviceCache     AdviceCacheRepository
+	ClinicalInsight ClinicalInsightRepository
+	Literature      LiteratureRepository
+	LLMUsage        LLMUsageRepository
 
 	Role       RoleRepository
 	Permission PermissionRepository
@@ -27,16 +42,31 @@ type Repositories struct {
 // NewRepositories initializes all repository implementations.
 func NewRepositories(db *pgxpool.Pool) *Repositories {
 	return &Repositories{
-		User:           postgres.NewUserRepository(db),
-		RefreshToken:   postgres.NewRefreshTokenRepository(db),
-		AuditLog:       postgres.NewAuditLogRepository(db),
-		SurveyTemplate: postgres.NewSurveyTemplateRepository(db),
-		SurveyResponse: postgres.NewSurveyResponseRepository(db),
-		Drug:           postgres.NewDrugRepository(db),
-		TherapyLog:     postgres.NewTherapyLogRepository(db),
-		Patient:        postgres.NewPatientRepository(db),
-		AIAdvice:       postgres.NewAIAdviceRepository(db),
-		Role:           postgres.NewRoleRepository(db),
-		Permission:     postgres.NewPermissionRepository(db),
+		User:            postgres.NewUserRepository(db),
+		RefreshToken:    postgres.NewRefreshTokenRepository(db),
+		AuditLog:        postgres.NewAuditLogRepository(db),
+		SurveyTemplate:  postgres.NewSurveyTemplateRepository(db),
+		SurveyResponse:  postgres.NewSurveyResponseRepository(db),
+		MedicalIndex:    postgres.NewMedicalIndexRepository(db),
+		AlertRule:       postgres.NewAlertRuleRepository(db),
+		Alert:           postgres.NewAlertRepository(db),
+		RescoreJob:      postgres.NewRescoreJobRepository(db),
+		AIJob:           postgres.NewAIJobRepository(db),
+		PromptTemplate:  postgres.NewPromptTemplateRepository(db),
+		Drug:            postgres.NewDrugRepository(db),
+		ATC:             postgres.NewATCRepository(db),
+		DrugInteraction: postgres.NewDrugInteractionRepository(db),
+		Perioperative:   postgres.NewDrugPerioperativeRepository(db),
+		TherapyLog:      postgres.NewTherapyLogRepository(db),
+		Patient:         postgres.NewPatientRepository(db),
+		AIAdvice:        postgres.NewAIAdviceRepository(db),
+		AdviceMessage:   postgres.NewAdviceMessageRepository(db),
+		AdviceFeedback:  postgres.NewAdviceFeedbackRepository(db),
+		AdviceCache:     postgres.NewAdviceCacheRepository(db),
+		ClinicalInsight: postgres.NewClinicalInsightRepository(db),
+		Literature:      postgres.NewLiteratureRepository(db),
+		LLMUsage:        postgres.NewLLMUsageRepository(db),
+		Role:            postgres.NewRoleRepository(db),
+		Permission:      postgres.NewPermissionRepository(db),
 	}
 }
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+// adviceCacheKeyVersion is part of every cache key; bump it when the key derivation or the way
+// cached text is produced changes, so older entries stop matching.
+const adviceCacheKeyVersion = "1"
+
+// adviceCachePurgeInterval is how often writing to the cache also deletes expired entries; reads
+// already ignore them, the purge only keeps the table from growing.
+const adviceCachePurgeInterval = time.Hour
+
+// adviceCacheKey returns the cache key for a scored request, or "" when the result must not be cached:
+// caching is off, no LLM is involved, or the patient added a comment (free text makes the prompt unique).
+// The key covers the survey code and template version, the prompt version and text, the model and the
+// canonical answers, so a new template, prompt or model never serves older advice.
+func (s *AIAdviceService) adviceCacheKey(in *adviceInput, answers map[string]any, userText string) string {
+	if s.cache == nil || s.cacheTTL <= 0 || in.prompt == nil || strings.TrimSpace(userText) != "" {
+		return ""
+	}
+	canonical, err := json.Marshal(canonicalAnswers(answers))
+	if err != nil {
+		return ""
+	}
+	promptHash := sha256.Sum256([]byte(in.prompt.SystemPrompt))
+
+	h := sha256.New()
+	fmt.Fprintf(h, "v%s\n%s\n%d\n%d\n%x\n%s\n", adviceCacheKeyVersion, in.template.Code, in.template.Version,
+		in.prompt.Version, promptHash, in.model)
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalAnswers normalizes answers so equivalent submissions hash alike: numbers of any Go type
+// become float64 and null answers are dropped (scoring treats them as missing). json.Marshal sorts
+// map keys. Strings are kept verbatim because scoring does not treat "1" and 1 the same.
+func canonicalAnswers(answers map[string]any) map[string]any {
+	out := make(map[string]any, len(answers))
+	for k, v := range answers {
+		if v == nil {
+			continue
+		}
+		out[k] = canonicalValue(v)
+	}
+	return out
+}
+
+func canonicalValue(v any) any {
+	switch val := v.(type) {
+	case float64, float32, int, int64, json.Number:
+		return toFloat(val)
+	case map[string]any:
+		return canonicalAnswers(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = canonicalValue(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
+
+// cachedAdvice returns the live cache entry for key; lookup failures are logged and treated as a miss.
+func (s *AIAdviceService) cachedAdvice(ctx context.Context, key string) *entity.AdviceCacheEntry {
+	if key == "" {
+		return nil
+	}
+	e, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("[AIAdvice] advice cache lookup failed: %v", err)
+		return nil
+	}
+	return e
+}
+
+// storeCachedAdvice records a cache hit as a new advice record of the patient.
+func (s *AIAdviceService) storeCachedAdvice(ctx context.Context, in *adviceInput, hit *entity.AdviceCacheEntry) (*AIAdviceResult, error) {
+	item := in.newAdvice("", hit.AdviceText, hit.Sections, entity.AIStatusDone)
+	item.Citations = hit.Citations
+	if err := s.adviceRepo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	result := toAdviceResult(item)
+	result.Cached = true
+	return result, nil
+}
+
+// cacheAdvice stores generated advice under key; failures only cost a future LLM call, so they are logged.
+func (s *AIAdviceService) cacheAdvice(ctx context.Context, key string, t *entity.SurveyTemplate, prompt *entity.PromptTemplate, text string, sections *entity.AdviceSections, citations []string) {
+	if key == "" || s.cache == nil || prompt == nil {
+		return
+	}
+	now := time.Now().UTC()
+	e := &entity.AdviceCacheEntry{
+		Key:             key,
+		SurveyCode:      t.Code,
+		TemplateVersion: t.Version,
+		PromptVersion:   prompt.Version,
+		Model:           s.llm.Model(),
+		AdviceText:      text,
+		Sections:        sections,
+		Citations:       citations,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.cacheTTL),
+	}
+	if prompt.Version > 0 {
+		e.PromptTemplateID = &prompt.ID
+	}
+	if err := s.cache.Put(ctx, e); err != nil {
+		log.Printf("[AIAdvice] could not cache advice for %s: %v", t.Code, err)
+	}
+	s.purgeExpiredAdvice(ctx, now)
+}
+
+// purgeExpiredAdvice deletes expired cache entries, at most once per adviceCachePurgeInterval.
+func (s *AIAdviceService) purgeExpiredAdvice(ctx context.Context, now time.Time) {
+	last := s.cachePurgedAt.Load()
+	if now.Unix()-last < int64(adviceCachePurgeInterval/time.Second) || !s.cachePurgedAt.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	n, err := s.cache.DeleteExpired(ctx, now)
+	if err != nil {
+		log.Printf("[AIAdvice] could not purge expired cached advice: %v", err)
+	} else if n > 0 {
+		log.Printf("[AIAdvice] purged %d expired cached advice entries", n)
+	}
+}
+
+// InvalidateCache removes cached advice of one survey code, or all cached advice when surveyCode is empty.
+func (s *AIAdviceService) InvalidateCache(ctx context.Context, surveyCode string) (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.DeleteBySurvey(ctx, strings.TrimSpace(surveyCode))
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type fakeAdviceCacheRepo struct {
+	entries map[string]*entity.AdviceCacheEntry
+	purges  int
+}
+
+func (r *fakeAdviceCacheRepo) Get(ctx context.Context, key string) (*entity.AdviceCacheEntry, error) {
+	e := r.entries[key]
+	if e == nil || !e.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	e.Hits++
+	return e, nil
+}
+
+func (r *fakeAdviceCacheRepo) Put(ctx context.Context, e *entity.AdviceCacheEntry) error {
+	r.entries[e.Key] = e
+	return nil
+}
+
+func (r *fakeAdviceCacheRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.purges++
+	var n int64
+	for k, e := range r.entries {
+		if e.ExpiresAt.Before(now) {
+			delete(r.entries, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeAdviceCacheRepo) DeleteBySurvey(ctx context.Context, surveyCode string) (int64, error) {
+	var n int64
+	for k, e := range r.entries {
+		if surveyCode == "" || e.SurveyCode == surveyCode {
+			delete(r.entries, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+type fakeTemplateRepo struct {
+	byCode map[string]*entity.SurveyTemplate
+}
+
+func (r *fakeTemplateRepo) ListActive(ctx context.Context) ([]*entity.SurveyTemplate, error) {
+	var out []*entity.SurveyTemplate
+	for _, t := range r.byCode {
+		if t.IsActive {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeTemplateRepo) GetByCode(ctx context.Context, code string) (*entity.SurveyTemplate, error) {
+	return r.byCode[code], nil
+}
+
+func (r *fakeTemplateRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.SurveyTemplate, error) {
+	for _, t := range r.byCode {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestAdviceCacheKey(t *testing.T) {
+	svc := &AIAdviceService{cache: &fakeAdviceCacheRepo{}, cacheTTL: time.Hour}
+	input := func(templateVersion, promptVersion int) *adviceInput {
+		return &adviceInput{
+			template: &entity.SurveyTemplate{Code: "RCRI", Version: templateVersion},
+			prompt:   &entity.PromptTemplate{Version: promptVersion, SystemPrompt: "prompt"},
+			model:    "yandexgpt-lite",
+		}
+	}
+	answers := map[string]any{"high_risk_surgery": true, "creatinine": 2.0, "note": nil}
+
+	key := svc.adviceCacheKey(input(1, 1), answers, "")
+	if key == "" {
+		t.Fatal("adviceCacheKey() = empty")
+	}
+	equivalent := map[string]any{"creatinine": json.Number("2"), "high_risk_surgery": true}
+	if got := svc.adviceCacheKey(input(1, 1), equivalent, " "); got != key {
+		t.Errorf("equivalent answers hash differently")
+	}
+	if got := svc.adviceCacheKey(input(1, 1), map[string]any{"high_risk_surgery": true, "creatinine": "2"}, ""); got == key {
+		t.Errorf("string answer hashes like a number")
+	}
+	if got := svc.adviceCacheKey(input(2, 1), answers, ""); got == key {
+		t.Errorf("template version is not part of the key")
+	}
+	if got := svc.adviceCacheKey(input(1, 2), answers, ""); got == key {
+		t.Errorf("prompt version is not part of the key")
+	}
+	if got := svc.adviceCacheKey(input(1, 1), answers, "у меня диабет"); got != "" {
+		t.Errorf("advice with a patient comment must not be cached")
+	}
+	svc.cacheTTL = 0
+	if got := svc.adviceCacheKey(input(1, 1), answers, ""); got != "" {
+		t.Errorf("zero TTL must disable the cache")
+	}
+}
+
+func TestCreateForUserUsesCache(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	llm := &recordingLLM{}
+	cache := &fakeAdviceCacheRepo{entries: map[string]*entity.AdviceCacheEntry{}}
+	template := &entity.SurveyTemplate{Code: "DAS28_CRP", Version: 1, Questions: json.RawMessage("[]")}
+	svc := &AIAdviceService{
+		templateRepo: &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{template.Code: template}},
+		patientRepo:  &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{}},
+		adviceRepo:   &fakeAdviceRepo{items: map[uuid.UUID]*entity.AIAdvice{}},
+		cache:        cache,
+		cacheTTL:     time.Hour,
+		llm:          llm,
+	}
+	answers := map[string]any{"tjc28": 2.0, "sjc28": 2.0, "crp": 5.0, "gh": 30.0}
+
+	first, err := svc.CreateForUser(ctx, userID, template.Code, answers, "")
+	if err != nil {
+		t.Fatalf("CreateForUser() error = %v", err)
+	}
+	second, err := svc.CreateForUser(ctx, userID, template.Code, map[string]any{"gh": 30, "crp": 5, "sjc28": 2, "tjc28": 2}, "")
+	if err != nil {
+		t.Fatalf("CreateForUser(repeat) error = %v", err)
+	}
+	if first.Cached || !second.Cached || len(llm.user) != 1 {
+		t.Fatalf("cached = %v/%v after %d LLM calls, want false/true after 1", first.Cached, second.Cached, len(llm.user))
+	}
+	if second.ID == first.ID || second.AdviceText != first.AdviceText || second.AIStatus != entity.AIStatusDone {
+		t.Fatalf("cached advice = %+v", second)
+	}
+
+	if _, err := svc.CreateForUser(ctx, userID, template.Code, answers, "болит колено"); err != nil {
+		t.Fatalf("CreateForUser(comment) error = %v", err)
+	}
+	if len(llm.user) != 2 || len(cache.entries) != 1 {
+		t.Fatalf("advice with a comment: %d LLM calls, %d cache entries", len(llm.user), len(cache.entries))
+	}
+
+	if n, _ := svc.InvalidateCache(ctx, template.Code); n != 1 {
+		t.Fatalf("InvalidateCache() = %d, want 1", n)
+	}
+	if again, _ := svc.CreateForUser(ctx, userID, template.Code, answers, ""); again.Cached || len(llm.user) != 3 {
+		t.Fatalf("after invalidation cached = %v, %d LLM calls", again.Cached, len(llm.user))
+	}
+}
+
+func TestCacheAdvicePurgesExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	expired := &entity.AdviceCacheEntry{Key: "old", SurveyCode: "RCRI", ExpiresAt: time.Now().Add(-time.Minute)}
+	cache := &fakeAdviceCacheRepo{entries: map[string]*entity.AdviceCacheEntry{expired.Key: expired}}
+	svc := &AIAdviceService{cache: cache, cacheTTL: time.Hour, llm: &recordingLLM{}}
+	template := &entity.SurveyTemplate{Code: "RCRI", Version: 1}
+	prompt := &entity.PromptTemplate{SystemPrompt: "prompt"}
+
+	svc.cacheAdvice(ctx, "a", template, prompt, "Совет", nil, nil)
+	if _, ok := cache.entries["old"]; ok || len(cache.entries) != 1 || cache.purges != 1 {
+		t.Fatalf("after first write: %d entries, %d purges, want the expired entry purged", len(cache.entries), cache.purges)
+	}
+
+	// Writes within the purge interval do not delete again.
+	svc.cacheAdvice(ctx, "b", template, prompt, "Совет", nil, nil)
+	if len(cache.entries) != 2 || cache.purges != 1 {
+		t.Fatalf("after second write: %d entries, %d purges, want 2 entries and 1 purge", len(cache.entries), cache.purges)
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+// Checks applied to every evaluated advice text.
+const (
+	EvalCheckSections  = "sections"
+	EvalCheckNoDosage  = "no_dosage"
+	EvalCheckRiskClass = "risk_class"
+	EvalCheckSafety    = "safety"
+)
+
+var adviceEvalChecks = []string{EvalCheckSections, EvalCheckNoDosage, EvalCheckRiskClass, EvalCheckSafety}
+
+// AdviceEvalCase is one stored survey submission of the advice evaluation corpus. Score, category
+// and breakdown are not stored: they are derived from the answers by CalculateScore, as on submit.
+type AdviceEvalCase struct {
+	ID         string         `json:"id"`
+	SurveyCode string         `json:"survey_code"`
+	Answers    map[string]any `json:"answers"`
+	// RiskTerms name the expected risk class; the advice must mention at least one (case-insensitive).
+	RiskTerms []string `json:"risk_terms"`
+}
+
+// AdviceEvalCheck is the outcome of one rule-based assertion.
+type AdviceEvalCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AdviceEvalResult is the evaluation of one case; Score is the share of passed checks.
+type AdviceEvalResult struct {
+	CaseID     string `json:"case_id"`
+	SurveyCode string `json:"survey_code"`
+	// Category is the engine category of the case's answers.
+	Category string            `json:"category"`
+	Score    float64           `json:"score"`
+	Checks   []AdviceEvalCheck `json:"checks"`
+	Error    string            `json:"error,omitempty"`
+	Text     string            `json:"text,omitempty"`
+}
+
+// AdviceEvalReport summarizes a corpus run. Score is the mean case score; CheckPassRate is the share
+// of cases passing each check.
+type AdviceEvalReport struct {
+	Model         string              `json:"model"`
+	Cases         int                 `json:"cases"`
+	Passed        int                 `json:"passed"`
+	Score         float64             `json:"score"`
+	CheckPassRate map[string]float64  `json:"check_pass_rate"`
+	Results       []*AdviceEvalResult `json:"results"`
+}
+
+// LoadAdviceEvalCases reads and validates a JSON corpus of evaluation cases.
+func LoadAdviceEvalCases(path string) ([]AdviceEvalCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read eval corpus: %w", err)
+	}
+	var cases []AdviceEvalCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("decode eval corpus: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("eval corpus %s has no cases", path)
+	}
+	seen := make(map[string]bool, len(cases))
+	for i, c := range cases {
+		switch {
+		case c.ID == "":
+			return nil, fmt.Errorf("eval case %d: id is required", i)
+		case seen[c.ID]:
+			return nil, fmt.Errorf("eval case %s: duplicate id", c.ID)
+		case c.SurveyCode == "" || len(c.Answers) == 0:
+			return nil, fmt.Errorf("eval case %s: survey_code and answers are required", c.ID)
+		case len(c.RiskTerms) == 0:
+			return nil, fmt.Errorf("eval case %s: risk_terms are required", c.ID)
+		}
+		seen[c.ID] = true
+	}
+	return cases, nil
+}
+
+// LoadAdviceEvalTemplates reads the survey templates the corpus is scored with, keyed by code. The
+// file is a snapshot of the seeded templates (code, name and questions), so no database is needed.
+func LoadAdviceEvalTemplates(path string) (map[string]*entity.SurveyTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read eval templates: %w", err)
+	}
+	var list []*entity.SurveyTemplate
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode eval templates: %w", err)
+	}
+	templates := make(map[string]*entity.SurveyTemplate, len(list))
+	for i, t := range list {
+		if t.Code == "" || t.Name == "" {
+			return nil, fmt.Errorf("eval template %d: code and name are required", i)
+		}
+		templates[t.Code] = t
+	}
+	return templates, nil
+}
+
+// RunAdviceEval scores every case with CalculateScore, generates patient advice the way the service
+// does (same user prompt, structured output when the provider supports it) and checks each text.
+// An empty systemPrompt evaluates the built-in patient advice prompt. Drug names are not known
+// offline, so the safety check covers dosages, diagnoses and stop-medication instructions but not
+// drug amounts.
+func RunAdviceEval(ctx context.Context, llm external.LLMProvider, systemPrompt string, templates map[string]*entity.SurveyTemplate, cases []AdviceEvalCase) *AdviceEvalReport {
+	if systemPrompt == "" {
+		systemPrompt = patientAdviceSystemPrompt
+	}
+	svc := &AIAdviceService{llm: llm}
+	prompt := &entity.PromptTemplate{Key: entity.PromptPatientAdvice, SystemPrompt: systemPrompt}
+
+	report := &AdviceEvalReport{Model: llm.Model(), Cases: len(cases), CheckPassRate: make(map[string]float64, len(adviceEvalChecks))}
+	for _, c := range cases {
+		result := &AdviceEvalResult{CaseID: c.ID, SurveyCode: c.SurveyCode}
+		t := templates[c.SurveyCode]
+		if t == nil {
+			result.Error = "unknown survey template " + c.SurveyCode
+			result.Checks = failedEvalChecks(result.Error)
+			report.add(result)
+			continue
+		}
+		score, category, breakdown, err := CalculateScore(t, c.Answers)
+		if err != nil {
+			result.Error = "score answers: " + err.Error()
+			result.Checks = failedEvalChecks(result.Error)
+			report.add(result)
+			continue
+		}
+		result.Category = category
+
+		text, sections, err := svc.generatePatientAdvice(ctx, prompt, patientAdviceUserPrompt(t, score, category, breakdown, ""))
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Text = text
+		result.Checks = checkEvalAdvice(c, text, sections)
+		if err != nil {
+			for i := range result.Checks {
+				result.Checks[i].Passed = false
+			}
+		}
+		report.add(result)
+	}
+	report.finish()
+	return report
+}
+
+// failedEvalChecks marks every check failed for a case that could not be evaluated.
+func failedEvalChecks(detail string) []AdviceEvalCheck {
+	checks := make([]AdviceEvalCheck, 0, len(adviceEvalChecks))
+	for _, name := range adviceEvalChecks {
+		checks = append(checks, AdviceEvalCheck{Name: name, Detail: detail})
+	}
+	return checks
+}
+
+func checkEvalAdvice(c AdviceEvalCase, text string, sections *entity.AdviceSections) []AdviceEvalCheck {
+	checks := make([]AdviceEvalCheck, 0, len(adviceEvalChecks))
+
+	missing := missingAdviceSections(sections)
+	sectionsCheck := AdviceEvalCheck{Name: EvalCheckSections, Passed: len(missing) == 0}
+	if !sectionsCheck.Passed {
+		sectionsCheck.Detail = "missing " + strings.Join(missing, ",")
+	}
+	checks = append(checks, sectionsCheck)
+
+	violations := checkAdviceSafety(text, nil)
+	dosage := AdviceEvalCheck{Name: EvalCheckNoDosage, Passed: !slices.Contains(violations, SafetyDosage)}
+	if !dosage.Passed {
+		dosage.Detail = "mentions a dose"
+	}
+	checks = append(checks, dosage)
+
+	lower := strings.ToLower(text)
+	risk := AdviceEvalCheck{Name: EvalCheckRiskClass}
+	for _, term := range c.RiskTerms {
+		if strings.Contains(lower, strings.ToLower(term)) {
+			risk.Passed = true
+			break
+		}
+	}
+	if !risk.Passed {
+		risk.Detail = "expected one of: " + strings.Join(c.RiskTerms, ", ")
+	}
+	checks = append(checks, risk)
+
+	other := slices.DeleteFunc(slices.Clone(violations), func(v string) bool { return v == SafetyDosage })
+	safety := AdviceEvalCheck{Name: EvalCheckSafety, Passed: len(other) == 0}
+	if !safety.Passed {
+		safety.Detail = strings.Join(other, ",")
+	}
+	checks = append(checks, safety)
+
+	return checks
+}
+
+// missingAdviceSections lists the empty sections by their JSON names; all five are required here,
+// unlike the parser, which accepts partly filled answers.
+func missingAdviceSections(sec *entity.AdviceSections) []string {
+	if sec == nil {
+		return []string{"summary", "risk_level", "preparation", "tests", "questions"}
+	}
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"summary", sec.Summary}, {"risk_level", sec.RiskLevel}, {"preparation", sec.Preparation},
+		{"tests", sec.Tests}, {"questions", sec.Questions},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+func (r *AdviceEvalReport) add(result *AdviceEvalResult) {
+	passed := 0
+	for _, check := range result.Checks {
+		if check.Passed {
+			passed++
+			r.CheckPassRate[check.Name]++
+		}
+	}
+	result.Score = round2(float64(passed) / float64(len(result.Checks)))
+	if passed == len(result.Checks) {
+		r.Passed++
+	}
+	r.Score += result.Score
+	r.Results = append(r.Results, result)
+}
+
+func (r *AdviceEvalReport) finish() {
+	if r.Cases == 0 {
+		return
+	}
+	r.Score = math.Round(r.Score/float64(r.Cases)*1000) / 1000
+	for _, name := range adviceEvalChecks {
+		r.CheckPassRate[name] = round2(r.CheckPassRate[name] / float64(r.Cases))
+	}
+}
+
+// WriteText prints the report as a table: one row per case, then per-check pass rates and the total.
+func (r *AdviceEvalReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "model: %s\n\n", r.Model)
+	fmt.Fprintf(tw, "CASE\tSCORE\t%s\tNOTES\n", strings.ToUpper(strings.Join(adviceEvalChecks, "\t")))
+	for _, res := range r.Results {
+		marks := make([]string, 0, len(res.Checks))
+		var notes []string
+		for _, check := range res.Checks {
+			mark := "ok"
+			if !check.Passed {
+				mark = "FAIL"
+				if check.Detail != "" {
+					notes = append(notes, check.Name+": "+check.Detail)
+				}
+			}
+			marks = append(marks, mark)
+		}
+		if res.Error != "" {
+			notes = append([]string{"error: " + res.Error}, notes...)
+		}
+		fmt.Fprintf(tw, "%s\t%.2f\t%s\t%s\n", res.CaseID, res.Score, strings.Join(marks, "\t"), strings.Join(notes, "; "))
+	}
+	fmt.Fprintln(tw)
+	for _, name := range adviceEvalChecks {
+		fmt.Fprintf(tw, "%s\t%.0f%%\n", name, r.CheckPassRate[name]*100)
+	}
+	fmt.Fprintf(tw, "passed\t%d/%d\n", r.Passed, r.Cases)
+	fmt.Fprintf(tw, "score\t%.3f\n", r.Score)
+	return tw.Flush()
+}
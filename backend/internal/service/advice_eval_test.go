@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+type failingLLM struct{}
+
+func (failingLLM) Complete(ctx context.Context, messages []external.Message, temperature float64, maxTokens int) (*external.Completion, error) {
+	return nil, errors.New("upstream unavailable")
+}
+
+func (failingLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return "", errors.New("upstream unavailable")
+}
+
+func (failingLLM) Model() string { return "test/failing" }
+
+// compliantLLM answers with all five sections, naming the risk class and staying clear of doses.
+type compliantLLM struct{}
+
+func (compliantLLM) Complete(ctx context.Context, messages []external.Message, temperature float64, maxTokens int) (*external.Completion, error) {
+	return nil, errors.New("not used")
+}
+
+func (compliantLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return "1) Резюме: Оценка кардиального риска по шкале Goldman выполнена.\n" +
+		"2) Уровень риска: Класс IV, высокий риск осложнений.\n" +
+		"3) Подготовка: Обсудите с анестезиологом план операции и текущее лечение.\n" +
+		"4) Обследования: ЭКГ и эхокардиография по назначению врача.\n" +
+		"5) Вопросы: Нужна ли консультация кардиолога до операции?", nil
+}
+
+func (compliantLLM) Model() string { return "test/compliant" }
+
+func loadAdviceEvalCorpus(t *testing.T) (map[string]*entity.SurveyTemplate, []AdviceEvalCase) {
+	t.Helper()
+	templates, err := LoadAdviceEvalTemplates("../../evals/survey_templates.json")
+	if err != nil {
+		t.Fatalf("LoadAdviceEvalTemplates() error = %v", err)
+	}
+	cases, err := LoadAdviceEvalCases("../../evals/advice_cases.json")
+	if err != nil {
+		t.Fatalf("LoadAdviceEvalCases() error = %v", err)
+	}
+	return templates, cases
+}
+
+func TestAdviceEvalCorpusWithStub(t *testing.T) {
+	templates, cases := loadAdviceEvalCorpus(t)
+
+	// The stub does not read the survey result, so it can never name the risk class; every other
+	// check must pass for every case.
+	report := RunAdviceEval(context.Background(), external.NewStubLLM(), "", templates, cases)
+	for _, name := range []string{EvalCheckSections, EvalCheckNoDosage, EvalCheckSafety} {
+		if report.CheckPassRate[name] != 1 {
+			var out bytes.Buffer
+			_ = report.WriteText(&out)
+			t.Fatalf("stub run failed %s:\n%s", name, out.String())
+		}
+	}
+	if report.Cases != len(cases) || report.Passed != 0 || report.CheckPassRate[EvalCheckRiskClass] != 0 {
+		t.Fatalf("stub run: %d cases, %d passed, risk class pass rate %v", report.Cases, report.Passed, report.CheckPassRate[EvalCheckRiskClass])
+	}
+
+	// Every case is scored by the engine into the class its id names.
+	want := map[string]string{
+		"asa-2-elective": "asa_2", "asa-4-emergency": "asa_4_e",
+		"rcri-class-1": "class_i", "rcri-class-3": "class_iii", "rcri-class-4": "class_iv",
+		"goldman-class-2": "class_ii", "goldman-class-4": "class_iv",
+		"caprini-moderate": "moderate", "caprini-high": "high",
+	}
+	for _, res := range report.Results {
+		if res.Category != want[res.CaseID] {
+			t.Errorf("case %s scored as %q, want %q", res.CaseID, res.Category, want[res.CaseID])
+		}
+	}
+
+	report = RunAdviceEval(context.Background(), failingLLM{}, "", templates, cases[:1])
+	if report.Score != 0 || report.Results[0].Error == "" {
+		t.Fatalf("failed generation scored %v, error %q", report.Score, report.Results[0].Error)
+	}
+}
+
+func TestAdviceEvalPassesCompliantAdvice(t *testing.T) {
+	templates, cases := loadAdviceEvalCorpus(t)
+	var goldman []AdviceEvalCase
+	for _, c := range cases {
+		if c.ID == "goldman-class-4" {
+			goldman = append(goldman, c)
+		}
+	}
+	if len(goldman) != 1 {
+		t.Fatal("goldman-class-4 case not found in the corpus")
+	}
+
+	report := RunAdviceEval(context.Background(), compliantLLM{}, "", templates, goldman)
+	res := report.Results[0]
+	if report.CheckPassRate[EvalCheckRiskClass] != 1 || report.Passed != 1 || report.Score != 1 || res.Error != "" {
+		t.Fatalf("compliant advice: passed %d, score %v, checks %+v, error %q", report.Passed, report.Score, res.Checks, res.Error)
+	}
+}
+
+func TestAdviceEvalTemplatesMatchSeed(t *testing.T) {
+	templates, _ := loadAdviceEvalCorpus(t)
+	sql, err := os.ReadFile("../../migrations/005_preop_risk_surveys.up.sql")
+	if err != nil {
+		t.Fatalf("read migration: %v", err)
+	}
+	seeded := regexp.MustCompile(`(?s)'[0-9a-f-]{36}',\s*'([A-Z_0-9]+)',\s*'([^']*)',.*?\$\$(\[.*?\])\$\$::jsonb`).FindAllStringSubmatch(string(sql), -1)
+	if len(seeded) != len(templates) {
+		t.Fatalf("migration seeds %d templates, snapshot has %d", len(seeded), len(templates))
+	}
+	for _, m := range seeded {
+		snap := templates[m[1]]
+		if snap == nil {
+			t.Fatalf("template %s missing from the snapshot", m[1])
+		}
+		var want, got any
+		_ = json.Unmarshal([]byte(m[3]), &want)
+		_ = json.Unmarshal(snap.Questions, &got)
+		if snap.Name != m[2] || !reflect.DeepEqual(got, want) {
+			t.Errorf("snapshot of %s differs from the seed migration", m[1])
+		}
+	}
+}
+
+func TestCheckEvalAdvice(t *testing.T) {
+	c := AdviceEvalCase{ID: "rcri", SurveyCode: "RCRI", Answers: map[string]any{"ihd": true}, RiskTerms: []string{"Класс IV", "высокий риск"}}
+	sections := &entity.AdviceSections{Summary: "Итог.", RiskLevel: "Класс II.", Preparation: "Примите 40 мг метопролола.", Tests: "ЭКГ.", Questions: "Что дальше?"}
+
+	checks := checkEvalAdvice(c, renderAdviceSections(sections), sections)
+	failed := map[string]bool{}
+	for _, check := range checks {
+		if !check.Passed {
+			failed[check.Name] = true
+		}
+	}
+	if !failed[EvalCheckNoDosage] || !failed[EvalCheckRiskClass] || failed[EvalCheckSections] || failed[EvalCheckSafety] {
+		t.Fatalf("checks = %+v", checks)
+	}
+
+	partial := &entity.AdviceSections{Summary: "Класс IV.", RiskLevel: "Высокий риск."}
+	checks = checkEvalAdvice(c, renderAdviceSections(partial), partial)
+	if checks[0].Passed || !strings.Contains(checks[0].Detail, "preparation") {
+		t.Fatalf("sections check = %+v", checks[0])
+	}
+}
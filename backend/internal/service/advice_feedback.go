@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+const adviceFeedbackCommentMaxLength = 2000
+
+// Rate stores the patient's rating of their own advice; rating again replaces the previous rating.
+func (s *AIAdviceService) Rate(ctx context.Context, userID uuid.UUID, adviceID uuid.UUID, req entity.AdviceRatingCreate) (*entity.AdviceFeedback, error) {
+	req.Comment = strings.TrimSpace(req.Comment)
+	v := validator.New()
+	if req.Rating < 1 || req.Rating > 5 {
+		v.AddError("rating", "rating must be between 1 and 5")
+	}
+	v.MaxLength("comment", req.Comment, adviceFeedbackCommentMaxLength, "comment is too long")
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if _, err := s.ownedAdvice(ctx, userID, adviceID); err != nil {
+		return nil, err
+	}
+
+	rating := req.Rating
+	f := newAdviceFeedback(adviceID, userID, entity.AdviceFeedbackRating, req.Comment)
+	f.Rating = &rating
+	if err := s.feedbackRepo.Upsert(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Flag records a clinician's report that advice is inaccurate or unsafe. A reason comment is required;
+// flagging the same advice again replaces the clinician's previous flag. As with the review queue, only
+// the attending doctor of the patient may flag their advice.
+func (s *AIAdviceService) Flag(ctx context.Context, clinicianID uuid.UUID, adviceID uuid.UUID, req entity.AdviceFlagCreate) (*entity.AdviceFeedback, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.Comment = strings.TrimSpace(req.Comment)
+	v := validator.New()
+	if req.Reason != entity.AdviceFlagInaccurate && req.Reason != entity.AdviceFlagUnsafe {
+		v.AddError("reason", "reason must be inaccurate or unsafe")
+	}
+	v.Required("comment", req.Comment, "comment is required")
+	v.MaxLength("comment", req.Comment, adviceFeedbackCommentMaxLength, "comment is too long")
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	advice, err := s.adviceRepo.GetByID(ctx, adviceID)
+	if err != nil {
+		return nil, err
+	}
+	if advice == nil {
+		return nil, ErrAdviceNotFound
+	}
+	patient, err := s.patientRepo.GetByID(ctx, advice.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil || patient.AttendingDoctorID == nil || *patient.AttendingDoctorID != clinicianID {
+		return nil, ErrNotAttendingDoctor
+	}
+
+	f := newAdviceFeedback(adviceID, clinicianID, entity.AdviceFeedbackFlag, req.Comment)
+	f.Reason = req.Reason
+	if err := s.feedbackRepo.Upsert(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// QualityReport aggregates ratings and flags per prompt version and model for advice created
+// on the UTC days from..to inclusive.
+func (s *AIAdviceService) QualityReport(ctx context.Context, from time.Time, to time.Time) ([]*entity.AdviceQualityRow, error) {
+	start, end, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.feedbackRepo.QualityReport(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.AvgRating != nil {
+			avg := round2(*row.AvgRating)
+			row.AvgRating = &avg
+		}
+		if row.Advice > 0 {
+			row.FlagRate = math.Round(float64(row.Flagged)/float64(row.Advice)*1000) / 1000
+		}
+	}
+	return rows, nil
+}
+
+func newAdviceFeedback(adviceID uuid.UUID, userID uuid.UUID, kind string, comment string) *entity.AdviceFeedback {
+	now := time.Now().UTC()
+	return &entity.AdviceFeedback{
+		ID:        uuid.New(),
+		AdviceID:  adviceID,
+		UserID:    userID,
+		Kind:      kind,
+		Comment:   comment,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeAdviceFeedbackRepo struct {
+	items map[string]*entity.AdviceFeedback
+	rows  []*entity.AdviceQualityRow
+}
+
+func (r *fakeAdviceFeedbackRepo) Upsert(ctx context.Context, f *entity.AdviceFeedback) error {
+	r.items[f.AdviceID.String()+f.UserID.String()+f.Kind] = f
+	return nil
+}
+
+func (r *fakeAdviceFeedbackRepo) QualityReport(ctx context.Context, from time.Time, to time.Time) ([]*entity.AdviceQualityRow, error) {
+	return r.rows, nil
+}
+
+func TestAdviceFeedback(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	doctorID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: userID, AttendingDoctorID: &doctorID}
+	advice := &entity.AIAdvice{ID: uuid.New(), PatientID: patient.ID, SurveyCode: "RCRI"}
+	feedback := &fakeAdviceFeedbackRepo{items: map[string]*entity.AdviceFeedback{}}
+	svc := &AIAdviceService{
+		adviceRepo:   &fakeAdviceRepo{items: map[uuid.UUID]*entity.AIAdvice{advice.ID: advice}},
+		patientRepo:  &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{userID: patient}},
+		feedbackRepo: feedback,
+	}
+
+	var verr validator.ValidationErrors
+	if _, err := svc.Rate(ctx, userID, advice.ID, entity.AdviceRatingCreate{Rating: 6}); !errors.As(err, &verr) {
+		t.Fatalf("Rate(6) error = %v, want validation error", err)
+	}
+	if _, err := svc.Rate(ctx, uuid.New(), advice.ID, entity.AdviceRatingCreate{Rating: 4}); !errors.Is(err, ErrAdviceAccessDenied) {
+		t.Fatalf("Rate(other user) error = %v, want ErrAdviceAccessDenied", err)
+	}
+	if _, err := svc.Rate(ctx, userID, advice.ID, entity.AdviceRatingCreate{Rating: 4}); err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	rated, err := svc.Rate(ctx, userID, advice.ID, entity.AdviceRatingCreate{Rating: 2, Comment: "  непонятно  "})
+	if err != nil {
+		t.Fatalf("Rate(again) error = %v", err)
+	}
+	if len(feedback.items) != 1 || *rated.Rating != 2 || rated.Comment != "непонятно" {
+		t.Fatalf("after re-rating: %d items, rated = %+v", len(feedback.items), rated)
+	}
+
+	if _, err := svc.Flag(ctx, doctorID, advice.ID, entity.AdviceFlagCreate{Reason: "boring", Comment: "x"}); !errors.As(err, &verr) {
+		t.Fatalf("Flag(bad reason) error = %v, want validation error", err)
+	}
+	if _, err := svc.Flag(ctx, doctorID, advice.ID, entity.AdviceFlagCreate{Reason: entity.AdviceFlagUnsafe}); !errors.As(err, &verr) {
+		t.Fatalf("Flag(no comment) error = %v, want validation error", err)
+	}
+	if _, err := svc.Flag(ctx, doctorID, uuid.New(), entity.AdviceFlagCreate{Reason: entity.AdviceFlagUnsafe, Comment: "x"}); !errors.Is(err, ErrAdviceNotFound) {
+		t.Fatalf("Flag(missing advice) error = %v, want ErrAdviceNotFound", err)
+	}
+	if _, err := svc.Flag(ctx, uuid.New(), advice.ID, entity.AdviceFlagCreate{Reason: entity.AdviceFlagUnsafe, Comment: "x"}); !errors.Is(err, ErrNotAttendingDoctor) {
+		t.Fatalf("Flag(another doctor) error = %v, want ErrNotAttendingDoctor", err)
+	}
+	flagged, err := svc.Flag(ctx, doctorID, advice.ID, entity.AdviceFlagCreate{Reason: entity.AdviceFlagUnsafe, Comment: "Советует отменить антикоагулянт"})
+	if err != nil {
+		t.Fatalf("Flag() error = %v", err)
+	}
+	if flagged.Kind != entity.AdviceFeedbackFlag || flagged.Rating != nil || len(feedback.items) != 2 {
+		t.Fatalf("flagged = %+v, %d items", flagged, len(feedback.items))
+	}
+}
+
+func TestAdviceQualityReport(t *testing.T) {
+	avg := 3.6666
+	feedback := &fakeAdviceFeedbackRepo{rows: []*entity.AdviceQualityRow{
+		{PromptVersion: 2, Model: "yandexgpt-lite", Advice: 3, Ratings: 3, AvgRating: &avg, Flagged: 1},
+		{PromptVersion: 0, Model: ""},
+	}}
+	svc := &AIAdviceService{feedbackRepo: feedback}
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows, err := svc.QualityReport(context.Background(), day, day)
+	if err != nil {
+		t.Fatalf("QualityReport() error = %v", err)
+	}
+	if *rows[0].AvgRating != 3.67 || rows[0].FlagRate != 0.333 {
+		t.Fatalf("row = avg %v, flag rate %v", *rows[0].AvgRating, rows[0].FlagRate)
+	}
+	if rows[1].FlagRate != 0 {
+		t.Fatalf("empty row flag rate = %v", rows[1].FlagRate)
+	}
+
+	if _, err := svc.QualityReport(context.Background(), day, day.AddDate(0, 0, -1)); err == nil {
+		t.Fatal("QualityReport(to before from) error = nil")
+	}
+}
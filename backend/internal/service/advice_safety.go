@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/medical-app/backend/internal/repository"
+)
+
+// Safety violation categories reported by AdviceSafetyChecker.
+const (
+	SafetyDosage         = "dosage"
+	SafetyDrugAmount     = "drug_amount"
+	SafetyDiagnosis      = "definitive_diagnosis"
+	SafetyStopMedication = "stop_medication"
+)
+
+const (
+	safetyDrugCacheTTL = 10 * time.Minute
+	safetyDrugLimit    = 2000
+	// How far (in characters, within one sentence) an amount may stand from a drug name.
+	safetyDrugWindow = 40
+)
+
+var (
+	// A number with a dose unit: "40 мг", "0,5мл", "10 mg/kg", "2 таблетки".
+	safetyDosagePattern = regexp.MustCompile(`\d+(?:[.,]\d+)?\s*(?:мкг|мг|мл|ед|ме|mcg|µg|mg|ml|iu|units?|таблет\p{L}*|капсул\p{L}*|ампул\p{L}*|tablets?|capsules?)(?:\s*/\s*(?:кг|сут\p{L}*|день|мл|kg|day|ml))?(?:[^\p{L}]|$)`)
+
+	safetyDiagnosisPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`у вас (?:точно |определ[её]нно |несомненно )?(?:диагностирован|выявлен|подтвержд[её]н|имеется заболевание)`),
+		regexp.MustCompile(`ваш диагноз`),
+		regexp.MustCompile(`вы (?:точно |определ[её]нно |несомненно )?(?:больны|страдаете)`),
+		regexp.MustCompile(`you (?:have been|are) diagnosed`),
+		regexp.MustCompile(`your diagnosis is`),
+		regexp.MustCompile(`you (?:definitely|certainly|clearly) (?:have|suffer)`),
+	}
+
+	safetyStopMedicationPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?:прекратите|перестаньте|бросьте)\s+(?:при[её]м|принимать|лечение|терапию|колоть|использовать)`),
+		regexp.MustCompile(`отмените\s+(?:при[её]м|препарат|лекарств|терапию|лечение)`),
+		regexp.MustCompile(`не принимайте\s+(?:препарат|лекарств|таблет)`),
+		regexp.MustCompile(`stop (?:taking|using|your medication|the medication|your treatment|the treatment|your therapy|the therapy)`),
+		regexp.MustCompile(`discontinue (?:your |the )?(?:medication|medicine|treatment|therapy|drug)`),
+	}
+
+	// An amount next to a drug name: a number with a dose unit or a count of doses ("1 инъекцию",
+	// "2 укола"). A bare number is not enough — "за 2 недели", "раз в 2 недели" are timing.
+	safetyAmountPattern = regexp.MustCompile(`\d+(?:[.,]\d+)?\s*(?:мкг|мг|мл|ед|ме|единиц\p{L}*|mcg|µg|mg|ml|iu|units?|таб\p{L}*|капс\p{L}*|ампул\p{L}*|инъекц\p{L}*|укол\p{L}*|доз\p{L}*|шприц\p{L}*|tablets?|tabs?|capsules?|caps?|pills?|injections?|shots?|doses?)(?:[^\p{L}]|$)`)
+	// Section numbers of the answer ("3) Подготовка") are not amounts.
+	safetyListMarkerPattern = regexp.MustCompile(`(?m)^\s*\d+[.)]\s`)
+)
+
+// AdviceSafetyChecker verifies generated patient advice before it is shown: no dosages, no drug names
+// combined with amounts, no definitive diagnoses and no instructions to stop medication.
+type AdviceSafetyChecker struct {
+	drugRepo repository.DrugRepository
+
+	mu       sync.Mutex
+	stems    []string
+	loadedAt time.Time
+}
+
+func NewAdviceSafetyChecker(drugRepo repository.DrugRepository) *AdviceSafetyChecker {
+	return &AdviceSafetyChecker{drugRepo: drugRepo}
+}
+
+// Check returns the violated categories, sorted; an empty result means the text is safe to show.
+func (c *AdviceSafetyChecker) Check(ctx context.Context, text string) []string {
+	return checkAdviceSafety(text, c.drugStems(ctx))
+}
+
+// drugStems returns lower-cased drug name stems from the drugs table, cached for safetyDrugCacheTTL.
+// A failed reload keeps the previous list; the pattern checks still run without it.
+func (c *AdviceSafetyChecker) drugStems(ctx context.Context) []string {
+	if c == nil || c.drugRepo == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stems != nil && time.Since(c.loadedAt) < safetyDrugCacheTTL {
+		return c.stems
+	}
+
+	drugs, err := c.drugRepo.List(ctx, "", safetyDrugLimit)
+	if err != nil {
+		log.Printf("[AdviceSafety] could not load drug names: %v", err)
+		return c.stems
+	}
+	seen := map[string]bool{}
+	stems := make([]string, 0, len(drugs)*3)
+	for _, d := range drugs {
+		for _, name := range []string{d.Name, d.InternationalName, d.TradeName} {
+			stem := drugNameStem(name)
+			if stem != "" && !seen[stem] {
+				seen[stem] = true
+				stems = append(stems, stem)
+			}
+		}
+	}
+	c.stems = stems
+	c.loadedAt = time.Now()
+	return c.stems
+}
+
+// drugNameStem lower-cases a name and drops a trailing vowel so Russian case forms still match
+// ("Хумира" -> "хумир" matches "хумиры"). Very short names are ignored to avoid false positives.
+func drugNameStem(name string) string {
+	stem := strings.ToLower(strings.TrimSpace(name))
+	if utf8.RuneCountInString(stem) < 5 {
+		return ""
+	}
+	if r, size := utf8.DecodeLastRuneInString(stem); strings.ContainsRune("аеёиоуыэюяйьae", r) {
+		stem = stem[:len(stem)-size]
+	}
+	return stem
+}
+
+func checkAdviceSafety(text string, drugStems []string) []string {
+	lower := safetyListMarkerPattern.ReplaceAllString(strings.ToLower(text), "- ")
+	found := map[string]bool{}
+
+	if safetyDosagePattern.MatchString(lower) {
+		found[SafetyDosage] = true
+	}
+	for _, re := range safetyDiagnosisPatterns {
+		if re.MatchString(lower) {
+			found[SafetyDiagnosis] = true
+			break
+		}
+	}
+	for _, re := range safetyStopMedicationPatterns {
+		if re.MatchString(lower) {
+			found[SafetyStopMedication] = true
+			break
+		}
+	}
+	for _, stem := range drugStems {
+		if drugWithAmount(lower, stem) {
+			found[SafetyDrugAmount] = true
+			break
+		}
+	}
+
+	out := make([]string, 0, len(found))
+	for k := range found {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// drugWithAmount reports whether an amount (a number with a unit) appears near any mention of the drug
+// within the same sentence.
+func drugWithAmount(lower string, stem string) bool {
+	for offset := 0; ; {
+		i := strings.Index(lower[offset:], stem)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(stem)
+		if amountNear(lower[:start], true) || amountNear(lower[end:], false) {
+			return true
+		}
+		offset = end
+	}
+}
+
+func amountNear(s string, before bool) bool {
+	runes := []rune(s)
+	if before {
+		if len(runes) > safetyDrugWindow {
+			runes = runes[len(runes)-safetyDrugWindow:]
+		}
+		if i := lastSentenceBreak(runes); i >= 0 {
+			runes = runes[i+1:]
+		}
+	} else {
+		if len(runes) > safetyDrugWindow {
+			runes = runes[:safetyDrugWindow]
+		}
+		if i := firstSentenceBreak(runes); i >= 0 {
+			runes = runes[:i]
+		}
+	}
+	return safetyAmountPattern.MatchString(string(runes))
+}
+
+func isSentenceBreak(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '\n' || r == ';'
+}
+
+func firstSentenceBreak(runes []rune) int {
+	for i, r := range runes {
+		if isSentenceBreak(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+func lastSentenceBreak(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if isSentenceBreak(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckAdviceSafety(t *testing.T) {
+	stems := []string{drugNameStem("Адалимумаб"), drugNameStem("Хумира"), drugNameStem("Methotrexate"), drugNameStem("Метотрексат")}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "safe structured advice",
+			text: "1) Резюме: риск низкий.\n2) Уровень риска: класс ASA 2.\n3) Подготовка: обсудите с врачом приём адалимумаба перед операцией.\n4) Обследования: ЭКГ.\n5) Вопросы: не прекращайте лечение без консультации.",
+			want: []string{},
+		},
+		{name: "dosage in mg", text: "Примите 40 мг за сутки до операции.", want: []string{SafetyDosage}},
+		{name: "dosage per kg", text: "Usually 0.5 mg/kg is enough.", want: []string{SafetyDosage}},
+		{name: "tablet count", text: "Выпейте 2 таблетки вечером.", want: []string{SafetyDosage}},
+		{name: "year is not a dose", text: "Рекомендации 2024 г. не изменились.", want: []string{}},
+		{name: "drug with amount", text: "Хумиру колите по 2 шприца.", want: []string{SafetyDrugAmount}},
+		{name: "amount before drug", text: "Сделайте 1 инъекцию адалимумаба.", want: []string{SafetyDrugAmount}},
+		{name: "drug amount in another sentence", text: "Операция через 3 дня. Адалимумаб обсудите с ревматологом.", want: []string{}},
+		{name: "english drug", text: "Take methotrexate 2 tabs weekly.", want: []string{SafetyDrugAmount}},
+		{name: "drug with timing", text: "Врач может отменить метотрексат за 2 недели до операции.", want: []string{}},
+		{name: "drug with interval", text: "Хумиру обычно колют раз в 2 недели.", want: []string{}},
+		{name: "drug with date", text: "Methotrexate was last given 10 days ago, on 12.03.", want: []string{}},
+		{name: "definitive diagnosis", text: "У вас диагностирована ишемическая болезнь сердца.", want: []string{SafetyDiagnosis}},
+		{name: "definitive diagnosis en", text: "Your diagnosis is heart failure.", want: []string{SafetyDiagnosis}},
+		{name: "stop medication", text: "Прекратите приём препаратов за неделю.", want: []string{SafetyStopMedication}},
+		{name: "stop medication en", text: "Stop taking aspirin now.", want: []string{SafetyStopMedication}},
+		{
+			name: "several categories",
+			text: "Ваш диагноз — стенокардия. Отмените препарат и примите 75 мг.",
+			want: []string{SafetyDiagnosis, SafetyDosage, SafetyStopMedication},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkAdviceSafety(tt.text, stems)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkAdviceSafety() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
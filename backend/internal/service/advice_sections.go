@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+// adviceSectionsSchema is the JSON schema requested from providers that support structured output.
+var adviceSectionsSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "summary": {"type": "string"},
+    "risk_level": {"type": "string"},
+    "preparation": {"type": "string"},
+    "tests": {"type": "string"},
+    "questions": {"type": "string"}
+  },
+  "required": ["summary", "risk_level", "preparation", "tests", "questions"],
+  "additionalProperties": false
+}`)
+
+// patientAdviceJSONInstruction is appended to the registry prompt when a structured answer is requested.
+// It belongs to the code, not the registry, because the parser depends on it.
+const patientAdviceJSONInstruction = `
+
+Верни ответ строго в виде JSON-объекта без пояснений и без markdown:
+{"summary": "...", "risk_level": "...", "preparation": "...", "tests": "...", "questions": "..."}
+где summary — резюме результата, risk_level — уровень периоперационного риска, preparation — меры подготовки,
+tests — дополнительные обследования, questions — вопросы для анестезиолога и хирурга.`
+
+// adviceSectionTitles are the headings used when sections are rendered back into plain text.
+var adviceSectionTitles = [5]string{"Резюме", "Уровень риска", "Подготовка", "Обследования", "Вопросы"}
+
+var (
+	adviceSectionMarker = regexp.MustCompile(`(?m)^\s*\**\s*([1-5])\s*[).]\s*`)
+	adviceJSONFence     = regexp.MustCompile("(?s)^```(?:json)?\\s*(.*?)\\s*```$")
+)
+
+// parseAdviceJSON validates a structured answer. It accepts a bare object, one wrapped in a markdown
+// fence, and array values (joined line by line). Summary plus two other sections must be present.
+func parseAdviceJSON(text string) (*entity.AdviceSections, bool) {
+	text = strings.TrimSpace(text)
+	if m := adviceJSONFence.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end <= start {
+		return nil, false
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, false
+	}
+	field := func(key string) string {
+		v, ok := raw[key]
+		if !ok {
+			return ""
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return strings.TrimSpace(s)
+		}
+		var list []string
+		if err := json.Unmarshal(v, &list); err == nil {
+			return strings.TrimSpace(strings.Join(list, "\n"))
+		}
+		return ""
+	}
+
+	sec := &entity.AdviceSections{
+		Summary:     field("summary"),
+		RiskLevel:   field("risk_level"),
+		Preparation: field("preparation"),
+		Tests:       field("tests"),
+		Questions:   field("questions"),
+	}
+	if !validAdviceSections(sec) {
+		return nil, false
+	}
+	return sec, true
+}
+
+// parseAdviceSections splits a free-text answer numbered "1) ... 5) ..." as the prompt asks.
+// It returns nil when the text does not follow that structure.
+func parseAdviceSections(text string) *entity.AdviceSections {
+	locs := adviceSectionMarker.FindAllStringSubmatchIndex(text, -1)
+	var parts [5]string
+	next := 1
+	for i, loc := range locs {
+		n := int(text[loc[2]] - '0')
+		if n != next {
+			continue
+		}
+		end := len(text)
+		for _, after := range locs[i+1:] {
+			if int(text[after[2]]-'0') == next+1 {
+				end = after[0]
+				break
+			}
+		}
+		parts[n-1] = stripSectionHeading(text[loc[1]:end])
+		next++
+		if next > 5 {
+			break
+		}
+	}
+
+	sec := &entity.AdviceSections{
+		Summary:     parts[0],
+		RiskLevel:   parts[1],
+		Preparation: parts[2],
+		Tests:       parts[3],
+		Questions:   parts[4],
+	}
+	if !validAdviceSections(sec) {
+		return nil
+	}
+	return sec
+}
+
+// stripSectionHeading drops a short "Резюме результата:" style label in front of the section body.
+func stripSectionHeading(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, ":"); i > 0 && i <= 60 && !strings.ContainsAny(s[:i], ".\n") {
+		s = strings.TrimSpace(s[i+1:])
+	}
+	return strings.TrimSpace(strings.Trim(s, "*"))
+}
+
+func validAdviceSections(sec *entity.AdviceSections) bool {
+	if sec.Summary == "" {
+		return false
+	}
+	filled := 0
+	for _, v := range []string{sec.RiskLevel, sec.Preparation, sec.Tests, sec.Questions} {
+		if v != "" {
+			filled++
+		}
+	}
+	return filled >= 2
+}
+
+// renderAdviceSections turns sections into the numbered text stored in advice_text for older clients.
+func renderAdviceSections(sec *entity.AdviceSections) string {
+	values := [5]string{sec.Summary, sec.RiskLevel, sec.Preparation, sec.Tests, sec.Questions}
+	var b strings.Builder
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "%d) %s: %s", i+1, adviceSectionTitles[i], v)
+	}
+	return b.String()
+}
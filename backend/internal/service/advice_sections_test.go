@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+func TestParseAdviceJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want *entity.AdviceSections
+	}{
+		{
+			name: "plain object",
+			text: `{"summary":"Риск низкий.","risk_level":"ASA 2.","preparation":"Не курите.","tests":"ЭКГ.","questions":"Нужна ли премедикация?"}`,
+			want: &entity.AdviceSections{Summary: "Риск низкий.", RiskLevel: "ASA 2.", Preparation: "Не курите.", Tests: "ЭКГ.", Questions: "Нужна ли премедикация?"},
+		},
+		{
+			name: "fenced with arrays",
+			text: "```json\n{\"summary\":\"Риск низкий.\",\"preparation\":[\"Не курите.\",\"Больше ходите.\"],\"tests\":[\"ЭКГ.\"]}\n```",
+			want: &entity.AdviceSections{Summary: "Риск низкий.", Preparation: "Не курите.\nБольше ходите.", Tests: "ЭКГ."},
+		},
+		{name: "missing summary", text: `{"risk_level":"ASA 2.","preparation":"Не курите.","tests":"ЭКГ."}`},
+		{name: "too few sections", text: `{"summary":"Риск низкий.","tests":"ЭКГ."}`},
+		{name: "not json", text: "Риск низкий, обсудите с врачом."},
+		{name: "truncated", text: `{"summary":"Риск низкий.","risk_level":"ASA`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseAdviceJSON(tt.text)
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("parseAdviceJSON() = %+v, want failure", got)
+				}
+				return
+			}
+			if !ok || *got != *tt.want {
+				t.Fatalf("parseAdviceJSON() = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAdviceSections(t *testing.T) {
+	text := "1) **Резюме результата:** риск низкий.\n2) Уровень риска: ASA 2.\n3) Подготовка: не курите за 2 недели.\n4) Обследования: ЭКГ.\n5) Вопросы: нужна ли премедикация?"
+	got := parseAdviceSections(text)
+	want := entity.AdviceSections{Summary: "риск низкий.", RiskLevel: "ASA 2.", Preparation: "не курите за 2 недели.", Tests: "ЭКГ.", Questions: "нужна ли премедикация?"}
+	if got == nil || *got != want {
+		t.Fatalf("parseAdviceSections() = %+v, want %+v", got, want)
+	}
+
+	if got := parseAdviceSections("Риск низкий. Обсудите подготовку с врачом."); got != nil {
+		t.Fatalf("parseAdviceSections(unstructured) = %+v, want nil", got)
+	}
+
+	rendered := renderAdviceSections(&want)
+	if back := parseAdviceSections(rendered); back == nil || *back != want {
+		t.Fatalf("render/parse round trip = %+v, want %+v", back, want)
+	}
+}
+
+func TestGeneratePatientAdviceStructured(t *testing.T) {
+	svc := &AIAdviceService{llm: external.NewStubLLM()}
+	prompt := &entity.PromptTemplate{SystemPrompt: patientAdviceSystemPrompt}
+
+	text, sections, err := svc.generatePatientAdvice(context.Background(), prompt, "Опросник: STOP-BANG")
+	if err != nil {
+		t.Fatalf("generatePatientAdvice() error = %v", err)
+	}
+	if sections == nil || sections.Summary != "Опросник: STOP-BANG." || sections.Questions == "" {
+		t.Fatalf("sections = %+v", sections)
+	}
+	if text != renderAdviceSections(sections) {
+		t.Fatalf("text = %q, want rendered sections", text)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,21 @@ import (
 	"github.com/medical-app/backend/internal/repository"
 )
 
+// patientAdviceSystemPrompt is the built-in prompt used until a version is approved in the prompt registry.
+const patientAdviceSystemPrompt = `Ты — информационный помощник по предоперационной оценке рисков для пациента.
+Твои ответы должны быть безопасными и не содержать конкретных назначений или дозировок препаратов.
+Пиши простым русским языком.
+Не добавляй дисклеймеры/предупреждения в стиле "Важно:" — приложение покажет стандартное предупреждение отдельно.
+
+Контекст: пациент заполнил шкалу предоперационной оценки риска (ASA, RCRI/Lee, Goldman, Caprini или другую).
+
+Структура ответа:
+1) Резюме результата (1-2 предложения, что означает балл/класс)
+2) Уровень периоперационного риска и его значение
+3) Возможные меры предоперационной подготовки (без конкретных назначений)
+4) Какие дополнительные обследования могут потребоваться
+5) Вопросы для обсуждения с анестезиологом и хирургом`
+
 const PatientAdviceDisclaimer = "Важно: это информационная справка, а не клиническая рекомендация и не заменяет консультацию анестезиолога или хирурга. Окончательное решение о предоперационной подготовке принимает лечащий врач."
 
 func normalizeAdviceText(text string) string {
@@ -52,14 +68,37 @@ type AIAdviceService struct {
 	templateRepo repository.SurveyTemplateRepository
 	patientRepo  repository.PatientRepository
 	adviceRepo   repository.AIAdviceRepository
-	gptClient    *external.YandexGPTClient
+	messageRepo  repository.AdviceMessageRepository
+	feedbackRepo repository.AdviceFeedbackRepository
+	cache        repository.AdviceCacheRepository
+	cacheTTL     time.Duration
+	// cachePurgedAt is when expired cache entries were last deleted (unix seconds).
+	cachePurgedAt atomic.Int64
+	llm           external.LLMProvider
+	jobs          *AIJobService
+	prompts       *PromptService
+	safety        *AdviceSafetyChecker
+	redactor      *PHIRedactor
+	literature    *LiteratureService
+	usage         *LLMUsageService
 }
 
 type AIAdviceDeps struct {
 	TemplateRepo repository.SurveyTemplateRepository
 	PatientRepo  repository.PatientRepository
 	AdviceRepo   repository.AIAdviceRepository
-	GPTClient    *external.YandexGPTClient
+	MessageRepo  repository.AdviceMessageRepository
+	FeedbackRepo repository.AdviceFeedbackRepository
+	// Cache holds generated advice for reuse; caching is off when it is nil or CacheTTL is zero.
+	Cache      repository.AdviceCacheRepository
+	CacheTTL   time.Duration
+	LLM        external.LLMProvider
+	Jobs       *AIJobService
+	Prompts    *PromptService
+	Safety     *AdviceSafetyChecker
+	Redactor   *PHIRedactor
+	Literature *LiteratureService
+	Usage      *LLMUsageService
 }
 
 func NewAIAdviceService(d AIAdviceDeps) *AIAdviceService {
@@ -67,7 +106,17 @@ func NewAIAdviceService(d AIAdviceDeps) *AIAdviceService {
 		templateRepo: d.TemplateRepo,
 		patientRepo:  d.PatientRepo,
 		adviceRepo:   d.AdviceRepo,
-		gptClient:    d.GPTClient,
+		messageRepo:  d.MessageRepo,
+		feedbackRepo: d.FeedbackRepo,
+		cache:        d.Cache,
+		cacheTTL:     d.CacheTTL,
+		llm:          d.LLM,
+		jobs:         d.Jobs,
+		prompts:      d.Prompts,
+		safety:       d.Safety,
+		redactor:     d.Redactor,
+		literature:   d.Literature,
+		usage:        d.Usage,
 	}
 }
 
@@ -76,13 +125,43 @@ type AIAdviceResult struct {
 	SurveyCode string    `json:"survey_code"`
 	UserText   string    `json:"user_text,omitempty"`
 	AdviceText string    `json:"advice_text"`
-	Disclaimer string    `json:"disclaimer"`
-	Score      *float64  `json:"score,omitempty"`
-	Category   string    `json:"category,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
+	// Sections is the structured form of AdviceText; absent when the text has no recognizable structure.
+	Sections *entity.AdviceSections `json:"sections,omitempty"`
+	// Citations are PubMed IDs of the abstracts the advice was grounded in.
+	Citations  []string   `json:"citations,omitempty"`
+	Disclaimer string     `json:"disclaimer"`
+	Score      *float64   `json:"score,omitempty"`
+	Category   string     `json:"category,omitempty"`
+	AIStatus   string     `json:"ai_status,omitempty"`
+	AIJobID    *uuid.UUID `json:"ai_job_id,omitempty"`
+	// PromptVersion is the registry version of the system prompt; 0 for the built-in prompt or fallback text.
+	PromptVersion int    `json:"prompt_version,omitempty"`
+	Model         string `json:"model,omitempty"`
+	// Cached reports that the text was reused from earlier identical answers instead of a new LLM call.
+	Cached    bool      `json:"cached"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-func (s *AIAdviceService) CreateForUser(ctx context.Context, userID uuid.UUID, surveyCode string, answers map[string]any, userText string) (*AIAdviceResult, error) {
+// adviceInput is a scored survey ready for advice generation.
+type adviceInput struct {
+	// id is assigned up front so redaction audit entries can reference the advice being generated.
+	id        uuid.UUID
+	userID    uuid.UUID
+	template  *entity.SurveyTemplate
+	patient   *entity.Patient
+	score     float64
+	category  string
+	breakdown map[string]any
+	// prompt is the system prompt version used for generation; nil when no LLM is involved.
+	prompt *entity.PromptTemplate
+	// model is the provider model the prompt is sent to.
+	model string
+	// evidence are the PubMed abstracts included in the prompt.
+	evidence []*entity.PubMedAbstract
+}
+
+// prepareAdvice validates the request, scores the answers and resolves (or creates) the patient record.
+func (s *AIAdviceService) prepareAdvice(ctx context.Context, userID uuid.UUID, surveyCode string, answers map[string]any) (*adviceInput, error) {
 	if userID == uuid.Nil {
 		return nil, errors.New("user_id is required")
 	}
@@ -115,56 +194,200 @@ func (s *AIAdviceService) CreateForUser(ctx context.Context, userID uuid.UUID, s
 		}
 	}
 
-	adviceText := s.fallbackAdviceText(t, score, category, breakdown)
-	if s.gptClient != nil {
-		gptText, err := s.generatePatientAdvice(ctx, t, score, category, breakdown, userText)
-		if err != nil {
-			log.Printf("[AIAdvice] YandexGPT error (using fallback): %v", err)
-		} else {
-			trimmed := normalizeAdviceText(gptText)
-			if trimmed != "" {
-				adviceText = trimmed
-			} else {
-				log.Printf("[AIAdvice] YandexGPT returned empty response, using fallback")
-			}
-		}
-	} else {
-		log.Printf("[AIAdvice] gptClient is nil, using fallback")
-	}
+	return &adviceInput{id: uuid.New(), userID: userID, template: t, patient: patient, score: score, category: category, breakdown: breakdown}, nil
+}
 
+func (in *adviceInput) newAdvice(userText, adviceText string, sections *entity.AdviceSections, aiStatus string) *entity.AIAdvice {
 	detailsJSON, _ := json.Marshal(map[string]any{
-		"score":     score,
-		"category":  category,
-		"breakdown": breakdown,
+		"score":     in.score,
+		"category":  in.category,
+		"breakdown": in.breakdown,
 	})
 
-	now := time.Now().UTC()
+	score := in.score
 	item := &entity.AIAdvice{
-		ID:         uuid.New(),
-		PatientID:  patient.ID,
-		SurveyCode: surveyCode,
+		ID:         in.id,
+		PatientID:  in.patient.ID,
+		SurveyCode: in.template.Code,
 		UserText:   strings.TrimSpace(userText),
 		Score:      &score,
-		Category:   category,
+		Category:   in.category,
 		Details:    detailsJSON,
 		AdviceText: normalizeAdviceText(adviceText),
-		CreatedAt:  now,
+		Sections:   sections,
+		AIStatus:   aiStatus,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if in.prompt != nil && in.prompt.Version > 0 {
+		item.PromptTemplateID = &in.prompt.ID
+		item.PromptVersion = in.prompt.Version
+	}
+	if in.prompt != nil {
+		item.Citations = citationPMIDs(in.evidence)
+		item.Model = in.model
+	}
+	return item
+}
+
+func toAdviceResult(item *entity.AIAdvice) *AIAdviceResult {
+	sections := item.Sections
+	if sections == nil {
+		// Advice stored before sections were kept separately.
+		sections = parseAdviceSections(item.AdviceText)
+	}
+	return &AIAdviceResult{
+		ID:            item.ID,
+		SurveyCode:    item.SurveyCode,
+		UserText:      item.UserText,
+		AdviceText:    normalizeAdviceText(item.AdviceText),
+		Sections:      sections,
+		Citations:     item.Citations,
+		Disclaimer:    PatientAdviceDisclaimer,
+		Score:         item.Score,
+		Category:      item.Category,
+		AIStatus:      item.AIStatus,
+		PromptVersion: item.PromptVersion,
+		Model:         item.Model,
+		CreatedAt:     item.CreatedAt,
+	}
+}
+
+// CreateForUser scores the answers and stores advice for the user. Identical answers without a patient
+// comment reuse cached advice; otherwise the text is generated (in the background when jobs are
+// configured) and the rule-based fallback is stored until it is ready.
+func (s *AIAdviceService) CreateForUser(ctx context.Context, userID uuid.UUID, surveyCode string, answers map[string]any, userText string) (*AIAdviceResult, error) {
+	in, err := s.prepareAdvice(ctx, userID, surveyCode, answers)
+	if err != nil {
+		return nil, err
+	}
+	t, score, category, breakdown := in.template, in.score, in.category, in.breakdown
+	ctx = withLLMUsageScope(ctx, &userID, t.Code, entity.PromptPatientAdvice)
+
+	if s.llm != nil {
+		in.prompt = s.prompts.Resolve(ctx, entity.PromptPatientAdvice, t.Code)
+		in.model = s.llm.Model()
+	}
+	cacheKey := s.adviceCacheKey(in, answers, userText)
+	if hit := s.cachedAdvice(ctx, cacheKey); hit != nil {
+		return s.storeCachedAdvice(ctx, in, hit)
+	}
+	// Cache hits cost nothing; only requests that reach the model count against the quota.
+	if err := s.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	adviceText := s.fallbackAdviceText(t, score, category, breakdown)
+	var sections *entity.AdviceSections
+	aiStatus := ""
+	if s.llm != nil && s.jobs != nil {
+		// Generated by a background job; the fallback text is shown until it is ready.
+		aiStatus = entity.AIStatusPending
+	} else if s.llm != nil {
+		in.evidence = s.literature.Evidence(ctx, t)
+		userPrompt := s.outboundAdvicePrompt(ctx, &userID, in.id, t, score, category, breakdown, userText) + literaturePromptBlock(in.evidence)
+		text, secs, err := s.generatePatientAdvice(ctx, in.prompt, userPrompt)
+		if err != nil {
+			log.Printf("[AIAdvice] LLM error (using fallback): %v", err)
+			in.prompt = nil
+		} else if text == "" {
+			log.Printf("[AIAdvice] LLM returned empty response, using fallback")
+			in.prompt = nil
+		} else if s.unsafeAdvice(ctx, text) {
+			in.prompt = nil
+		} else {
+			adviceText, sections = text, secs
+			s.cacheAdvice(ctx, cacheKey, t, in.prompt, text, secs, citationPMIDs(in.evidence))
+		}
+	} else {
+		log.Printf("[AIAdvice] LLM provider not configured, using fallback")
 	}
 
+	item := in.newAdvice(userText, adviceText, sections, aiStatus)
 	if err := s.adviceRepo.Create(ctx, item); err != nil {
 		return nil, err
 	}
 
-	return &AIAdviceResult{
-		ID:         item.ID,
-		SurveyCode: item.SurveyCode,
-		UserText:   item.UserText,
-		AdviceText: item.AdviceText,
-		Disclaimer: PatientAdviceDisclaimer,
-		Score:      item.Score,
-		Category:   item.Category,
-		CreatedAt:  item.CreatedAt,
-	}, nil
+	result := toAdviceResult(item)
+
+	if aiStatus == entity.AIStatusPending {
+		job := &entity.AIJob{Kind: entity.AIJobPatientAdvice, UserID: &userID, AIAdviceID: &item.ID}
+		payload := patientAdvicePayload{SurveyCode: t.Code, Score: score, Category: category, Breakdown: breakdown, UserText: item.UserText, PromptTemplateID: item.PromptTemplateID, CacheKey: cacheKey}
+		if err := s.jobs.Enqueue(ctx, job, payload); err != nil {
+			log.Printf("[AIAdvice] could not queue generation for advice %s: %v", item.ID, err)
+			if err := s.adviceRepo.UpdateAdviceText(ctx, item.ID, "", nil, nil, entity.AIStatusFailed); err != nil {
+				log.Printf("[AIAdvice] could not mark advice %s failed: %v", item.ID, err)
+			}
+			result.AIStatus = entity.AIStatusFailed
+		} else {
+			result.AIJobID = &job.ID
+		}
+	}
+
+	return result, nil
+}
+
+type patientAdvicePayload struct {
+	SurveyCode string         `json:"survey_code"`
+	Score      float64        `json:"score"`
+	Category   string         `json:"category"`
+	Breakdown  map[string]any `json:"breakdown"`
+	UserText   string         `json:"user_text"`
+	// Prompt version recorded on the advice; the job generates with exactly this version.
+	PromptTemplateID *uuid.UUID `json:"prompt_template_id,omitempty"`
+	// CacheKey is set when the generated advice may be cached.
+	CacheKey string `json:"cache_key,omitempty"`
+}
+
+// RunAIJob generates patient advice text and replaces the fallback text (AIJobRunner).
+func (s *AIAdviceService) RunAIJob(ctx context.Context, job *entity.AIJob) (string, error) {
+	if s.llm == nil {
+		return "", errors.New("llm provider not configured")
+	}
+	if job.AIAdviceID == nil {
+		return "", errors.New("ai_advice_id is required")
+	}
+	var p patientAdvicePayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+
+	t, err := s.templateRepo.GetByCode(ctx, p.SurveyCode)
+	if err != nil {
+		return "", err
+	}
+	if t == nil {
+		return "", errors.New("survey template not found")
+	}
+
+	ctx = withLLMUsageScope(ctx, job.UserID, t.Code, entity.PromptPatientAdvice)
+	prompt := s.prompts.ResolveByID(ctx, p.PromptTemplateID, entity.PromptPatientAdvice, t.Code)
+	evidence := s.literature.Evidence(ctx, t)
+	userPrompt := s.outboundAdvicePrompt(ctx, job.UserID, *job.AIAdviceID, t, p.Score, p.Category, p.Breakdown, p.UserText) + literaturePromptBlock(evidence)
+	text, sections, err := s.generatePatientAdvice(ctx, prompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", errors.New("llm returned empty response")
+	}
+	if s.unsafeAdvice(ctx, text) {
+		// The fallback text stays in place (FailAIJob); regenerating the same prompt is not retried.
+		return "", fmt.Errorf("advice rejected by safety check: %w", ErrAIJobPermanent)
+	}
+
+	if err := s.adviceRepo.UpdateAdviceText(ctx, *job.AIAdviceID, text, sections, citationPMIDs(evidence), entity.AIStatusDone); err != nil {
+		return "", err
+	}
+	s.cacheAdvice(ctx, p.CacheKey, t, prompt, text, sections, citationPMIDs(evidence))
+	return text, nil
+}
+
+// FailAIJob keeps the fallback text and marks the advice failed (AIJobRunner).
+func (s *AIAdviceService) FailAIJob(ctx context.Context, job *entity.AIJob, cause error) error {
+	if job.AIAdviceID == nil {
+		return nil
+	}
+	return s.adviceRepo.UpdateAdviceText(ctx, *job.AIAdviceID, "", nil, nil, entity.AIStatusFailed)
 }
 
 func (s *AIAdviceService) ListForUser(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*AIAdviceResult, error) {
@@ -186,20 +409,33 @@ func (s *AIAdviceService) ListForUser(ctx context.Context, userID uuid.UUID, lim
 
 	out := make([]*AIAdviceResult, 0, len(items))
 	for _, it := range items {
-		out = append(out, &AIAdviceResult{
-			ID:         it.ID,
-			SurveyCode: it.SurveyCode,
-			UserText:   it.UserText,
-			AdviceText: normalizeAdviceText(it.AdviceText),
-			Disclaimer: PatientAdviceDisclaimer,
-			Score:      it.Score,
-			Category:   it.Category,
-			CreatedAt:  it.CreatedAt,
-		})
+		out = append(out, toAdviceResult(it))
 	}
 	return out, nil
 }
 
+// CheckQuota reports whether the user may start another generation today; it returns a
+// *QuotaExceededError when the daily quota is used up. Without an LLM provider nothing is metered.
+func (s *AIAdviceService) CheckQuota(ctx context.Context, userID uuid.UUID) error {
+	if s.llm == nil {
+		return nil
+	}
+	return s.usage.CheckQuota(ctx, userID)
+}
+
+// unsafeAdvice runs the output safety check and logs the violated categories (never the text itself).
+func (s *AIAdviceService) unsafeAdvice(ctx context.Context, text string) bool {
+	if s.safety == nil {
+		return false
+	}
+	violations := s.safety.Check(ctx, text)
+	if len(violations) == 0 {
+		return false
+	}
+	log.Printf("[AIAdvice] safety check rejected generated advice (%s), using fallback", strings.Join(violations, ","))
+	return true
+}
+
 func (s *AIAdviceService) fallbackAdviceText(t *entity.SurveyTemplate, score float64, category string, breakdown map[string]any) string {
 	interpretation := category
 	if desc, ok := breakdown["category_description"].(string); ok && strings.TrimSpace(desc) != "" {
@@ -213,22 +449,55 @@ func (s *AIAdviceService) fallbackAdviceText(t *entity.SurveyTemplate, score flo
 	)
 }
 
-func (s *AIAdviceService) generatePatientAdvice(ctx context.Context, t *entity.SurveyTemplate, score float64, category string, breakdown map[string]any, userText string) (string, error) {
-	systemPrompt := `Ты — информационный помощник по предоперационной оценке рисков для пациента.
-Твои ответы должны быть безопасными и не содержать конкретных назначений или дозировок препаратов.
-Пиши простым русским языком.
-Не добавляй дисклеймеры/предупреждения в стиле "Важно:" — приложение покажет стандартное предупреждение отдельно.
+// generatePatientAdvice returns normalized advice text and its sections. Providers with structured
+// output are asked for JSON; free-text answers are split on the numbered sections when possible.
+func (s *AIAdviceService) generatePatientAdvice(ctx context.Context, prompt *entity.PromptTemplate, userPrompt string) (string, *entity.AdviceSections, error) {
+	structured, ok := s.llm.(external.StructuredLLMProvider)
+	if !ok {
+		text, err := s.llm.GenerateText(ctx, prompt.SystemPrompt, userPrompt)
+		if err != nil {
+			return "", nil, err
+		}
+		text = normalizeAdviceText(text)
+		return text, parseAdviceSections(text), nil
+	}
 
-Контекст: пациент заполнил шкалу предоперационной оценки риска (ASA, RCRI/Lee, Goldman, Caprini или другую).
+	messages := []external.Message{
+		{Role: "system", Text: prompt.SystemPrompt + patientAdviceJSONInstruction},
+		{Role: "user", Text: userPrompt},
+	}
+	resp, err := structured.CompleteJSON(ctx, messages, adviceSectionsSchema, external.DefaultTemperature, external.DefaultMaxTokens)
+	if err != nil {
+		return "", nil, err
+	}
+	if sections, ok := parseAdviceJSON(resp.Text); ok {
+		normalizeAdviceSections(sections)
+		return renderAdviceSections(sections), sections, nil
+	}
 
-Структура ответа:
-1) Резюме результата (1-2 предложения, что означает балл/класс)
-2) Уровень периоперационного риска и его значение
-3) Возможные меры предоперационной подготовки (без конкретных назначений)
-4) Какие дополнительные обследования могут потребоваться
-5) Вопросы для обсуждения с анестезиологом и хирургом`
+	// Some models ignore the schema and answer in prose; keep that, but never show broken JSON.
+	text := normalizeAdviceText(resp.Text)
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "```") {
+		return "", nil, errors.New("structured advice failed validation")
+	}
+	log.Printf("[AIAdvice] model answered in free text instead of JSON")
+	return text, parseAdviceSections(text), nil
+}
+
+func normalizeAdviceSections(sec *entity.AdviceSections) {
+	for _, v := range []*string{&sec.Summary, &sec.RiskLevel, &sec.Preparation, &sec.Tests, &sec.Questions} {
+		*v = normalizeAdviceText(*v)
+	}
+}
+
+// outboundAdvicePrompt builds the user prompt and masks personal identifiers before it leaves for the LLM.
+// Literature excerpts are appended afterwards: they hold no patient data, and author names must stay intact.
+func (s *AIAdviceService) outboundAdvicePrompt(ctx context.Context, userID *uuid.UUID, adviceID uuid.UUID, t *entity.SurveyTemplate, score float64, category string, breakdown map[string]any, userText string) string {
+	return s.redactor.RedactText(ctx, userID, entity.ResourceAdvice, &adviceID, patientAdviceUserPrompt(t, score, category, breakdown, userText))
+}
 
-	userPrompt := fmt.Sprintf(
+func patientAdviceUserPrompt(t *entity.SurveyTemplate, score float64, category string, breakdown map[string]any, userText string) string {
+	return fmt.Sprintf(
 		"Опросник: %s (%s)\nИтоговый балл: %.2f\nКатегория: %s\nДетали: %v\n\nКомментарий пациента (если есть - учесть в ответе): %s\n",
 		t.Name,
 		t.Code,
@@ -237,10 +506,4 @@ func (s *AIAdviceService) generatePatientAdvice(ctx context.Context, t *entity.S
 		breakdown,
 		strings.TrimSpace(userText),
 	)
-
-	text, err := s.gptClient.GenerateText(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		return "", err
-	}
-	return text, nil
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+// Event names emitted by StreamForUser.
+const (
+	AdviceEventDelta = "delta"
+	AdviceEventDone  = "done"
+)
+
+// AdviceDelta is the payload of a "delta" event.
+type AdviceDelta struct {
+	Text string `json:"text"`
+}
+
+// StreamForUser generates patient advice synchronously and relays the text through emit sentence by
+// sentence, each only after it passed the safety check. The advice is stored once generation ends and
+// sent as the final "done" event, whose advice_text is authoritative: it is normalized and replaces
+// partial text if the stream broke off or was rejected.
+// Providers without streaming support (or a failed stream) yield the fallback text as a single delta,
+// and cached advice is sent the same way.
+func (s *AIAdviceService) StreamForUser(ctx context.Context, userID uuid.UUID, surveyCode string, answers map[string]any, userText string, emit func(event string, data any) error) (*AIAdviceResult, error) {
+	in, err := s.prepareAdvice(ctx, userID, surveyCode, answers)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withLLMUsageScope(ctx, &userID, in.template.Code, entity.PromptPatientAdvice)
+
+	if s.llm != nil {
+		in.prompt = s.prompts.Resolve(ctx, entity.PromptPatientAdvice, in.template.Code)
+		in.model = s.llm.Model()
+	}
+	cacheKey := s.adviceCacheKey(in, answers, userText)
+	if hit := s.cachedAdvice(ctx, cacheKey); hit != nil {
+		if err := emit(AdviceEventDelta, AdviceDelta{Text: hit.AdviceText}); err != nil {
+			return nil, err
+		}
+		result, err := s.storeCachedAdvice(ctx, in, hit)
+		if err != nil {
+			return nil, err
+		}
+		if err := emit(AdviceEventDone, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err := s.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	text, aiStatus, err := s.streamPatientAdvice(ctx, in, userText, emit)
+	if err != nil {
+		// The client went away; nothing is stored for an aborted stream.
+		return nil, err
+	}
+
+	sections := parseAdviceSections(text)
+	item := in.newAdvice(userText, text, sections, aiStatus)
+	if err := s.adviceRepo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	if aiStatus == entity.AIStatusDone {
+		s.cacheAdvice(ctx, cacheKey, in.template, in.prompt, item.AdviceText, sections, item.Citations)
+	}
+
+	result := toAdviceResult(item)
+	if err := emit(AdviceEventDone, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// streamPatientAdvice returns the advice text and its ai_status. Only errors from emit are returned.
+func (s *AIAdviceService) streamPatientAdvice(ctx context.Context, in *adviceInput, userText string, emit func(event string, data any) error) (string, string, error) {
+	fallback := func(status string) (string, string, error) {
+		in.prompt = nil
+		text := s.fallbackAdviceText(in.template, in.score, in.category, in.breakdown)
+		if err := emit(AdviceEventDelta, AdviceDelta{Text: text}); err != nil {
+			return "", "", err
+		}
+		return text, status, nil
+	}
+
+	if s.llm == nil {
+		log.Printf("[AIAdvice] LLM provider not configured, using fallback")
+		return fallback("")
+	}
+	streamer, ok := s.llm.(external.StreamingLLMProvider)
+	if !ok {
+		log.Printf("[AIAdvice] LLM provider %s cannot stream, using fallback", s.llm.Model())
+		return fallback(entity.AIStatusFailed)
+	}
+
+	in.evidence = s.literature.Evidence(ctx, in.template)
+	userPrompt := s.outboundAdvicePrompt(ctx, &in.userID, in.id, in.template, in.score, in.category, in.breakdown, userText) + literaturePromptBlock(in.evidence)
+	messages := []external.Message{
+		{Role: "system", Text: in.prompt.SystemPrompt},
+		{Role: "user", Text: userPrompt},
+	}
+
+	// Deltas are held back until a sentence is complete, and everything sent so far plus the new
+	// sentences passes the safety check before it reaches the client. On the first violation the
+	// stream is aborted and the fallback text follows; the "done" event replaces what was shown.
+	var sent, pending string
+	var emitErr error
+	unsafe := false
+	resp, err := streamer.Stream(ctx, messages, external.DefaultTemperature, external.DefaultMaxTokens, func(delta string) error {
+		pending += delta
+		end := lastSentenceEnd(pending)
+		if end == 0 {
+			return nil
+		}
+		ready := pending[:end]
+		if s.unsafeAdvice(ctx, sent+ready) {
+			unsafe = true
+			return errUnsafeAdviceStream
+		}
+		if err := emit(AdviceEventDelta, AdviceDelta{Text: ready}); err != nil {
+			emitErr = err
+			return err
+		}
+		sent += ready
+		pending = pending[end:]
+		return nil
+	})
+	if emitErr != nil {
+		return "", "", emitErr
+	}
+	if unsafe {
+		return fallback(entity.AIStatusFailed)
+	}
+	if err != nil {
+		log.Printf("[AIAdvice] LLM stream error (using fallback): %v", err)
+		return fallback(entity.AIStatusFailed)
+	}
+
+	text := normalizeAdviceText(resp.Text)
+	if text == "" {
+		log.Printf("[AIAdvice] LLM returned empty response, using fallback")
+		return fallback(entity.AIStatusFailed)
+	}
+	if s.unsafeAdvice(ctx, text) {
+		return fallback(entity.AIStatusFailed)
+	}
+	if pending != "" {
+		if err := emit(AdviceEventDelta, AdviceDelta{Text: pending}); err != nil {
+			return "", "", err
+		}
+	}
+	return text, entity.AIStatusDone, nil
+}
+
+// errUnsafeAdviceStream aborts a stream whose text failed the safety check.
+var errUnsafeAdviceStream = errors.New("streamed advice rejected by safety check")
+
+// lastSentenceEnd returns the length of the longest prefix of text that ends a sentence: a line
+// break, or ".", "!", "?" or "…" followed by whitespace (so "0.5 мг" is not split). It is 0 when
+// no sentence is complete yet.
+func lastSentenceEnd(text string) int {
+	end := 0
+	prev := rune(0)
+	for i, r := range text {
+		if r == '\n' {
+			end = i + 1
+		} else if unicode.IsSpace(r) && strings.ContainsRune(".!?…", prev) {
+			end = i
+		}
+		prev = r
+	}
+	return end
+}
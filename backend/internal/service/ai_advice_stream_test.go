@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+type chunkedStreamLLM struct {
+	chunks []string
+}
+
+func (l *chunkedStreamLLM) Complete(ctx context.Context, messages []external.Message, temperature float64, maxTokens int) (*external.Completion, error) {
+	return &external.Completion{Text: strings.Join(l.chunks, "")}, nil
+}
+
+func (l *chunkedStreamLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return strings.Join(l.chunks, ""), nil
+}
+
+func (l *chunkedStreamLLM) Model() string { return "test/chunked" }
+
+func (l *chunkedStreamLLM) Stream(ctx context.Context, messages []external.Message, temperature float64, maxTokens int, onDelta func(delta string) error) (*external.Completion, error) {
+	for _, c := range l.chunks {
+		if err := onDelta(c); err != nil {
+			return nil, err
+		}
+	}
+	return &external.Completion{Text: strings.Join(l.chunks, "")}, nil
+}
+
+func TestStreamPatientAdviceChecksSafetyBeforeSending(t *testing.T) {
+	template := &entity.SurveyTemplate{Code: "RCRI", Name: "RCRI"}
+	stream := func(chunks ...string) ([]string, string, string) {
+		svc := &AIAdviceService{llm: &chunkedStreamLLM{chunks: chunks}, safety: NewAdviceSafetyChecker(nil)}
+		in := &adviceInput{template: template, category: "class_ii", prompt: &entity.PromptTemplate{SystemPrompt: "system"}}
+		var deltas []string
+		text, status, err := svc.streamPatientAdvice(context.Background(), in, "", func(event string, data any) error {
+			deltas = append(deltas, data.(AdviceDelta).Text)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("streamPatientAdvice() error = %v", err)
+		}
+		return deltas, text, status
+	}
+
+	deltas, text, status := stream("Сохраняйте ", "активность. Снизьте вес на 2", ".5 кг.\nОбсудите ", "план с врачом.")
+	if status != entity.AIStatusDone {
+		t.Fatalf("safe stream = %q (%s), want done", text, status)
+	}
+	// Whole sentences only; the decimal point does not end one.
+	want := []string{"Сохраняйте активность.", " Снизьте вес на 2.5 кг.\n", "Обсудите план с врачом."}
+	if strings.Join(deltas, "|") != strings.Join(want, "|") {
+		t.Fatalf("deltas = %q, want %q", deltas, want)
+	}
+
+	deltas, text, status = stream("Сохраняйте активность. ", "Принимайте 40 ", "мг препарата. ", "Обсудите план с врачом.")
+	if status != entity.AIStatusFailed || strings.Contains(text, "40 мг") {
+		t.Fatalf("unsafe stream = %q (%s), want the fallback", text, status)
+	}
+	for _, d := range deltas {
+		if strings.Contains(d, "40") || strings.Contains(d, "Обсудите") {
+			t.Fatalf("unsafe or later text reached the client: %q", deltas)
+		}
+	}
+	if len(deltas) != 2 || deltas[0] != "Сохраняйте активность." || deltas[1] != text {
+		t.Fatalf("deltas = %q, want the safe sentence then the fallback", deltas)
+	}
+}
+
+func TestLastSentenceEnd(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Без конца", 0},
+		{"Первое. Второе", len("Первое.")},
+		{"Доза 0.5", 0},
+		{"Строка\nещё", len("Строка\n")},
+		{"Один! Два? Три", len("Один! Два?")},
+	}
+	for _, tt := range tests {
+		if got := lastSentenceEnd(tt.text); got != tt.want {
+			t.Errorf("lastSentenceEnd(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrAdviceNotFound     = errors.New("advice not found")
+	ErrAdviceAccessDenied = errors.New("access to this advice is not allowed")
+	ErrAdviceThreadClosed = errors.New("advice thread has reached its question limit")
+)
+
+const (
+	// adviceThreadMaxTurns caps the patient questions per advice record.
+	adviceThreadMaxTurns         = 10
+	adviceThreadQuestionMaxRunes = 1000
+	// adviceThreadHistoryBudget is how much earlier conversation (in characters) is sent with a question;
+	// older turns are dropped first. The advice itself is always sent.
+	adviceThreadHistoryBudget = 6000
+	// adviceThreadPurpose is recorded as the purpose of thread completions in llm_usage.
+	adviceThreadPurpose = "advice_thread"
+)
+
+// adviceThreadInstruction is appended to the patient advice prompt for follow-up questions.
+const adviceThreadInstruction = `
+
+Сейчас пациент задаёт уточняющие вопросы по уже выданной справке. Отвечай кратко (не более 5 предложений), простым языком и только по теме справки.
+Объясняй медицинские термины и сокращения. Не называй дозировки, не ставь диагноз и не советуй отменять препараты — в таких случаях предложи обсудить вопрос с лечащим врачом.`
+
+// adviceThreadFallbackReply is stored when no safe answer could be generated.
+const adviceThreadFallbackReply = "Не получилось подготовить ответ на этот вопрос. Пожалуйста, обсудите его с лечащим врачом, анестезиологом или хирургом."
+
+// AdviceThread is the follow-up conversation about one advice record.
+type AdviceThread struct {
+	AdviceID   uuid.UUID               `json:"advice_id"`
+	Messages   []*entity.AdviceMessage `json:"messages"`
+	TurnsLeft  int                     `json:"turns_left"`
+	Disclaimer string                  `json:"disclaimer"`
+}
+
+func newAdviceThread(adviceID uuid.UUID, messages []*entity.AdviceMessage) *AdviceThread {
+	return &AdviceThread{
+		AdviceID:   adviceID,
+		Messages:   messages,
+		TurnsLeft:  max(adviceThreadMaxTurns-countUserTurns(messages), 0),
+		Disclaimer: PatientAdviceDisclaimer,
+	}
+}
+
+// GetThread returns the conversation about an advice record of the user.
+func (s *AIAdviceService) GetThread(ctx context.Context, userID uuid.UUID, adviceID uuid.UUID) (*AdviceThread, error) {
+	if _, err := s.ownedAdvice(ctx, userID, adviceID); err != nil {
+		return nil, err
+	}
+	messages, err := s.messageRepo.ListByAdvice(ctx, adviceID)
+	if err != nil {
+		return nil, err
+	}
+	return newAdviceThread(adviceID, messages), nil
+}
+
+// AskInThread stores a follow-up question and the assistant reply. The model gets the advice and as much
+// of the earlier conversation as fits adviceThreadHistoryBudget; replies pass the same safety check as the
+// advice, and a fallback reply is stored when generation fails or is rejected.
+func (s *AIAdviceService) AskInThread(ctx context.Context, userID uuid.UUID, adviceID uuid.UUID, question string) (*AdviceThread, error) {
+	question = strings.TrimSpace(question)
+	v := validator.New()
+	v.Required("text", question, "text is required")
+	if utf8.RuneCountInString(question) > adviceThreadQuestionMaxRunes {
+		v.AddError("text", fmt.Sprintf("text must be at most %d characters", adviceThreadQuestionMaxRunes))
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	advice, err := s.ownedAdvice(ctx, userID, adviceID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.messageRepo.ListByAdvice(ctx, adviceID)
+	if err != nil {
+		return nil, err
+	}
+	if countUserTurns(history) >= adviceThreadMaxTurns {
+		return nil, ErrAdviceThreadClosed
+	}
+	if err := s.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// The count above is only a fast path: the cap is enforced again while the question is stored,
+	// so concurrent questions cannot push the thread past it.
+	asked := &entity.AdviceMessage{ID: uuid.New(), AdviceID: adviceID, Role: entity.AdviceMessageUser, Content: question, CreatedAt: time.Now().UTC()}
+	ok, err := s.messageRepo.CreateUserTurn(ctx, asked, adviceThreadMaxTurns)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAdviceThreadClosed
+	}
+
+	reply := &entity.AdviceMessage{ID: uuid.New(), AdviceID: adviceID, Role: entity.AdviceMessageAssistant, Content: adviceThreadFallbackReply, AIStatus: entity.AIStatusFailed}
+	if s.llm != nil {
+		ctx = withLLMUsageScope(ctx, &userID, advice.SurveyCode, adviceThreadPurpose)
+		text, err := s.threadReply(ctx, userID, advice, trimThreadHistory(history, adviceThreadHistoryBudget), question)
+		if err != nil {
+			log.Printf("[AIAdvice] thread reply for advice %s failed (using fallback): %v", adviceID, err)
+		} else if text == "" {
+			log.Printf("[AIAdvice] thread reply for advice %s is empty, using fallback", adviceID)
+		} else if !s.unsafeAdvice(ctx, text) {
+			reply.Content, reply.AIStatus = text, entity.AIStatusDone
+		}
+	}
+	reply.CreatedAt = time.Now().UTC()
+	if err := s.messageRepo.Create(ctx, reply); err != nil {
+		return nil, err
+	}
+
+	return newAdviceThread(adviceID, append(history, asked, reply)), nil
+}
+
+// ownedAdvice loads an advice record and checks it belongs to the user's patient record.
+func (s *AIAdviceService) ownedAdvice(ctx context.Context, userID uuid.UUID, adviceID uuid.UUID) (*entity.AIAdvice, error) {
+	advice, err := s.adviceRepo.GetByID(ctx, adviceID)
+	if err != nil {
+		return nil, err
+	}
+	if advice == nil {
+		return nil, ErrAdviceNotFound
+	}
+	patient, err := s.patientRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil || patient.ID != advice.PatientID {
+		return nil, ErrAdviceAccessDenied
+	}
+	return advice, nil
+}
+
+// threadReply sends the advice, the trimmed history and the new question to the model.
+// Patient messages are masked in one pass so the redaction is audited once per question.
+func (s *AIAdviceService) threadReply(ctx context.Context, userID uuid.UUID, advice *entity.AIAdvice, history []*entity.AdviceMessage, question string) (string, error) {
+	userTexts := make([]any, 0, len(history)+1)
+	for _, m := range history {
+		if m.Role == entity.AdviceMessageUser {
+			userTexts = append(userTexts, m.Content)
+		}
+	}
+	userTexts = append(userTexts, question)
+	redacted, ok := s.redactor.RedactValues(ctx, &userID, entity.ResourceAdvice, &advice.ID, map[string]any{"messages": userTexts})["messages"].([]any)
+	if !ok || len(redacted) != len(userTexts) {
+		return "", errors.New("redaction returned an unexpected shape for the thread messages")
+	}
+	masked := make([]string, len(redacted))
+	for i, v := range redacted {
+		if masked[i], ok = v.(string); !ok {
+			return "", fmt.Errorf("redaction returned %T for thread message %d", v, i)
+		}
+	}
+	next := func() string {
+		text := masked[0]
+		masked = masked[1:]
+		return text
+	}
+
+	// The thread continues with the prompt version the advice was generated with.
+	prompt := s.prompts.ResolveByID(ctx, advice.PromptTemplateID, entity.PromptPatientAdvice, advice.SurveyCode)
+	messages := make([]external.Message, 0, len(history)+4)
+	messages = append(messages,
+		external.Message{Role: "system", Text: prompt.SystemPrompt + adviceThreadInstruction},
+		external.Message{Role: "user", Text: adviceThreadContext(advice)},
+		external.Message{Role: "assistant", Text: normalizeAdviceText(advice.AdviceText)},
+	)
+	for _, m := range history {
+		text := m.Content
+		if m.Role == entity.AdviceMessageUser {
+			text = next()
+		}
+		messages = append(messages, external.Message{Role: m.Role, Text: text})
+	}
+	messages = append(messages, external.Message{Role: "user", Text: next()})
+
+	resp, err := s.llm.Complete(ctx, messages, external.DefaultTemperature, external.DefaultMaxTokens)
+	if err != nil {
+		return "", err
+	}
+	return normalizeAdviceText(resp.Text), nil
+}
+
+// adviceThreadContext restates what the advice was about; the patient's own comment is not repeated.
+func adviceThreadContext(advice *entity.AIAdvice) string {
+	score := "—"
+	if advice.Score != nil {
+		score = fmt.Sprintf("%.2f", *advice.Score)
+	}
+	return fmt.Sprintf("Опросник: %s\nИтоговый балл: %s\nКатегория: %s\n\nОбъясни результат.", advice.SurveyCode, score, advice.Category)
+}
+
+// trimThreadHistory keeps the most recent messages whose combined length fits budget. The result
+// starts with a patient question so the conversation the model sees is well-formed.
+func trimThreadHistory(history []*entity.AdviceMessage, budget int) []*entity.AdviceMessage {
+	start := len(history)
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += utf8.RuneCountInString(history[i].Content)
+		if used > budget {
+			break
+		}
+		start = i
+	}
+	for start < len(history) && history[start].Role != entity.AdviceMessageUser {
+		start++
+	}
+	return history[start:]
+}
+
+func countUserTurns(messages []*entity.AdviceMessage) int {
+	n := 0
+	for _, m := range messages {
+		if m.Role == entity.AdviceMessageUser {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+type fakeAdviceRepo struct {
+	items map[uuid.UUID]*entity.AIAdvice
+}
+
+func (r *fakeAdviceRepo) Create(ctx context.Context, item *entity.AIAdvice) error {
+	r.items[item.ID] = item
+	return nil
+}
+
+func (r *fakeAdviceRepo) ListByPatient(ctx context.Context, patientID uuid.UUID, limit int, offset int) ([]*entity.AIAdvice, error) {
+	return nil, nil
+}
+
+func (r *fakeAdviceRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.AIAdvice, error) {
+	return r.items[id], nil
+}
+
+func (r *fakeAdviceRepo) UpdateAdviceText(ctx context.Context, id uuid.UUID, text string, sections *entity.AdviceSections, citations []string, aiStatus string) error {
+	return nil
+}
+
+type fakePatientRepo struct {
+	byUser map[uuid.UUID]*entity.Patient
+}
+
+func (r *fakePatientRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Patient, error) {
+	for _, p := range r.byUser {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePatientRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*entity.Patient, error) {
+	return r.byUser[userID], nil
+}
+
+func (r *fakePatientRepo) Create(ctx context.Context, patient *entity.Patient) error {
+	r.byUser[patient.UserID] = patient
+	return nil
+}
+
+type fakeAdviceMessageRepo struct {
+	messages []*entity.AdviceMessage
+}
+
+func (r *fakeAdviceMessageRepo) Create(ctx context.Context, m *entity.AdviceMessage) error {
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+func (r *fakeAdviceMessageRepo) CreateUserTurn(ctx context.Context, m *entity.AdviceMessage, maxTurns int) (bool, error) {
+	n := 0
+	for _, x := range r.messages {
+		if x.AdviceID == m.AdviceID && x.Role == entity.AdviceMessageUser {
+			n++
+		}
+	}
+	if n >= maxTurns {
+		return false, nil
+	}
+	r.messages = append(r.messages, m)
+	return true, nil
+}
+
+func (r *fakeAdviceMessageRepo) ListByAdvice(ctx context.Context, adviceID uuid.UUID) ([]*entity.AdviceMessage, error) {
+	var out []*entity.AdviceMessage
+	for _, m := range r.messages {
+		if m.AdviceID == adviceID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func TestTrimThreadHistory(t *testing.T) {
+	msg := func(role, text string) *entity.AdviceMessage { return &entity.AdviceMessage{Role: role, Content: text} }
+	history := []*entity.AdviceMessage{
+		msg(entity.AdviceMessageUser, "aaaaaaaaaa"),
+		msg(entity.AdviceMessageAssistant, "bbbbbbbbbb"),
+		msg(entity.AdviceMessageUser, "cccccccccc"),
+		msg(entity.AdviceMessageAssistant, "dddddddddd"),
+	}
+
+	if got := trimThreadHistory(history, 100); len(got) != 4 {
+		t.Fatalf("trimThreadHistory(100) kept %d messages, want 4", len(got))
+	}
+	// 30 characters fit the last three messages, but the kept part must start with a question.
+	if got := trimThreadHistory(history, 30); len(got) != 2 || got[0].Content != "cccccccccc" {
+		t.Fatalf("trimThreadHistory(30) = %d messages starting with %q", len(got), got[0].Content)
+	}
+	if got := trimThreadHistory(history, 5); len(got) != 0 {
+		t.Fatalf("trimThreadHistory(5) kept %d messages, want 0", len(got))
+	}
+}
+
+func TestAskInThread(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: userID}
+	score := 3.0
+	advice := &entity.AIAdvice{ID: uuid.New(), PatientID: patient.ID, SurveyCode: "RCRI", Score: &score, Category: "high", AdviceText: "Риск MACE повышен."}
+	messages := &fakeAdviceMessageRepo{}
+	svc := &AIAdviceService{
+		adviceRepo:  &fakeAdviceRepo{items: map[uuid.UUID]*entity.AIAdvice{advice.ID: advice}},
+		patientRepo: &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{userID: patient}},
+		messageRepo: messages,
+		llm:         external.NewStubLLM(),
+		safety:      &AdviceSafetyChecker{},
+	}
+
+	thread, err := svc.AskInThread(ctx, userID, advice.ID, "Что такое MACE?")
+	if err != nil {
+		t.Fatalf("AskInThread() error = %v", err)
+	}
+	if len(thread.Messages) != 2 || thread.TurnsLeft != adviceThreadMaxTurns-1 {
+		t.Fatalf("thread = %d messages, %d turns left", len(thread.Messages), thread.TurnsLeft)
+	}
+	reply := thread.Messages[1]
+	if reply.Role != entity.AdviceMessageAssistant || reply.AIStatus != entity.AIStatusDone || !strings.Contains(reply.Content, "Что такое MACE?") {
+		t.Fatalf("reply = %+v", reply)
+	}
+
+	// The stub echoes the question, so a dosage in it makes the reply unsafe.
+	thread, err = svc.AskInThread(ctx, userID, advice.ID, "Можно принять 40 мг перед операцией?")
+	if err != nil {
+		t.Fatalf("AskInThread(unsafe) error = %v", err)
+	}
+	if reply := thread.Messages[3]; reply.AIStatus != entity.AIStatusFailed || reply.Content != adviceThreadFallbackReply {
+		t.Fatalf("unsafe reply = %+v", reply)
+	}
+
+	if _, err := svc.AskInThread(ctx, uuid.New(), advice.ID, "Чужая справка?"); !errors.Is(err, ErrAdviceAccessDenied) {
+		t.Fatalf("AskInThread(other user) error = %v, want ErrAdviceAccessDenied", err)
+	}
+
+	for i := countUserTurns(messages.messages); i < adviceThreadMaxTurns; i++ {
+		if _, err := svc.AskInThread(ctx, userID, advice.ID, "Ещё вопрос"); err != nil {
+			t.Fatalf("AskInThread(turn %d) error = %v", i+1, err)
+		}
+	}
+	if _, err := svc.AskInThread(ctx, userID, advice.ID, "Лишний вопрос"); !errors.Is(err, ErrAdviceThreadClosed) {
+		t.Fatalf("AskInThread(over cap) error = %v, want ErrAdviceThreadClosed", err)
+	}
+}
+
+// messageCapturingLLM remembers the messages of the last completion.
+type messageCapturingLLM struct {
+	recordingLLM
+	messages []external.Message
+}
+
+func (l *messageCapturingLLM) Complete(ctx context.Context, messages []external.Message, temperature float64, maxTokens int) (*external.Completion, error) {
+	l.messages = messages
+	return &external.Completion{Text: "Ответ по справке."}, nil
+}
+
+// staleHistoryRepo hides the stored questions from ListByAdvice, as a request racing another one sees it.
+type staleHistoryRepo struct {
+	*fakeAdviceMessageRepo
+}
+
+func (r staleHistoryRepo) ListByAdvice(ctx context.Context, adviceID uuid.UUID) ([]*entity.AdviceMessage, error) {
+	return nil, nil
+}
+
+func TestAskInThreadPromptVersionAndCap(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: userID}
+	pinned := &entity.PromptTemplate{ID: uuid.New(), Key: entity.PromptPatientAdvice, Version: 3, SystemPrompt: "Промпт версии 3."}
+	advice := &entity.AIAdvice{ID: uuid.New(), PatientID: patient.ID, SurveyCode: "RCRI", Category: "class_ii", AdviceText: "Риск умеренный.", PromptTemplateID: &pinned.ID}
+	llm := &messageCapturingLLM{}
+	messages := &fakeAdviceMessageRepo{}
+	svc := &AIAdviceService{
+		adviceRepo:  &fakeAdviceRepo{items: map[uuid.UUID]*entity.AIAdvice{advice.ID: advice}},
+		patientRepo: &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{userID: patient}},
+		messageRepo: staleHistoryRepo{messages},
+		llm:         llm,
+		safety:      &AdviceSafetyChecker{},
+		prompts:     NewPromptService(PromptDeps{Repo: newFakePromptRepo(pinned)}),
+	}
+
+	if _, err := svc.AskInThread(ctx, userID, advice.ID, "Что значит класс II?"); err != nil {
+		t.Fatalf("AskInThread() error = %v", err)
+	}
+	if len(llm.messages) == 0 || !strings.HasPrefix(llm.messages[0].Text, pinned.SystemPrompt) {
+		t.Fatalf("system prompt = %q, want the advice's prompt version", llm.messages[0].Text)
+	}
+
+	// The history read says the thread is open, but the store already holds the maximum.
+	for countUserTurns(messages.messages) < adviceThreadMaxTurns {
+		messages.messages = append(messages.messages, &entity.AdviceMessage{ID: uuid.New(), AdviceID: advice.ID, Role: entity.AdviceMessageUser, Content: "вопрос"})
+	}
+	if _, err := svc.AskInThread(ctx, userID, advice.ID, "Лишний вопрос"); !errors.Is(err, ErrAdviceThreadClosed) {
+		t.Fatalf("AskInThread(over cap) error = %v, want ErrAdviceThreadClosed", err)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+)
+
+var (
+	ErrAIJobNotFound     = errors.New("ai job not found")
+	ErrAIJobAccessDenied = errors.New("access to this ai job is not allowed")
+	// ErrAIJobPermanent marks a runner error that a retry cannot fix; the job fails immediately.
+	ErrAIJobPermanent = errors.New("ai job failed permanently")
+)
+
+const (
+	aiJobMaxAttempts  = 3
+	aiJobPollInterval = time.Second
+	// A running job not finished within this window is considered abandoned by a dead worker.
+	aiJobStaleAfter = 5 * time.Minute
+)
+
+// AIJobRunner executes one kind of AI job. Run returns the generated text; Fail is called
+// once the job has exhausted its attempts so the target record can leave the pending state.
+type AIJobRunner interface {
+	RunAIJob(ctx context.Context, job *entity.AIJob) (string, error)
+	FailAIJob(ctx context.Context, job *entity.AIJob, cause error) error
+}
+
+// AIJobService is a PostgreSQL-backed queue for LLM generation. Request handlers enqueue work
+// and return immediately; workers started with Start claim jobs with SELECT ... FOR UPDATE SKIP LOCKED.
+type AIJobService struct {
+	repo    repository.AIJobRepository
+	usage   *LLMUsageService
+	workers int
+
+	mu      sync.RWMutex
+	runners map[string]AIJobRunner
+}
+
+type AIJobDeps struct {
+	Repo repository.AIJobRepository
+	// Usage re-checks the requester's quota before a job calls the provider; optional.
+	Usage   *LLMUsageService
+	Workers int
+}
+
+func NewAIJobService(d AIJobDeps) *AIJobService {
+	workers := d.Workers
+	if workers <= 0 {
+		workers = 2
+	}
+	return &AIJobService{repo: d.Repo, usage: d.Usage, workers: workers, runners: map[string]AIJobRunner{}}
+}
+
+// Register binds a runner to a job kind. It must be called before Start.
+func (s *AIJobService) Register(kind string, runner AIJobRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runners[kind] = runner
+}
+
+// Enqueue stores a new pending job with a JSON payload.
+func (s *AIJobService) Enqueue(ctx context.Context, job *entity.AIJob, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode ai job payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job.ID = uuid.New()
+	job.Status = entity.AIJobStatusPending
+	job.Payload = raw
+	job.MaxAttempts = aiJobMaxAttempts
+	job.RunAfter = now
+	job.CreatedAt = now
+	return s.repo.Create(ctx, job)
+}
+
+// Get returns a job for polling. Only the requester or staff may see it.
+func (s *AIJobService) Get(ctx context.Context, userID uuid.UUID, staff bool, id uuid.UUID) (*entity.AIJob, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrAIJobNotFound
+	}
+	if !staff && (job.UserID == nil || *job.UserID != userID) {
+		return nil, ErrAIJobAccessDenied
+	}
+	return job, nil
+}
+
+// Start launches the workers; they stop when ctx is cancelled.
+func (s *AIJobService) Start(ctx context.Context) {
+	for i := 0; i < s.workers; i++ {
+		go s.worker(ctx, i)
+	}
+	go s.reaper(ctx)
+	log.Printf("[AIJobs] started %d workers", s.workers)
+}
+
+func (s *AIJobService) worker(ctx context.Context, n int) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		job, err := s.repo.ClaimNext(ctx, time.Now().UTC())
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[AIJobs] worker %d: claim failed: %v", n, err)
+			}
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(aiJobPollInterval):
+			}
+			continue
+		}
+
+		s.process(ctx, job)
+	}
+}
+
+func (s *AIJobService) process(ctx context.Context, job *entity.AIJob) {
+	s.mu.RLock()
+	runner := s.runners[job.Kind]
+	s.mu.RUnlock()
+
+	var result string
+	err := fmt.Errorf("no runner registered for kind %q", job.Kind)
+	if runner != nil {
+		// Usage may have grown since the job was queued; an exhausted quota does not recover by retrying today.
+		if qerr := s.usage.CheckJobQuota(ctx, job); qerr != nil {
+			err = fmt.Errorf("%w: %w", qerr, ErrAIJobPermanent)
+		} else {
+			result, err = runAIJob(ctx, runner, job)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err == nil {
+		if err := s.repo.Complete(ctx, job.ID, result, now); err != nil {
+			log.Printf("[AIJobs] job %s: complete failed: %v", job.ID, err)
+		}
+		return
+	}
+
+	log.Printf("[AIJobs] job %s (%s) attempt %d/%d failed: %v", job.ID, job.Kind, job.Attempts, job.MaxAttempts, err)
+	if runner != nil && job.Attempts < job.MaxAttempts && !errors.Is(err, ErrAIJobPermanent) {
+		retryAt := now.Add(aiJobBackoff(job.Attempts))
+		if ferr := s.repo.Fail(ctx, job.ID, err.Error(), &retryAt, now); ferr != nil {
+			log.Printf("[AIJobs] job %s: requeue failed: %v", job.ID, ferr)
+		}
+		return
+	}
+
+	if ferr := s.repo.Fail(ctx, job.ID, err.Error(), nil, now); ferr != nil {
+		log.Printf("[AIJobs] job %s: fail failed: %v", job.ID, ferr)
+	}
+	if runner != nil {
+		if ferr := runner.FailAIJob(ctx, job, err); ferr != nil {
+			log.Printf("[AIJobs] job %s: target update failed: %v", job.ID, ferr)
+		}
+	}
+}
+
+// runAIJob calls the runner and turns a panic into a permanent error, so the job and its target
+// are failed instead of the worker goroutine crashing the process.
+func runAIJob(ctx context.Context, runner AIJobRunner, job *entity.AIJob) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[AIJobs] job %s (%s) panicked: %v\n%s", job.ID, job.Kind, r, debug.Stack())
+			result, err = "", fmt.Errorf("panic: %v: %w", r, ErrAIJobPermanent)
+		}
+	}()
+	return runner.RunAIJob(ctx, job)
+}
+
+// reaper periodically returns jobs abandoned by crashed workers to the queue.
+func (s *AIJobService) reaper(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.repo.RequeueStale(ctx, time.Now().UTC().Add(-aiJobStaleAfter))
+			if err != nil {
+				log.Printf("[AIJobs] requeue stale jobs failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[AIJobs] requeued %d stale jobs", n)
+			}
+		}
+	}
+}
+
+// aiJobBackoff is the delay before retrying after the given (1-based) attempt: 10s, 40s, 90s, ...
+func aiJobBackoff(attempt int) time.Duration {
+	return time.Duration(attempt*attempt) * 10 * time.Second
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type fakeAIJobRepo struct {
+	completed map[uuid.UUID]string
+	retried   map[uuid.UUID]time.Time
+	failed    map[uuid.UUID]string
+	active    map[uuid.UUID]int
+}
+
+func newFakeAIJobRepo() *fakeAIJobRepo {
+	return &fakeAIJobRepo{completed: map[uuid.UUID]string{}, retried: map[uuid.UUID]time.Time{}, failed: map[uuid.UUID]string{}}
+}
+
+func (r *fakeAIJobRepo) Create(ctx context.Context, job *entity.AIJob) error { return nil }
+func (r *fakeAIJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.AIJob, error) {
+	return nil, nil
+}
+func (r *fakeAIJobRepo) ClaimNext(ctx context.Context, now time.Time) (*entity.AIJob, error) {
+	return nil, nil
+}
+func (r *fakeAIJobRepo) Complete(ctx context.Context, id uuid.UUID, result string, at time.Time) error {
+	r.completed[id] = result
+	return nil
+}
+func (r *fakeAIJobRepo) Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time, at time.Time) error {
+	if retryAt != nil {
+		r.retried[id] = *retryAt
+	} else {
+		r.failed[id] = errMsg
+	}
+	return nil
+}
+func (r *fakeAIJobRepo) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+func (r *fakeAIJobRepo) CountActiveByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	return r.active[userID], nil
+}
+
+type fakeRunner struct {
+	text      string
+	err       error
+	panics    bool
+	runs      int
+	finalized int
+}
+
+func (f *fakeRunner) RunAIJob(ctx context.Context, job *entity.AIJob) (string, error) {
+	f.runs++
+	if f.panics {
+		panic("boom")
+	}
+	return f.text, f.err
+}
+func (f *fakeRunner) FailAIJob(ctx context.Context, job *entity.AIJob, cause error) error {
+	f.finalized++
+	return nil
+}
+
+func TestAIJobServiceProcess(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success completes the job", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+		svc.Register("k", &fakeRunner{text: "ok"})
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if repo.completed[job.ID] != "ok" {
+			t.Errorf("job not completed: %+v", repo)
+		}
+	})
+
+	t.Run("error before last attempt is retried", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		runner := &fakeRunner{err: errors.New("timeout")}
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+		svc.Register("k", runner)
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if _, ok := repo.retried[job.ID]; !ok {
+			t.Errorf("job was not requeued")
+		}
+		if runner.finalized != 0 {
+			t.Errorf("FailAIJob called before attempts were exhausted")
+		}
+	})
+
+	t.Run("error on last attempt fails the job and its target", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		runner := &fakeRunner{err: errors.New("timeout")}
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+		svc.Register("k", runner)
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", Attempts: 3, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if repo.failed[job.ID] != "timeout" {
+			t.Errorf("job not failed: %+v", repo)
+		}
+		if runner.finalized != 1 {
+			t.Errorf("FailAIJob called %d times, want 1", runner.finalized)
+		}
+	})
+
+	t.Run("permanent error fails without retry", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		runner := &fakeRunner{err: fmt.Errorf("unsafe output: %w", ErrAIJobPermanent)}
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+		svc.Register("k", runner)
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if _, ok := repo.retried[job.ID]; ok {
+			t.Errorf("permanent failure was requeued")
+		}
+		if runner.finalized != 1 {
+			t.Errorf("FailAIJob called %d times, want 1", runner.finalized)
+		}
+	})
+
+	t.Run("panic fails the job and its target", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		runner := &fakeRunner{panics: true}
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+		svc.Register("k", runner)
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if msg, ok := repo.failed[job.ID]; !ok || !strings.Contains(msg, "panic: boom") {
+			t.Errorf("panicking job not failed: %+v", repo)
+		}
+		if runner.finalized != 1 {
+			t.Errorf("FailAIJob called %d times, want 1", runner.finalized)
+		}
+	})
+
+	t.Run("exhausted quota fails before calling the provider", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		userID := uuid.New()
+		usage := &fakeLLMUsageRepo{quota: &entity.LLMQuota{DailyRequests: intPtr(2)}}
+		usage.usage = append(usage.usage, &entity.LLMUsage{UserID: &userID, CreatedAt: time.Now().UTC()})
+		runner := &fakeRunner{text: "ok"}
+		svc := NewAIJobService(AIJobDeps{Repo: repo, Usage: NewLLMUsageService(LLMUsageDeps{Repo: usage, JobRepo: repo})})
+		svc.Register("k", runner)
+
+		// One recorded request plus this job: within the limit of 2.
+		job := &entity.AIJob{ID: uuid.New(), Kind: "k", UserID: &userID, Attempts: 1, MaxAttempts: 3}
+		repo.active = map[uuid.UUID]int{userID: 1}
+		svc.process(ctx, job)
+		if repo.completed[job.ID] != "ok" {
+			t.Fatalf("job within quota not completed: %+v", repo)
+		}
+
+		// Another generation was recorded meanwhile.
+		usage.usage = append(usage.usage, &entity.LLMUsage{UserID: &userID, CreatedAt: time.Now().UTC()})
+		job = &entity.AIJob{ID: uuid.New(), Kind: "k", UserID: &userID, Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if _, ok := repo.failed[job.ID]; !ok || runner.runs != 1 || runner.finalized != 1 {
+			t.Errorf("over-quota job: failed %v, runs %d, finalized %d", ok, runner.runs, runner.finalized)
+		}
+	})
+
+	t.Run("unknown kind fails without retry", func(t *testing.T) {
+		repo := newFakeAIJobRepo()
+		svc := NewAIJobService(AIJobDeps{Repo: repo})
+
+		job := &entity.AIJob{ID: uuid.New(), Kind: "missing", Attempts: 1, MaxAttempts: 3}
+		svc.process(ctx, job)
+		if _, ok := repo.failed[job.ID]; !ok {
+			t.Errorf("job with unknown kind was not failed")
+		}
+	})
+}
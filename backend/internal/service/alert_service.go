@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrAlertNotFound     = errors.New("alert not found")
+	ErrAlertNotAssigned  = errors.New("alert is not assigned to this doctor")
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+)
+
+type AlertService struct {
+	ruleRepo    repository.AlertRuleRepository
+	alertRepo   repository.AlertRepository
+	indexRepo   repository.MedicalIndexRepository
+	patientRepo repository.PatientRepository
+}
+
+type AlertDeps struct {
+	RuleRepo    repository.AlertRuleRepository
+	AlertRepo   repository.AlertRepository
+	IndexRepo   repository.MedicalIndexRepository
+	PatientRepo repository.PatientRepository
+}
+
+func NewAlertService(d AlertDeps) *AlertService {
+	return &AlertService{
+		ruleRepo:    d.RuleRepo,
+		alertRepo:   d.AlertRepo,
+		indexRepo:   d.IndexRepo,
+		patientRepo: d.PatientRepo,
+	}
+}
+
+// EvaluateIndex runs all active rules for the index type against a newly recorded measurement
+// and raises alerts for the patient's attending doctor. Alerts of a patient without one go to the
+// unassigned queue worked by admins.
+func (s *AlertService) EvaluateIndex(ctx context.Context, idx *entity.MedicalIndex) ([]*entity.Alert, error) {
+	rules, prev, doctorID, err := s.evaluationContext(ctx, idx)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	var raised []*entity.Alert
+	for _, rule := range rules {
+		triggered, message := EvaluateAlertRule(rule, prev, idx)
+		if !triggered {
+			continue
+		}
+		a := newAlert(rule, prev, idx, doctorID, message)
+		if err := s.alertRepo.Create(ctx, a); err != nil {
+			return raised, err
+		}
+		raised = append(raised, a)
+	}
+	return raised, nil
+}
+
+// ReevaluateIndex re-runs the rules after a measurement's value or category changed (an amended or
+// re-scored response). Unresolved alerts whose rule no longer triggers are resolved by the system,
+// and rules that now trigger raise a new alert unless one is already open for the measurement.
+func (s *AlertService) ReevaluateIndex(ctx context.Context, idx *entity.MedicalIndex) (raised []*entity.Alert, cleared []*entity.Alert, err error) {
+	existing, err := s.alertRepo.ListUnresolvedByMedicalIndex(ctx, idx.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, prev, doctorID, err := s.evaluationContext(ctx, idx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	triggeredRules := make(map[uuid.UUID]bool, len(rules))
+	for _, rule := range rules {
+		triggered, message := EvaluateAlertRule(rule, prev, idx)
+		if !triggered {
+			continue
+		}
+		triggeredRules[rule.ID] = true
+		if slices.ContainsFunc(existing, func(a *entity.Alert) bool { return a.RuleID == rule.ID }) {
+			continue
+		}
+		a := newAlert(rule, prev, idx, doctorID, message)
+		if err := s.alertRepo.Create(ctx, a); err != nil {
+			return raised, cleared, err
+		}
+		raised = append(raised, a)
+	}
+
+	for _, a := range existing {
+		if triggeredRules[a.RuleID] {
+			continue
+		}
+		if err := s.alertRepo.Resolve(ctx, a.ID, nil, now, alertClearedNote); err != nil {
+			return raised, cleared, err
+		}
+		a.Status = entity.AlertStatusResolved
+		a.ResolvedAt = &now
+		a.ResolutionNote = alertClearedNote
+		cleared = append(cleared, a)
+	}
+	return raised, cleared, nil
+}
+
+// alertClearedNote is the resolution note of alerts closed by ReevaluateIndex.
+const alertClearedNote = "Оценка пересчитана: условие оповещения больше не выполняется"
+
+// evaluationContext loads the active rules for the index type, the preceding measurement and the
+// patient's attending doctor.
+func (s *AlertService) evaluationContext(ctx context.Context, idx *entity.MedicalIndex) ([]*entity.AlertRule, *entity.MedicalIndex, *uuid.UUID, error) {
+	rules, err := s.ruleRepo.List(ctx, idx.IndexType, true)
+	if err != nil || len(rules) == 0 {
+		return nil, nil, nil, err
+	}
+
+	prev, err := s.indexRepo.GetPrevious(ctx, idx.PatientID, idx.IndexType, idx.RecordedAt, idx.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, idx.PatientID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var doctorID *uuid.UUID
+	if patient != nil {
+		doctorID = patient.AttendingDoctorID
+	}
+	return rules, prev, doctorID, nil
+}
+
+func newAlert(rule *entity.AlertRule, prev *entity.MedicalIndex, idx *entity.MedicalIndex, doctorID *uuid.UUID, message string) *entity.Alert {
+	a := &entity.Alert{
+		ID:              uuid.New(),
+		RuleID:          rule.ID,
+		PatientID:       idx.PatientID,
+		DoctorID:        doctorID,
+		MedicalIndexID:  &idx.ID,
+		IndexType:       idx.IndexType,
+		CurrentValue:    idx.Value,
+		CurrentCategory: idx.Category,
+		Severity:        rule.Severity,
+		Message:         message,
+		Status:          entity.AlertStatusOpen,
+		CreatedAt:       time.Now().UTC(),
+	}
+	if prev != nil {
+		a.PreviousValue = &prev.Value
+		a.PreviousCategory = prev.Category
+	}
+	return a
+}
+
+// EvaluateAlertRule reports whether curr (compared with prev, which may be nil) triggers the rule.
+func EvaluateAlertRule(rule *entity.AlertRule, prev *entity.MedicalIndex, curr *entity.MedicalIndex) (bool, string) {
+	label := rule.Description
+	if label == "" {
+		label = rule.IndexType
+	}
+
+	switch rule.RuleType {
+	case entity.AlertRuleThreshold:
+		if rule.Threshold == nil {
+			return false, ""
+		}
+		limit := *rule.Threshold
+		crossed := false
+		switch rule.Direction {
+		case entity.AlertDirectionFall:
+			crossed = curr.Value < limit && (prev == nil || prev.Value >= limit)
+		default:
+			crossed = curr.Value > limit && (prev == nil || prev.Value <= limit)
+		}
+		if !crossed {
+			return false, ""
+		}
+		return true, fmt.Sprintf("%s: %s = %.2f (порог %.2f)", label, curr.IndexType, curr.Value, limit)
+
+	case entity.AlertRuleDelta:
+		if rule.Threshold == nil || prev == nil {
+			return false, ""
+		}
+		delta := round2(curr.Value - prev.Value)
+		var hit bool
+		switch rule.Direction {
+		case entity.AlertDirectionFall:
+			hit = -delta > *rule.Threshold
+		case entity.AlertDirectionAny:
+			hit = delta > *rule.Threshold || -delta > *rule.Threshold
+		default:
+			hit = delta > *rule.Threshold
+		}
+		if !hit {
+			return false, ""
+		}
+		return true, fmt.Sprintf("%s: %s %.2f → %.2f (Δ %+.2f)", label, curr.IndexType, prev.Value, curr.Value, delta)
+
+	case entity.AlertRuleCategoryChange:
+		if prev == nil || prev.Category == curr.Category {
+			return false, ""
+		}
+		order, err := rule.GetCategoryOrder()
+		if err != nil {
+			return false, ""
+		}
+		from, to := categoryRank(order, prev.Category), categoryRank(order, curr.Category)
+		var hit bool
+		switch rule.Direction {
+		case entity.AlertDirectionFall:
+			hit = from >= 0 && to >= 0 && to < from
+		case entity.AlertDirectionAny:
+			hit = true
+		default:
+			hit = from >= 0 && to >= 0 && to > from
+		}
+		if !hit {
+			return false, ""
+		}
+		return true, fmt.Sprintf("%s: %s %s → %s", label, curr.IndexType, prev.Category, curr.Category)
+	}
+	return false, ""
+}
+
+func categoryRank(order []string, category string) int {
+	for i, c := range order {
+		if c == category {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListForDoctor returns the doctor's alerts; with admin set it also returns the unassigned queue
+// (alerts of patients without an attending doctor).
+func (s *AlertService) ListForDoctor(ctx context.Context, doctorID uuid.UUID, admin bool, status string, limit int, offset int) ([]*entity.Alert, error) {
+	return s.alertRepo.ListByDoctor(ctx, doctorID, admin, strings.TrimSpace(status), limit, offset)
+}
+
+// Acknowledge moves an open alert to "acknowledged".
+func (s *AlertService) Acknowledge(ctx context.Context, doctorID uuid.UUID, admin bool, alertID uuid.UUID) (*entity.Alert, error) {
+	a, err := s.getOwned(ctx, doctorID, admin, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != entity.AlertStatusOpen {
+		v := validator.New()
+		v.AddError("status", "only open alerts can be acknowledged")
+		return nil, v.Errors()
+	}
+
+	now := time.Now().UTC()
+	if err := s.alertRepo.Acknowledge(ctx, a.ID, doctorID, now); err != nil {
+		return nil, err
+	}
+	a.Status = entity.AlertStatusAcknowledged
+	a.AcknowledgedBy = &doctorID
+	a.AcknowledgedAt = &now
+	return a, nil
+}
+
+// Resolve closes an open or acknowledged alert.
+func (s *AlertService) Resolve(ctx context.Context, doctorID uuid.UUID, admin bool, alertID uuid.UUID, note string) (*entity.Alert, error) {
+	a, err := s.getOwned(ctx, doctorID, admin, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status == entity.AlertStatusResolved {
+		v := validator.New()
+		v.AddError("status", "alert is already resolved")
+		return nil, v.Errors()
+	}
+
+	now := time.Now().UTC()
+	note = strings.TrimSpace(note)
+	if err := s.alertRepo.Resolve(ctx, a.ID, &doctorID, now, note); err != nil {
+		return nil, err
+	}
+	a.Status = entity.AlertStatusResolved
+	a.ResolvedBy = &doctorID
+	a.ResolvedAt = &now
+	a.ResolutionNote = note
+	return a, nil
+}
+
+// getOwned loads an alert assigned to the doctor; admins may also take unassigned alerts.
+func (s *AlertService) getOwned(ctx context.Context, doctorID uuid.UUID, admin bool, alertID uuid.UUID) (*entity.Alert, error) {
+	a, err := s.alertRepo.GetByID(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAlertNotFound
+	}
+	if a.DoctorID == nil && admin {
+		return a, nil
+	}
+	if a.DoctorID == nil || *a.DoctorID != doctorID {
+		return nil, ErrAlertNotAssigned
+	}
+	return a, nil
+}
+
+func (s *AlertService) ListRules(ctx context.Context, indexType string) ([]*entity.AlertRule, error) {
+	return s.ruleRepo.List(ctx, strings.ToUpper(strings.TrimSpace(indexType)), false)
+}
+
+func (s *AlertService) CreateRule(ctx context.Context, req entity.AlertRuleUpsert) (*entity.AlertRule, error) {
+	now := time.Now().UTC()
+	rule := &entity.AlertRule{
+		ID:        uuid.New(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyAlertRuleUpsert(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces a rule's configuration; it returns the updated rule and the previous version.
+func (s *AlertService) UpdateRule(ctx context.Context, id uuid.UUID, req entity.AlertRuleUpsert) (*entity.AlertRule, *entity.AlertRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rule == nil {
+		return nil, nil, ErrAlertRuleNotFound
+	}
+	previous := *rule
+
+	if err := applyAlertRuleUpsert(rule, req); err != nil {
+		return nil, nil, err
+	}
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, nil, err
+	}
+	rule.UpdatedAt = time.Now().UTC()
+	return rule, &previous, nil
+}
+
+func applyAlertRuleUpsert(rule *entity.AlertRule, req entity.AlertRuleUpsert) error {
+	req.IndexType = strings.ToUpper(strings.TrimSpace(req.IndexType))
+	if req.Direction == "" {
+		req.Direction = entity.AlertDirectionRise
+	}
+	if req.Severity == "" {
+		req.Severity = entity.AlertSeverityWarning
+	}
+
+	v := validator.New()
+	v.Required("index_type", req.IndexType, "index_type is required")
+	v.OneOf("rule_type", req.RuleType, []string{entity.AlertRuleThreshold, entity.AlertRuleDelta, entity.AlertRuleCategoryChange}, "invalid rule_type")
+	v.OneOf("direction", req.Direction, []string{entity.AlertDirectionRise, entity.AlertDirectionFall, entity.AlertDirectionAny}, "invalid direction")
+	v.OneOf("severity", req.Severity, []string{entity.AlertSeverityInfo, entity.AlertSeverityWarning, entity.AlertSeverityCritical}, "invalid severity")
+	switch req.RuleType {
+	case entity.AlertRuleThreshold, entity.AlertRuleDelta:
+		if req.Threshold == nil {
+			v.AddError("threshold", "threshold is required for threshold and delta rules")
+		}
+	case entity.AlertRuleCategoryChange:
+		if len(req.CategoryOrder) < 2 && req.Direction != entity.AlertDirectionAny {
+			v.AddError("category_order", "category_order needs at least two categories")
+		}
+	}
+	if v.HasErrors() {
+		return v.Errors()
+	}
+
+	rule.IndexType = req.IndexType
+	rule.RuleType = req.RuleType
+	rule.Direction = req.Direction
+	rule.Threshold = req.Threshold
+	rule.Severity = req.Severity
+	rule.Description = strings.TrimSpace(req.Description)
+	rule.CategoryOrder = nil
+	if len(req.CategoryOrder) > 0 {
+		order, _ := json.Marshal(req.CategoryOrder)
+		rule.CategoryOrder = order
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// reevaluateAlerts re-runs the alert rules for the measurement of a changed survey response.
+// Like the evaluation on submit, a failure is logged and never blocks the change itself.
+func reevaluateAlerts(ctx context.Context, alerts *AlertService, indexRepo repository.MedicalIndexRepository, responseID uuid.UUID, logPrefix string) {
+	if alerts == nil {
+		return
+	}
+	idx, err := indexRepo.GetBySurveyResponse(ctx, responseID)
+	if err == nil && idx != nil {
+		_, _, err = alerts.ReevaluateIndex(ctx, idx)
+	}
+	if err != nil {
+		log.Printf("%s alert re-evaluation failed for response %s: %v", logPrefix, responseID, err)
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type fakeAlertRuleRepo struct {
+	rules []*entity.AlertRule
+}
+
+func (r *fakeAlertRuleRepo) List(ctx context.Context, indexType string, activeOnly bool) ([]*entity.AlertRule, error) {
+	var out []*entity.AlertRule
+	for _, rule := range r.rules {
+		if rule.IndexType == indexType && (!activeOnly || rule.IsActive) {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+func (r *fakeAlertRuleRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertRule, error) {
+	return nil, nil
+}
+func (r *fakeAlertRuleRepo) Create(ctx context.Context, rule *entity.AlertRule) error { return nil }
+func (r *fakeAlertRuleRepo) Update(ctx context.Context, rule *entity.AlertRule) error { return nil }
+
+type fakeAlertRepo struct {
+	alerts []*entity.Alert
+}
+
+func (r *fakeAlertRepo) Create(ctx context.Context, a *entity.Alert) error {
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+func (r *fakeAlertRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Alert, error) {
+	for _, a := range r.alerts {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+func (r *fakeAlertRepo) ListByDoctor(ctx context.Context, doctorID uuid.UUID, includeUnassigned bool, status string, limit int, offset int) ([]*entity.Alert, error) {
+	var out []*entity.Alert
+	for _, a := range r.alerts {
+		if (a.DoctorID != nil && *a.DoctorID == doctorID) || (a.DoctorID == nil && includeUnassigned) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+func (r *fakeAlertRepo) Acknowledge(ctx context.Context, id uuid.UUID, userID uuid.UUID, at time.Time) error {
+	return nil
+}
+func (r *fakeAlertRepo) Resolve(ctx context.Context, id uuid.UUID, userID *uuid.UUID, at time.Time, note string) error {
+	for _, a := range r.alerts {
+		if a.ID == id {
+			a.Status, a.ResolvedBy, a.ResolutionNote = entity.AlertStatusResolved, userID, note
+		}
+	}
+	return nil
+}
+func (r *fakeAlertRepo) ListUnresolvedByMedicalIndex(ctx context.Context, medicalIndexID uuid.UUID) ([]*entity.Alert, error) {
+	var out []*entity.Alert
+	for _, a := range r.alerts {
+		if a.MedicalIndexID != nil && *a.MedicalIndexID == medicalIndexID && a.Status != entity.AlertStatusResolved {
+			cp := *a
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+type fakeIndexRepo struct {
+	items []*entity.MedicalIndex
+}
+
+func (r *fakeIndexRepo) Create(ctx context.Context, idx *entity.MedicalIndex) error {
+	r.items = append(r.items, idx)
+	return nil
+}
+func (r *fakeIndexRepo) ListByPatientAndType(ctx context.Context, patientID uuid.UUID, indexType string, from *time.Time, to *time.Time) ([]*entity.MedicalIndex, error) {
+	return nil, nil
+}
+func (r *fakeIndexRepo) GetPrevious(ctx context.Context, patientID uuid.UUID, indexType string, before time.Time, excludeID uuid.UUID) (*entity.MedicalIndex, error) {
+	var prev *entity.MedicalIndex
+	for _, idx := range r.items {
+		if idx.PatientID == patientID && idx.IndexType == indexType && idx.ID != excludeID && !idx.RecordedAt.After(before) &&
+			(prev == nil || idx.RecordedAt.After(prev.RecordedAt)) {
+			prev = idx
+		}
+	}
+	return prev, nil
+}
+func (r *fakeIndexRepo) UpdateValueBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID, value float64, category string) error {
+	if idx, _ := r.GetBySurveyResponse(ctx, surveyResponseID); idx != nil {
+		idx.Value, idx.Category = value, category
+	}
+	return nil
+}
+func (r *fakeIndexRepo) GetBySurveyResponse(ctx context.Context, surveyResponseID uuid.UUID) (*entity.MedicalIndex, error) {
+	for _, idx := range r.items {
+		if idx.SurveyResponseID != nil && *idx.SurveyResponseID == surveyResponseID {
+			return idx, nil
+		}
+	}
+	return nil, nil
+}
+func (r *fakeIndexRepo) ListLatestByPatient(ctx context.Context, patientID uuid.UUID) ([]*entity.MedicalIndex, error) {
+	pos := map[string]int{}
+	var out []*entity.MedicalIndex
+	for _, idx := range r.items {
+		if idx.PatientID != patientID {
+			continue
+		}
+		if i, ok := pos[idx.IndexType]; !ok {
+			pos[idx.IndexType] = len(out)
+			out = append(out, idx)
+		} else if idx.RecordedAt.After(out[i].RecordedAt) {
+			out[i] = idx
+		}
+	}
+	return out, nil
+}
+
+// engineCategory scores a template holding one boolean question per entry of points, with the
+// first n answered, and returns the category the scoring engine assigns.
+func engineCategory(t *testing.T, code string, points []float64, n int) string {
+	t.Helper()
+	section := entity.SurveySection{Section: "main"}
+	answers := map[string]interface{}{}
+	for i, p := range points {
+		id := fmt.Sprintf("q%d", i)
+		section.Questions = append(section.Questions, entity.SurveyQuestion{ID: id, Type: "boolean", Score: p})
+		answers[id] = i < n
+	}
+	// RCRI counts its fixed risk factors rather than template questions.
+	for i, field := range []string{"high_risk_surgery", "ihd", "chf", "cvd", "insulin_dm", "ckd"} {
+		answers[field] = code == "RCRI" && i < n
+	}
+	questions, _ := json.Marshal([]entity.SurveySection{section})
+	_, category, _, err := CalculateScore(&entity.SurveyTemplate{Code: code, Questions: questions}, answers)
+	if err != nil {
+		t.Fatalf("CalculateScore(%s) error = %v", code, err)
+	}
+	return category
+}
+
+// engineCategories lists the categories of a template from the lowest to the highest risk.
+func engineCategories(t *testing.T, code string) []string {
+	t.Helper()
+	var points []float64
+	switch code {
+	case "GOLDMAN":
+		points = []float64{10, 10, 10}
+	case "CAPRINI":
+		points = []float64{1, 2, 2}
+	}
+	var out []string
+	for n := 0; n <= 3; n++ {
+		out = append(out, engineCategory(t, code, points, n))
+	}
+	return out
+}
+
+func TestSeededAlertCategoryOrdersMatchEngine(t *testing.T) {
+	var sql []byte
+	for _, name := range []string{"007_alerts.up.sql", "025_alert_rules_live_templates.up.sql"} {
+		b, err := os.ReadFile("../../migrations/" + name)
+		if err != nil {
+			t.Fatalf("read migration: %v", err)
+		}
+		sql = append(sql, b...)
+	}
+	seeded := regexp.MustCompile(`'([A-Z_0-9]+)', 'category_change', '\w+', NULL,\s*'(\[[^']*\])'`).FindAllStringSubmatch(string(sql), -1)
+	if len(seeded) == 0 {
+		t.Fatal("no category_change rules found in the migrations")
+	}
+	for _, m := range seeded {
+		var order []string
+		if err := json.Unmarshal([]byte(m[2]), &order); err != nil {
+			t.Fatalf("%s category_order: %v", m[1], err)
+		}
+		want := engineCategories(t, m[1])
+		for i, category := range want {
+			if rank := categoryRank(order, category); rank != i {
+				t.Errorf("%s: engine category %q has rank %d in the seeded order %v, want %d", m[1], category, rank, order, i)
+			}
+		}
+	}
+}
+
+func TestEvaluateAlertRule(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	rcri := engineCategories(t, "RCRI")
+	rcriOrder, _ := json.Marshal(rcri)
+
+	capriniDelta := &entity.AlertRule{IndexType: "CAPRINI", RuleType: entity.AlertRuleDelta, Direction: entity.AlertDirectionRise, Threshold: f(2)}
+	capriniHigh := &entity.AlertRule{IndexType: "CAPRINI", RuleType: entity.AlertRuleThreshold, Direction: entity.AlertDirectionRise, Threshold: f(4)}
+	rcriUp := &entity.AlertRule{IndexType: "RCRI", RuleType: entity.AlertRuleCategoryChange, Direction: entity.AlertDirectionRise, CategoryOrder: rcriOrder}
+
+	tests := []struct {
+		name string
+		rule *entity.AlertRule
+		prev *entity.MedicalIndex
+		curr *entity.MedicalIndex
+		want bool
+	}{
+		{"delta above limit", capriniDelta, &entity.MedicalIndex{Value: 1}, &entity.MedicalIndex{Value: 4}, true},
+		{"delta exactly at limit", capriniDelta, &entity.MedicalIndex{Value: 1}, &entity.MedicalIndex{Value: 3}, false},
+		{"delta improvement", capriniDelta, &entity.MedicalIndex{Value: 5}, &entity.MedicalIndex{Value: 1}, false},
+		{"delta without baseline", capriniDelta, nil, &entity.MedicalIndex{Value: 6}, false},
+		{"threshold crossed", capriniHigh, &entity.MedicalIndex{Value: 3}, &entity.MedicalIndex{Value: 5}, true},
+		{"threshold first measurement", capriniHigh, nil, &entity.MedicalIndex{Value: 5}, true},
+		{"threshold already above", capriniHigh, &entity.MedicalIndex{Value: 5}, &entity.MedicalIndex{Value: 6}, false},
+		{"rcri class up", rcriUp, &entity.MedicalIndex{Category: rcri[1]}, &entity.MedicalIndex{Category: rcri[2]}, true},
+		{"rcri class down", rcriUp, &entity.MedicalIndex{Category: rcri[2]}, &entity.MedicalIndex{Category: rcri[0]}, false},
+		{"rcri unchanged", rcriUp, &entity.MedicalIndex{Category: rcri[1]}, &entity.MedicalIndex{Category: rcri[1]}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := EvaluateAlertRule(tt.rule, tt.prev, tt.curr)
+			if got != tt.want {
+				t.Errorf("EvaluateAlertRule() = %v, want %v", got, tt.want)
+			}
+			if got && msg == "" {
+				t.Errorf("EvaluateAlertRule() returned empty message for triggered rule")
+			}
+		})
+	}
+}
+
+func TestReevaluateIndexRaisesAndClears(t *testing.T) {
+	rcri := engineCategories(t, "RCRI")
+	order, _ := json.Marshal(rcri)
+	rule := &entity.AlertRule{ID: uuid.New(), IndexType: "RCRI", RuleType: entity.AlertRuleCategoryChange, Direction: entity.AlertDirectionRise, CategoryOrder: order, IsActive: true}
+
+	doctorID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: uuid.New(), AttendingDoctorID: &doctorID}
+	responseID := uuid.New()
+	earlier := time.Now().UTC().Add(-time.Hour)
+	indices := &fakeIndexRepo{items: []*entity.MedicalIndex{
+		{ID: uuid.New(), PatientID: patient.ID, IndexType: "RCRI", Category: rcri[1], RecordedAt: earlier},
+		{ID: uuid.New(), PatientID: patient.ID, IndexType: "RCRI", Category: rcri[1], SurveyResponseID: &responseID, RecordedAt: time.Now().UTC()},
+	}}
+	alerts := &fakeAlertRepo{}
+	svc := NewAlertService(AlertDeps{
+		RuleRepo:    &fakeAlertRuleRepo{rules: []*entity.AlertRule{rule}},
+		AlertRepo:   alerts,
+		IndexRepo:   indices,
+		PatientRepo: &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{patient.UserID: patient}},
+	})
+	ctx := context.Background()
+
+	// Re-scored up a class: a new alert for the attending doctor.
+	_ = indices.UpdateValueBySurveyResponse(ctx, responseID, 2, rcri[2])
+	reevaluateAlerts(ctx, svc, indices, responseID, "[Test]")
+	if len(alerts.alerts) != 1 || alerts.alerts[0].Status != entity.AlertStatusOpen || *alerts.alerts[0].DoctorID != doctorID {
+		t.Fatalf("alerts after rise = %+v", alerts.alerts)
+	}
+
+	// Evaluating again does not duplicate the open alert.
+	idx := indices.items[1]
+	if raised, cleared, err := svc.ReevaluateIndex(ctx, idx); err != nil || len(raised)+len(cleared) != 0 {
+		t.Fatalf("ReevaluateIndex() raised %d, cleared %d, error %v", len(raised), len(cleared), err)
+	}
+
+	// Amended back to the previous class: the alert is resolved by the system.
+	idx.Category = rcri[1]
+	raised, cleared, err := svc.ReevaluateIndex(ctx, idx)
+	if err != nil || len(raised) != 0 || len(cleared) != 1 {
+		t.Fatalf("ReevaluateIndex() raised %d, cleared %d, error %v", len(raised), len(cleared), err)
+	}
+	if a := alerts.alerts[0]; a.Status != entity.AlertStatusResolved || a.ResolvedBy != nil || a.ResolutionNote == "" {
+		t.Fatalf("cleared alert = %+v", a)
+	}
+}
+
+func TestUnassignedAlertsGoToAdminQueue(t *testing.T) {
+	caprini := &entity.AlertRule{ID: uuid.New(), IndexType: "CAPRINI", RuleType: entity.AlertRuleThreshold, Direction: entity.AlertDirectionRise, Threshold: func(v float64) *float64 { return &v }(4), IsActive: true}
+	patient := &entity.Patient{ID: uuid.New(), UserID: uuid.New()}
+	alerts := &fakeAlertRepo{}
+	svc := NewAlertService(AlertDeps{
+		RuleRepo:    &fakeAlertRuleRepo{rules: []*entity.AlertRule{caprini}},
+		AlertRepo:   alerts,
+		IndexRepo:   &fakeIndexRepo{},
+		PatientRepo: &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{patient.UserID: patient}},
+	})
+	ctx := context.Background()
+
+	idx := &entity.MedicalIndex{ID: uuid.New(), PatientID: patient.ID, IndexType: "CAPRINI", Value: 5, RecordedAt: time.Now().UTC()}
+	raised, err := svc.EvaluateIndex(ctx, idx)
+	if err != nil || len(raised) != 1 || raised[0].DoctorID != nil {
+		t.Fatalf("EvaluateIndex() = %+v, %v", raised, err)
+	}
+
+	doctorID, adminID := uuid.New(), uuid.New()
+	if list, _ := svc.ListForDoctor(ctx, doctorID, false, "", 20, 0); len(list) != 0 {
+		t.Fatalf("doctor sees unassigned alerts: %+v", list)
+	}
+	if _, err := svc.Resolve(ctx, doctorID, false, raised[0].ID, "seen"); err != ErrAlertNotAssigned {
+		t.Fatalf("Resolve() by doctor error = %v, want ErrAlertNotAssigned", err)
+	}
+	if list, _ := svc.ListForDoctor(ctx, adminID, true, "", 20, 0); len(list) != 1 {
+		t.Fatalf("admin queue = %+v, want the unassigned alert", list)
+	}
+	a, err := svc.Resolve(ctx, adminID, true, raised[0].ID, "routed to the surgical team")
+	if err != nil || a.Status != entity.AlertStatusResolved {
+		t.Fatalf("Resolve() by admin = %+v, %v", a, err)
+	}
+}
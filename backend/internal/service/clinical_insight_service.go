@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var ErrClinicalInsightNotFound = errors.New("clinical insight not found")
+
+const clinicalTherapyHistoryLimit = 30
+
+// ClinicalInsightService produces clinician-level AI output for one patient: an interpretation of the
+// latest indices and biologic therapy considerations. The output is stored apart from patient advice,
+// is not run through the patient-facing safety check and is only available to clinicians.
+type ClinicalInsightService struct {
+	patientRepo  repository.PatientRepository
+	templateRepo repository.SurveyTemplateRepository
+	indexRepo    repository.MedicalIndexRepository
+	therapyRepo  repository.TherapyLogRepository
+	insightRepo  repository.ClinicalInsightRepository
+	llm          external.LLMProvider
+	jobs         *AIJobService
+	prompts      *PromptService
+	redactor     *PHIRedactor
+	usage        *LLMUsageService
+}
+
+type ClinicalInsightDeps struct {
+	PatientRepo  repository.PatientRepository
+	TemplateRepo repository.SurveyTemplateRepository
+	IndexRepo    repository.MedicalIndexRepository
+	TherapyRepo  repository.TherapyLogRepository
+	InsightRepo  repository.ClinicalInsightRepository
+	LLM          external.LLMProvider
+	Jobs         *AIJobService
+	Prompts      *PromptService
+	Redactor     *PHIRedactor
+	Usage        *LLMUsageService
+}
+
+func NewClinicalInsightService(d ClinicalInsightDeps) *ClinicalInsightService {
+	return &ClinicalInsightService{
+		patientRepo:  d.PatientRepo,
+		templateRepo: d.TemplateRepo,
+		indexRepo:    d.IndexRepo,
+		therapyRepo:  d.TherapyRepo,
+		insightRepo:  d.InsightRepo,
+		llm:          d.LLM,
+		jobs:         d.Jobs,
+		prompts:      d.Prompts,
+		redactor:     d.Redactor,
+		usage:        d.Usage,
+	}
+}
+
+// ClinicalInsightResult is a stored insight plus the job that is still generating its text.
+type ClinicalInsightResult struct {
+	*entity.ClinicalInsight
+	AIJobID *uuid.UUID `json:"ai_job_id,omitempty"`
+}
+
+type clinicalInsightPayload struct {
+	// Prompt version recorded on the insight; the job generates with exactly this version.
+	PromptTemplateID *uuid.UUID `json:"prompt_template_id,omitempty"`
+}
+
+// Create stores a rule-based insight of the given kind for a patient (patients.id or users.id) and
+// generates the clinician text, in a background job when the queue is available. Only the attending
+// doctor of the patient may request one.
+func (s *ClinicalInsightService) Create(ctx context.Context, doctorID uuid.UUID, patientID uuid.UUID, kind string) (*ClinicalInsightResult, error) {
+	patient, err := s.attendedPatient(ctx, doctorID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	if s.llm != nil {
+		if err := s.usage.CheckQuota(ctx, doctorID); err != nil {
+			return nil, err
+		}
+	}
+
+	cc, err := s.clinicalContext(ctx, patient)
+	if err != nil {
+		return nil, err
+	}
+	v := validator.New()
+	if len(cc.Indices) == 0 {
+		v.AddError("indices", "patient has no recorded indices")
+	} else if kind == entity.ClinicalInsightTherapy && latestActivityIndex(cc) == nil {
+		v.AddError("indices", "patient has no index of an active survey template")
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	item := &entity.ClinicalInsight{
+		ID:          uuid.New(),
+		PatientID:   patient.ID,
+		RequestedBy: &doctorID,
+		Kind:        kind,
+		Text:        fallbackClinicalText(kind, cc),
+		Context:     cc,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	var prompt *entity.PromptTemplate
+	if s.llm != nil {
+		ctx = withLLMUsageScope(ctx, &doctorID, "", clinicalPromptKey(kind))
+		prompt = s.prompts.Resolve(ctx, clinicalPromptKey(kind), "")
+	}
+	if s.llm != nil && s.jobs != nil {
+		// Generated by a background job; the rule-based text is shown until it is ready.
+		item.AIStatus = entity.AIStatusPending
+		setInsightPrompt(item, prompt)
+	} else if s.llm != nil {
+		text, interactions, err := s.generate(ctx, item, prompt)
+		if err != nil {
+			log.Printf("[ClinicalInsight] LLM error (using rule-based text): %v", err)
+		} else {
+			item.Text, item.InteractionText = text, interactions
+			setInsightPrompt(item, prompt)
+		}
+	}
+
+	if err := s.insightRepo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	result := &ClinicalInsightResult{ClinicalInsight: item}
+
+	if item.AIStatus == entity.AIStatusPending {
+		job := &entity.AIJob{Kind: entity.AIJobClinicalInsight, UserID: &doctorID, ClinicalInsightID: &item.ID}
+		if err := s.jobs.Enqueue(ctx, job, clinicalInsightPayload{PromptTemplateID: item.PromptTemplateID}); err != nil {
+			log.Printf("[ClinicalInsight] could not queue generation for insight %s: %v", item.ID, err)
+			if err := s.insightRepo.UpdateText(ctx, item.ID, "", "", entity.AIStatusFailed); err != nil {
+				log.Printf("[ClinicalInsight] could not mark insight %s failed: %v", item.ID, err)
+			}
+			item.AIStatus = entity.AIStatusFailed
+		} else {
+			result.AIJobID = &job.ID
+		}
+	}
+	return result, nil
+}
+
+// List returns the insights stored for a patient of the doctor, newest first; an empty kind lists
+// every kind.
+func (s *ClinicalInsightService) List(ctx context.Context, doctorID uuid.UUID, patientID uuid.UUID, kind string, limit int, offset int) ([]*entity.ClinicalInsight, error) {
+	patient, err := s.attendedPatient(ctx, doctorID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	return s.insightRepo.ListByPatient(ctx, patient.ID, kind, limit, offset)
+}
+
+// RunAIJob generates the clinician text of a stored insight (AIJobRunner).
+func (s *ClinicalInsightService) RunAIJob(ctx context.Context, job *entity.AIJob) (string, error) {
+	if s.llm == nil {
+		return "", errors.New("llm provider not configured")
+	}
+	if job.ClinicalInsightID == nil {
+		return "", errors.New("clinical_insight_id is required")
+	}
+	var p clinicalInsightPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+
+	item, err := s.insightRepo.GetByID(ctx, *job.ClinicalInsightID)
+	if err != nil {
+		return "", err
+	}
+	if item == nil {
+		return "", fmt.Errorf("%w: %w", ErrClinicalInsightNotFound, ErrAIJobPermanent)
+	}
+
+	key := clinicalPromptKey(item.Kind)
+	ctx = withLLMUsageScope(ctx, job.UserID, "", key)
+	prompt := s.prompts.ResolveByID(ctx, p.PromptTemplateID, key, "")
+	text, interactions, err := s.generate(ctx, item, prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := s.insightRepo.UpdateText(ctx, item.ID, text, interactions, entity.AIStatusDone); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// FailAIJob keeps the rule-based text and marks the insight failed (AIJobRunner).
+func (s *ClinicalInsightService) FailAIJob(ctx context.Context, job *entity.AIJob, cause error) error {
+	if job.ClinicalInsightID == nil {
+		return nil
+	}
+	return s.insightRepo.UpdateText(ctx, *job.ClinicalInsightID, "", "", entity.AIStatusFailed)
+}
+
+func (s *ClinicalInsightService) resolvePatient(ctx context.Context, id uuid.UUID) (*entity.Patient, error) {
+	p, err := s.patientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		if p, err = s.patientRepo.GetByUserID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if p == nil {
+		return nil, ErrPatientNotFound
+	}
+	return p, nil
+}
+
+// attendedPatient resolves a patient and checks that the doctor attends them, as the review queue does.
+func (s *ClinicalInsightService) attendedPatient(ctx context.Context, doctorID uuid.UUID, patientID uuid.UUID) (*entity.Patient, error) {
+	patient, err := s.resolvePatient(ctx, patientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient.AttendingDoctorID == nil || *patient.AttendingDoctorID != doctorID {
+		return nil, ErrNotAttendingDoctor
+	}
+	return patient, nil
+}
+
+// clinicalContext collects the diagnosis, the latest value of every index and the therapy history.
+// Indices of the active survey templates are marked; the latest of them drives therapy insights.
+func (s *ClinicalInsightService) clinicalContext(ctx context.Context, patient *entity.Patient) (*entity.ClinicalContext, error) {
+	indices, err := s.indexRepo.ListLatestByPatient(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	active := map[string]bool{}
+	if s.templateRepo != nil {
+		templates, err := s.templateRepo.ListActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range templates {
+			active[t.Code] = true
+		}
+	}
+	logs, err := s.therapyRepo.ListByPatient(ctx, patient.ID, clinicalTherapyHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := &entity.ClinicalContext{
+		Diagnosis:     strings.TrimSpace(patient.Diagnosis),
+		DiagnosisDate: patient.DiagnosisDate,
+		Indices:       make([]entity.ClinicalIndex, 0, len(indices)),
+		Therapy:       make([]entity.ClinicalTherapy, 0, len(logs)),
+	}
+	for _, idx := range indices {
+		cc.Indices = append(cc.Indices, entity.ClinicalIndex{Type: idx.IndexType, Value: idx.Value, Category: idx.Category, RecordedAt: idx.RecordedAt, ActiveTemplate: active[idx.IndexType]})
+	}
+	for _, l := range logs {
+		cc.Therapy = append(cc.Therapy, entity.ClinicalTherapy{
+			Drug:             l.DrugName,
+			Status:           l.Status,
+			AdministeredAt:   l.AdministeredAt,
+			NextScheduled:    l.NextScheduled,
+			CycleNumber:      l.CycleNumber,
+			AdverseReactions: strings.TrimSpace(l.AdverseReactions),
+		})
+	}
+	return cc, nil
+}
+
+// generate returns the clinician text and, for therapy insights on more than one drug, an interaction review.
+// Free text from the record (diagnosis, adverse reactions) is masked before it leaves for the LLM.
+func (s *ClinicalInsightService) generate(ctx context.Context, item *entity.ClinicalInsight, prompt *entity.PromptTemplate) (string, string, error) {
+	cc := s.redactContext(ctx, item)
+	diagnosis := cc.Diagnosis
+	if diagnosis == "" {
+		diagnosis = "не указан"
+	}
+
+	var text string
+	var err error
+	switch item.Kind {
+	case entity.ClinicalInsightTherapy:
+		activity := latestActivityIndex(cc)
+		if activity == nil {
+			return "", "", fmt.Errorf("no disease activity index: %w", ErrAIJobPermanent)
+		}
+		text, err = external.RecommendTherapy(ctx, s.llm, prompt.SystemPrompt, diagnosis, activity.Type, activity.Value, therapyLines(cc))
+	default:
+		text, err = external.InterpretPatientScores(ctx, s.llm, prompt.SystemPrompt, diagnosis, indexLines(cc), therapyLines(cc))
+	}
+	if err != nil {
+		return "", "", err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", "", errors.New("llm returned empty response")
+	}
+
+	interactions := ""
+	if drugs := currentDrugs(cc); item.Kind == entity.ClinicalInsightTherapy && len(drugs) > 1 {
+		ictx := withLLMUsageScope(ctx, item.RequestedBy, "", entity.PromptDrugInteraction)
+		ip := s.prompts.Resolve(ictx, entity.PromptDrugInteraction, "")
+		// The therapy text is useful on its own; a failed interaction review does not fail the insight.
+		if out, err := external.CheckDrugInteractions(ictx, s.llm, ip.SystemPrompt, drugs); err != nil {
+			log.Printf("[ClinicalInsight] interaction review failed for insight %s: %v", item.ID, err)
+		} else {
+			interactions = strings.TrimSpace(out)
+		}
+	}
+	return text, interactions, nil
+}
+
+// redactContext returns a copy of the insight context with personal identifiers masked in free-text fields.
+func (s *ClinicalInsightService) redactContext(ctx context.Context, item *entity.ClinicalInsight) *entity.ClinicalContext {
+	values := map[string]any{"diagnosis": item.Context.Diagnosis}
+	reactions := make([]any, len(item.Context.Therapy))
+	for i, t := range item.Context.Therapy {
+		reactions[i] = t.AdverseReactions
+	}
+	values["adverse_reactions"] = reactions
+	values = s.redactor.RedactValues(ctx, item.RequestedBy, entity.ResourceInsight, &item.ID, values)
+
+	out := *item.Context
+	out.Diagnosis, _ = values["diagnosis"].(string)
+	out.Therapy = append([]entity.ClinicalTherapy(nil), item.Context.Therapy...)
+	masked, _ := values["adverse_reactions"].([]any)
+	for i := range out.Therapy {
+		if i < len(masked) {
+			out.Therapy[i].AdverseReactions, _ = masked[i].(string)
+		}
+	}
+	return &out
+}
+
+func clinicalPromptKey(kind string) string {
+	if kind == entity.ClinicalInsightTherapy {
+		return entity.PromptTherapyRecommendation
+	}
+	return entity.PromptSurveyInterpretation
+}
+
+func setInsightPrompt(item *entity.ClinicalInsight, prompt *entity.PromptTemplate) {
+	if prompt != nil && prompt.Version > 0 {
+		item.PromptTemplateID = &prompt.ID
+		item.PromptVersion = prompt.Version
+	}
+}
+
+// latestActivityIndex returns the most recent index of an active survey template, or nil.
+func latestActivityIndex(cc *entity.ClinicalContext) *entity.ClinicalIndex {
+	var latest *entity.ClinicalIndex
+	for i := range cc.Indices {
+		idx := &cc.Indices[i]
+		if idx.ActiveTemplate && (latest == nil || idx.RecordedAt.After(latest.RecordedAt)) {
+			latest = idx
+		}
+	}
+	return latest
+}
+
+// currentDrugs returns the distinct drugs of the therapy history that were not cancelled.
+func currentDrugs(cc *entity.ClinicalContext) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range cc.Therapy {
+		if t.Drug == "" || t.Status == entity.TherapyStatusCancelled || seen[t.Drug] {
+			continue
+		}
+		seen[t.Drug] = true
+		out = append(out, t.Drug)
+	}
+	return out
+}
+
+func indexLines(cc *entity.ClinicalContext) []string {
+	out := make([]string, 0, len(cc.Indices))
+	for _, idx := range cc.Indices {
+		line := fmt.Sprintf("- %s: %.2f", idx.Type, idx.Value)
+		if idx.Category != "" {
+			line += " (" + idx.Category + ")"
+		}
+		out = append(out, line+", "+idx.RecordedAt.Format("2006-01-02"))
+	}
+	return out
+}
+
+func therapyLines(cc *entity.ClinicalContext) []string {
+	if len(cc.Therapy) == 0 {
+		return []string{"нет записей"}
+	}
+	out := make([]string, 0, len(cc.Therapy))
+	for _, t := range cc.Therapy {
+		line := t.Drug + " — " + t.Status
+		if t.AdministeredAt != nil {
+			line += ", введён " + t.AdministeredAt.Format("2006-01-02")
+		} else if t.NextScheduled != nil {
+			line += ", запланирован на " + t.NextScheduled.Format("2006-01-02")
+		}
+		if t.CycleNumber > 0 {
+			line += fmt.Sprintf(", цикл %d", t.CycleNumber)
+		}
+		if t.AdverseReactions != "" {
+			line += ", НЯ: " + t.AdverseReactions
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// fallbackClinicalText summarizes the context without an LLM.
+func fallbackClinicalText(kind string, cc *entity.ClinicalContext) string {
+	var b strings.Builder
+	diagnosis := cc.Diagnosis
+	if diagnosis == "" {
+		diagnosis = "не указан"
+	}
+	fmt.Fprintf(&b, "Диагноз: %s\n\nПоследние индексы:\n%s", diagnosis, strings.Join(indexLines(cc), "\n"))
+
+	if kind == entity.ClinicalInsightTherapy {
+		if a := latestActivityIndex(cc); a != nil {
+			fmt.Fprintf(&b, "\n\nТекущий индекс: %s %.2f", a.Type, a.Value)
+			if a.Category != "" {
+				fmt.Fprintf(&b, " (%s)", a.Category)
+			}
+		}
+		fmt.Fprintf(&b, "\n\nТерапия:\n- %s", strings.Join(therapyLines(cc), "\n- "))
+		if drugs := currentDrugs(cc); len(drugs) > 1 {
+			fmt.Fprintf(&b, "\n\nОдновременно назначено несколько препаратов (%s): проверьте взаимодействия.", strings.Join(drugs, ", "))
+		}
+	}
+	return b.String()
+}
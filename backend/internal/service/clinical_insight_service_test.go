@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+// recordingLLM remembers the prompts of every call and answers with a fixed text.
+type recordingLLM struct {
+	system []string
+	user   []string
+}
+
+func (l *recordingLLM) Complete(ctx context.Context, messages []external.Message, temperature float64, maxTokens int) (*external.Completion, error) {
+	return &external.Completion{Text: "ответ"}, nil
+}
+
+func (l *recordingLLM) GenerateText(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	l.system = append(l.system, systemPrompt)
+	l.user = append(l.user, userPrompt)
+	return "ответ", nil
+}
+
+func (l *recordingLLM) Model() string { return "test/recording" }
+
+func testClinicalContext() *entity.ClinicalContext {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 10, 0, 0, 0, time.UTC) }
+	given := day(1)
+	return &entity.ClinicalContext{
+		Diagnosis: "Ревматоидный артрит, пациент Иванов Иван Иванович",
+		Indices: []entity.ClinicalIndex{
+			{Type: "DAS28_CRP", Value: 5.4, Category: "high_activity", RecordedAt: day(20)},
+			{Type: "ASA", Value: 2, Category: "asa_2", RecordedAt: day(2), ActiveTemplate: true},
+			{Type: "RCRI", Value: 2, Category: "class_iii", RecordedAt: day(10), ActiveTemplate: true},
+		},
+		Therapy: []entity.ClinicalTherapy{
+			{Drug: "Адалимумаб", Status: entity.TherapyStatusCompleted, AdministeredAt: &given, CycleNumber: 3},
+			{Drug: "Метотрексат", Status: entity.TherapyStatusScheduled},
+			{Drug: "Адалимумаб", Status: entity.TherapyStatusCompleted},
+			{Drug: "Тоцилизумаб", Status: entity.TherapyStatusCancelled},
+		},
+	}
+}
+
+func TestLatestActivityIndexAndCurrentDrugs(t *testing.T) {
+	cc := testClinicalContext()
+
+	if a := latestActivityIndex(cc); a == nil || a.Type != "RCRI" {
+		t.Fatalf("latestActivityIndex() = %+v, want RCRI", a)
+	}
+	if a := latestActivityIndex(&entity.ClinicalContext{Indices: cc.Indices[:1]}); a != nil {
+		t.Fatalf("latestActivityIndex(removed template only) = %+v, want nil", a)
+	}
+
+	got := currentDrugs(cc)
+	if strings.Join(got, ",") != "Адалимумаб,Метотрексат" {
+		t.Fatalf("currentDrugs() = %v", got)
+	}
+}
+
+func TestClinicalInsightGenerate(t *testing.T) {
+	llm := &recordingLLM{}
+	svc := &ClinicalInsightService{llm: llm}
+	requestedBy := uuid.New()
+
+	item := &entity.ClinicalInsight{ID: uuid.New(), RequestedBy: &requestedBy, Kind: entity.ClinicalInsightTherapy, Context: testClinicalContext()}
+	text, interactions, err := svc.generate(context.Background(), item, svc.prompts.Resolve(context.Background(), entity.PromptTherapyRecommendation, ""))
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if text != "ответ" || interactions != "ответ" {
+		t.Fatalf("generate() = %q, %q", text, interactions)
+	}
+	if len(llm.system) != 2 || llm.system[0] != builtinPrompts[entity.PromptTherapyRecommendation] || llm.system[1] != builtinPrompts[entity.PromptDrugInteraction] {
+		t.Fatalf("system prompts = %q", llm.system)
+	}
+	if !strings.Contains(llm.user[0], "RCRI") || !strings.Contains(llm.user[0], "2.00") {
+		t.Fatalf("therapy prompt lacks the current index: %q", llm.user[0])
+	}
+	if strings.Contains(llm.user[0], "Иванов") || !strings.Contains(llm.user[0], phiNameToken) {
+		t.Fatalf("therapy prompt was not redacted: %q", llm.user[0])
+	}
+	if item.Context.Diagnosis != testClinicalContext().Diagnosis {
+		t.Fatalf("stored context was modified: %q", item.Context.Diagnosis)
+	}
+
+	llm = &recordingLLM{}
+	svc.llm = llm
+	item.Kind = entity.ClinicalInsightInterpretation
+	if _, interactions, err := svc.generate(context.Background(), item, svc.prompts.Resolve(context.Background(), entity.PromptSurveyInterpretation, "")); err != nil || interactions != "" {
+		t.Fatalf("generate(interpretation) = %q, %v", interactions, err)
+	}
+	if len(llm.user) != 1 || !strings.Contains(llm.user[0], "DAS28_CRP") {
+		t.Fatalf("interpretation prompts = %q", llm.user)
+	}
+}
+
+func TestFallbackClinicalText(t *testing.T) {
+	text := fallbackClinicalText(entity.ClinicalInsightTherapy, testClinicalContext())
+	for _, want := range []string{"RCRI 2.00 (class_iii)", "Адалимумаб — completed, введён 2026-09-01, цикл 3", "проверьте взаимодействия"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("fallback text lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(fallbackClinicalText(entity.ClinicalInsightInterpretation, testClinicalContext()), "Терапия") {
+		t.Errorf("interpretation fallback should not list therapy")
+	}
+}
+
+type fakeInsightRepo struct {
+	items []*entity.ClinicalInsight
+}
+
+func (r *fakeInsightRepo) Create(ctx context.Context, item *entity.ClinicalInsight) error {
+	r.items = append(r.items, item)
+	return nil
+}
+func (r *fakeInsightRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.ClinicalInsight, error) {
+	return nil, nil
+}
+func (r *fakeInsightRepo) ListByPatient(ctx context.Context, patientID uuid.UUID, kind string, limit int, offset int) ([]*entity.ClinicalInsight, error) {
+	return r.items, nil
+}
+func (r *fakeInsightRepo) UpdateText(ctx context.Context, id uuid.UUID, text string, interactionText string, aiStatus string) error {
+	return nil
+}
+
+func TestClinicalInsightCreateTherapy(t *testing.T) {
+	doctorID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: uuid.New(), AttendingDoctorID: &doctorID}
+	indices := &fakeIndexRepo{items: []*entity.MedicalIndex{
+		{ID: uuid.New(), PatientID: patient.ID, IndexType: "RCRI", Value: 2, Category: "class_iii", RecordedAt: time.Now().UTC()},
+	}}
+	templates := &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{"RCRI": {ID: uuid.New(), Code: "RCRI", IsActive: true}}}
+	insights := &fakeInsightRepo{}
+	svc := NewClinicalInsightService(ClinicalInsightDeps{
+		PatientRepo:  &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{patient.UserID: patient}},
+		TemplateRepo: templates,
+		IndexRepo:    indices,
+		TherapyRepo:  &fakeTherapyRepo{},
+		InsightRepo:  insights,
+	})
+	ctx := context.Background()
+
+	item, err := svc.Create(ctx, doctorID, patient.ID, entity.ClinicalInsightTherapy)
+	if err != nil {
+		t.Fatalf("Create(therapy) error = %v", err)
+	}
+	if !strings.Contains(item.Text, "Текущий индекс: RCRI 2.00 (class_iii)") || len(insights.items) != 1 {
+		t.Fatalf("Create(therapy) text = %q", item.Text)
+	}
+
+	// Another doctor may neither create nor list the patient's insights.
+	if _, err := svc.Create(ctx, uuid.New(), patient.ID, entity.ClinicalInsightTherapy); !errors.Is(err, ErrNotAttendingDoctor) {
+		t.Fatalf("Create() by another doctor error = %v, want ErrNotAttendingDoctor", err)
+	}
+	if _, err := svc.List(ctx, uuid.New(), patient.UserID, "", 50, 0); !errors.Is(err, ErrNotAttendingDoctor) {
+		t.Fatalf("List() by another doctor error = %v, want ErrNotAttendingDoctor", err)
+	}
+
+	// Without an index of an active template there is nothing to base therapy on.
+	delete(templates.byCode, "RCRI")
+	if _, err := svc.Create(ctx, doctorID, patient.ID, entity.ClinicalInsightTherapy); err == nil {
+		t.Fatal("Create(therapy) without an active template index succeeded")
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+// maxInteractionDrugs bounds one interaction check request.
+const maxInteractionDrugs = 30
+
+var interactionSeverities = []string{
+	entity.InteractionContraindicated, entity.InteractionMajor, entity.InteractionModerate, entity.InteractionMinor,
+}
+
+// CheckInteractions reports the interaction of every pair of the given drugs that a rule matches,
+// most severe first. The same drug listed twice is not an interaction with itself.
+func (s *DrugService) CheckInteractions(ctx context.Context, drugIDs []uuid.UUID) ([]entity.DrugInteraction, error) {
+	ids := uniqueIDs(drugIDs)
+	v := validator.New()
+	if len(ids) == 0 {
+		v.AddError("drug_ids", "drug_ids are required")
+	}
+	if len(ids) > maxInteractionDrugs {
+		v.AddError("drug_ids", fmt.Sprintf("at most %d drugs per check", maxInteractionDrugs))
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	drugs, err := s.loadDrugs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return s.matchInteractions(ctx, drugs, uuid.Nil)
+}
+
+// InteractionsWith reports the interactions of one drug with each of others, e.g. a newly logged
+// therapy against the patient's current ones. Drugs no longer in the catalogue are skipped.
+func (s *DrugService) InteractionsWith(ctx context.Context, drugID uuid.UUID, others []uuid.UUID) ([]entity.DrugInteraction, error) {
+	ids := uniqueIDs(append([]uuid.UUID{drugID}, others...))
+	if len(ids) < 2 || s.interactionRepo == nil {
+		return nil, nil
+	}
+	drugs, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return s.matchInteractions(ctx, drugs, drugID)
+}
+
+// loadDrugs fetches all ids and fails validation naming any that are unknown.
+func (s *DrugService) loadDrugs(ctx context.Context, ids []uuid.UUID) ([]*entity.Drug, error) {
+	drugs, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uuid.UUID]bool, len(drugs))
+	for _, d := range drugs {
+		found[d.ID] = true
+	}
+	v := validator.New()
+	for _, id := range ids {
+		if !found[id] {
+			v.AddError("drug_ids", "unknown drug "+id.String())
+		}
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+	return drugs, nil
+}
+
+// matchInteractions checks every pair of drugs against the active rules; with a non-nil focus only
+// pairs including that drug are checked, and it is reported as drug_a. When several rules match a
+// pair, only the most specific one is reported (see interactionRuleSpecificity).
+func (s *DrugService) matchInteractions(ctx context.Context, drugs []*entity.Drug, focus uuid.UUID) ([]entity.DrugInteraction, error) {
+	out := make([]entity.DrugInteraction, 0)
+	if s.interactionRepo == nil {
+		return out, nil
+	}
+	rules, err := s.interactionRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(drugs, func(a, b *entity.Drug) int {
+		switch {
+		case a.ID == focus:
+			return -1
+		case b.ID == focus:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for i, a := range drugs {
+		for _, b := range drugs[i+1:] {
+			if focus != uuid.Nil && a.ID != focus {
+				continue
+			}
+			var rule *entity.DrugInteractionRule
+			for _, r := range rules {
+				if interactionRuleMatches(r, a, b) && moreSpecificInteractionRule(r, rule) {
+					rule = r
+				}
+			}
+			if rule != nil {
+				out = append(out, entity.DrugInteraction{
+					RuleID:     rule.ID,
+					Severity:   rule.Severity,
+					DrugA:      entity.InteractionDrug{ID: a.ID, Name: a.Name, ATCCode: a.ATCCode},
+					DrugB:      entity.InteractionDrug{ID: b.ID, Name: b.Name, ATCCode: b.ATCCode},
+					Mechanism:  rule.Mechanism,
+					Management: rule.Management,
+				})
+			}
+		}
+	}
+	// Stable, so pairs keep their name order within a severity.
+	slices.SortStableFunc(out, func(x, y entity.DrugInteraction) int {
+		return entity.InteractionSeverityRank(y.Severity) - entity.InteractionSeverityRank(x.Severity)
+	})
+	return out, nil
+}
+
+// interactionRuleSpecificity ranks how narrowly a rule names its drugs: a catalogue drug outranks any
+// ATC class, and a longer ATC prefix outranks a shorter one.
+func interactionRuleSpecificity(rule *entity.DrugInteractionRule) int {
+	side := func(drugID *uuid.UUID, atcCode string) int {
+		if drugID != nil {
+			return 100
+		}
+		return len(atcCode)
+	}
+	return side(rule.DrugAID, rule.ATCCodeA) + side(rule.DrugBID, rule.ATCCodeB)
+}
+
+// moreSpecificInteractionRule reports whether rule should replace current for a drug pair; equally
+// specific rules are decided by severity.
+func moreSpecificInteractionRule(rule, current *entity.DrugInteractionRule) bool {
+	if current == nil {
+		return true
+	}
+	if a, b := interactionRuleSpecificity(rule), interactionRuleSpecificity(current); a != b {
+		return a > b
+	}
+	return entity.InteractionSeverityRank(rule.Severity) > entity.InteractionSeverityRank(current.Severity)
+}
+
+func interactionRuleMatches(rule *entity.DrugInteractionRule, a, b *entity.Drug) bool {
+	sideA := func(d *entity.Drug) bool { return interactionSideMatches(rule.DrugAID, rule.ATCCodeA, d) }
+	sideB := func(d *entity.Drug) bool { return interactionSideMatches(rule.DrugBID, rule.ATCCodeB, d) }
+	return (sideA(a) && sideB(b)) || (sideA(b) && sideB(a))
+}
+
+func interactionSideMatches(drugID *uuid.UUID, atcCode string, d *entity.Drug) bool {
+	if drugID != nil {
+		return *drugID == d.ID
+	}
+	return atcCode != "" && strings.HasPrefix(d.ATCCode, atcCode)
+}
+
+func (s *DrugService) ListInteractionRules(ctx context.Context) ([]*entity.DrugInteractionRule, error) {
+	if s.interactionRepo == nil {
+		return []*entity.DrugInteractionRule{}, nil
+	}
+	return s.interactionRepo.ListActive(ctx)
+}
+
+// CreateInteractionRule adds a rule. Each side is exactly one of a catalogue drug or an ATC class.
+func (s *DrugService) CreateInteractionRule(ctx context.Context, userID uuid.UUID, req entity.DrugInteractionRuleCreate) (*entity.DrugInteractionRule, error) {
+	req.ATCCodeA = strings.ToUpper(strings.TrimSpace(req.ATCCodeA))
+	req.ATCCodeB = strings.ToUpper(strings.TrimSpace(req.ATCCodeB))
+	req.Severity = strings.TrimSpace(req.Severity)
+	req.Mechanism = strings.TrimSpace(req.Mechanism)
+	req.Management = strings.TrimSpace(req.Management)
+
+	if s.interactionRepo == nil {
+		return nil, errors.New("drug interaction rules are not configured")
+	}
+
+	v := validator.New()
+	for _, side := range []struct {
+		field  string
+		drugID *uuid.UUID
+		atc    string
+	}{{"a", req.DrugAID, req.ATCCodeA}, {"b", req.DrugBID, req.ATCCodeB}} {
+		switch {
+		case (side.drugID == nil) == (side.atc == ""):
+			v.AddError("drug_"+side.field+"_id", "give either drug_"+side.field+"_id or atc_code_"+side.field)
+		case side.atc != "" && !atcPrefixPattern.MatchString(side.atc):
+			v.AddError("atc_code_"+side.field, "atc_code_"+side.field+" must be an ATC code or prefix such as L04AB")
+		}
+	}
+	v.OneOf("severity", req.Severity, interactionSeverities, "severity must be one of: "+strings.Join(interactionSeverities, ", "))
+	v.Required("mechanism", req.Mechanism, "mechanism is required")
+	v.Required("management", req.Management, "management is required")
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	var ids []uuid.UUID
+	for _, id := range []*uuid.UUID{req.DrugAID, req.DrugBID} {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	if _, err := s.loadDrugs(ctx, uniqueIDs(ids)); err != nil {
+		return nil, err
+	}
+
+	rule := &entity.DrugInteractionRule{
+		ID:         uuid.New(),
+		DrugAID:    req.DrugAID,
+		ATCCodeA:   req.ATCCodeA,
+		DrugBID:    req.DrugBID,
+		ATCCodeB:   req.ATCCodeB,
+		Severity:   req.Severity,
+		Mechanism:  req.Mechanism,
+		Management: req.Management,
+		IsActive:   true,
+		CreatedBy:  &userID,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.interactionRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id != uuid.Nil && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
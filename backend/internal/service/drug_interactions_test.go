@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeInteractionRepo struct {
+	rules []*entity.DrugInteractionRule
+}
+
+func (r *fakeInteractionRepo) Create(ctx context.Context, rule *entity.DrugInteractionRule) error {
+	r.rules = append(r.rules, rule)
+	return nil
+}
+func (r *fakeInteractionRepo) ListActive(ctx context.Context) ([]*entity.DrugInteractionRule, error) {
+	return r.rules, nil
+}
+
+// Catalogue drugs shared by the interaction and therapy tests.
+var (
+	testAdalimumab   = &entity.Drug{ID: uuid.New(), Name: "Адалимумаб", ATCCode: "L04AB04", IsActive: true}
+	testInfliximab   = &entity.Drug{ID: uuid.New(), Name: "Инфликсимаб", ATCCode: "L04AB02", IsActive: true}
+	testTocilizumab  = &entity.Drug{ID: uuid.New(), Name: "Тоцилизумаб", ATCCode: "L04AC07", IsActive: true}
+	testMethotrexate = &entity.Drug{ID: uuid.New(), Name: "Метотрексат", ATCCode: "L04AX03", IsActive: true}
+	testIbuprofen    = &entity.Drug{ID: uuid.New(), Name: "Ибупрофен", ATCCode: "M01AE01", IsActive: true}
+)
+
+// newInteractionTestService seeds a subset of migration 022: biologic pairs and methotrexate with NSAIDs.
+func newInteractionTestService() (*DrugService, *fakeInteractionRepo) {
+	rules := &fakeInteractionRepo{}
+	for _, pair := range [][2]string{{"L04AB", "L04AB"}, {"L04AB", "L04AC"}, {"L04AC", "L04AC"}} {
+		rules.rules = append(rules.rules, &entity.DrugInteractionRule{ID: uuid.New(), ATCCodeA: pair[0], ATCCodeB: pair[1], Severity: entity.InteractionMajor, Mechanism: "ГИБП + ГИБП"})
+	}
+	rules.rules = append(rules.rules, &entity.DrugInteractionRule{ID: uuid.New(), ATCCodeA: "L04AX03", ATCCodeB: "M01A", Severity: entity.InteractionModerate, Mechanism: "МТ + НПВП"})
+	repo := newFakeDrugRepo(testAdalimumab, testInfliximab, testTocilizumab, testMethotrexate, testIbuprofen)
+	return NewDrugService(DrugDeps{Repo: repo, InteractionRepo: rules}), rules
+}
+
+func TestCheckInteractions(t *testing.T) {
+	svc, _ := newInteractionTestService()
+	ctx := context.Background()
+
+	got, err := svc.CheckInteractions(ctx, []uuid.UUID{testIbuprofen.ID, testTocilizumab.ID, testMethotrexate.ID, testAdalimumab.ID})
+	if err != nil {
+		t.Fatalf("CheckInteractions() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Severity != entity.InteractionMajor || got[1].Severity != entity.InteractionModerate {
+		t.Fatalf("interactions = %+v", got)
+	}
+	if got[0].DrugA.ID != testAdalimumab.ID || got[0].DrugB.ID != testTocilizumab.ID {
+		t.Fatalf("major interaction pair = %s + %s", got[0].DrugA.Name, got[0].DrugB.Name)
+	}
+	// The rule is written methotrexate -> NSAID; it matches the pair in either order.
+	if got[1].DrugA.ID != testIbuprofen.ID || got[1].DrugB.ID != testMethotrexate.ID {
+		t.Fatalf("moderate interaction pair = %s + %s", got[1].DrugA.Name, got[1].DrugB.Name)
+	}
+
+	if got, _ := svc.CheckInteractions(ctx, []uuid.UUID{testAdalimumab.ID, testAdalimumab.ID}); len(got) != 0 {
+		t.Fatalf("a drug interacts with itself: %+v", got)
+	}
+
+	var ve validator.ValidationErrors
+	if _, err := svc.CheckInteractions(ctx, []uuid.UUID{testAdalimumab.ID, uuid.New()}); !errors.As(err, &ve) {
+		t.Fatalf("unknown drug: error = %v, want validation error", err)
+	}
+}
+
+func TestCheckInteractionsPrefersSpecificRule(t *testing.T) {
+	svc, rules := newInteractionTestService()
+	ctx := context.Background()
+	drugRule := &entity.DrugInteractionRule{ID: uuid.New(), DrugAID: &testTocilizumab.ID, DrugBID: &testAdalimumab.ID, Severity: entity.InteractionContraindicated}
+	narrowClass := &entity.DrugInteractionRule{ID: uuid.New(), ATCCodeA: "M01AE", ATCCodeB: "L04AX03", Severity: entity.InteractionMinor}
+	rules.rules = append(rules.rules, drugRule, narrowClass)
+
+	got, err := svc.CheckInteractions(ctx, []uuid.UUID{testIbuprofen.ID, testTocilizumab.ID, testMethotrexate.ID, testAdalimumab.ID})
+	if err != nil {
+		t.Fatalf("CheckInteractions() error = %v", err)
+	}
+	if len(got) != 2 || got[0].RuleID != drugRule.ID || got[1].RuleID != narrowClass.ID {
+		t.Fatalf("interactions = %+v, want one per pair from the drug rule and the longer ATC prefix", got)
+	}
+
+	// Without rules configured there is nothing to report, and rules cannot be added.
+	bare := NewDrugService(DrugDeps{Repo: newFakeDrugRepo(testAdalimumab, testTocilizumab)})
+	if got, err := bare.CheckInteractions(ctx, []uuid.UUID{testAdalimumab.ID, testTocilizumab.ID}); err != nil || len(got) != 0 {
+		t.Fatalf("CheckInteractions(no rules) = %+v, %v", got, err)
+	}
+	if got, err := bare.ListInteractionRules(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("ListInteractionRules(no rules) = %+v, %v", got, err)
+	}
+	req := entity.DrugInteractionRuleCreate{ATCCodeA: "L04AB", ATCCodeB: "L04AC", Severity: entity.InteractionMajor, Mechanism: "ГИБП", Management: "Не сочетать"}
+	if _, err := bare.CreateInteractionRule(ctx, uuid.New(), req); err == nil {
+		t.Fatal("CreateInteractionRule(no rules repo) succeeded")
+	}
+}
+
+func TestCreateInteractionRuleValidatesSides(t *testing.T) {
+	svc, rules := newInteractionTestService()
+	ctx := context.Background()
+	before := len(rules.rules)
+
+	_, err := svc.CreateInteractionRule(ctx, uuid.New(), entity.DrugInteractionRuleCreate{
+		DrugAID: &testAdalimumab.ID, ATCCodeA: "L04AB", ATCCodeB: "L04", Severity: "fatal",
+	})
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) || len(ve) != 4 {
+		t.Fatalf("error = %v, want errors for side a, severity, mechanism and management", err)
+	}
+
+	rule, err := svc.CreateInteractionRule(ctx, uuid.New(), entity.DrugInteractionRuleCreate{
+		DrugAID: &testMethotrexate.ID, ATCCodeB: "p01ba", Severity: entity.InteractionMinor, Mechanism: "m", Management: "k",
+	})
+	if err != nil || rule.ATCCodeB != "P01BA" || len(rules.rules) != before+1 {
+		t.Fatalf("CreateInteractionRule() = %+v, %v", rule, err)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+// Bounds of a perioperative rule: the longest regimen is rituximab every six months.
+const (
+	maxPerioperativeIntervalDays = 366
+	maxPerioperativeHoldInterval = 12
+	maxPerioperativeResumeDays   = 180
+)
+
+func (s *DrugService) ListPerioperativeRules(ctx context.Context) ([]*entity.DrugPerioperativeRule, error) {
+	if s.periopRepo == nil {
+		return []*entity.DrugPerioperativeRule{}, nil
+	}
+	return s.periopRepo.List(ctx)
+}
+
+// SetPerioperativeRule creates the rule of a catalogue drug or ATC class, replacing an existing one.
+// It returns the replaced rule (nil when the rule is new) and the stored one.
+func (s *DrugService) SetPerioperativeRule(ctx context.Context, userID uuid.UUID, req entity.DrugPerioperativeRuleSet) (old, rule *entity.DrugPerioperativeRule, err error) {
+	if s.periopRepo == nil {
+		return nil, nil, errors.New("perioperative rules are not configured")
+	}
+	req.ATCCode = strings.ToUpper(strings.TrimSpace(req.ATCCode))
+	req.Guidance = strings.TrimSpace(req.Guidance)
+
+	v := validator.New()
+	switch {
+	case (req.DrugID == nil) == (req.ATCCode == ""):
+		v.AddError("drug_id", "give either drug_id or atc_code")
+	case req.ATCCode != "" && !atcPrefixPattern.MatchString(req.ATCCode):
+		v.AddError("atc_code", "atc_code must be an ATC code or prefix such as L04AB")
+	}
+	if req.DosingIntervalDays < 1 || req.DosingIntervalDays > maxPerioperativeIntervalDays {
+		v.AddError("dosing_interval_days", "dosing_interval_days must be between 1 and 366")
+	}
+	if req.HoldIntervals < 0 || req.HoldIntervals > maxPerioperativeHoldInterval {
+		v.AddError("hold_intervals", "hold_intervals must be between 0 and 12")
+	}
+	if req.ResumeAfterDays < 0 || req.ResumeAfterDays > maxPerioperativeResumeDays {
+		v.AddError("resume_after_days", "resume_after_days must be between 0 and 180")
+	}
+	v.Required("guidance", req.Guidance, "guidance is required")
+	if v.HasErrors() {
+		return nil, nil, v.Errors()
+	}
+
+	if req.DrugID != nil {
+		drug, err := s.repo.GetByID(ctx, *req.DrugID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if drug == nil {
+			v.AddError("drug_id", "unknown drug "+req.DrugID.String())
+			return nil, nil, v.Errors()
+		}
+	}
+
+	rule = &entity.DrugPerioperativeRule{
+		ID:                 uuid.New(),
+		DrugID:             req.DrugID,
+		ATCCode:            req.ATCCode,
+		DosingIntervalDays: req.DosingIntervalDays,
+		HoldIntervals:      req.HoldIntervals,
+		ResumeAfterDays:    req.ResumeAfterDays,
+		Guidance:           req.Guidance,
+		CreatedBy:          &userID,
+		CreatedAt:          time.Now().UTC(),
+	}
+	old, err = s.periopRepo.Upsert(ctx, rule)
+	if err != nil {
+		return nil, nil, err
+	}
+	return old, rule, nil
+}
+
+// perioperativeRules loads the given drugs and the rule applying to each of them; drugs without a
+// rule are absent from the map. Drugs no longer in the catalogue are skipped.
+func (s *DrugService) perioperativeRules(ctx context.Context, ids []uuid.UUID) ([]*entity.Drug, map[uuid.UUID]*entity.DrugPerioperativeRule, error) {
+	drugs, err := s.repo.GetByIDs(ctx, uniqueIDs(ids))
+	if err != nil {
+		return nil, nil, err
+	}
+	matched := make(map[uuid.UUID]*entity.DrugPerioperativeRule, len(drugs))
+	if s.periopRepo == nil || len(drugs) == 0 {
+		return drugs, matched, nil
+	}
+	rules, err := s.periopRepo.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range drugs {
+		if rule := matchPerioperativeRule(rules, d); rule != nil {
+			matched[d.ID] = rule
+		}
+	}
+	return drugs, matched, nil
+}
+
+// matchPerioperativeRule picks the most specific rule for a drug: its own rule, else the rule of the
+// longest ATC class it belongs to.
+func matchPerioperativeRule(rules []*entity.DrugPerioperativeRule, d *entity.Drug) *entity.DrugPerioperativeRule {
+	var best *entity.DrugPerioperativeRule
+	for _, rule := range rules {
+		switch {
+		case rule.DrugID != nil:
+			if *rule.DrugID == d.ID {
+				return rule
+			}
+		case rule.ATCCode != "" && strings.HasPrefix(d.ATCCode, rule.ATCCode):
+			if best == nil || len(rule.ATCCode) > len(best.ATCCode) {
+				best = rule
+			}
+		}
+	}
+	return best
+}
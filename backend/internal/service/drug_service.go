@@ -2,39 +2,364 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/medical-app/backend/internal/entity"
 	"github.com/medical-app/backend/internal/external"
 	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/validator"
 )
 
+var (
+	ErrDrugNotFound     = errors.New("drug not found")
+	ErrDrugNameTaken    = errors.New("a drug with this name already exists")
+	ErrATCClassNotFound = errors.New("ATC class not found")
+)
+
+const (
+	// maxDrugBulkItems bounds one bulk upsert request.
+	maxDrugBulkItems = 500
+
+	defaultDrugsPerPage = 50
+	maxDrugsPerPage     = 100
+)
+
+// atcCodePattern matches a full (level 5, chemical substance) ATC code such as L04AB04. The first
+// letter must be one of the 14 anatomical main groups.
+var atcCodePattern = regexp.MustCompile(`^[ABCDGHJLMNPRSV][0-9]{2}[A-Z]{2}[0-9]{2}$`)
+
+// atcPrefixPattern matches a code of any ATC level (L, L04, L04A, L04AB, L04AB04).
+var atcPrefixPattern = regexp.MustCompile(`^[ABCDGHJLMNPRSV]([0-9]{2}([A-Z]([A-Z]([0-9]{2})?)?)?)?$`)
+
+// Outcomes of one bulk upsert item.
+const (
+	DrugBulkCreated   = "created"
+	DrugBulkUpdated   = "updated"
+	DrugBulkUnchanged = "unchanged"
+)
+
+// DrugBulkResult reports what happened to one item of a bulk upsert; Old is the entry before an update.
+type DrugBulkResult struct {
+	Index  int          `json:"index"`
+	Action string       `json:"action"`
+	Drug   *entity.Drug `json:"drug"`
+	Old    *entity.Drug `json:"-"`
+}
+
 type DrugService struct {
-	repo       repository.DrugRepository
-	ncbiClient *external.NCBIClient
+	repo            repository.DrugRepository
+	atcRepo         repository.ATCRepository
+	interactionRepo repository.DrugInteractionRepository
+	periopRepo      repository.DrugPerioperativeRepository
+	ncbiClient      *external.NCBIClient
 }
 
 type DrugDeps struct {
-	Repo       repository.DrugRepository
-	NCBIClient *external.NCBIClient
+	Repo            repository.DrugRepository
+	ATCRepo         repository.ATCRepository
+	InteractionRepo repository.DrugInteractionRepository
+	PeriopRepo      repository.DrugPerioperativeRepository
+	NCBIClient      *external.NCBIClient
 }
 
 func NewDrugService(d DrugDeps) *DrugService {
 	return &DrugService{
-		repo:       d.Repo,
-		ncbiClient: d.NCBIClient,
+		repo:            d.Repo,
+		atcRepo:         d.ATCRepo,
+		interactionRepo: d.InteractionRepo,
+		periopRepo:      d.PeriopRepo,
+		ncbiClient:      d.NCBIClient,
 	}
 }
 
-func (s *DrugService) List(ctx context.Context, search string, limit int) ([]*entity.Drug, error) {
-	return s.repo.List(ctx, search, limit)
+// DrugPage is one page of search results; Page and PerPage are the values actually applied.
+type DrugPage struct {
+	Items   []*entity.Drug
+	Total   int64
+	Page    int
+	PerPage int
+}
+
+// Search returns one page of drugs ranked by relevance to filter.Search (see DrugRepository.Search),
+// optionally restricted to an ATC group and to active or inactive entries, with the total match count.
+func (s *DrugService) Search(ctx context.Context, filter entity.DrugFilter) (*DrugPage, error) {
+	filter.Search = strings.TrimSpace(filter.Search)
+	filter.ATCCode = strings.ToUpper(strings.TrimSpace(filter.ATCCode))
+
+	v := validator.New()
+	v.MaxLength("search", filter.Search, 100, "search is too long")
+	if filter.ATCCode != "" && !atcPrefixPattern.MatchString(filter.ATCCode) {
+		v.AddError("atc_code", "atc_code must be an ATC code or prefix such as L04AB")
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 {
+		filter.PerPage = defaultDrugsPerPage
+	}
+	filter.PerPage = min(filter.PerPage, maxDrugsPerPage)
+
+	items, total, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &DrugPage{Items: items, Total: total, Page: filter.Page, PerPage: filter.PerPage}, nil
+}
+
+// ATCChildren returns the direct subclasses of an ATC class, or the anatomical main groups when
+// parentCode is empty.
+func (s *DrugService) ATCChildren(ctx context.Context, parentCode string) ([]*entity.ATCClass, error) {
+	parentCode = strings.ToUpper(strings.TrimSpace(parentCode))
+	if parentCode != "" {
+		if _, err := s.ATCClass(ctx, parentCode); err != nil {
+			return nil, err
+		}
+	}
+	return s.atcRepo.ListChildren(ctx, parentCode)
+}
+
+// ATCClass returns a class with its ancestors and direct subclasses.
+func (s *DrugService) ATCClass(ctx context.Context, code string) (*entity.ATCClassDetail, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !atcPrefixPattern.MatchString(code) {
+		return nil, ErrATCClassNotFound
+	}
+
+	classes, err := s.atcRepo.GetByCodes(ctx, append(entity.ATCAncestors(code), code))
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 || classes[len(classes)-1].Code != code {
+		return nil, ErrATCClassNotFound
+	}
+	children, err := s.atcRepo.ListChildren(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ATCClassDetail{
+		ATCClass: *classes[len(classes)-1],
+		Path:     classes[:len(classes)-1],
+		Children: children,
+	}, nil
+}
+
+// ATCDrugs pages through the catalogue drugs in an ATC class and all its subclasses.
+func (s *DrugService) ATCDrugs(ctx context.Context, code string, filter entity.DrugFilter) (*entity.ATCClassDetail, *DrugPage, error) {
+	class, err := s.ATCClass(ctx, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.ATCCode = class.Code
+	page, err := s.Search(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return class, page, nil
 }
 
 func (s *DrugService) Get(ctx context.Context, id uuid.UUID) (*entity.Drug, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// Create adds a catalogue entry. The ATC code, when given, is stored upper-cased.
+func (s *DrugService) Create(ctx context.Context, req entity.DrugCreate) (*entity.Drug, error) {
+	req = normalizeDrugCreate(req)
+	v := validator.New()
+	validateDrugCreate(v, "", req)
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	now := time.Now().UTC()
+	d := &entity.Drug{ID: uuid.New(), IsActive: true, CreatedAt: now, UpdatedAt: now}
+	applyDrugCreate(d, req)
+	created, err := s.repo.Create(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrDrugNameTaken
+	}
+	return d, nil
+}
+
+// Update applies the given fields and returns the entry before and after the change.
+func (s *DrugService) Update(ctx context.Context, id uuid.UUID, req entity.DrugUpdate) (old, updated *entity.Drug, err error) {
+	old, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if old == nil {
+		return nil, nil, ErrDrugNotFound
+	}
+
+	d := *old
+	set := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	set(&d.Name, req.Name)
+	set(&d.InternationalName, req.InternationalName)
+	set(&d.TradeName, req.TradeName)
+	set(&d.NCBIPubchemID, req.NCBIPubchemID)
+	set(&d.ATCCode, req.ATCCode)
+	set(&d.DosageForm, req.DosageForm)
+	set(&d.Manufacturer, req.Manufacturer)
+	set(&d.Description, req.Description)
+	set(&d.Contraindications, req.Contraindications)
+	if req.IsActive != nil {
+		d.IsActive = *req.IsActive
+	}
+	d.ATCCode = strings.ToUpper(d.ATCCode)
+
+	check := drugCreateOf(&d)
+	if req.ATCCode == nil {
+		// Entries imported before codes were validated stay editable.
+		check.ATCCode = ""
+	}
+	v := validator.New()
+	validateDrugCreate(v, "", check)
+	if v.HasErrors() {
+		return nil, nil, v.Errors()
+	}
+
+	d.UpdatedAt = time.Now().UTC()
+	saved, err := s.repo.Update(ctx, &d)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !saved {
+		return nil, nil, ErrDrugNameTaken
+	}
+	return old, &d, nil
+}
+
+// Deactivate hides a drug from pickers without deleting it, since therapy logs keep referring to it.
+// It returns the entry before the change; deactivating an inactive drug is a no-op.
+func (s *DrugService) Deactivate(ctx context.Context, id uuid.UUID) (*entity.Drug, error) {
+	active := false
+	old, _, err := s.Update(ctx, id, entity.DrugUpdate{IsActive: &active})
+	return old, err
+}
+
+// BulkUpsert creates or updates drugs matched by name (case-insensitive). Every item is validated
+// before anything is written, so a bad row rejects the whole request, and all rows are written in one
+// transaction, so a failed write leaves the catalogue unchanged. Matched entries are reactivated.
+func (s *DrugService) BulkUpsert(ctx context.Context, items []entity.DrugCreate) ([]*DrugBulkResult, error) {
+	v := validator.New()
+	if len(items) == 0 {
+		v.AddError("items", "items are required")
+	}
+	if len(items) > maxDrugBulkItems {
+		v.AddError("items", fmt.Sprintf("at most %d items per request", maxDrugBulkItems))
+	}
+	seen := make(map[string]int, len(items))
+	for i := range items {
+		items[i] = normalizeDrugCreate(items[i])
+		field := fmt.Sprintf("items[%d].", i)
+		validateDrugCreate(v, field, items[i])
+		key := strings.ToLower(items[i].Name)
+		if j, dup := seen[key]; dup && key != "" {
+			v.AddError(field+"name", fmt.Sprintf("duplicates items[%d]", j))
+		}
+		seen[key] = i
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	drugs := make([]*entity.Drug, 0, len(items))
+	now := time.Now().UTC()
+	for _, req := range items {
+		d := &entity.Drug{ID: uuid.New(), IsActive: true, CreatedAt: now, UpdatedAt: now}
+		applyDrugCreate(d, req)
+		drugs = append(drugs, d)
+	}
+	stored, old, err := s.repo.BulkUpsert(ctx, drugs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*DrugBulkResult, 0, len(items))
+	for i, d := range stored {
+		result := &DrugBulkResult{Index: i, Action: DrugBulkUpdated, Drug: d, Old: old[i]}
+		// A drug created by a concurrent request after the lookup is matched without an old entry.
+		switch {
+		case old[i] == nil && d.ID == drugs[i].ID:
+			result.Action = DrugBulkCreated
+		case old[i] != nil && drugCatalogueEqual(old[i], d):
+			result.Action = DrugBulkUnchanged
+			result.Old = nil
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func normalizeDrugCreate(req entity.DrugCreate) entity.DrugCreate {
+	req.Name = strings.TrimSpace(req.Name)
+	req.InternationalName = strings.TrimSpace(req.InternationalName)
+	req.TradeName = strings.TrimSpace(req.TradeName)
+	req.NCBIPubchemID = strings.TrimSpace(req.NCBIPubchemID)
+	req.ATCCode = strings.ToUpper(strings.TrimSpace(req.ATCCode))
+	req.DosageForm = strings.TrimSpace(req.DosageForm)
+	req.Manufacturer = strings.TrimSpace(req.Manufacturer)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Contraindications = strings.TrimSpace(req.Contraindications)
+	return req
+}
+
+// validateDrugCreate checks a normalized entry against the column limits of the drugs table;
+// prefix names the item in bulk requests.
+func validateDrugCreate(v *validator.Validator, prefix string, req entity.DrugCreate) {
+	v.Required(prefix+"name", req.Name, "name is required")
+	v.MaxLength(prefix+"name", req.Name, 255, "name is too long")
+	v.MaxLength(prefix+"international_name", req.InternationalName, 255, "international_name is too long")
+	v.MaxLength(prefix+"trade_name", req.TradeName, 255, "trade_name is too long")
+	v.MaxLength(prefix+"ncbi_pubchem_id", req.NCBIPubchemID, 50, "ncbi_pubchem_id is too long")
+	v.MaxLength(prefix+"dosage_form", req.DosageForm, 100, "dosage_form is too long")
+	v.MaxLength(prefix+"manufacturer", req.Manufacturer, 255, "manufacturer is too long")
+	if req.ATCCode != "" && !atcCodePattern.MatchString(req.ATCCode) {
+		v.AddError(prefix+"atc_code", "atc_code must be a 7-character ATC code such as L04AB04")
+	}
+}
+
+func applyDrugCreate(d *entity.Drug, req entity.DrugCreate) {
+	d.Name = req.Name
+	d.InternationalName = req.InternationalName
+	d.TradeName = req.TradeName
+	d.NCBIPubchemID = req.NCBIPubchemID
+	d.ATCCode = req.ATCCode
+	d.DosageForm = req.DosageForm
+	d.Manufacturer = req.Manufacturer
+	d.Description = req.Description
+	d.Contraindications = req.Contraindications
+}
+
+func drugCreateOf(d *entity.Drug) entity.DrugCreate {
+	return entity.DrugCreate{
+		Name: d.Name, InternationalName: d.InternationalName, TradeName: d.TradeName,
+		NCBIPubchemID: d.NCBIPubchemID, ATCCode: d.ATCCode, DosageForm: d.DosageForm,
+		Manufacturer: d.Manufacturer, Description: d.Description, Contraindications: d.Contraindications,
+	}
+}
+
+// drugCatalogueEqual compares the editable fields of two entries.
+func drugCatalogueEqual(a, b *entity.Drug) bool {
+	return drugCreateOf(a) == drugCreateOf(b) && a.IsActive == b.IsActive
+}
+
 // SearchPubChem searches for a drug in PubChem using NCBI client.
 func (s *DrugService) SearchPubChem(ctx context.Context, query string) (string, error) {
 	if s.ncbiClient == nil {
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeDrugRepo struct {
+	items   map[uuid.UUID]*entity.Drug
+	created int
+	updated int
+	filter  entity.DrugFilter
+	// failName makes BulkUpsert fail on the drug with this name, rolling back the batch.
+	failName string
+}
+
+func newFakeDrugRepo(items ...*entity.Drug) *fakeDrugRepo {
+	r := &fakeDrugRepo{items: map[uuid.UUID]*entity.Drug{}}
+	for _, d := range items {
+		r.items[d.ID] = d
+	}
+	return r
+}
+
+func (r *fakeDrugRepo) List(ctx context.Context, search string, limit int) ([]*entity.Drug, error) {
+	out := make([]*entity.Drug, 0, len(r.items))
+	for _, d := range r.items {
+		out = append(out, d)
+	}
+	return out, nil
+}
+func (r *fakeDrugRepo) Search(ctx context.Context, filter entity.DrugFilter) ([]*entity.Drug, int64, error) {
+	r.filter = filter
+	out, _ := r.List(ctx, filter.Search, 0)
+	return out, int64(len(out)), nil
+}
+func (r *fakeDrugRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Drug, error) {
+	if d, ok := r.items[id]; ok {
+		cp := *d
+		return &cp, nil
+	}
+	return nil, nil
+}
+func (r *fakeDrugRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Drug, error) {
+	var out []*entity.Drug
+	for _, id := range ids {
+		if d, ok := r.items[id]; ok {
+			cp := *d
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+func (r *fakeDrugRepo) byName(name string) *entity.Drug {
+	for _, d := range r.items {
+		if strings.EqualFold(d.Name, name) {
+			return d
+		}
+	}
+	return nil
+}
+func (r *fakeDrugRepo) Create(ctx context.Context, d *entity.Drug) (bool, error) {
+	if r.byName(d.Name) != nil {
+		return false, nil
+	}
+	r.created++
+	cp := *d
+	r.items[d.ID] = &cp
+	return true, nil
+}
+func (r *fakeDrugRepo) Update(ctx context.Context, d *entity.Drug) (bool, error) {
+	if other := r.byName(d.Name); other != nil && other.ID != d.ID {
+		return false, nil
+	}
+	r.updated++
+	cp := *d
+	r.items[d.ID] = &cp
+	return true, nil
+}
+
+func (r *fakeDrugRepo) BulkUpsert(ctx context.Context, drugs []*entity.Drug) (stored, old []*entity.Drug, err error) {
+	for _, d := range drugs {
+		if r.failName != "" && strings.EqualFold(d.Name, r.failName) {
+			return nil, nil, errors.New("write failed")
+		}
+	}
+	for _, d := range drugs {
+		prev := r.byName(d.Name)
+		next := *d
+		if prev != nil {
+			next.ID, next.CreatedAt = prev.ID, prev.CreatedAt
+			if drugCatalogueEqual(prev, &next) {
+				cp := *prev
+				stored, old = append(stored, &cp), append(old, &cp)
+				continue
+			}
+			cp := *prev
+			prev = &cp
+			_, err = r.Update(ctx, &next)
+		} else {
+			_, err = r.Create(ctx, &next)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		stored, old = append(stored, &next), append(old, prev)
+	}
+	return stored, old, nil
+}
+
+func TestDrugServiceCreateValidatesATC(t *testing.T) {
+	repo := newFakeDrugRepo()
+	svc := NewDrugService(DrugDeps{Repo: repo})
+
+	d, err := svc.Create(context.Background(), entity.DrugCreate{Name: " Адалимумаб ", ATCCode: "l04ab04"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if d.Name != "Адалимумаб" || d.ATCCode != "L04AB04" || !d.IsActive {
+		t.Fatalf("created drug = %+v", d)
+	}
+
+	for _, code := range []string{"L04AB", "X04AB04", "L4AB04", "L04AB04X"} {
+		_, err := svc.Create(context.Background(), entity.DrugCreate{Name: "Тест", ATCCode: code})
+		var ve validator.ValidationErrors
+		if !errors.As(err, &ve) || ve[0].Field != "atc_code" {
+			t.Errorf("Create(atc %q) error = %v, want atc_code validation error", code, err)
+		}
+	}
+	if repo.created != 1 {
+		t.Fatalf("created %d drugs, want 1", repo.created)
+	}
+
+	if _, err := svc.Create(context.Background(), entity.DrugCreate{Name: "адалимумаб"}); !errors.Is(err, ErrDrugNameTaken) {
+		t.Fatalf("Create(duplicate name) error = %v, want ErrDrugNameTaken", err)
+	}
+}
+
+func TestDrugServiceUpdateAndDeactivate(t *testing.T) {
+	id := uuid.New()
+	repo := newFakeDrugRepo(&entity.Drug{ID: id, Name: "Тоцилизумаб", ATCCode: "L04AC07", IsActive: true})
+	svc := NewDrugService(DrugDeps{Repo: repo})
+
+	trade := "Актемра"
+	old, d, err := svc.Update(context.Background(), id, entity.DrugUpdate{TradeName: &trade})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if old.TradeName != "" || d.TradeName != "Актемра" || d.ATCCode != "L04AC07" {
+		t.Fatalf("old = %+v, new = %+v", old, d)
+	}
+
+	bad := "L04"
+	if _, _, err := svc.Update(context.Background(), id, entity.DrugUpdate{ATCCode: &bad}); err == nil {
+		t.Fatalf("Update() accepted atc_code %q", bad)
+	}
+
+	repo.items[uuid.New()] = &entity.Drug{Name: "Сарилумаб", IsActive: true}
+	taken := "САРИЛУМАБ"
+	if _, _, err := svc.Update(context.Background(), id, entity.DrugUpdate{Name: &taken}); !errors.Is(err, ErrDrugNameTaken) {
+		t.Fatalf("Update(taken name) error = %v, want ErrDrugNameTaken", err)
+	}
+
+	old, err = svc.Deactivate(context.Background(), id)
+	if err != nil || !old.IsActive || repo.items[id].IsActive {
+		t.Fatalf("Deactivate() old = %+v, err = %v, stored active = %v", old, err, repo.items[id].IsActive)
+	}
+	if _, err := svc.Deactivate(context.Background(), uuid.New()); !errors.Is(err, ErrDrugNotFound) {
+		t.Fatalf("Deactivate(unknown) error = %v, want ErrDrugNotFound", err)
+	}
+}
+
+func TestDrugServiceBulkUpsert(t *testing.T) {
+	id := uuid.New()
+	unchangedID := uuid.New()
+	repo := newFakeDrugRepo(
+		&entity.Drug{ID: id, Name: "Ритуксимаб", ATCCode: "L01XC02", IsActive: false},
+		&entity.Drug{ID: unchangedID, Name: "Этанерцепт", ATCCode: "L04AB01", IsActive: true},
+	)
+	svc := NewDrugService(DrugDeps{Repo: repo})
+
+	results, err := svc.BulkUpsert(context.Background(), []entity.DrugCreate{
+		{Name: "ритуксимаб", ATCCode: "L01FA01"},
+		{Name: "Этанерцепт", ATCCode: "L04AB01"},
+		{Name: "Анакинра", ATCCode: "L04AC03"},
+	})
+	if err != nil {
+		t.Fatalf("BulkUpsert() error = %v", err)
+	}
+	actions := []string{results[0].Action, results[1].Action, results[2].Action}
+	if actions[0] != DrugBulkUpdated || actions[1] != DrugBulkUnchanged || actions[2] != DrugBulkCreated {
+		t.Fatalf("actions = %v", actions)
+	}
+	if results[0].Old.ATCCode != "L01XC02" || !repo.items[id].IsActive || repo.items[id].ATCCode != "L01FA01" {
+		t.Fatalf("updated entry: old = %+v, stored = %+v", results[0].Old, repo.items[id])
+	}
+
+	// One invalid row rejects the whole batch before anything is written.
+	writes := repo.created + repo.updated
+	_, err = svc.BulkUpsert(context.Background(), []entity.DrugCreate{
+		{Name: "Сарилумаб", ATCCode: "L04AC14"},
+		{Name: "", ATCCode: "bad"},
+		{Name: "сарилумаб"},
+	})
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) || len(ve) != 3 || repo.created+repo.updated != writes {
+		t.Fatalf("BulkUpsert(invalid) error = %v, writes %d -> %d", err, writes, repo.created+repo.updated)
+	}
+	if ve[0].Field != "items[1].name" || ve[2].Field != "items[2].name" {
+		t.Fatalf("validation fields = %+v", ve)
+	}
+}
+
+func TestDrugServiceBulkUpsertWritesNothingOnFailure(t *testing.T) {
+	id := uuid.New()
+	repo := newFakeDrugRepo(&entity.Drug{ID: id, Name: "Ритуксимаб", ATCCode: "L01XC02", IsActive: false})
+	repo.failName = "Анакинра"
+	svc := NewDrugService(DrugDeps{Repo: repo})
+
+	results, err := svc.BulkUpsert(context.Background(), []entity.DrugCreate{
+		{Name: "Сарилумаб", ATCCode: "L04AC14"},
+		{Name: "Ритуксимаб", ATCCode: "L01FA01"},
+		{Name: "Анакинра", ATCCode: "L04AC03"},
+		{Name: "Тоцилизумаб", ATCCode: "L04AC07"},
+	})
+	if err == nil || results != nil {
+		t.Fatalf("BulkUpsert() = %v, %v; want an error and no results", results, err)
+	}
+	if repo.created != 0 || repo.updated != 0 || len(repo.items) != 1 || repo.items[id].ATCCode != "L01XC02" || repo.items[id].IsActive {
+		t.Fatalf("failed batch wrote %d creates, %d updates; stored %+v", repo.created, repo.updated, repo.items[id])
+	}
+}
+
+func TestDrugServiceSearchNormalizesFilter(t *testing.T) {
+	repo := newFakeDrugRepo(&entity.Drug{ID: uuid.New(), Name: "Адалимумаб", ATCCode: "L04AB04"})
+	svc := NewDrugService(DrugDeps{Repo: repo})
+
+	page, err := svc.Search(context.Background(), entity.DrugFilter{Search: " хумира ", ATCCode: "l04ab", PerPage: 500})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if page.Page != 1 || page.PerPage != maxDrugsPerPage || page.Total != 1 {
+		t.Fatalf("page = %+v", page)
+	}
+	if repo.filter.Search != "хумира" || repo.filter.ATCCode != "L04AB" {
+		t.Fatalf("repository filter = %+v", repo.filter)
+	}
+
+	if page, _ := svc.Search(context.Background(), entity.DrugFilter{Page: 3}); page.Page != 3 || page.PerPage != defaultDrugsPerPage {
+		t.Fatalf("default page = %+v", page)
+	}
+
+	for _, prefix := range []string{"L", "L04", "L04A", "L04AB04"} {
+		if _, err := svc.Search(context.Background(), entity.DrugFilter{ATCCode: prefix}); err != nil {
+			t.Errorf("Search(atc %q) error = %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"L0", "L04AB0", "X01", "L04%"} {
+		var ve validator.ValidationErrors
+		if _, err := svc.Search(context.Background(), entity.DrugFilter{ATCCode: prefix}); !errors.As(err, &ve) {
+			t.Errorf("Search(atc %q) error = %v, want validation error", prefix, err)
+		}
+	}
+}
+
+type fakeATCRepo struct {
+	classes []*entity.ATCClass
+}
+
+func (r *fakeATCRepo) ListChildren(ctx context.Context, parentCode string) ([]*entity.ATCClass, error) {
+	out := make([]*entity.ATCClass, 0)
+	for _, c := range r.classes {
+		if c.ParentCode == parentCode {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+func (r *fakeATCRepo) GetByCodes(ctx context.Context, codes []string) ([]*entity.ATCClass, error) {
+	var out []*entity.ATCClass
+	for _, c := range r.classes {
+		if slices.Contains(codes, c.Code) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestDrugServiceATCHierarchy(t *testing.T) {
+	atc := &fakeATCRepo{classes: []*entity.ATCClass{
+		{Code: "L", Level: 1},
+		{Code: "L04", Level: 2, ParentCode: "L"},
+		{Code: "L04A", Level: 3, ParentCode: "L04"},
+		{Code: "L04AB", Level: 4, ParentCode: "L04A"},
+		{Code: "L04AB04", Level: 5, ParentCode: "L04AB"},
+		{Code: "L04AC", Level: 4, ParentCode: "L04A"},
+	}}
+	repo := newFakeDrugRepo()
+	svc := NewDrugService(DrugDeps{Repo: repo, ATCRepo: atc})
+	ctx := context.Background()
+
+	class, err := svc.ATCClass(ctx, "l04ab")
+	if err != nil {
+		t.Fatalf("ATCClass() error = %v", err)
+	}
+	var path []string
+	for _, p := range class.Path {
+		path = append(path, p.Code)
+	}
+	if class.Code != "L04AB" || !slices.Equal(path, []string{"L", "L04", "L04A"}) || len(class.Children) != 1 {
+		t.Fatalf("class = %+v, path = %v", class.ATCClass, path)
+	}
+
+	if roots, _ := svc.ATCChildren(ctx, ""); len(roots) != 1 || roots[0].Code != "L" {
+		t.Fatalf("ATCChildren(root) = %+v", roots)
+	}
+	for _, code := range []string{"L04AD", "L04AB0", "drop table"} {
+		if _, err := svc.ATCChildren(ctx, code); !errors.Is(err, ErrATCClassNotFound) {
+			t.Errorf("ATCChildren(%q) error = %v, want ErrATCClassNotFound", code, err)
+		}
+	}
+
+	if _, _, err := svc.ATCDrugs(ctx, "L04AB", entity.DrugFilter{ATCCode: "A"}); err != nil || repo.filter.ATCCode != "L04AB" {
+		t.Fatalf("ATCDrugs() error = %v, filter = %+v", err, repo.filter)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/internal/repository"
+)
+
+const (
+	literatureMaxArticles = 3
+	literatureQueryTTL    = 30 * 24 * time.Hour
+	// Retrieval must not hold up advice generation for long; whatever is cached by then is used.
+	literatureTimeout    = 10 * time.Second
+	literatureExcerptLen = 400
+)
+
+// literatureQueries are the PubMed search terms per survey code. Codes not listed here
+// are searched by the code itself together with the perioperative context.
+var literatureQueries = map[string]string{
+	"ASA":     `"ASA physical status" AND perioperative risk`,
+	"RCRI":    `"revised cardiac risk index" AND noncardiac surgery`,
+	"GOLDMAN": `Goldman cardiac risk index AND noncardiac surgery`,
+	"CAPRINI": `Caprini score AND venous thromboembolism AND surgery`,
+}
+
+// pubMedSource is the part of the NCBI client used for retrieval.
+type pubMedSource interface {
+	SearchPubMed(ctx context.Context, query string, maxResults int) ([]string, error)
+	EFetchAbstracts(ctx context.Context, pmids []string) ([]external.PubMedAbstract, error)
+}
+
+// LiteratureService retrieves PubMed abstracts relevant to a survey so generated advice can be
+// grounded in published evidence. Search results and abstracts are cached in the database,
+// so repeated requests for the same survey do not reach NCBI.
+type LiteratureService struct {
+	ncbi pubMedSource
+	repo repository.LiteratureRepository
+}
+
+type LiteratureDeps struct {
+	NCBIClient *external.NCBIClient
+	Repo       repository.LiteratureRepository
+}
+
+func NewLiteratureService(d LiteratureDeps) *LiteratureService {
+	s := &LiteratureService{repo: d.Repo}
+	if d.NCBIClient != nil {
+		s.ncbi = d.NCBIClient
+	}
+	return s
+}
+
+// Evidence returns up to literatureMaxArticles abstracts for the survey, most relevant first.
+// Failures are logged and yield fewer (or no) abstracts; advice is then generated without them.
+func (s *LiteratureService) Evidence(ctx context.Context, t *entity.SurveyTemplate) []*entity.PubMedAbstract {
+	if s == nil || s.repo == nil || t == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, literatureTimeout)
+	defer cancel()
+
+	pmids := s.searchPMIDs(ctx, literatureQuery(t.Code))
+	if len(pmids) == 0 {
+		return nil
+	}
+	abstracts, err := s.abstracts(ctx, pmids)
+	if err != nil {
+		log.Printf("[Literature] %s: %v", t.Code, err)
+	}
+	return abstracts
+}
+
+func literatureQuery(surveyCode string) string {
+	if q, ok := literatureQueries[surveyCode]; ok {
+		return q
+	}
+	return strings.ReplaceAll(surveyCode, "_", " ") + " AND perioperative"
+}
+
+// searchPMIDs returns cached search results while fresh; a stale entry is still used when NCBI is unavailable.
+func (s *LiteratureService) searchPMIDs(ctx context.Context, term string) []string {
+	cached, err := s.repo.GetQuery(ctx, term)
+	if err != nil {
+		log.Printf("[Literature] read cached query: %v", err)
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < literatureQueryTTL {
+		return cached.PMIDs
+	}
+	if s.ncbi == nil {
+		if cached != nil {
+			return cached.PMIDs
+		}
+		return nil
+	}
+
+	pmids, err := s.ncbi.SearchPubMed(ctx, term, literatureMaxArticles)
+	if err != nil {
+		log.Printf("[Literature] PubMed search failed: %v", err)
+		if cached != nil {
+			return cached.PMIDs
+		}
+		return nil
+	}
+	if err := s.repo.SaveQuery(ctx, &entity.PubMedQuery{Term: term, PMIDs: pmids, FetchedAt: time.Now().UTC()}); err != nil {
+		log.Printf("[Literature] cache query: %v", err)
+	}
+	return pmids
+}
+
+// abstracts returns abstracts in pmids order, fetching only those missing from the cache.
+// Articles without abstract text are skipped.
+func (s *LiteratureService) abstracts(ctx context.Context, pmids []string) ([]*entity.PubMedAbstract, error) {
+	cached, err := s.repo.GetAbstracts(ctx, pmids)
+	if err != nil {
+		return nil, fmt.Errorf("read cached abstracts: %w", err)
+	}
+	byID := make(map[string]*entity.PubMedAbstract, len(pmids))
+	for _, a := range cached {
+		byID[a.PMID] = a
+	}
+
+	var missing []string
+	for _, id := range pmids {
+		if byID[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 && s.ncbi != nil {
+		fetched, ferr := s.ncbi.EFetchAbstracts(ctx, missing)
+		if ferr != nil {
+			err = fmt.Errorf("fetch abstracts: %w", ferr)
+		}
+		now := time.Now().UTC()
+		items := make([]*entity.PubMedAbstract, 0, len(fetched))
+		for _, f := range fetched {
+			a := &entity.PubMedAbstract{PMID: f.PMID, Title: f.Title, Abstract: f.Abstract, Journal: f.Journal, PubYear: f.PubYear, FetchedAt: now}
+			byID[a.PMID] = a
+			items = append(items, a)
+		}
+		if uerr := s.repo.UpsertAbstracts(ctx, items); uerr != nil {
+			log.Printf("[Literature] cache abstracts: %v", uerr)
+		}
+	}
+
+	out := make([]*entity.PubMedAbstract, 0, len(pmids))
+	for _, id := range pmids {
+		if a := byID[id]; a != nil && strings.TrimSpace(a.Abstract) != "" {
+			out = append(out, a)
+		}
+	}
+	return out, err
+}
+
+// literaturePromptBlock formats abstracts as short excerpts for the user prompt. It returns ""
+// when there is no evidence, so the prompt is unchanged.
+func literaturePromptBlock(abstracts []*entity.PubMedAbstract) string {
+	if len(abstracts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nНаучные источники (PubMed). Опирайся на них, но не выдумывай других источников и не указывай номера PMID в ответе:\n")
+	for _, a := range abstracts {
+		source := a.Journal
+		if a.PubYear != "" {
+			source = strings.TrimSpace(source + ", " + a.PubYear)
+		}
+		fmt.Fprintf(&b, "[PMID %s] %s (%s): %s\n", a.PMID, a.Title, strings.Trim(source, ", "), abstractExcerpt(a.Abstract, literatureExcerptLen))
+	}
+	return b.String()
+}
+
+// abstractExcerpt prefers the conclusions of a structured abstract and shortens the text to maxRunes at a word boundary.
+func abstractExcerpt(abstract string, maxRunes int) string {
+	text := strings.TrimSpace(abstract)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.ToUpper(line), "CONCLUSION") {
+			if i := strings.Index(line, ":"); i >= 0 {
+				text = strings.TrimSpace(line[i+1:])
+			}
+			break
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	cut := string([]rune(text)[:maxRunes])
+	if i := strings.LastIndex(cut, " "); i > maxRunes/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ",;: ") + "…"
+}
+
+func citationPMIDs(abstracts []*entity.PubMedAbstract) []string {
+	if len(abstracts) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(abstracts))
+	for _, a := range abstracts {
+		out = append(out, a.PMID)
+	}
+	return out
+}
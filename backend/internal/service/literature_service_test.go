@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+)
+
+type fakeLiteratureRepo struct {
+	queries   map[string]*entity.PubMedQuery
+	abstracts map[string]*entity.PubMedAbstract
+}
+
+func newFakeLiteratureRepo() *fakeLiteratureRepo {
+	return &fakeLiteratureRepo{queries: map[string]*entity.PubMedQuery{}, abstracts: map[string]*entity.PubMedAbstract{}}
+}
+
+func (r *fakeLiteratureRepo) GetQuery(ctx context.Context, term string) (*entity.PubMedQuery, error) {
+	return r.queries[term], nil
+}
+
+func (r *fakeLiteratureRepo) SaveQuery(ctx context.Context, q *entity.PubMedQuery) error {
+	r.queries[q.Term] = q
+	return nil
+}
+
+func (r *fakeLiteratureRepo) GetAbstracts(ctx context.Context, pmids []string) ([]*entity.PubMedAbstract, error) {
+	var out []*entity.PubMedAbstract
+	for _, id := range pmids {
+		if a, ok := r.abstracts[id]; ok {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeLiteratureRepo) UpsertAbstracts(ctx context.Context, items []*entity.PubMedAbstract) error {
+	for _, a := range items {
+		r.abstracts[a.PMID] = a
+	}
+	return nil
+}
+
+type fakePubMed struct {
+	pmids     []string
+	abstracts map[string]external.PubMedAbstract
+	err       error
+
+	searches int
+	fetched  []string
+}
+
+func (f *fakePubMed) SearchPubMed(ctx context.Context, query string, maxResults int) ([]string, error) {
+	f.searches++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.pmids, nil
+}
+
+func (f *fakePubMed) EFetchAbstracts(ctx context.Context, pmids []string) ([]external.PubMedAbstract, error) {
+	f.fetched = append(f.fetched, pmids...)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []external.PubMedAbstract
+	for _, id := range pmids {
+		if a, ok := f.abstracts[id]; ok {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func TestLiteratureServiceEvidenceCaches(t *testing.T) {
+	repo := newFakeLiteratureRepo()
+	repo.abstracts["1"] = &entity.PubMedAbstract{PMID: "1", Title: "Cached", Abstract: "Cached abstract."}
+	ncbi := &fakePubMed{
+		pmids: []string{"2", "1", "3"},
+		abstracts: map[string]external.PubMedAbstract{
+			"2": {PMID: "2", Title: "Fetched", Abstract: "Fetched abstract."},
+			"3": {PMID: "3", Title: "No abstract"},
+		},
+	}
+	svc := &LiteratureService{ncbi: ncbi, repo: repo}
+	tmpl := &entity.SurveyTemplate{Code: "RCRI"}
+
+	got := svc.Evidence(context.Background(), tmpl)
+	if ids := citationPMIDs(got); strings.Join(ids, ",") != "2,1" {
+		t.Fatalf("Evidence() PMIDs = %v, want [2 1]", ids)
+	}
+	if strings.Join(ncbi.fetched, ",") != "2,3" {
+		t.Fatalf("fetched %v, want only the uncached PMIDs [2 3]", ncbi.fetched)
+	}
+
+	// The second request is served from the cache.
+	got = svc.Evidence(context.Background(), tmpl)
+	if len(got) != 2 || ncbi.searches != 1 || len(ncbi.fetched) != 2 {
+		t.Fatalf("second Evidence() = %d abstracts, %d searches, fetched %v; want cache hit", len(got), ncbi.searches, ncbi.fetched)
+	}
+}
+
+func TestLiteratureServiceStaleQueryWhenNCBIFails(t *testing.T) {
+	repo := newFakeLiteratureRepo()
+	term := literatureQuery("ASA")
+	repo.queries[term] = &entity.PubMedQuery{Term: term, PMIDs: []string{"1"}, FetchedAt: time.Now().Add(-2 * literatureQueryTTL)}
+	repo.abstracts["1"] = &entity.PubMedAbstract{PMID: "1", Title: "Cached", Abstract: "Cached abstract."}
+	ncbi := &fakePubMed{err: errors.New("unavailable")}
+	svc := &LiteratureService{ncbi: ncbi, repo: repo}
+
+	got := svc.Evidence(context.Background(), &entity.SurveyTemplate{Code: "ASA"})
+	if len(got) != 1 || got[0].PMID != "1" || ncbi.searches != 1 {
+		t.Fatalf("Evidence() = %v after %d searches, want the stale cached abstract", citationPMIDs(got), ncbi.searches)
+	}
+
+	var nilSvc *LiteratureService
+	if got := nilSvc.Evidence(context.Background(), &entity.SurveyTemplate{Code: "ASA"}); got != nil {
+		t.Fatalf("nil service Evidence() = %v, want nil", got)
+	}
+}
+
+func TestAbstractExcerpt(t *testing.T) {
+	structured := "BACKGROUND: Long background.\nCONCLUSIONS: Six factors predict risk."
+	if got := abstractExcerpt(structured, 400); got != "Six factors predict risk." {
+		t.Errorf("abstractExcerpt(structured) = %q", got)
+	}
+	long := strings.Repeat("word ", 100)
+	got := abstractExcerpt(long, 42)
+	if !strings.HasSuffix(got, "…") || len([]rune(got)) > 43 || strings.Contains(got, "wor…") {
+		t.Errorf("abstractExcerpt(long) = %q", got)
+	}
+
+	block := literaturePromptBlock([]*entity.PubMedAbstract{{PMID: "10487994", Title: "Cardiac risk index", Journal: "Circulation", PubYear: "1999", Abstract: structured}})
+	if !strings.Contains(block, "[PMID 10487994] Cardiac risk index (Circulation, 1999): Six factors predict risk.") {
+		t.Errorf("literaturePromptBlock() = %q", block)
+	}
+	if literaturePromptBlock(nil) != "" {
+		t.Errorf("literaturePromptBlock(nil) should be empty")
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+const (
+	llmUsageRecordTimeout = 5 * time.Second
+	reportMaxDays         = 366
+)
+
+// QuotaExceededError is returned when a user has used up their daily LLM quota.
+type QuotaExceededError struct {
+	// Limit is "tokens" or "requests".
+	Limit string
+	// RetryAfter is the time left until the quota resets at midnight UTC.
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("daily LLM %s quota exceeded", e.Limit)
+}
+
+// llmUsageScope attributes completions made with a context to a user, survey and purpose.
+type llmUsageScope struct {
+	userID     *uuid.UUID
+	surveyCode string
+	purpose    string
+}
+
+type llmUsageScopeKey struct{}
+
+// withLLMUsageScope marks ctx so completions made with it are recorded against the user and survey.
+func withLLMUsageScope(ctx context.Context, userID *uuid.UUID, surveyCode string, purpose string) context.Context {
+	return context.WithValue(ctx, llmUsageScopeKey{}, llmUsageScope{userID: userID, surveyCode: surveyCode, purpose: purpose})
+}
+
+// LLMUsageService records token consumption of every completion and enforces daily quotas
+// per role, with optional per-user overrides.
+type LLMUsageService struct {
+	repo     repository.LLMUsageRepository
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+	jobRepo  repository.AIJobRepository
+	now      func() time.Time
+}
+
+type LLMUsageDeps struct {
+	Repo     repository.LLMUsageRepository
+	RoleRepo repository.RoleRepository
+	UserRepo repository.UserRepository
+	// JobRepo counts queued generations against the daily request limit; optional.
+	JobRepo repository.AIJobRepository
+}
+
+func NewLLMUsageService(d LLMUsageDeps) *LLMUsageService {
+	return &LLMUsageService{repo: d.Repo, roleRepo: d.RoleRepo, userRepo: d.UserRepo, jobRepo: d.JobRepo, now: time.Now}
+}
+
+// Record stores one completion (external.UsageRecorder). It never fails the caller: the row is
+// written even if the request context was cancelled, and errors are only logged.
+func (s *LLMUsageService) Record(ctx context.Context, u external.Usage) {
+	scope, _ := ctx.Value(llmUsageScopeKey{}).(llmUsageScope)
+	status := entity.LLMUsageOK
+	if u.Err != nil {
+		status = entity.LLMUsageError
+	}
+	item := &entity.LLMUsage{
+		ID:           uuid.New(),
+		UserID:       scope.userID,
+		SurveyCode:   scope.surveyCode,
+		Purpose:      scope.purpose,
+		Model:        u.Model,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		LatencyMS:    int(u.Latency.Milliseconds()),
+		Status:       status,
+		CreatedAt:    s.now().UTC(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), llmUsageRecordTimeout)
+	defer cancel()
+	if err := s.repo.Create(ctx, item); err != nil {
+		log.Printf("[LLMUsage] could not record %s completion (%d+%d tokens): %v", item.Model, item.InputTokens, item.OutputTokens, err)
+	}
+}
+
+// CheckQuota returns a *QuotaExceededError when the user has reached the daily token or request limit.
+// Pending and running AI jobs count as requests already made, so queued work cannot exceed the cap.
+// Quota lookup failures are logged and let the request through.
+func (s *LLMUsageService) CheckQuota(ctx context.Context, userID uuid.UUID) error {
+	return s.checkQuota(ctx, userID, 0)
+}
+
+// CheckJobQuota is CheckQuota for a running job about to call the provider; the job's own slot
+// is not held against it.
+func (s *LLMUsageService) CheckJobQuota(ctx context.Context, job *entity.AIJob) error {
+	if job.UserID == nil {
+		return nil
+	}
+	return s.checkQuota(ctx, *job.UserID, 1)
+}
+
+func (s *LLMUsageService) checkQuota(ctx context.Context, userID uuid.UUID, ownJobs int) error {
+	if s == nil || s.repo == nil || userID == uuid.Nil {
+		return nil
+	}
+	quota, err := s.repo.GetEffectiveQuota(ctx, userID)
+	if err != nil {
+		log.Printf("[LLMUsage] quota lookup for user %s failed: %v", userID, err)
+		return nil
+	}
+	if quota == nil || (quota.DailyTokens == nil && quota.DailyRequests == nil) {
+		return nil
+	}
+
+	now := s.now().UTC()
+	dayStart := now.Truncate(24 * time.Hour)
+	used, err := s.repo.Totals(ctx, userID, dayStart)
+	if err != nil {
+		log.Printf("[LLMUsage] usage lookup for user %s failed: %v", userID, err)
+		return nil
+	}
+	if quota.DailyRequests != nil && s.jobRepo != nil {
+		queued, err := s.jobRepo.CountActiveByUser(ctx, userID)
+		if err != nil {
+			log.Printf("[LLMUsage] queued job lookup for user %s failed: %v", userID, err)
+		} else if queued > ownJobs {
+			used.Requests += queued - ownJobs
+		}
+	}
+	return quotaExceeded(quota, used, dayStart.Add(24*time.Hour).Sub(now))
+}
+
+func quotaExceeded(quota *entity.LLMQuota, used *entity.LLMUsageTotals, retryAfter time.Duration) error {
+	if quota.DailyRequests != nil && used.Requests >= *quota.DailyRequests {
+		return &QuotaExceededError{Limit: "requests", RetryAfter: retryAfter}
+	}
+	if quota.DailyTokens != nil && used.Tokens >= *quota.DailyTokens {
+		return &QuotaExceededError{Limit: "tokens", RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Report aggregates consumption by day, survey code and model for the UTC days from..to inclusive.
+func (s *LLMUsageService) Report(ctx context.Context, from time.Time, to time.Time) ([]*entity.LLMUsageReportRow, error) {
+	start, end, err := reportPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Report(ctx, start, end)
+}
+
+// reportPeriod turns an inclusive range of UTC days into the half-open interval [start, end)
+// used by report queries, rejecting reversed or overly long ranges.
+func reportPeriod(from time.Time, to time.Time) (time.Time, time.Time, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	if to.Before(from) {
+		v := validator.New()
+		v.AddError("to", "to must not be before from")
+		return time.Time{}, time.Time{}, v.Errors()
+	}
+	if to.Sub(from) > reportMaxDays*24*time.Hour {
+		v := validator.New()
+		v.AddError("from", "report period is too long")
+		return time.Time{}, time.Time{}, v.Errors()
+	}
+	return from, to.Add(24 * time.Hour), nil
+}
+
+func (s *LLMUsageService) ListQuotas(ctx context.Context) ([]*entity.LLMQuota, error) {
+	return s.repo.ListQuotas(ctx)
+}
+
+// SetQuota creates or replaces the daily limits of a role (by name) or a single user.
+func (s *LLMUsageService) SetQuota(ctx context.Context, adminID uuid.UUID, req entity.LLMQuotaUpsert) (*entity.LLMQuota, error) {
+	req.Role = strings.TrimSpace(req.Role)
+
+	v := validator.New()
+	if (req.Role == "") == (req.UserID == nil) {
+		v.AddError("role", "exactly one of role or user_id is required")
+	}
+	if req.DailyTokens != nil && *req.DailyTokens < 0 {
+		v.AddError("daily_tokens", "daily_tokens must not be negative")
+	}
+	if req.DailyRequests != nil && *req.DailyRequests < 0 {
+		v.AddError("daily_requests", "daily_requests must not be negative")
+	}
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	quota := &entity.LLMQuota{
+		ID:            uuid.New(),
+		UserID:        req.UserID,
+		DailyTokens:   req.DailyTokens,
+		DailyRequests: req.DailyRequests,
+		UpdatedBy:     &adminID,
+		UpdatedAt:     s.now().UTC(),
+	}
+	if req.Role != "" {
+		role, err := s.roleRepo.GetByName(ctx, req.Role)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, ErrRoleNotFound
+		}
+		quota.RoleID = &role.ID
+		quota.RoleName = role.Name
+	} else {
+		user, err := s.userRepo.GetByID(ctx, *req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	if err := s.repo.UpsertQuota(ctx, quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
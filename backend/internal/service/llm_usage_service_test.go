@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeLLMUsageRepo struct {
+	usage []*entity.LLMUsage
+	quota *entity.LLMQuota
+}
+
+func (r *fakeLLMUsageRepo) Create(ctx context.Context, u *entity.LLMUsage) error {
+	r.usage = append(r.usage, u)
+	return nil
+}
+
+func (r *fakeLLMUsageRepo) Totals(ctx context.Context, userID uuid.UUID, since time.Time) (*entity.LLMUsageTotals, error) {
+	t := &entity.LLMUsageTotals{}
+	for _, u := range r.usage {
+		if u.UserID != nil && *u.UserID == userID && !u.CreatedAt.Before(since) {
+			t.Requests++
+			t.Tokens += u.InputTokens + u.OutputTokens
+		}
+	}
+	return t, nil
+}
+
+func (r *fakeLLMUsageRepo) Report(ctx context.Context, from time.Time, to time.Time) ([]*entity.LLMUsageReportRow, error) {
+	return nil, nil
+}
+
+func (r *fakeLLMUsageRepo) GetEffectiveQuota(ctx context.Context, userID uuid.UUID) (*entity.LLMQuota, error) {
+	return r.quota, nil
+}
+
+func (r *fakeLLMUsageRepo) ListQuotas(ctx context.Context) ([]*entity.LLMQuota, error) {
+	return nil, nil
+}
+
+func (r *fakeLLMUsageRepo) UpsertQuota(ctx context.Context, q *entity.LLMQuota) error {
+	r.quota = q
+	return nil
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestLLMUsageServiceRecordAndQuota(t *testing.T) {
+	now := time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC)
+	repo := &fakeLLMUsageRepo{quota: &entity.LLMQuota{DailyTokens: intPtr(100), DailyRequests: intPtr(3)}}
+	svc := &LLMUsageService{repo: repo, now: func() time.Time { return now }}
+	userID := uuid.New()
+	ctx := context.Background()
+
+	// Yesterday's usage does not count.
+	yesterday := now.Add(-24 * time.Hour)
+	repo.usage = append(repo.usage, &entity.LLMUsage{UserID: &userID, InputTokens: 500, CreatedAt: yesterday})
+	if err := svc.CheckQuota(ctx, userID); err != nil {
+		t.Fatalf("CheckQuota() = %v, want nil", err)
+	}
+
+	scoped := withLLMUsageScope(ctx, &userID, "RCRI", entity.PromptPatientAdvice)
+	svc.Record(scoped, external.Usage{Model: "stub/deterministic", InputTokens: 40, OutputTokens: 30, Latency: 1500 * time.Millisecond})
+	last := repo.usage[len(repo.usage)-1]
+	if last.UserID == nil || *last.UserID != userID || last.SurveyCode != "RCRI" || last.Purpose != entity.PromptPatientAdvice ||
+		last.LatencyMS != 1500 || last.Status != entity.LLMUsageOK {
+		t.Fatalf("recorded usage = %+v", last)
+	}
+	if err := svc.CheckQuota(ctx, userID); err != nil {
+		t.Fatalf("CheckQuota() after 70 tokens = %v, want nil", err)
+	}
+
+	svc.Record(scoped, external.Usage{Model: "stub/deterministic", InputTokens: 20, OutputTokens: 10})
+	err := svc.CheckQuota(ctx, userID)
+	var qe *QuotaExceededError
+	if !errors.As(err, &qe) || qe.Limit != "tokens" {
+		t.Fatalf("CheckQuota() after 100 tokens = %v, want token quota error", err)
+	}
+	if qe.RetryAfter != 90*time.Minute {
+		t.Errorf("RetryAfter = %v, want 1h30m until midnight UTC", qe.RetryAfter)
+	}
+
+	// A failed call counts as a request.
+	repo.quota = &entity.LLMQuota{DailyRequests: intPtr(3)}
+	svc.Record(scoped, external.Usage{Model: "stub/deterministic", Err: errors.New("timeout")})
+	if last := repo.usage[len(repo.usage)-1]; last.Status != entity.LLMUsageError {
+		t.Errorf("failed call status = %q", last.Status)
+	}
+	if err := svc.CheckQuota(ctx, userID); !errors.As(err, &qe) || qe.Limit != "requests" {
+		t.Fatalf("CheckQuota() after 3 requests = %v, want request quota error", err)
+	}
+
+	// No quota means unlimited; a nil service never blocks.
+	repo.quota = nil
+	if err := svc.CheckQuota(ctx, userID); err != nil {
+		t.Fatalf("CheckQuota() without quota = %v", err)
+	}
+	var nilSvc *LLMUsageService
+	if err := nilSvc.CheckQuota(ctx, userID); err != nil {
+		t.Fatalf("nil CheckQuota() = %v", err)
+	}
+}
+
+func TestLLMUsageServiceQuotaCountsQueuedJobs(t *testing.T) {
+	userID := uuid.New()
+	jobs := newFakeAIJobRepo()
+	jobs.active = map[uuid.UUID]int{userID: 2}
+	repo := &fakeLLMUsageRepo{quota: &entity.LLMQuota{DailyRequests: intPtr(3)}}
+	repo.usage = append(repo.usage, &entity.LLMUsage{UserID: &userID, CreatedAt: time.Now().UTC()})
+	svc := NewLLMUsageService(LLMUsageDeps{Repo: repo, JobRepo: jobs})
+	ctx := context.Background()
+
+	// One recorded request and two queued jobs use up the limit of 3.
+	var qe *QuotaExceededError
+	if err := svc.CheckQuota(ctx, userID); !errors.As(err, &qe) || qe.Limit != "requests" {
+		t.Fatalf("CheckQuota() with queued jobs = %v, want request quota error", err)
+	}
+	// A queued job itself still may run.
+	if err := svc.CheckJobQuota(ctx, &entity.AIJob{UserID: &userID}); err != nil {
+		t.Fatalf("CheckJobQuota() = %v, want nil", err)
+	}
+}
+
+func TestLLMUsageServiceSetQuotaValidation(t *testing.T) {
+	svc := &LLMUsageService{repo: &fakeLLMUsageRepo{}, now: time.Now}
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		req  entity.LLMQuotaUpsert
+	}{
+		{name: "neither role nor user", req: entity.LLMQuotaUpsert{DailyTokens: intPtr(10)}},
+		{name: "both role and user", req: entity.LLMQuotaUpsert{Role: "patient", UserID: &userID}},
+		{name: "negative tokens", req: entity.LLMQuotaUpsert{Role: "patient", DailyTokens: intPtr(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.SetQuota(context.Background(), uuid.New(), tt.req)
+			var ve validator.ValidationErrors
+			if !errors.As(err, &ve) {
+				t.Fatalf("SetQuota() error = %v, want validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+)
+
+var ErrPatientNotFound = errors.New("patient not found")
+
+type MedicalIndexService struct {
+	repo        repository.MedicalIndexRepository
+	patientRepo repository.PatientRepository
+}
+
+type MedicalIndexDeps struct {
+	Repo        repository.MedicalIndexRepository
+	PatientRepo repository.PatientRepository
+}
+
+func NewMedicalIndexService(d MedicalIndexDeps) *MedicalIndexService {
+	return &MedicalIndexService{repo: d.Repo, patientRepo: d.PatientRepo}
+}
+
+// ResolvePatient accepts either a patients.id or users.id and returns the patient record.
+func (s *MedicalIndexService) ResolvePatient(ctx context.Context, id uuid.UUID) (*entity.Patient, error) {
+	p, err := s.patientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		return p, nil
+	}
+	return s.patientRepo.GetByUserID(ctx, id)
+}
+
+// GetTrend returns the time series of one index type for a patient with deltas and category transitions.
+func (s *MedicalIndexService) GetTrend(ctx context.Context, patientID uuid.UUID, indexType string, from *time.Time, to *time.Time) (*entity.IndexTrend, error) {
+	indexType = strings.ToUpper(strings.TrimSpace(indexType))
+	if indexType == "" {
+		return nil, errors.New("index_type is required")
+	}
+
+	items, err := s.repo.ListByPatientAndType(ctx, patientID, indexType, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := BuildIndexTrend(items)
+	trend.PatientID = patientID
+	trend.IndexType = indexType
+	return trend, nil
+}
+
+// BuildIndexTrend turns chronologically ordered measurements into a trend.
+func BuildIndexTrend(items []*entity.MedicalIndex) *entity.IndexTrend {
+	trend := &entity.IndexTrend{
+		Points:      make([]entity.IndexTrendPoint, 0, len(items)),
+		Transitions: make([]entity.CategoryTransition, 0),
+	}
+	if len(items) == 0 {
+		return trend
+	}
+
+	baseline := items[0].Value
+	for i, it := range items {
+		p := entity.IndexTrendPoint{
+			MedicalIndexID:    it.ID,
+			SurveyResponseID:  it.SurveyResponseID,
+			RecordedAt:        it.RecordedAt,
+			Value:             it.Value,
+			Category:          it.Category,
+			DeltaFromBaseline: round2(it.Value - baseline),
+		}
+		if i > 0 {
+			prev := items[i-1]
+			d := round2(it.Value - prev.Value)
+			p.Delta = &d
+			if it.Category != prev.Category {
+				p.CategoryChanged = true
+				trend.Transitions = append(trend.Transitions, entity.CategoryTransition{
+					From:       prev.Category,
+					To:         it.Category,
+					At:         it.RecordedAt,
+					ValueDelta: d,
+				})
+			}
+		}
+		trend.Points = append(trend.Points, p)
+	}
+
+	trend.Baseline = &trend.Points[0]
+	trend.Latest = &trend.Points[len(trend.Points)-1]
+	trend.TotalDelta = trend.Latest.DeltaFromBaseline
+	return trend
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+func TestBuildIndexTrend(t *testing.T) {
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	items := []*entity.MedicalIndex{
+		{ID: uuid.New(), IndexType: "DAS28_CRP", Value: 5.6, Category: "high_activity", RecordedAt: start},
+		{ID: uuid.New(), IndexType: "DAS28_CRP", Value: 4.2, Category: "moderate_activity", RecordedAt: start.AddDate(0, 3, 0)},
+		{ID: uuid.New(), IndexType: "DAS28_CRP", Value: 3.9, Category: "moderate_activity", RecordedAt: start.AddDate(0, 6, 0)},
+		{ID: uuid.New(), IndexType: "DAS28_CRP", Value: 2.4, Category: "remission", RecordedAt: start.AddDate(0, 9, 0)},
+	}
+
+	trend := BuildIndexTrend(items)
+
+	if len(trend.Points) != 4 {
+		t.Fatalf("BuildIndexTrend() points = %d, want 4", len(trend.Points))
+	}
+	if trend.Points[0].Delta != nil {
+		t.Errorf("first point delta = %v, want nil", *trend.Points[0].Delta)
+	}
+	if d := trend.Points[1].Delta; d == nil || *d != -1.4 {
+		t.Errorf("second point delta = %v, want -1.4", d)
+	}
+	if trend.TotalDelta != -3.2 {
+		t.Errorf("TotalDelta = %v, want -3.2", trend.TotalDelta)
+	}
+	if len(trend.Transitions) != 2 {
+		t.Fatalf("transitions = %d, want 2", len(trend.Transitions))
+	}
+	if tr := trend.Transitions[1]; tr.From != "moderate_activity" || tr.To != "remission" {
+		t.Errorf("second transition = %s -> %s, want moderate_activity -> remission", tr.From, tr.To)
+	}
+	if trend.Points[2].CategoryChanged {
+		t.Errorf("third point CategoryChanged = true, want false")
+	}
+}
+
+func TestBuildIndexTrendEmpty(t *testing.T) {
+	trend := BuildIndexTrend(nil)
+	if len(trend.Points) != 0 || trend.Baseline != nil || trend.Latest != nil {
+		t.Errorf("BuildIndexTrend(nil) = %+v, want empty trend", trend)
+	}
+}
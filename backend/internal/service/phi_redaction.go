@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+)
+
+// PHI categories masked before text leaves for an external LLM.
+const (
+	PHIName      = "name"
+	PHIPhone     = "phone"
+	PHISNILS     = "snils"
+	PHIBirthDate = "birth_date"
+	PHIEmail     = "email"
+)
+
+type phiRule struct {
+	category string
+	re       *regexp.Regexp
+	// group is the submatch that is replaced; 0 replaces the whole match.
+	group int
+	token string
+}
+
+const (
+	phiNameToken      = "[ИМЯ]"
+	phiPhoneToken     = "[ТЕЛЕФОН]"
+	phiSNILSToken     = "[СНИЛС]"
+	phiBirthDateToken = "[ДАТА РОЖДЕНИЯ]"
+	phiEmailToken     = "[EMAIL]"
+)
+
+const (
+	phiDate          = `\d{4}-\d{2}-\d{2}|\d{1,2}[./-]\d{1,2}[./-]\d{2,4}|\d{1,2}\s+\p{L}+\s+\d{4}(?:\s*г(?:ода|\.)?)?|\p{L}+\s+\d{1,2},?\s+\d{4}|\d{4}(?:\s*г(?:ода|\.)?)?`
+	phiCapitalized   = `\p{Lu}\p{Ll}+`
+	phiPatronymicEnd = `(?:ович|евич|ьич|ична|инична|овна|евна)\p{Ll}*`
+)
+
+// Rules run in order on the progressively masked text: specific formats before generic digit runs.
+var phiRules = []phiRule{
+	{PHIEmail, regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`), 0, phiEmailToken},
+	{PHISNILS, regexp.MustCompile(`\b\d{3}[- ]\d{3}[- ]\d{3}[- ]\d{2}\b`), 0, phiSNILSToken},
+	{PHISNILS, regexp.MustCompile(`((?i:снилс))[\s:№#-]*(\d{11})\b`), 2, phiSNILSToken},
+	{PHIBirthDate, regexp.MustCompile(`((?i:дата рождения|д\.\s?р\.|родил(?:ся|ась)|date of birth|birth\s?date|dob|born(?: on)?))[\s:–—-]*(` + phiDate + `)`), 2, phiBirthDateToken},
+	{PHIBirthDate, regexp.MustCompile(`(?:\d{1,2}[./-]\d{1,2}[./-]\d{2,4}|\d{4})\s*г\.\s?р\.?`), 0, phiBirthDateToken},
+	{PHIPhone, regexp.MustCompile(`(?:\+\d{1,3}|\b8)[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}\b`), 0, phiPhoneToken},
+	{PHIPhone, regexp.MustCompile(`\b(?:7\d{10}|9\d{9})\b`), 0, phiPhoneToken},
+	{PHISNILS, regexp.MustCompile(`\b\d{11}\b`), 0, phiSNILSToken},
+	{PHIName, regexp.MustCompile(`((?i:меня зовут|мо[её] имя|фио|my name is|name is|i am|i'm))[\s:—-]+(` + phiCapitalized + `(?:[\s-]+` + phiCapitalized + `){0,2})`), 2, phiNameToken},
+	{PHIName, regexp.MustCompile(`(?:` + phiCapitalized + `\s+)?` + phiCapitalized + `\s+\p{Lu}\p{Ll}+?` + phiPatronymicEnd + `(?:\s+` + phiCapitalized + `)?`), 0, phiNameToken},
+	{PHIName, regexp.MustCompile(phiCapitalized + `\s+\p{Lu}\.\s?\p{Lu}\.|\p{Lu}\.\s?\p{Lu}\.\s?` + phiCapitalized), 0, phiNameToken},
+	{PHIName, regexp.MustCompile(`((?:Mr|Mrs|Ms|Dr)\.?|(?i:доктор|пациентк?а?))\s+(` + phiCapitalized + `(?:\s+` + phiCapitalized + `)?)`), 2, phiNameToken},
+}
+
+func (r phiRule) apply(text string, counts map[string]int) string {
+	matches := r.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*r.group], m[2*r.group+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.token)
+		last = end
+		counts[r.category]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// redactPHI masks personal identifiers and returns the masked text with a count per category.
+func redactPHI(text string) (string, map[string]int) {
+	counts := map[string]int{}
+	for _, rule := range phiRules {
+		text = rule.apply(text, counts)
+	}
+	return text, counts
+}
+
+// redactPHIValue masks every string inside a JSON-like value (maps, slices, strings).
+func redactPHIValue(v any, counts map[string]int) any {
+	switch t := v.(type) {
+	case string:
+		out, c := redactPHI(t)
+		for k, n := range c {
+			counts[k] += n
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[k] = redactPHIValue(val, counts)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = redactPHIValue(val, counts)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// PHIRedactor masks names, phone numbers, SNILS, birth dates and e-mails before text is sent to an
+// external LLM. Each redaction is audited with the categories and counts only, never the values.
+type PHIRedactor struct {
+	auditRepo repository.AuditLogRepository
+}
+
+func NewPHIRedactor(auditRepo repository.AuditLogRepository) *PHIRedactor {
+	return &PHIRedactor{auditRepo: auditRepo}
+}
+
+// RedactText masks text that is about to leave the system on behalf of userID for the given resource.
+func (r *PHIRedactor) RedactText(ctx context.Context, userID *uuid.UUID, resourceType string, resourceID *uuid.UUID, text string) string {
+	out, counts := redactPHI(text)
+	r.audit(ctx, userID, resourceType, resourceID, counts)
+	return out
+}
+
+// RedactValues is RedactText for a structured payload such as a score breakdown.
+func (r *PHIRedactor) RedactValues(ctx context.Context, userID *uuid.UUID, resourceType string, resourceID *uuid.UUID, values map[string]any) map[string]any {
+	if values == nil {
+		return nil
+	}
+	counts := map[string]int{}
+	out := redactPHIValue(values, counts).(map[string]any)
+	r.audit(ctx, userID, resourceType, resourceID, counts)
+	return out
+}
+
+func (r *PHIRedactor) audit(ctx context.Context, userID *uuid.UUID, resourceType string, resourceID *uuid.UUID, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	categories := make([]string, 0, len(counts))
+	for k := range counts {
+		categories = append(categories, k)
+	}
+	sort.Strings(categories)
+	log.Printf("[PHI] redacted %s before LLM call (%s)", strings.Join(categories, ","), resourceType)
+
+	if r == nil || r.auditRepo == nil {
+		return
+	}
+	newValue, _ := json.Marshal(map[string]any{"destination": "llm", "categories": categories, "counts": counts})
+	entry := &entity.AuditLog{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Action:       entity.AuditActionRedact,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		NewValue:     newValue,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := r.auditRepo.Create(ctx, entry); err != nil {
+		log.Printf("[PHI] could not write redaction audit entry: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactPHI(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		counts map[string]int
+	}{
+		{
+			name:   "nothing to redact",
+			text:   "Беспокоит одышка при подъёме на 2 этаж, операция 12.03.2025.",
+			want:   "Беспокоит одышка при подъёме на 2 этаж, операция 12.03.2025.",
+			counts: map[string]int{},
+		},
+		{
+			name:   "russian phone formats",
+			text:   "Звоните +7 (912) 345-67-89 или 8 912 345 67 89, а также 79123456789",
+			want:   "Звоните [ТЕЛЕФОН] или [ТЕЛЕФОН], а также [ТЕЛЕФОН]",
+			counts: map[string]int{PHIPhone: 3},
+		},
+		{
+			name:   "snils",
+			text:   "СНИЛС 112-233-445 95, ещё раз: снилс №11223344595",
+			want:   "СНИЛС [СНИЛС], ещё раз: снилс №[СНИЛС]",
+			counts: map[string]int{PHISNILS: 2},
+		},
+		{
+			name:   "birth dates",
+			text:   "Дата рождения: 01.02.1960. Родилась 5 марта 1958 г. Пациент 1961 г.р. DOB 1960-04-03",
+			want:   "Дата рождения: [ДАТА РОЖДЕНИЯ]. Родилась [ДАТА РОЖДЕНИЯ] Пациент [ДАТА РОЖДЕНИЯ] DOB [ДАТА РОЖДЕНИЯ]",
+			counts: map[string]int{PHIBirthDate: 4},
+		},
+		{
+			name:   "russian names",
+			text:   "Меня зовут Анна Петрова. Лечащий врач Сидоров И.П., направил Иван Петрович Смирнов.",
+			want:   "Меня зовут [ИМЯ]. Лечащий врач [ИМЯ], направил [ИМЯ].",
+			counts: map[string]int{PHIName: 3},
+		},
+		{
+			name:   "english names and email",
+			text:   "My name is John Smith, write to john.smith@example.com. Dr. Brown said I am fine.",
+			want:   "My name is [ИМЯ], write to [EMAIL]. Dr. [ИМЯ] said I am fine.",
+			counts: map[string]int{PHIName: 2, PHIEmail: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, counts := redactPHI(tt.text)
+			if got != tt.want {
+				t.Errorf("redactPHI() text\n got: %s\nwant: %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(counts, tt.counts) {
+				t.Errorf("redactPHI() counts = %v, want %v", counts, tt.counts)
+			}
+		})
+	}
+}
+
+func TestPHIRedactorValues(t *testing.T) {
+	values := map[string]any{
+		"score":    3.0,
+		"comment":  "телефон 89123456789",
+		"nested":   []any{"ФИО: Иванов Иван"},
+		"category": "high",
+	}
+	var r *PHIRedactor
+	out := r.RedactValues(context.Background(), nil, "survey", nil, values)
+
+	if out["score"] != 3.0 || out["category"] != "high" {
+		t.Errorf("non-PHI values changed: %v", out)
+	}
+	if strings.Contains(out["comment"].(string), "8912") {
+		t.Errorf("phone not redacted: %v", out["comment"])
+	}
+	if got := out["nested"].([]any)[0]; got != "ФИО: [ИМЯ]" {
+		t.Errorf("nested name not redacted: %v", got)
+	}
+	if values["comment"] != "телефон 89123456789" {
+		t.Errorf("input map was modified")
+	}
+}
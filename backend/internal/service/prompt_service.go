@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/external"
+	"github.com/medical-app/backend/internal/repository"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrPromptTemplateNotFound = errors.New("prompt template not found")
+	ErrPromptTemplateNotDraft = errors.New("prompt template has already been reviewed")
+	ErrPromptSelfApproval     = errors.New("prompt template cannot be approved by its author")
+)
+
+// builtinPrompts are used when the registry has no approved version (or is unreachable).
+var builtinPrompts = map[string]string{
+	entity.PromptPatientAdvice:         patientAdviceSystemPrompt,
+	entity.PromptSurveyInterpretation:  external.MedicalSummaryPrompts.SurveyInterpretation,
+	entity.PromptTherapyRecommendation: external.MedicalSummaryPrompts.TherapyRecommendation,
+	entity.PromptDrugInteraction:       external.MedicalSummaryPrompts.DrugInteraction,
+}
+
+// PromptService is the registry of versioned LLM system prompts. New versions start as drafts
+// and take effect only once a clinical reviewer approves them; survey-specific overrides win over the default.
+type PromptService struct {
+	repo         repository.PromptTemplateRepository
+	templateRepo repository.SurveyTemplateRepository
+	adviceCache  repository.AdviceCacheRepository
+}
+
+type PromptDeps struct {
+	Repo         repository.PromptTemplateRepository
+	TemplateRepo repository.SurveyTemplateRepository
+	// AdviceCache is cleared when a patient advice prompt is approved.
+	AdviceCache repository.AdviceCacheRepository
+}
+
+func NewPromptService(d PromptDeps) *PromptService {
+	return &PromptService{repo: d.Repo, templateRepo: d.TemplateRepo, adviceCache: d.AdviceCache}
+}
+
+// Resolve returns the approved prompt for a key and survey code. The built-in prompt (zero ID and version)
+// is returned when nothing is approved, so generation never depends on the registry being populated.
+func (s *PromptService) Resolve(ctx context.Context, key string, surveyCode string) *entity.PromptTemplate {
+	if s != nil && s.repo != nil {
+		p, err := s.repo.GetActive(ctx, key, surveyCode)
+		if err != nil {
+			log.Printf("[Prompts] resolve %s/%s failed (using built-in prompt): %v", key, surveyCode, err)
+		} else if p != nil {
+			return p
+		}
+	}
+	return &entity.PromptTemplate{Key: key, SystemPrompt: builtinPrompts[key], Status: entity.PromptStatusApproved}
+}
+
+// ResolveByID returns the exact version recorded for a queued generation, or the current one if it is gone.
+func (s *PromptService) ResolveByID(ctx context.Context, id *uuid.UUID, key string, surveyCode string) *entity.PromptTemplate {
+	if id != nil && s != nil && s.repo != nil {
+		p, err := s.repo.GetByID(ctx, *id)
+		if err != nil {
+			log.Printf("[Prompts] load %s failed: %v", *id, err)
+		} else if p != nil {
+			return p
+		}
+	}
+	return s.Resolve(ctx, key, surveyCode)
+}
+
+func (s *PromptService) Get(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPromptTemplateNotFound
+	}
+	return p, nil
+}
+
+func (s *PromptService) List(ctx context.Context, key string, surveyCode string) ([]*entity.PromptTemplate, error) {
+	return s.repo.List(ctx, strings.TrimSpace(key), strings.TrimSpace(surveyCode))
+}
+
+// Create adds a new draft version for a key, optionally scoped to one survey code.
+func (s *PromptService) Create(ctx context.Context, authorID uuid.UUID, req entity.PromptTemplateCreate) (*entity.PromptTemplate, error) {
+	req.Key = strings.TrimSpace(req.Key)
+	req.SurveyCode = strings.TrimSpace(req.SurveyCode)
+	req.SystemPrompt = strings.TrimSpace(req.SystemPrompt)
+
+	v := validator.New()
+	v.Required("key", req.Key, "key is required")
+	if _, ok := builtinPrompts[req.Key]; req.Key != "" && !ok {
+		v.AddError("key", "unknown prompt key")
+	}
+	v.Required("system_prompt", req.SystemPrompt, "system_prompt is required")
+	v.MaxLength("system_prompt", req.SystemPrompt, 20000, "system_prompt is too long")
+	v.MaxLength("notes", req.Notes, 2000, "notes is too long")
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if req.SurveyCode != "" {
+		t, err := s.templateRepo.GetByCode(ctx, req.SurveyCode)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, ErrSurveyTemplateNotFound
+		}
+	}
+
+	p := &entity.PromptTemplate{
+		ID:           uuid.New(),
+		Key:          req.Key,
+		SurveyCode:   req.SurveyCode,
+		SystemPrompt: req.SystemPrompt,
+		Status:       entity.PromptStatusDraft,
+		Notes:        strings.TrimSpace(req.Notes),
+		CreatedBy:    &authorID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Review approves or rejects a draft. Approval activates the version and retires the previous one;
+// the author cannot approve their own version.
+func (s *PromptService) Review(ctx context.Context, reviewerID uuid.UUID, id uuid.UUID, req entity.PromptTemplateReview) (*entity.PromptTemplate, error) {
+	req.Comment = strings.TrimSpace(req.Comment)
+	if !req.Approve && req.Comment == "" {
+		v := validator.New()
+		v.AddError("comment", "comment is required when rejecting")
+		return nil, v.Errors()
+	}
+
+	p, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entity.PromptStatusDraft {
+		return nil, ErrPromptTemplateNotDraft
+	}
+	if req.Approve && p.CreatedBy != nil && *p.CreatedBy == reviewerID {
+		return nil, ErrPromptSelfApproval
+	}
+
+	status := entity.PromptStatusRejected
+	if req.Approve {
+		status = entity.PromptStatusApproved
+	}
+	now := time.Now().UTC()
+	ok, err := s.repo.Review(ctx, p, status, reviewerID, req.Comment, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPromptTemplateNotDraft
+	}
+
+	p.Status = status
+	p.ReviewedBy = &reviewerID
+	p.ReviewComment = req.Comment
+	p.ReviewedAt = &now
+	if req.Approve && p.Key == entity.PromptPatientAdvice {
+		s.invalidateAdviceCache(ctx, p.SurveyCode)
+	}
+	return p, nil
+}
+
+// invalidateAdviceCache drops advice cached under the previous prompt. A new default prompt clears
+// every survey code. Cache keys include the prompt version, so a failure here only leaves dead entries.
+func (s *PromptService) invalidateAdviceCache(ctx context.Context, surveyCode string) {
+	if s.adviceCache == nil {
+		return
+	}
+	n, err := s.adviceCache.DeleteBySurvey(ctx, surveyCode)
+	if err != nil {
+		log.Printf("[Prompts] could not clear advice cache for %q: %v", surveyCode, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[Prompts] cleared %d cached advice entries for %q", n, surveyCode)
+	}
+}
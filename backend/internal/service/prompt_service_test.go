@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+type fakePromptRepo struct {
+	items    map[uuid.UUID]*entity.PromptTemplate
+	getErr   error
+	reviewed int
+}
+
+func newFakePromptRepo(items ...*entity.PromptTemplate) *fakePromptRepo {
+	r := &fakePromptRepo{items: map[uuid.UUID]*entity.PromptTemplate{}}
+	for _, p := range items {
+		r.items[p.ID] = p
+	}
+	return r
+}
+
+func (r *fakePromptRepo) Create(ctx context.Context, p *entity.PromptTemplate) error {
+	r.items[p.ID] = p
+	return nil
+}
+func (r *fakePromptRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.PromptTemplate, error) {
+	return r.items[id], nil
+}
+func (r *fakePromptRepo) GetActive(ctx context.Context, key string, surveyCode string) (*entity.PromptTemplate, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	var def *entity.PromptTemplate
+	for _, p := range r.items {
+		if p.Key != key || p.Status != entity.PromptStatusApproved {
+			continue
+		}
+		if p.SurveyCode == surveyCode {
+			return p, nil
+		}
+		if p.SurveyCode == "" {
+			def = p
+		}
+	}
+	return def, nil
+}
+func (r *fakePromptRepo) List(ctx context.Context, key string, surveyCode string) ([]*entity.PromptTemplate, error) {
+	return nil, nil
+}
+func (r *fakePromptRepo) Review(ctx context.Context, p *entity.PromptTemplate, status string, reviewedBy uuid.UUID, comment string, at time.Time) (bool, error) {
+	r.reviewed++
+	return true, nil
+}
+
+func TestPromptServiceResolve(t *testing.T) {
+	def := &entity.PromptTemplate{ID: uuid.New(), Key: entity.PromptPatientAdvice, Version: 2, SystemPrompt: "default", Status: entity.PromptStatusApproved}
+	override := &entity.PromptTemplate{ID: uuid.New(), Key: entity.PromptPatientAdvice, SurveyCode: "CAPRINI", Version: 1, SystemPrompt: "caprini", Status: entity.PromptStatusApproved}
+	draft := &entity.PromptTemplate{ID: uuid.New(), Key: entity.PromptPatientAdvice, SurveyCode: "ASA", Version: 1, SystemPrompt: "draft", Status: entity.PromptStatusDraft}
+	repo := newFakePromptRepo(def, override, draft)
+	svc := NewPromptService(PromptDeps{Repo: repo})
+	ctx := context.Background()
+
+	if got := svc.Resolve(ctx, entity.PromptPatientAdvice, "CAPRINI"); got.ID != override.ID {
+		t.Errorf("override not preferred: got %q", got.SystemPrompt)
+	}
+	if got := svc.Resolve(ctx, entity.PromptPatientAdvice, "ASA"); got.ID != def.ID {
+		t.Errorf("draft must not be used: got %q", got.SystemPrompt)
+	}
+	if got := svc.ResolveByID(ctx, &override.ID, entity.PromptPatientAdvice, "ASA"); got.ID != override.ID {
+		t.Errorf("ResolveByID did not return the recorded version: got %q", got.SystemPrompt)
+	}
+
+	repo.getErr = errors.New("db down")
+	got := svc.Resolve(ctx, entity.PromptPatientAdvice, "RCRI")
+	if got.Version != 0 || got.SystemPrompt != patientAdviceSystemPrompt {
+		t.Errorf("expected built-in prompt on registry error, got version %d", got.Version)
+	}
+
+	var nilSvc *PromptService
+	if got := nilSvc.Resolve(ctx, entity.PromptSurveyInterpretation, ""); got.SystemPrompt == "" {
+		t.Errorf("nil registry must fall back to the built-in prompt")
+	}
+}
+
+func TestPromptServiceReview(t *testing.T) {
+	author := uuid.New()
+	draft := &entity.PromptTemplate{ID: uuid.New(), Key: entity.PromptPatientAdvice, Version: 3, SystemPrompt: "new", Status: entity.PromptStatusDraft, CreatedBy: &author}
+	repo := newFakePromptRepo(draft)
+	cache := &fakeAdviceCacheRepo{entries: map[string]*entity.AdviceCacheEntry{
+		"a": {Key: "a", SurveyCode: "RCRI"},
+		"b": {Key: "b", SurveyCode: "ASA"},
+	}}
+	svc := NewPromptService(PromptDeps{Repo: repo, AdviceCache: cache})
+	ctx := context.Background()
+
+	if _, err := svc.Review(ctx, author, draft.ID, entity.PromptTemplateReview{Approve: true}); !errors.Is(err, ErrPromptSelfApproval) {
+		t.Fatalf("self approval: err = %v, want ErrPromptSelfApproval", err)
+	}
+	if _, err := svc.Review(ctx, uuid.New(), draft.ID, entity.PromptTemplateReview{Approve: false}); err == nil {
+		t.Fatalf("rejection without comment must fail validation")
+	}
+
+	p, err := svc.Review(ctx, uuid.New(), draft.ID, entity.PromptTemplateReview{Approve: true, Comment: "ok"})
+	if err != nil {
+		t.Fatalf("Review() error = %v", err)
+	}
+	if p.Status != entity.PromptStatusApproved || repo.reviewed != 1 {
+		t.Errorf("status = %s, reviewed = %d", p.Status, repo.reviewed)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("approving the default advice prompt left %d cached entries", len(cache.entries))
+	}
+	if _, err := svc.Review(ctx, uuid.New(), draft.ID, entity.PromptTemplateReview{Approve: true}); !errors.Is(err, ErrPromptTemplateNotDraft) {
+		t.Errorf("second review: err = %v, want ErrPromptTemplateNotDraft", err)
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/internal/repository"
+)
+
+var (
+	ErrSurveyTemplateNotFound = errors.New("survey template not found")
+	ErrRescoreJobNotFound     = errors.New("rescore job not found")
+	ErrRescoreJobActive       = errors.New("a rescore job for this template is already in progress")
+	ErrRescoreJobNotReady     = errors.New("rescore job is not awaiting confirmation")
+)
+
+const (
+	rescoreBatchSize = 200
+	// rescoreJobStaleAfter is how long an unfinished job may go without a progress update before
+	// it is considered abandoned; every batch updates the job, so a live job never gets near it.
+	rescoreJobStaleAfter = 15 * time.Minute
+)
+
+// RescoreService re-runs the scoring engine over stored responses after a template
+// or engine change. A job first produces a diff report; stored values are only
+// overwritten once an administrator confirms the job.
+type RescoreService struct {
+	templateRepo repository.SurveyTemplateRepository
+	responseRepo repository.SurveyResponseRepository
+	indexRepo    repository.MedicalIndexRepository
+	jobRepo      repository.RescoreJobRepository
+	alerts       *AlertService
+}
+
+type RescoreDeps struct {
+	TemplateRepo repository.SurveyTemplateRepository
+	ResponseRepo repository.SurveyResponseRepository
+	IndexRepo    repository.MedicalIndexRepository
+	JobRepo      repository.RescoreJobRepository
+	// Alerts re-evaluates the alert rules for every applied change; optional.
+	Alerts *AlertService
+}
+
+func NewRescoreService(d RescoreDeps) *RescoreService {
+	return &RescoreService{
+		templateRepo: d.TemplateRepo,
+		responseRepo: d.ResponseRepo,
+		indexRepo:    d.IndexRepo,
+		jobRepo:      d.JobRepo,
+		alerts:       d.Alerts,
+	}
+}
+
+// Start creates a job for the template and computes the diff in the background.
+func (s *RescoreService) Start(ctx context.Context, userID uuid.UUID, templateCode string) (*entity.RescoreJob, error) {
+	template, err := s.templateRepo.GetByCode(ctx, templateCode)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrSurveyTemplateNotFound
+	}
+
+	active, err := s.jobRepo.HasActive(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrRescoreJobActive
+	}
+
+	job := &entity.RescoreJob{
+		ID:              uuid.New(),
+		TemplateID:      template.ID,
+		TemplateVersion: template.Version,
+		RequestedBy:     &userID,
+		Status:          entity.RescoreStatusPending,
+		CreatedAt:       time.Now().UTC(),
+	}
+	created, err := s.jobRepo.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	// Lost a race with a concurrent Start for the same template.
+	if !created {
+		return nil, ErrRescoreJobActive
+	}
+
+	go s.run(job.ID, template)
+	return job, nil
+}
+
+func (s *RescoreService) Get(ctx context.Context, id uuid.UUID) (*entity.RescoreJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrRescoreJobNotFound
+	}
+	return job, nil
+}
+
+func (s *RescoreService) ListItems(ctx context.Context, id uuid.UUID, categoryChangedOnly bool, limit int, offset int) ([]*entity.RescoreJobItem, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.jobRepo.ListItems(ctx, id, categoryChangedOnly, limit, offset)
+}
+
+// Confirm approves a ready job; the new scores are written back in the background.
+func (s *RescoreService) Confirm(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.RescoreJob, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.jobRepo.TransitionStatus(ctx, id, entity.RescoreStatusReady, entity.RescoreStatusApplying)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrRescoreJobNotReady
+	}
+
+	now := time.Now().UTC()
+	job.Status = entity.RescoreStatusApplying
+	job.ConfirmedBy = &userID
+	job.ConfirmedAt = &now
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, job.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrSurveyTemplateNotFound
+	}
+
+	go s.apply(job, template)
+	return job, nil
+}
+
+// FailInterrupted fails every job left pending, running or applying by the previous process: the
+// work of a job runs in a goroutine of the process that started it, so none survives a restart.
+// It is run once on startup, before the reaper.
+func (s *RescoreService) FailInterrupted(ctx context.Context) error {
+	n, err := s.jobRepo.FailStale(ctx, time.Now().UTC(), "job was interrupted by a server restart")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("[Rescore] failed %d interrupted jobs", n)
+	}
+	return nil
+}
+
+// FailStale fails jobs that made no progress for rescoreJobStaleAfter, so a job whose goroutine
+// got stuck no longer blocks new jobs for its template.
+func (s *RescoreService) FailStale(ctx context.Context) error {
+	n, err := s.jobRepo.FailStale(ctx, time.Now().UTC().Add(-rescoreJobStaleAfter), "job was interrupted: no progress for "+rescoreJobStaleAfter.String())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("[Rescore] failed %d stale jobs", n)
+	}
+	return nil
+}
+
+// StartReaper runs FailStale every minute until ctx is cancelled.
+func (s *RescoreService) StartReaper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.FailStale(ctx); err != nil {
+					log.Printf("[Rescore] fail stale jobs failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *RescoreService) run(jobID uuid.UUID, template *entity.SurveyTemplate) {
+	ctx := context.Background()
+	defer s.recoverJob(ctx, jobID)
+
+	ok, err := s.jobRepo.TransitionStatus(ctx, jobID, entity.RescoreStatusPending, entity.RescoreStatusRunning)
+	if err != nil || !ok {
+		log.Printf("[Rescore] job %s could not be started: %v", jobID, err)
+		return
+	}
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		log.Printf("[Rescore] job %s could not be loaded: %v", jobID, err)
+		return
+	}
+
+	report := entity.RescoreReport{Transitions: map[string]int{}}
+	afterID := uuid.Nil
+	for {
+		batch, err := s.responseRepo.ListByTemplate(ctx, template.ID, afterID, rescoreBatchSize)
+		if err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		items := make([]*entity.RescoreJobItem, 0)
+		for _, sr := range batch {
+			afterID = sr.ID
+			if sr.Status == entity.SurveyStatusDraft {
+				continue
+			}
+			job.TotalCount++
+
+			item, err := rescoreResponse(job.ID, template, sr)
+			if err != nil {
+				s.fail(ctx, job, fmt.Errorf("response %s: %w", sr.ID, err))
+				return
+			}
+			if item == nil {
+				continue
+			}
+			job.ChangedCount++
+			if item.CategoryChanged {
+				job.CategoryChangedCount++
+				report.Transitions[item.OldCategory+" -> "+item.NewCategory]++
+			}
+			items = append(items, item)
+		}
+
+		if err := s.jobRepo.AddItems(ctx, items); err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+		if err := s.jobRepo.Update(ctx, job); err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+	}
+
+	finished := time.Now().UTC()
+	job.Report, _ = json.Marshal(report)
+	job.Status = entity.RescoreStatusReady
+	job.FinishedAt = &finished
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("[Rescore] job %s could not be saved: %v", job.ID, err)
+	}
+}
+
+func (s *RescoreService) apply(job *entity.RescoreJob, template *entity.SurveyTemplate) {
+	ctx := context.Background()
+	defer s.recoverJob(ctx, job.ID)
+
+	offset := 0
+	for {
+		items, err := s.jobRepo.ListItems(ctx, job.ID, false, rescoreBatchSize, offset)
+		if err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+		if len(items) == 0 {
+			break
+		}
+		offset += len(items)
+
+		for _, item := range items {
+			if err := s.applyItem(ctx, template, item); err != nil {
+				s.fail(ctx, job, fmt.Errorf("response %s: %w", item.SurveyResponseID, err))
+				return
+			}
+		}
+		if err := s.jobRepo.Update(ctx, job); err != nil {
+			s.fail(ctx, job, err)
+			return
+		}
+	}
+
+	applied := time.Now().UTC()
+	job.Status = entity.RescoreStatusApplied
+	job.AppliedAt = &applied
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("[Rescore] job %s could not be saved: %v", job.ID, err)
+	}
+}
+
+func (s *RescoreService) applyItem(ctx context.Context, template *entity.SurveyTemplate, item *entity.RescoreJobItem) error {
+	sr, err := s.responseRepo.GetByID(ctx, item.SurveyResponseID)
+	if err != nil {
+		return err
+	}
+	if sr == nil {
+		return nil
+	}
+	// The response changed after the diff was computed; leave it alone.
+	if !sameScore(sr.CalculatedScore, item.OldScore) || sr.Category != item.OldCategory {
+		log.Printf("[Rescore] skipping response %s: changed since diff", sr.ID)
+		return nil
+	}
+
+	// Only the engine-generated interpretation is rewritten; GPT text is kept as is.
+	interpretation := sr.Interpretation
+	if interpretation == defaultInterpretation(template, item.OldCategory) {
+		interpretation = defaultInterpretation(template, item.NewCategory)
+	}
+
+	if err := s.responseRepo.UpdateScore(ctx, sr.ID, item.NewScore, item.NewCategory, interpretation, item.NewBreakdown); err != nil {
+		return err
+	}
+
+	// A clinician override still wins over the engine category in the index history.
+	indexCategory := item.NewCategory
+	if sr.CategoryOverride != "" {
+		indexCategory = sr.CategoryOverride
+	}
+	if err := s.indexRepo.UpdateValueBySurveyResponse(ctx, sr.ID, item.NewScore, indexCategory); err != nil {
+		return err
+	}
+	reevaluateAlerts(ctx, s.alerts, s.indexRepo, sr.ID, "[Rescore]")
+	return nil
+}
+
+func (s *RescoreService) fail(ctx context.Context, job *entity.RescoreJob, cause error) {
+	log.Printf("[Rescore] job %s failed: %v", job.ID, cause)
+	finished := time.Now().UTC()
+	job.Status = entity.RescoreStatusFailed
+	job.Error = cause.Error()
+	job.FinishedAt = &finished
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Printf("[Rescore] job %s could not be saved: %v", job.ID, err)
+	}
+}
+
+// recoverJob is deferred by the background steps: a panic fails the job instead of crashing the
+// process and leaving the job active.
+func (s *RescoreService) recoverJob(ctx context.Context, jobID uuid.UUID) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Printf("[Rescore] job %s panicked: %v\n%s", jobID, r, debug.Stack())
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil || job == nil {
+		log.Printf("[Rescore] job %s could not be loaded: %v", jobID, err)
+		return
+	}
+	s.fail(ctx, job, fmt.Errorf("panic: %v", r))
+}
+
+// rescoreResponse recomputes a stored response and returns a diff item, or nil when nothing changed.
+func rescoreResponse(jobID uuid.UUID, template *entity.SurveyTemplate, sr *entity.SurveyResponse) (*entity.RescoreJobItem, error) {
+	var answers map[string]interface{}
+	if err := json.Unmarshal(sr.Responses, &answers); err != nil {
+		return nil, fmt.Errorf("decode responses: %w", err)
+	}
+
+	score, category, breakdown, err := CalculateScore(template, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	if sameScore(sr.CalculatedScore, &score) && sr.Category == category {
+		return nil, nil
+	}
+
+	breakdownJSON, _ := json.Marshal(breakdown)
+	return &entity.RescoreJobItem{
+		ID:               uuid.New(),
+		JobID:            jobID,
+		SurveyResponseID: sr.ID,
+		OldScore:         sr.CalculatedScore,
+		NewScore:         score,
+		OldCategory:      sr.Category,
+		NewCategory:      category,
+		OldBreakdown:     sr.ScoreBreakdown,
+		NewBreakdown:     breakdownJSON,
+		CategoryChanged:  sr.Category != category,
+	}, nil
+}
+
+func sameScore(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Abs(*a-*b) < 0.005
+}
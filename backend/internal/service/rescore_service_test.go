@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+func TestRescoreResponse(t *testing.T) {
+	template := &entity.SurveyTemplate{Code: "DAS28_CRP", Questions: json.RawMessage("[]")}
+	answers, _ := json.Marshal(map[string]any{"tjc28": 2.0, "sjc28": 2.0, "crp": 5.0, "gh": 30.0})
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		score        *float64
+		category     string
+		wantItem     bool
+		wantCategory bool
+	}{
+		{"unchanged", f(3.21), "moderate_activity", false, false},
+		{"score drift only", f(3.5), "moderate_activity", true, false},
+		{"category changed", f(2.5), "low_activity", true, true},
+		{"never scored", nil, "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := &entity.SurveyResponse{ID: uuid.New(), Responses: answers, CalculatedScore: tt.score, Category: tt.category}
+			item, err := rescoreResponse(uuid.New(), template, sr)
+			if err != nil {
+				t.Fatalf("rescoreResponse() error = %v", err)
+			}
+			if (item != nil) != tt.wantItem {
+				t.Fatalf("rescoreResponse() item = %v, want item %v", item, tt.wantItem)
+			}
+			if item == nil {
+				return
+			}
+			if item.CategoryChanged != tt.wantCategory {
+				t.Errorf("CategoryChanged = %v, want %v", item.CategoryChanged, tt.wantCategory)
+			}
+			if item.NewScore != 3.21 || item.NewCategory != "moderate_activity" {
+				t.Errorf("new result = %v/%s, want 3.21/moderate_activity", item.NewScore, item.NewCategory)
+			}
+			if item.OldCategory != tt.category {
+				t.Errorf("OldCategory = %s, want original %s", item.OldCategory, tt.category)
+			}
+		})
+	}
+}
+
+type fakeRescoreJobRepo struct {
+	jobs        map[uuid.UUID]*entity.RescoreJob
+	items       []*entity.RescoreJobItem
+	staleBefore time.Time
+}
+
+func (r *fakeRescoreJobRepo) Create(ctx context.Context, job *entity.RescoreJob) (bool, error) {
+	for _, j := range r.jobs {
+		if j.TemplateID == job.TemplateID && j.Status != entity.RescoreStatusApplied && j.Status != entity.RescoreStatusFailed {
+			return false, nil
+		}
+	}
+	r.jobs[job.ID] = job
+	return true, nil
+}
+func (r *fakeRescoreJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.RescoreJob, error) {
+	if j, ok := r.jobs[id]; ok {
+		cp := *j
+		return &cp, nil
+	}
+	return nil, nil
+}
+func (r *fakeRescoreJobRepo) HasActive(ctx context.Context, templateID uuid.UUID) (bool, error) {
+	return false, nil
+}
+func (r *fakeRescoreJobRepo) Update(ctx context.Context, job *entity.RescoreJob) error {
+	cp := *job
+	r.jobs[job.ID] = &cp
+	return nil
+}
+func (r *fakeRescoreJobRepo) TransitionStatus(ctx context.Context, id uuid.UUID, from string, to string) (bool, error) {
+	j := r.jobs[id]
+	if j == nil || j.Status != from {
+		return false, nil
+	}
+	j.Status = to
+	return true, nil
+}
+func (r *fakeRescoreJobRepo) FailStale(ctx context.Context, updatedBefore time.Time, reason string) (int64, error) {
+	r.staleBefore = updatedBefore
+	var n int64
+	for _, j := range r.jobs {
+		if j.Status != entity.RescoreStatusReady && j.Status != entity.RescoreStatusApplied && j.Status != entity.RescoreStatusFailed && j.UpdatedAt.Before(updatedBefore) {
+			j.Status, j.Error = entity.RescoreStatusFailed, reason
+			n++
+		}
+	}
+	return n, nil
+}
+func (r *fakeRescoreJobRepo) AddItems(ctx context.Context, items []*entity.RescoreJobItem) error {
+	r.items = append(r.items, items...)
+	return nil
+}
+func (r *fakeRescoreJobRepo) ListItems(ctx context.Context, jobID uuid.UUID, categoryChangedOnly bool, limit int, offset int) ([]*entity.RescoreJobItem, error) {
+	if offset >= len(r.items) {
+		return nil, nil
+	}
+	return r.items[offset:], nil
+}
+
+// panickingResponseRepo stands in for a bug deep inside a background step.
+type panickingResponseRepo struct {
+	*fakeSurveyResponseRepo
+}
+
+func (r panickingResponseRepo) ListByTemplate(ctx context.Context, templateID uuid.UUID, afterID uuid.UUID, limit int) ([]*entity.SurveyResponse, error) {
+	panic("boom")
+}
+func (r panickingResponseRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.SurveyResponse, error) {
+	panic("boom")
+}
+
+func TestRescoreJobPanicFailsJob(t *testing.T) {
+	template := &entity.SurveyTemplate{ID: uuid.New(), Code: "RCRI"}
+	running := &entity.RescoreJob{ID: uuid.New(), TemplateID: template.ID, Status: entity.RescoreStatusPending}
+	applying := &entity.RescoreJob{ID: uuid.New(), TemplateID: template.ID, Status: entity.RescoreStatusApplying}
+	jobs := &fakeRescoreJobRepo{
+		jobs:  map[uuid.UUID]*entity.RescoreJob{running.ID: running, applying.ID: applying},
+		items: []*entity.RescoreJobItem{{ID: uuid.New(), JobID: applying.ID, SurveyResponseID: uuid.New()}},
+	}
+	svc := NewRescoreService(RescoreDeps{
+		ResponseRepo: panickingResponseRepo{newFakeSurveyResponseRepo(nil)},
+		JobRepo:      jobs,
+	})
+
+	svc.run(running.ID, template)
+	svc.apply(applying, template)
+
+	for _, id := range []uuid.UUID{running.ID, applying.ID} {
+		if j := jobs.jobs[id]; j.Status != entity.RescoreStatusFailed || !strings.Contains(j.Error, "panic: boom") || j.FinishedAt == nil {
+			t.Errorf("job %s = %s (%q), want failed with the panic", id, j.Status, j.Error)
+		}
+	}
+}
+
+func TestRescoreFailStale(t *testing.T) {
+	now := time.Now().UTC()
+	stale := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusRunning, UpdatedAt: now.Add(-time.Hour)}
+	live := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusApplying, UpdatedAt: now.Add(-time.Minute)}
+	ready := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusReady, UpdatedAt: now.Add(-time.Hour)}
+	jobs := &fakeRescoreJobRepo{jobs: map[uuid.UUID]*entity.RescoreJob{stale.ID: stale, live.ID: live, ready.ID: ready}}
+
+	if err := NewRescoreService(RescoreDeps{JobRepo: jobs}).FailStale(context.Background()); err != nil {
+		t.Fatalf("FailStale() error = %v", err)
+	}
+	if cutoff := now.Add(-rescoreJobStaleAfter); jobs.staleBefore.Before(cutoff) || jobs.staleBefore.After(cutoff.Add(time.Minute)) {
+		t.Errorf("FailStale() cutoff = %v, want about %v", jobs.staleBefore, cutoff)
+	}
+	if stale.Status != entity.RescoreStatusFailed || live.Status != entity.RescoreStatusApplying || ready.Status != entity.RescoreStatusReady {
+		t.Errorf("statuses = %s/%s/%s, want failed/applying/ready", stale.Status, live.Status, ready.Status)
+	}
+}
+
+func TestRescoreFailInterrupted(t *testing.T) {
+	now := time.Now().UTC()
+	pending := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusPending, UpdatedAt: now.Add(-time.Second)}
+	applying := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusApplying, UpdatedAt: now.Add(-time.Minute)}
+	ready := &entity.RescoreJob{ID: uuid.New(), Status: entity.RescoreStatusReady, UpdatedAt: now.Add(-time.Hour)}
+	jobs := &fakeRescoreJobRepo{jobs: map[uuid.UUID]*entity.RescoreJob{pending.ID: pending, applying.ID: applying, ready.ID: ready}}
+
+	if err := NewRescoreService(RescoreDeps{JobRepo: jobs}).FailInterrupted(context.Background()); err != nil {
+		t.Fatalf("FailInterrupted() error = %v", err)
+	}
+	// Recently updated jobs are failed too: no worker survives a restart.
+	if pending.Status != entity.RescoreStatusFailed || applying.Status != entity.RescoreStatusFailed || ready.Status != entity.RescoreStatusReady {
+		t.Errorf("statuses = %s/%s/%s, want failed/failed/ready", pending.Status, applying.Status, ready.Status)
+	}
+}
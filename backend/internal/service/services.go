@@ -4,6 +4,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/medical-app/backend/internal/entity"
 	"github.com/medical-app/backend/internal/external"
 	"github.com/medical-app/backend/internal/repository"
 	"github.com/medical-app/backend/pkg/crypto"
@@ -15,6 +16,13 @@ type Services struct {
 	Drug     *DrugService
 	Therapy  *TherapyService
 	AIAdvice *AIAdviceService
+	Indices  *MedicalIndexService
+	Alerts   *AlertService
+	Rescore  *RescoreService
+	AIJobs   *AIJobService
+	Prompts  *PromptService
+	LLMUsage *LLMUsageService
+	Insights *ClinicalInsightService
 }
 
 type Deps struct {
@@ -31,6 +39,18 @@ type Deps struct {
 	YandexIAMToken  string
 	YandexFolderID  string
 	YandexGPTModel  string
+
+	LLMProvider   string
+	OpenAIBaseURL string
+	OpenAIAPIKey  string
+	OpenAIModel   string
+
+	AIWorkers int
+
+	// AdviceLiterature grounds patient advice in PubMed abstracts.
+	AdviceLiterature bool
+	// AdviceCacheTTL is how long generated advice is reused for identical answers; zero disables the cache.
+	AdviceCacheTTL time.Duration
 }
 
 func NewServices(d Deps) *Services {
@@ -38,20 +58,17 @@ func NewServices(d Deps) *Services {
 
 	// Initialize external clients
 	ncbiClient := external.NewNCBIClient(d.NCBIApiKey)
-	var gptClient *external.YandexGPTClient
-	if d.YandexFolderID != "" && (d.YandexGPTApiKey != "" || d.YandexIAMToken != "") {
-		if d.YandexGPTApiKey != "" {
-			gptClient = external.NewYandexGPTClient(d.YandexGPTApiKey, d.YandexFolderID)
-			log.Printf("[Services] YandexGPT client initialized with API key, folder: %s, model: %s", d.YandexFolderID, d.YandexGPTModel)
-		} else {
-			gptClient = external.NewYandexGPTClientWithIAMToken(d.YandexIAMToken, d.YandexFolderID)
-			log.Printf("[Services] YandexGPT client initialized with IAM token, folder: %s", d.YandexFolderID)
-		}
-		if d.YandexGPTModel != "" {
-			gptClient.SetModel(d.YandexGPTModel)
-		}
-	} else {
-		log.Printf("[Services] YandexGPT client NOT initialized (missing YANDEX_FOLDER_ID=%q or API key=%v/IAM=%v)", d.YandexFolderID, d.YandexGPTApiKey != "", d.YandexIAMToken != "")
+	usageSvc := NewLLMUsageService(LLMUsageDeps{Repo: d.Repos.LLMUsage, RoleRepo: d.Repos.Role, UserRepo: d.Repos.User, JobRepo: d.Repos.AIJob})
+	llm := NewLLMProvider(d)
+	if llm != nil {
+		llm = external.WithUsageRecorder(llm, usageSvc.Record)
+	}
+	aiJobSvc := NewAIJobService(AIJobDeps{Repo: d.Repos.AIJob, Usage: usageSvc, Workers: d.AIWorkers})
+	redactor := NewPHIRedactor(d.Repos.AuditLog)
+	promptSvc := NewPromptService(PromptDeps{Repo: d.Repos.PromptTemplate, TemplateRepo: d.Repos.SurveyTemplate, AdviceCache: d.Repos.AdviceCache})
+	var literatureSvc *LiteratureService
+	if d.AdviceLiterature {
+		literatureSvc = NewLiteratureService(LiteratureDeps{NCBIClient: ncbiClient, Repo: d.Repos.Literature})
 	}
 
 	authSvc := NewAuthService(AuthDeps{
@@ -63,18 +80,77 @@ func NewServices(d Deps) *Services {
 		RefreshTTL:       d.JWTRefreshExpiry,
 	})
 
+	alertSvc := NewAlertService(AlertDeps{
+		RuleRepo:    d.Repos.AlertRule,
+		AlertRepo:   d.Repos.Alert,
+		IndexRepo:   d.Repos.MedicalIndex,
+		PatientRepo: d.Repos.Patient,
+	})
+
 	surveySvc := NewSurveyService(SurveyDeps{
 		TemplateRepo: d.Repos.SurveyTemplate,
 		ResponseRepo: d.Repos.SurveyResponse,
-		GPTClient:    gptClient,
+		PatientRepo:  d.Repos.Patient,
+		IndexRepo:    d.Repos.MedicalIndex,
+		Alerts:       alertSvc,
+		LLM:          llm,
+		Jobs:         aiJobSvc,
+		Prompts:      promptSvc,
+		Redactor:     redactor,
+		Usage:        usageSvc,
 	})
 
 	drugSvc := NewDrugService(DrugDeps{
-		Repo:       d.Repos.Drug,
-		NCBIClient: ncbiClient,
+		Repo:            d.Repos.Drug,
+		ATCRepo:         d.Repos.ATC,
+		InteractionRepo: d.Repos.DrugInteraction,
+		PeriopRepo:      d.Repos.Perioperative,
+		NCBIClient:      ncbiClient,
+	})
+	therapySvc := NewTherapyService(TherapyDeps{Repo: d.Repos.TherapyLog, PatientRepo: d.Repos.Patient, Drugs: drugSvc})
+	aiAdviceSvc := NewAIAdviceService(AIAdviceDeps{
+		TemplateRepo: d.Repos.SurveyTemplate,
+		PatientRepo:  d.Repos.Patient,
+		AdviceRepo:   d.Repos.AIAdvice,
+		MessageRepo:  d.Repos.AdviceMessage,
+		FeedbackRepo: d.Repos.AdviceFeedback,
+		Cache:        d.Repos.AdviceCache,
+		CacheTTL:     d.AdviceCacheTTL,
+		LLM:          llm,
+		Jobs:         aiJobSvc,
+		Prompts:      promptSvc,
+		Safety:       NewAdviceSafetyChecker(d.Repos.Drug),
+		Redactor:     redactor,
+		Literature:   literatureSvc,
+		Usage:        usageSvc,
+	})
+
+	insightSvc := NewClinicalInsightService(ClinicalInsightDeps{
+		PatientRepo:  d.Repos.Patient,
+		TemplateRepo: d.Repos.SurveyTemplate,
+		IndexRepo:    d.Repos.MedicalIndex,
+		TherapyRepo:  d.Repos.TherapyLog,
+		InsightRepo:  d.Repos.ClinicalInsight,
+		LLM:          llm,
+		Jobs:         aiJobSvc,
+		Prompts:      promptSvc,
+		Redactor:     redactor,
+		Usage:        usageSvc,
+	})
+
+	aiJobSvc.Register(entity.AIJobSurveyInterpretation, surveySvc)
+	aiJobSvc.Register(entity.AIJobPatientAdvice, aiAdviceSvc)
+	aiJobSvc.Register(entity.AIJobClinicalInsight, insightSvc)
+
+	indexSvc := NewMedicalIndexService(MedicalIndexDeps{Repo: d.Repos.MedicalIndex, PatientRepo: d.Repos.Patient})
+
+	rescoreSvc := NewRescoreService(RescoreDeps{
+		TemplateRepo: d.Repos.SurveyTemplate,
+		ResponseRepo: d.Repos.SurveyResponse,
+		IndexRepo:    d.Repos.MedicalIndex,
+		JobRepo:      d.Repos.RescoreJob,
+		Alerts:       alertSvc,
 	})
-	therapySvc := NewTherapyService(TherapyDeps{Repo: d.Repos.TherapyLog, PatientRepo: d.Repos.Patient})
-	aiAdviceSvc := NewAIAdviceService(AIAdviceDeps{TemplateRepo: d.Repos.SurveyTemplate, PatientRepo: d.Repos.Patient, AdviceRepo: d.Repos.AIAdvice, GPTClient: gptClient})
 
 	_ = encryptor // will be used when PatientService is implemented
 
@@ -84,5 +160,47 @@ func NewServices(d Deps) *Services {
 		Drug:     drugSvc,
 		Therapy:  therapySvc,
 		AIAdvice: aiAdviceSvc,
+		Indices:  indexSvc,
+		Alerts:   alertSvc,
+		Rescore:  rescoreSvc,
+		AIJobs:   aiJobSvc,
+		Prompts:  promptSvc,
+		LLMUsage: usageSvc,
+		Insights: insightSvc,
+	}
+}
+
+// NewLLMProvider selects the text-generation backend from configuration.
+// It returns nil when no provider is usable; services then fall back to rule-based text.
+func NewLLMProvider(d Deps) external.LLMProvider {
+	switch d.LLMProvider {
+	case "stub":
+		log.Printf("[Services] LLM provider: deterministic local stub")
+		return external.NewStubLLM()
+	case "openai":
+		if d.OpenAIBaseURL == "" || d.OpenAIModel == "" {
+			log.Printf("[Services] OpenAI-compatible provider NOT initialized (missing OPENAI_BASE_URL=%q or OPENAI_MODEL=%q)", d.OpenAIBaseURL, d.OpenAIModel)
+			return nil
+		}
+		log.Printf("[Services] LLM provider: OpenAI-compatible at %s, model: %s", d.OpenAIBaseURL, d.OpenAIModel)
+		return external.NewOpenAICompatibleClient(d.OpenAIBaseURL, d.OpenAIAPIKey, d.OpenAIModel)
+	}
+
+	if d.YandexFolderID == "" || (d.YandexGPTApiKey == "" && d.YandexIAMToken == "") {
+		log.Printf("[Services] YandexGPT client NOT initialized (missing YANDEX_FOLDER_ID=%q or API key=%v/IAM=%v)", d.YandexFolderID, d.YandexGPTApiKey != "", d.YandexIAMToken != "")
+		return nil
+	}
+
+	var gptClient *external.YandexGPTClient
+	if d.YandexGPTApiKey != "" {
+		gptClient = external.NewYandexGPTClient(d.YandexGPTApiKey, d.YandexFolderID)
+		log.Printf("[Services] YandexGPT client initialized with API key, folder: %s, model: %s", d.YandexFolderID, d.YandexGPTModel)
+	} else {
+		gptClient = external.NewYandexGPTClientWithIAMToken(d.YandexIAMToken, d.YandexFolderID)
+		log.Printf("[Services] YandexGPT client initialized with IAM token, folder: %s", d.YandexFolderID)
+	}
+	if d.YandexGPTModel != "" {
+		gptClient.SetModel(d.YandexGPTModel)
 	}
+	return gptClient
 }
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrSurveyAccessDenied     = errors.New("access to this survey response is not allowed")
+	ErrSurveyRevisionConflict = errors.New("survey response was amended concurrently")
+)
+
+// GetResponse returns the latest revision of a response. Staff may read any response,
+// other users only responses of their own patient record. withHistory loads the revision chain.
+func (s *SurveyService) GetResponse(ctx context.Context, userID uuid.UUID, staff bool, responseID uuid.UUID, withHistory bool) (*entity.SurveyResponse, error) {
+	sr, err := s.accessibleResponse(ctx, userID, staff, responseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if withHistory {
+		revisions, err := s.responseRepo.ListRevisions(ctx, sr.ID)
+		if err != nil {
+			return nil, err
+		}
+		sr.Revisions = revisions
+	}
+	return sr, nil
+}
+
+// AmendResponse replaces the answers of a response, re-scores it and appends an immutable revision.
+// A reviewed response goes back to "submitted" so the attending doctor sees the correction, and a
+// category override is dropped when the engine category changes. The
+// patient may amend their own responses; a reviewer only those of patients they attend.
+func (s *SurveyService) AmendResponse(ctx context.Context, userID uuid.UUID, reviewer bool, responseID uuid.UUID, req entity.SurveyResponseAmendRequest) (*entity.SurveyResponse, *entity.SurveyResponseRevision, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	v := validator.New()
+	v.Required("reason", req.Reason, "reason is required")
+	if len(req.Responses) == 0 {
+		v.AddError("responses", "responses are required")
+	}
+	if v.HasErrors() {
+		return nil, nil, v.Errors()
+	}
+
+	sr, err := s.amendableResponse(ctx, userID, reviewer, responseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.ExpectedRevision != 0 && req.ExpectedRevision != sr.Revision {
+		return nil, nil, ErrSurveyRevisionConflict
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, sr.TemplateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if template == nil {
+		return nil, nil, ErrSurveyTemplateNotFound
+	}
+
+	score, category, breakdown, err := CalculateScore(template, req.Responses)
+	if err != nil {
+		return nil, nil, err
+	}
+	responsesJSON, _ := json.Marshal(req.Responses)
+	breakdownJSON, _ := json.Marshal(breakdown)
+
+	status := sr.Status
+	if status == entity.SurveyStatusReviewed {
+		status = entity.SurveyStatusSubmitted
+	}
+	// A clinician override was a judgement on the old engine category; once that changes the
+	// override no longer applies and the response needs a fresh review.
+	clearOverride := sr.CategoryOverride != "" && category != sr.Category
+
+	rev := &entity.SurveyResponseRevision{
+		ID:               uuid.New(),
+		SurveyResponseID: sr.ID,
+		Revision:         sr.Revision + 1,
+		AmendedBy:        userID,
+		Reason:           req.Reason,
+		OldResponses:     sr.Responses,
+		NewResponses:     responsesJSON,
+		OldScore:         sr.CalculatedScore,
+		NewScore:         score,
+		OldCategory:      sr.Category,
+		NewCategory:      category,
+		CreatedAt:        time.Now().UTC(),
+	}
+	interpretation := defaultInterpretation(template, category)
+	aiStatus := ""
+	if s.aiEnabled() {
+		aiStatus = entity.AIStatusPending
+	}
+
+	ok, err := s.responseRepo.Amend(ctx, rev, status, interpretation, aiStatus, breakdownJSON, clearOverride)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrSurveyRevisionConflict
+	}
+
+	sr.Responses = responsesJSON
+	sr.CalculatedScore = &score
+	sr.Category = category
+	sr.ScoreBreakdown = breakdownJSON
+	sr.Interpretation = interpretation
+	sr.Status = status
+	sr.Revision = rev.Revision
+	sr.AIStatus = aiStatus
+	if clearOverride {
+		sr.CategoryOverride, sr.OverrideReason = "", ""
+	}
+
+	// Keep the index history in line with the corrected score.
+	if sr.Status != entity.SurveyStatusDraft {
+		if err := s.indexRepo.UpdateValueBySurveyResponse(ctx, sr.ID, score, sr.EffectiveCategory()); err != nil {
+			return nil, nil, err
+		}
+		reevaluateAlerts(ctx, s.alerts, s.indexRepo, sr.ID, "[Survey]")
+	}
+
+	if aiStatus == entity.AIStatusPending {
+		s.enqueueInterpretation(ctx, userID, sr, template, score, category, breakdown)
+	}
+
+	sr.Template = template
+	return sr, rev, nil
+}
+
+func (s *SurveyService) accessibleResponse(ctx context.Context, userID uuid.UUID, staff bool, responseID uuid.UUID) (*entity.SurveyResponse, error) {
+	sr, err := s.responseRepo.GetByID(ctx, responseID)
+	if err != nil {
+		return nil, err
+	}
+	if sr == nil {
+		return nil, ErrSurveyResponseNotFound
+	}
+	if staff {
+		return sr, nil
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, sr.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if patient == nil || patient.UserID != userID {
+		return nil, ErrSurveyAccessDenied
+	}
+	return sr, nil
+}
+
+// amendableResponse loads a response the user may change: one of their own patient record, or, for
+// a reviewer, one of a patient they are the attending doctor of.
+func (s *SurveyService) amendableResponse(ctx context.Context, userID uuid.UUID, reviewer bool, responseID uuid.UUID) (*entity.SurveyResponse, error) {
+	sr, err := s.responseRepo.GetByID(ctx, responseID)
+	if err != nil {
+		return nil, err
+	}
+	if sr == nil {
+		return nil, ErrSurveyResponseNotFound
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, sr.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case patient != nil && patient.UserID == userID:
+		return sr, nil
+	case !reviewer:
+		return nil, ErrSurveyAccessDenied
+	case patient == nil || patient.AttendingDoctorID == nil || *patient.AttendingDoctorID != userID:
+		return nil, ErrNotAttendingDoctor
+	}
+	return sr, nil
+}
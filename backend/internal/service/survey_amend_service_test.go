@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+)
+
+func TestAmendResponse(t *testing.T) {
+	rcri := &entity.SurveyTemplate{ID: uuid.New(), Code: "RCRI", Questions: json.RawMessage("[]")}
+	doctorID := uuid.New()
+	patient := &entity.Patient{ID: uuid.New(), UserID: uuid.New(), AttendingDoctorID: &doctorID}
+	answers, _ := json.Marshal(map[string]any{"ihd": true})
+	score := 1.0
+	sr := &entity.SurveyResponse{
+		ID: uuid.New(), PatientID: patient.ID, TemplateID: rcri.ID, Status: entity.SurveyStatusReviewed,
+		Responses: answers, CalculatedScore: &score, Category: "class_ii", Revision: 1,
+		CategoryOverride: "class_iv", OverrideReason: "нестабильная стенокардия",
+	}
+	responses := newFakeSurveyResponseRepo([]*entity.Patient{patient}, sr)
+
+	order, _ := json.Marshal([]string{"class_i", "class_ii", "class_iii", "class_iv"})
+	rule := &entity.AlertRule{ID: uuid.New(), IndexType: "RCRI", RuleType: entity.AlertRuleCategoryChange, Direction: entity.AlertDirectionRise, CategoryOrder: order, IsActive: true}
+	indices := &fakeIndexRepo{items: []*entity.MedicalIndex{
+		{ID: uuid.New(), PatientID: patient.ID, IndexType: "RCRI", Value: 1, Category: "class_ii", RecordedAt: time.Now().UTC().Add(-time.Hour)},
+		{ID: uuid.New(), PatientID: patient.ID, IndexType: "RCRI", Value: 1, Category: "class_ii", SurveyResponseID: &sr.ID, RecordedAt: time.Now().UTC()},
+	}}
+	patients := &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{patient.UserID: patient}}
+	alerts := &fakeAlertRepo{}
+	svc := NewSurveyService(SurveyDeps{
+		TemplateRepo: &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{"RCRI": rcri}},
+		ResponseRepo: responses,
+		PatientRepo:  patients,
+		IndexRepo:    indices,
+		Alerts: NewAlertService(AlertDeps{
+			RuleRepo:    &fakeAlertRuleRepo{rules: []*entity.AlertRule{rule}},
+			AlertRepo:   alerts,
+			IndexRepo:   indices,
+			PatientRepo: patients,
+		}),
+	})
+	ctx := context.Background()
+	amend := func(answers map[string]any, expected int) entity.SurveyResponseAmendRequest {
+		return entity.SurveyResponseAmendRequest{Responses: answers, Reason: "ошибка ввода", ExpectedRevision: expected}
+	}
+
+	// Someone else without the review permission, and a reviewer who does not attend the patient.
+	if _, _, err := svc.AmendResponse(ctx, uuid.New(), false, sr.ID, amend(map[string]any{"ihd": true}, 0)); !errors.Is(err, ErrSurveyAccessDenied) {
+		t.Fatalf("amend by another user error = %v, want ErrSurveyAccessDenied", err)
+	}
+	if _, _, err := svc.AmendResponse(ctx, uuid.New(), true, sr.ID, amend(map[string]any{"ihd": true}, 0)); !errors.Is(err, ErrNotAttendingDoctor) {
+		t.Fatalf("amend by a foreign reviewer error = %v, want ErrNotAttendingDoctor", err)
+	}
+
+	// The patient adds a risk factor: re-scored, back in the review queue, index and alerts follow.
+	updated, rev, err := svc.AmendResponse(ctx, patient.UserID, false, sr.ID, amend(map[string]any{"ihd": true, "chf": true}, 1))
+	if err != nil {
+		t.Fatalf("AmendResponse() error = %v", err)
+	}
+	if updated.Revision != 2 || *updated.CalculatedScore != 2 || updated.Category != "class_iii" || updated.Status != entity.SurveyStatusSubmitted {
+		t.Fatalf("amended = revision %d, score %v, category %s, status %s", updated.Revision, *updated.CalculatedScore, updated.Category, updated.Status)
+	}
+	if updated.CategoryOverride != "" || updated.OverrideReason != "" || sr.CategoryOverride != "" {
+		t.Fatalf("override after a category change = %q (%q), want cleared", updated.CategoryOverride, updated.OverrideReason)
+	}
+	if rev.Revision != 2 || *rev.OldScore != 1 || rev.OldCategory != "class_ii" || rev.NewCategory != "class_iii" || rev.AmendedBy != patient.UserID {
+		t.Fatalf("revision = %+v", rev)
+	}
+	if idx := indices.items[1]; idx.Value != 2 || idx.Category != "class_iii" {
+		t.Fatalf("index = %v/%s, want 2/class_iii", idx.Value, idx.Category)
+	}
+	if len(alerts.alerts) != 1 || alerts.alerts[0].Status != entity.AlertStatusOpen {
+		t.Fatalf("alerts after amend = %+v, want one open alert", alerts.alerts)
+	}
+
+	// A stale revision is a conflict.
+	if _, _, err := svc.AmendResponse(ctx, patient.UserID, false, sr.ID, amend(map[string]any{"ihd": true}, 1)); !errors.Is(err, ErrSurveyRevisionConflict) {
+		t.Fatalf("amend of a stale revision error = %v, want ErrSurveyRevisionConflict", err)
+	}
+
+	// The attending doctor corrects it back; the alert is cleared and the history keeps both revisions.
+	if _, _, err := svc.AmendResponse(ctx, doctorID, true, sr.ID, amend(map[string]any{"ihd": true}, 2)); err != nil {
+		t.Fatalf("amend by the attending doctor error = %v", err)
+	}
+	if alerts.alerts[0].Status != entity.AlertStatusResolved {
+		t.Fatalf("alert after correction = %s, want resolved", alerts.alerts[0].Status)
+	}
+	got, err := svc.GetResponse(ctx, patient.UserID, false, sr.ID, true)
+	if err != nil {
+		t.Fatalf("GetResponse() error = %v", err)
+	}
+	if len(got.Revisions) != 2 || got.Revisions[0].NewCategory != "class_iii" || got.Revisions[1].AmendedBy != doctorID || got.Revisions[1].NewCategory != "class_ii" {
+		t.Fatalf("revisions = %+v", got.Revisions)
+	}
+}
+
+func TestAmendResponseKeepsOverrideForSameCategory(t *testing.T) {
+	rcri := &entity.SurveyTemplate{ID: uuid.New(), Code: "RCRI", Questions: json.RawMessage("[]")}
+	patient := &entity.Patient{ID: uuid.New(), UserID: uuid.New()}
+	answers, _ := json.Marshal(map[string]any{"ihd": true})
+	score := 1.0
+	sr := &entity.SurveyResponse{
+		ID: uuid.New(), PatientID: patient.ID, TemplateID: rcri.ID, Status: entity.SurveyStatusReviewed,
+		Responses: answers, CalculatedScore: &score, Category: "class_ii", Revision: 1,
+		CategoryOverride: "class_iii", OverrideReason: "нестабильная стенокардия",
+	}
+	svc := NewSurveyService(SurveyDeps{
+		TemplateRepo: &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{"RCRI": rcri}},
+		ResponseRepo: newFakeSurveyResponseRepo([]*entity.Patient{patient}, sr),
+		PatientRepo:  &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{patient.UserID: patient}},
+		IndexRepo:    &fakeIndexRepo{},
+	})
+
+	req := entity.SurveyResponseAmendRequest{Responses: map[string]any{"chf": true}, Reason: "ошибка ввода"}
+	updated, _, err := svc.AmendResponse(context.Background(), patient.UserID, false, sr.ID, req)
+	if err != nil {
+		t.Fatalf("AmendResponse() error = %v", err)
+	}
+	if updated.Category != "class_ii" || updated.CategoryOverride != "class_iii" || updated.Status != entity.SurveyStatusSubmitted {
+		t.Fatalf("amended = category %s, override %q, status %s", updated.Category, updated.CategoryOverride, updated.Status)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+var (
+	ErrSurveyResponseNotFound = errors.New("survey response not found")
+	ErrNotAttendingDoctor     = errors.New("patient is not attended by this doctor")
+)
+
+// reviewQueueStatuses are the statuses a reviewer can list; drafts are never shown.
+var reviewQueueStatuses = []string{entity.SurveyStatusSubmitted, entity.SurveyStatusReviewed}
+
+// ListReviewQueue returns responses of the doctor's patients awaiting review (status "submitted" by default).
+func (s *SurveyService) ListReviewQueue(ctx context.Context, doctorID uuid.UUID, status string, limit int, offset int) ([]*entity.SurveyResponse, error) {
+	if doctorID == uuid.Nil {
+		return nil, errors.New("doctor_id is required")
+	}
+	status = strings.TrimSpace(status)
+	if status == "" {
+		status = entity.SurveyStatusSubmitted
+	}
+	v := validator.New()
+	v.OneOf("status", status, reviewQueueStatuses, "status must be one of: "+strings.Join(reviewQueueStatuses, ", "))
+	if v.HasErrors() {
+		return nil, v.Errors()
+	}
+	return s.responseRepo.ListForDoctor(ctx, doctorID, status, limit, offset)
+}
+
+// ReviewResponse marks a response reviewed by its attending doctor, optionally overriding the category.
+// It returns the updated response together with a snapshot taken before the change (for auditing).
+func (s *SurveyService) ReviewResponse(ctx context.Context, reviewerID uuid.UUID, responseID uuid.UUID, req entity.SurveyReviewRequest) (*entity.SurveyResponse, *entity.SurveyResponse, error) {
+	req.Notes = strings.TrimSpace(req.Notes)
+	req.OverrideCategory = strings.TrimSpace(req.OverrideCategory)
+	req.Justification = strings.TrimSpace(req.Justification)
+
+	v := validator.New()
+	if req.OverrideCategory != "" {
+		v.MaxLength("override_category", req.OverrideCategory, 50, "override_category must be at most 50 characters")
+		v.Required("justification", req.Justification, "justification is required when overriding the category")
+	}
+	if v.HasErrors() {
+		return nil, nil, v.Errors()
+	}
+
+	sr, err := s.responseRepo.GetByID(ctx, responseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sr == nil {
+		return nil, nil, ErrSurveyResponseNotFound
+	}
+	if sr.Status == entity.SurveyStatusDraft {
+		v.AddError("status", "draft responses cannot be reviewed")
+		return nil, nil, v.Errors()
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, sr.PatientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if patient == nil || patient.AttendingDoctorID == nil || *patient.AttendingDoctorID != reviewerID {
+		return nil, nil, ErrNotAttendingDoctor
+	}
+
+	if req.OverrideCategory != "" {
+		template, err := s.templateRepo.GetByID(ctx, sr.TemplateID)
+		if err != nil {
+			return nil, nil, err
+		}
+		var categories []string
+		if template != nil {
+			categories = ScoreCategories(template)
+		}
+		if len(categories) == 0 {
+			v.AddError("override_category", "this survey has no categories to override")
+		} else {
+			v.OneOf("override_category", req.OverrideCategory, categories, "override_category must be one of: "+strings.Join(categories, ", "))
+		}
+		if v.HasErrors() {
+			return nil, nil, v.Errors()
+		}
+	}
+
+	previous := *sr
+
+	// Overriding with the engine's own category is not an override.
+	override := req.OverrideCategory
+	reason := req.Justification
+	if override == sr.Category {
+		override = ""
+		reason = ""
+	}
+
+	// The index category follows the effective category; it is written with the review.
+	indexCategory := ""
+	if override != previous.CategoryOverride {
+		indexCategory = override
+		if indexCategory == "" {
+			indexCategory = sr.Category
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := s.responseRepo.UpdateReview(ctx, sr.ID, reviewerID, now, req.Notes, override, reason, indexCategory); err != nil {
+		return nil, nil, err
+	}
+
+	sr.Status = entity.SurveyStatusReviewed
+	sr.ReviewedBy = &reviewerID
+	sr.ReviewedAt = &now
+	sr.Notes = req.Notes
+	sr.CategoryOverride = override
+	sr.OverrideReason = reason
+	return sr, &previous, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeSurveyResponseRepo struct {
+	items     map[uuid.UUID]*entity.SurveyResponse
+	patients  map[uuid.UUID]*entity.Patient
+	revisions []*entity.SurveyResponseRevision
+	listed    string
+	// indices receives the index category written with a review.
+	indices *fakeIndexRepo
+}
+
+func newFakeSurveyResponseRepo(patients []*entity.Patient, items ...*entity.SurveyResponse) *fakeSurveyResponseRepo {
+	r := &fakeSurveyResponseRepo{items: map[uuid.UUID]*entity.SurveyResponse{}, patients: map[uuid.UUID]*entity.Patient{}}
+	for _, p := range patients {
+		r.patients[p.ID] = p
+	}
+	for _, sr := range items {
+		r.items[sr.ID] = sr
+	}
+	return r
+}
+
+func (r *fakeSurveyResponseRepo) Create(ctx context.Context, sr *entity.SurveyResponse, idx *entity.MedicalIndex) error {
+	r.items[sr.ID] = sr
+	if idx != nil && r.indices != nil {
+		r.indices.items = append(r.indices.items, idx)
+	}
+	return nil
+}
+func (r *fakeSurveyResponseRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.SurveyResponse, error) {
+	if sr, ok := r.items[id]; ok {
+		cp := *sr
+		return &cp, nil
+	}
+	return nil, nil
+}
+func (r *fakeSurveyResponseRepo) ListByPatient(ctx context.Context, patientID uuid.UUID, limit int) ([]*entity.SurveyResponse, error) {
+	return nil, nil
+}
+func (r *fakeSurveyResponseRepo) UpdateCalculated(ctx context.Context, id uuid.UUID, score float64, category string, interpretation string, aiSummary string, breakdown any) error {
+	return nil
+}
+func (r *fakeSurveyResponseRepo) ListForDoctor(ctx context.Context, doctorID uuid.UUID, status string, limit int, offset int) ([]*entity.SurveyResponse, error) {
+	r.listed = status
+	out := make([]*entity.SurveyResponse, 0)
+	for _, sr := range r.items {
+		p := r.patients[sr.PatientID]
+		if p != nil && p.AttendingDoctorID != nil && *p.AttendingDoctorID == doctorID && sr.Status == status {
+			out = append(out, sr)
+		}
+	}
+	return out, nil
+}
+func (r *fakeSurveyResponseRepo) UpdateReview(ctx context.Context, id uuid.UUID, reviewedBy uuid.UUID, reviewedAt time.Time, notes string, categoryOverride string, overrideReason string, indexCategory string) error {
+	sr := r.items[id]
+	sr.Status, sr.ReviewedBy, sr.ReviewedAt, sr.Notes = entity.SurveyStatusReviewed, &reviewedBy, &reviewedAt, notes
+	sr.CategoryOverride, sr.OverrideReason = categoryOverride, overrideReason
+	if indexCategory != "" && r.indices != nil {
+		if idx, _ := r.indices.GetBySurveyResponse(ctx, id); idx != nil {
+			idx.Category = indexCategory
+		}
+	}
+	return nil
+}
+func (r *fakeSurveyResponseRepo) ListByTemplate(ctx context.Context, templateID uuid.UUID, afterID uuid.UUID, limit int) ([]*entity.SurveyResponse, error) {
+	return nil, nil
+}
+func (r *fakeSurveyResponseRepo) UpdateScore(ctx context.Context, id uuid.UUID, score float64, category string, interpretation string, breakdown json.RawMessage) error {
+	return nil
+}
+func (r *fakeSurveyResponseRepo) Amend(ctx context.Context, rev *entity.SurveyResponseRevision, status string, interpretation string, aiStatus string, breakdown json.RawMessage, clearOverride bool) (bool, error) {
+	sr := r.items[rev.SurveyResponseID]
+	if sr == nil || sr.Revision != rev.Revision-1 {
+		return false, nil
+	}
+	r.revisions = append(r.revisions, rev)
+	sr.Revision = rev.Revision
+	score := rev.NewScore
+	sr.Responses, sr.CalculatedScore, sr.Category = rev.NewResponses, &score, rev.NewCategory
+	sr.Status, sr.Interpretation, sr.AIStatus, sr.ScoreBreakdown = status, interpretation, aiStatus, breakdown
+	if clearOverride {
+		sr.CategoryOverride, sr.OverrideReason = "", ""
+	}
+	return true, nil
+}
+func (r *fakeSurveyResponseRepo) UpdateAIResult(ctx context.Context, id uuid.UUID, revision int, interpretation string, aiStatus string) (bool, error) {
+	return false, nil
+}
+func (r *fakeSurveyResponseRepo) ListRevisions(ctx context.Context, responseID uuid.UUID) ([]*entity.SurveyResponseRevision, error) {
+	var out []*entity.SurveyResponseRevision
+	for _, rev := range r.revisions {
+		if rev.SurveyResponseID == responseID {
+			out = append(out, rev)
+		}
+	}
+	return out, nil
+}
+
+func newReviewTestService(t *testing.T) (*SurveyService, *fakeSurveyResponseRepo, *fakeIndexRepo, uuid.UUID, *entity.SurveyResponse) {
+	t.Helper()
+	doctorID := uuid.New()
+	mine := &entity.Patient{ID: uuid.New(), UserID: uuid.New(), AttendingDoctorID: &doctorID}
+	other := &entity.Patient{ID: uuid.New(), UserID: uuid.New()}
+	template := &entity.SurveyTemplate{ID: uuid.New(), Code: "RCRI"}
+	submitted := &entity.SurveyResponse{ID: uuid.New(), TemplateID: template.ID, PatientID: mine.ID, Status: entity.SurveyStatusSubmitted, Category: "class_ii"}
+	responses := newFakeSurveyResponseRepo([]*entity.Patient{mine, other},
+		submitted,
+		&entity.SurveyResponse{ID: uuid.New(), PatientID: mine.ID, Status: entity.SurveyStatusDraft},
+		&entity.SurveyResponse{ID: uuid.New(), PatientID: mine.ID, Status: entity.SurveyStatusReviewed},
+		&entity.SurveyResponse{ID: uuid.New(), PatientID: other.ID, Status: entity.SurveyStatusSubmitted},
+	)
+	indices := &fakeIndexRepo{items: []*entity.MedicalIndex{{ID: uuid.New(), PatientID: mine.ID, Category: "class_ii", SurveyResponseID: &submitted.ID}}}
+	responses.indices = indices
+	svc := NewSurveyService(SurveyDeps{
+		TemplateRepo: &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{template.Code: template}},
+		ResponseRepo: responses,
+		PatientRepo:  &fakePatientRepo{byUser: map[uuid.UUID]*entity.Patient{mine.UserID: mine, other.UserID: other}},
+		IndexRepo:    indices,
+	})
+	return svc, responses, indices, doctorID, submitted
+}
+
+func TestScoreCategoriesMatchEngine(t *testing.T) {
+	for _, code := range []string{"RCRI", "GOLDMAN", "CAPRINI"} {
+		if got, want := ScoreCategories(&entity.SurveyTemplate{Code: code}), engineCategories(t, code); !slices.Equal(got, want) {
+			t.Errorf("ScoreCategories(%s) = %v, engine assigns %v", code, got, want)
+		}
+	}
+	asa := ScoreCategories(&entity.SurveyTemplate{Code: "ASA"})
+	for _, answers := range []map[string]any{{"asa_class": 3.0}, {"asa_class": 4.0, "is_emergency": true}, {"asa_class": 6.0}} {
+		_, category, _, _ := CalculateScore(&entity.SurveyTemplate{Code: "ASA", Questions: json.RawMessage("[]")}, answers)
+		if !slices.Contains(asa, category) {
+			t.Errorf("ASA category %q missing from %v", category, asa)
+		}
+	}
+	if got := ScoreCategories(&entity.SurveyTemplate{Code: "CUSTOM"}); got != nil {
+		t.Errorf("ScoreCategories(generic) = %v, want nil", got)
+	}
+}
+
+func TestListReviewQueue(t *testing.T) {
+	svc, responses, _, doctorID, submitted := newReviewTestService(t)
+	ctx := context.Background()
+
+	// Only the doctor's own patients, submitted by default.
+	items, err := svc.ListReviewQueue(ctx, doctorID, "", 50, 0)
+	if err != nil {
+		t.Fatalf("ListReviewQueue() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ID != submitted.ID || responses.listed != entity.SurveyStatusSubmitted {
+		t.Fatalf("queue = %+v (status %q), want the one submitted response", items, responses.listed)
+	}
+
+	if items, err := svc.ListReviewQueue(ctx, doctorID, " reviewed ", 50, 0); err != nil || len(items) != 1 {
+		t.Fatalf("ListReviewQueue(reviewed) = %d items, error %v", len(items), err)
+	}
+
+	for _, status := range []string{entity.SurveyStatusDraft, "approved", "submitted' OR 1=1"} {
+		var ve validator.ValidationErrors
+		if _, err := svc.ListReviewQueue(ctx, doctorID, status, 50, 0); !errors.As(err, &ve) || ve[0].Field != "status" {
+			t.Errorf("ListReviewQueue(%q) error = %v, want status validation error", status, err)
+		}
+	}
+}
+
+func TestReviewResponseTransitions(t *testing.T) {
+	svc, responses, indices, doctorID, submitted := newReviewTestService(t)
+	ctx := context.Background()
+
+	updated, previous, err := svc.ReviewResponse(ctx, doctorID, submitted.ID, entity.SurveyReviewRequest{Notes: " ok "})
+	if err != nil {
+		t.Fatalf("ReviewResponse() error = %v", err)
+	}
+	if previous.Status != entity.SurveyStatusSubmitted || updated.Status != entity.SurveyStatusReviewed || *updated.ReviewedBy != doctorID || updated.Notes != "ok" {
+		t.Fatalf("review: previous = %+v, updated = %+v", previous, updated)
+	}
+
+	// A reviewed response can be reviewed again to override its category; the index follows.
+	updated, _, err = svc.ReviewResponse(ctx, doctorID, submitted.ID, entity.SurveyReviewRequest{OverrideCategory: "class_iii", Justification: "ЭКГ"})
+	if err != nil || updated.CategoryOverride != "class_iii" || indices.items[0].Category != "class_iii" {
+		t.Fatalf("override: updated = %+v, index category %q, error %v", updated, indices.items[0].Category, err)
+	}
+	// Overriding with the engine category clears the override.
+	updated, _, err = svc.ReviewResponse(ctx, doctorID, submitted.ID, entity.SurveyReviewRequest{OverrideCategory: "class_ii", Justification: "пересмотр"})
+	if err != nil || updated.CategoryOverride != "" || responses.items[submitted.ID].OverrideReason != "" || indices.items[0].Category != "class_ii" {
+		t.Fatalf("clear override: updated = %+v, index category %q, error %v", updated, indices.items[0].Category, err)
+	}
+
+	var ve validator.ValidationErrors
+	if _, _, err := svc.ReviewResponse(ctx, doctorID, submitted.ID, entity.SurveyReviewRequest{OverrideCategory: "class_iv"}); !errors.As(err, &ve) || ve[0].Field != "justification" {
+		t.Fatalf("override without justification error = %v", err)
+	}
+
+	for _, category := range []string{"Класс III", "class_v", "asa_3"} {
+		_, _, err := svc.ReviewResponse(ctx, doctorID, submitted.ID, entity.SurveyReviewRequest{OverrideCategory: category, Justification: "ЭКГ"})
+		if !errors.As(err, &ve) || ve[0].Field != "override_category" {
+			t.Fatalf("override with %q error = %v, want override_category validation error", category, err)
+		}
+	}
+	if responses.items[submitted.ID].CategoryOverride != "" || indices.items[0].Category != "class_ii" {
+		t.Fatalf("rejected override was stored: %+v, index category %q", responses.items[submitted.ID], indices.items[0].Category)
+	}
+
+	var draft, foreign uuid.UUID
+	for id, sr := range responses.items {
+		switch {
+		case sr.Status == entity.SurveyStatusDraft:
+			draft = id
+		case responses.patients[sr.PatientID].AttendingDoctorID == nil:
+			foreign = id
+		}
+	}
+	if _, _, err := svc.ReviewResponse(ctx, doctorID, draft, entity.SurveyReviewRequest{}); !errors.As(err, &ve) || ve[0].Field != "status" {
+		t.Fatalf("review of a draft error = %v, want status validation error", err)
+	}
+	if _, _, err := svc.ReviewResponse(ctx, doctorID, foreign, entity.SurveyReviewRequest{}); !errors.Is(err, ErrNotAttendingDoctor) {
+		t.Fatalf("review of another doctor's patient error = %v, want ErrNotAttendingDoctor", err)
+	}
+	if _, _, err := svc.ReviewResponse(ctx, doctorID, uuid.New(), entity.SurveyReviewRequest{}); !errors.Is(err, ErrSurveyResponseNotFound) {
+		t.Fatalf("review of unknown response error = %v, want ErrSurveyResponseNotFound", err)
+	}
+}
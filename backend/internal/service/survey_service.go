@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"time"
 
@@ -18,20 +19,41 @@ import (
 type SurveyService struct {
 	templateRepo repository.SurveyTemplateRepository
 	responseRepo repository.SurveyResponseRepository
-	gptClient    *external.YandexGPTClient
+	patientRepo  repository.PatientRepository
+	indexRepo    repository.MedicalIndexRepository
+	alerts       *AlertService
+	llm          external.LLMProvider
+	jobs         *AIJobService
+	prompts      *PromptService
+	redactor     *PHIRedactor
+	usage        *LLMUsageService
 }
 
 type SurveyDeps struct {
 	TemplateRepo repository.SurveyTemplateRepository
 	ResponseRepo repository.SurveyResponseRepository
-	GPTClient    *external.YandexGPTClient
+	PatientRepo  repository.PatientRepository
+	IndexRepo    repository.MedicalIndexRepository
+	Alerts       *AlertService
+	LLM          external.LLMProvider
+	Jobs         *AIJobService
+	Prompts      *PromptService
+	Redactor     *PHIRedactor
+	Usage        *LLMUsageService
 }
 
 func NewSurveyService(d SurveyDeps) *SurveyService {
 	return &SurveyService{
 		templateRepo: d.TemplateRepo,
 		responseRepo: d.ResponseRepo,
-		gptClient:    d.GPTClient,
+		patientRepo:  d.PatientRepo,
+		indexRepo:    d.IndexRepo,
+		alerts:       d.Alerts,
+		llm:          d.LLM,
+		jobs:         d.Jobs,
+		prompts:      d.Prompts,
+		redactor:     d.Redactor,
+		usage:        d.Usage,
 	}
 }
 
@@ -62,6 +84,7 @@ func (s *SurveyService) SubmitResponse(ctx context.Context, req entity.SurveyRes
 		PatientID:   req.PatientID,
 		Responses:   responsesJSON,
 		Status:      entity.SurveyStatusSubmitted,
+		Revision:    1,
 		SubmittedAt: now,
 		CreatedAt:   now,
 	}
@@ -71,28 +94,152 @@ func (s *SurveyService) SubmitResponse(ctx context.Context, req entity.SurveyRes
 		return nil, err
 	}
 	sr.CalculatedScore = &score
+	sr.Category = category
 	breakdownJSON, _ := json.Marshal(breakdown)
 	sr.ScoreBreakdown = breakdownJSON
-	sr.Interpretation = fmt.Sprintf("%s (%s)", template.Code, category)
-
-	// Enrich interpretation with GPT if available
-	if s.gptClient != nil {
-		// Add category to breakdown for richer context
-		breakdown["category"] = category
-		gptInterpretation, err := s.gptClient.InterpretSurvey(ctx, template.Name, score, breakdown)
-		if err == nil && gptInterpretation != "" {
-			sr.Interpretation = gptInterpretation
-		}
+	sr.Interpretation = defaultInterpretation(template, category)
+	if s.aiEnabled() {
+		sr.AIStatus = entity.AIStatusPending
 	}
 
-	if err := s.responseRepo.Create(ctx, sr); err != nil {
+	// Every scored response becomes a point in the patient's index history, stored with the response.
+	idx := &entity.MedicalIndex{
+		ID:               uuid.New(),
+		PatientID:        sr.PatientID,
+		IndexType:        template.Code,
+		Value:            score,
+		Category:         category,
+		SurveyResponseID: &sr.ID,
+		RecordedAt:       now,
+	}
+	if err := s.responseRepo.Create(ctx, sr, idx); err != nil {
 		return nil, err
 	}
 
+	// Alerting must never block the submission itself.
+	if s.alerts != nil {
+		if _, err := s.alerts.EvaluateIndex(ctx, idx); err != nil {
+			log.Printf("[Survey] alert evaluation failed for response %s: %v", sr.ID, err)
+		}
+	}
+
+	if sr.AIStatus == entity.AIStatusPending {
+		s.enqueueInterpretation(ctx, req.SubmittedBy, sr, template, score, category, breakdown)
+	}
+
 	sr.Template = template
 	return sr, nil
 }
 
+// defaultInterpretation is the rule-based interpretation stored until (or instead of) the AI one.
+func defaultInterpretation(template *entity.SurveyTemplate, category string) string {
+	return fmt.Sprintf("%s (%s)", template.Code, category)
+}
+
+func (s *SurveyService) aiEnabled() bool {
+	return s.llm != nil && s.jobs != nil
+}
+
+type surveyInterpretationPayload struct {
+	Revision     int            `json:"revision"`
+	TemplateName string         `json:"template_name"`
+	TemplateCode string         `json:"template_code"`
+	Score        float64        `json:"score"`
+	Category     string         `json:"category"`
+	Breakdown    map[string]any `json:"breakdown"`
+}
+
+// enqueueInterpretation queues GPT interpretation of a stored response. If the job cannot be
+// queued the response keeps its rule-based interpretation and is marked failed.
+func (s *SurveyService) enqueueInterpretation(ctx context.Context, requestedBy uuid.UUID, sr *entity.SurveyResponse, template *entity.SurveyTemplate, score float64, category string, breakdown map[string]any) {
+	if err := s.usage.CheckQuota(ctx, requestedBy); err != nil {
+		// Over quota: the rule-based interpretation stays, the submission itself still succeeds.
+		log.Printf("[Survey] interpretation for response %s skipped: %v", sr.ID, err)
+		s.markInterpretationFailed(ctx, sr)
+		return
+	}
+
+	job := &entity.AIJob{Kind: entity.AIJobSurveyInterpretation, SurveyResponseID: &sr.ID}
+	if requestedBy != uuid.Nil {
+		job.UserID = &requestedBy
+	}
+	payload := surveyInterpretationPayload{
+		Revision:     sr.Revision,
+		TemplateName: template.Name,
+		TemplateCode: template.Code,
+		Score:        score,
+		Category:     category,
+		Breakdown:    breakdown,
+	}
+
+	if err := s.jobs.Enqueue(ctx, job, payload); err != nil {
+		log.Printf("[Survey] could not queue interpretation for response %s: %v", sr.ID, err)
+		s.markInterpretationFailed(ctx, sr)
+		return
+	}
+	sr.AIJobID = &job.ID
+}
+
+func (s *SurveyService) markInterpretationFailed(ctx context.Context, sr *entity.SurveyResponse) {
+	if _, err := s.responseRepo.UpdateAIResult(ctx, sr.ID, sr.Revision, "", entity.AIStatusFailed); err != nil {
+		log.Printf("[Survey] could not mark interpretation failed for response %s: %v", sr.ID, err)
+	}
+	sr.AIStatus = entity.AIStatusFailed
+}
+
+// RunAIJob generates the GPT interpretation of a survey response (AIJobRunner).
+func (s *SurveyService) RunAIJob(ctx context.Context, job *entity.AIJob) (string, error) {
+	if s.llm == nil {
+		return "", errors.New("llm provider not configured")
+	}
+	if job.SurveyResponseID == nil {
+		return "", errors.New("survey_response_id is required")
+	}
+	var p surveyInterpretationPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+
+	breakdown := p.Breakdown
+	if breakdown == nil {
+		breakdown = map[string]any{}
+	}
+	// Add category to breakdown for richer context
+	breakdown["category"] = p.Category
+	breakdown = s.redactor.RedactValues(ctx, job.UserID, entity.ResourceSurvey, job.SurveyResponseID, breakdown)
+	ctx = withLLMUsageScope(ctx, job.UserID, p.TemplateCode, entity.PromptSurveyInterpretation)
+	prompt := s.prompts.Resolve(ctx, entity.PromptSurveyInterpretation, p.TemplateCode)
+	text, err := external.InterpretSurvey(ctx, s.llm, prompt.SystemPrompt, p.TemplateName, p.Score, breakdown)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", errors.New("empty interpretation")
+	}
+
+	ok, err := s.responseRepo.UpdateAIResult(ctx, *job.SurveyResponseID, p.Revision, text, entity.AIStatusDone)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		log.Printf("[Survey] interpretation job %s is stale (response %s was amended)", job.ID, *job.SurveyResponseID)
+	}
+	return text, nil
+}
+
+// FailAIJob leaves the rule-based interpretation in place and marks the response failed (AIJobRunner).
+func (s *SurveyService) FailAIJob(ctx context.Context, job *entity.AIJob, cause error) error {
+	if job.SurveyResponseID == nil {
+		return nil
+	}
+	var p surveyInterpretationPayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	_, err := s.responseRepo.UpdateAIResult(ctx, *job.SurveyResponseID, p.Revision, "", entity.AIStatusFailed)
+	return err
+}
+
 // CalculateScore is the MVP scoring engine.
 // Supports BVAS_V3 (boolean sum), DAS28_CRP (formula), BASDAI (formula),
 // ASA (direct value), RCRI (boolean sum), GOLDMAN (boolean sum with weights), CAPRINI (boolean sum with weights).
@@ -125,6 +272,41 @@ func CalculateScore(template *entity.SurveyTemplate, responses map[string]interf
 	}
 }
 
+// ScoreCategories lists the categories CalculateScore can assign for a template, lowest risk or
+// activity first; it is nil for templates scored by the generic sum, which have no categories.
+func ScoreCategories(template *entity.SurveyTemplate) []string {
+	switch template.Code {
+	case "BVAS_V3":
+		rules, _ := template.GetInterpretationRules()
+		if rules == nil {
+			return nil
+		}
+		out := make([]string, 0, len(rules.Ranges))
+		for _, r := range rules.Ranges {
+			out = append(out, r.Category)
+		}
+		return out
+	case "DAS28_CRP":
+		return []string{"remission", "low_activity", "moderate_activity", "high_activity"}
+	case "BASDAI":
+		return []string{"low_activity", "high_activity"}
+	case "ASA":
+		out := make([]string, 0, 11)
+		for class := 1; class <= 6; class++ {
+			out = append(out, fmt.Sprintf("asa_%d", class))
+			if class < 6 {
+				out = append(out, fmt.Sprintf("asa_%d_e", class))
+			}
+		}
+		return out
+	case "RCRI", "GOLDMAN":
+		return []string{"class_i", "class_ii", "class_iii", "class_iv"}
+	case "CAPRINI":
+		return []string{"very_low", "low", "moderate", "high"}
+	}
+	return nil
+}
+
 // calculateBVAS sums boolean scores per section.
 func calculateBVAS(template *entity.SurveyTemplate, sections []entity.SurveySection, responses map[string]interface{}) (float64, string, map[string]any, error) {
 	breakdown := map[string]any{}
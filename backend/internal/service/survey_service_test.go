@@ -1,11 +1,52 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/google/uuid"
+
 	"github.com/medical-app/backend/internal/entity"
 )
 
+// failingCreateResponseRepo rejects every new response, like a failed insert transaction.
+type failingCreateResponseRepo struct {
+	*fakeSurveyResponseRepo
+}
+
+func (r failingCreateResponseRepo) Create(ctx context.Context, sr *entity.SurveyResponse, idx *entity.MedicalIndex) error {
+	return errors.New("insert failed")
+}
+
+func TestSubmitResponseStoresIndexWithResponse(t *testing.T) {
+	ctx := context.Background()
+	template := &entity.SurveyTemplate{ID: uuid.New(), Code: "RCRI", Questions: json.RawMessage("[]")}
+	templates := &fakeTemplateRepo{byCode: map[string]*entity.SurveyTemplate{template.Code: template}}
+	req := entity.SurveyResponseCreate{TemplateID: template.ID, PatientID: uuid.New(), Responses: map[string]any{"ihd": true, "chf": true}}
+
+	indices := &fakeIndexRepo{}
+	responses := newFakeSurveyResponseRepo(nil)
+	responses.indices = indices
+	svc := NewSurveyService(SurveyDeps{TemplateRepo: templates, ResponseRepo: responses, IndexRepo: indices})
+	sr, err := svc.SubmitResponse(ctx, req)
+	if err != nil {
+		t.Fatalf("SubmitResponse() error = %v", err)
+	}
+	if len(indices.items) != 1 || *indices.items[0].SurveyResponseID != sr.ID || indices.items[0].Category != "class_iii" {
+		t.Fatalf("index history = %+v, want one class_iii entry for response %s", indices.items, sr.ID)
+	}
+
+	indices = &fakeIndexRepo{}
+	failing := failingCreateResponseRepo{newFakeSurveyResponseRepo(nil)}
+	failing.indices = indices
+	svc = NewSurveyService(SurveyDeps{TemplateRepo: templates, ResponseRepo: failing, IndexRepo: indices})
+	if _, err := svc.SubmitResponse(ctx, req); err == nil || len(indices.items) != 0 {
+		t.Fatalf("failed submit: error = %v, %d index entries", err, len(indices.items))
+	}
+}
+
 func TestCalculateDAS28CRP(t *testing.T) {
 	template := &entity.SurveyTemplate{Code: "DAS28_CRP"}
 
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+// perioperativeLookback bounds how long ago a therapy may have been given to appear in a plan; a
+// drug with a rule stays current for two dosing intervals after its last dose (rituximab for a year).
+const perioperativeLookback = 2 * maxPerioperativeIntervalDays * 24 * time.Hour
+
+// PerioperativePlan computes when each current therapy of the patient is last given before the
+// planned operation and when it is restarted, and flags the scheduled doses falling in between.
+// Current therapies are those counted by the interaction check plus drugs whose last dose is within
+// two of their rule's dosing intervals; a drug without a rule is listed with the no_rule action.
+func (s *TherapyService) PerioperativePlan(ctx context.Context, patientID uuid.UUID, surgeryDate time.Time) (*entity.PerioperativePlan, error) {
+	if s.drugs == nil {
+		return nil, errors.New("drug catalogue is not configured")
+	}
+	now := time.Now().UTC()
+	surgery, today := calendarDay(surgeryDate), calendarDay(now)
+	if surgery.Before(today) {
+		v := validator.New()
+		v.AddError("surgery_date", "surgery_date must not be in the past")
+		return nil, v.Errors()
+	}
+
+	logs, err := s.repo.ListByPatient(ctx, patientID, activeTherapyLookup)
+	if err != nil {
+		return nil, err
+	}
+	drugs, rules, err := s.drugs.perioperativeRules(ctx, activeTherapyDrugIDs(logs, uuid.Nil, now, perioperativeLookback))
+	if err != nil {
+		return nil, err
+	}
+	active := activeTherapyDrugIDs(logs, uuid.Nil, now, activeTherapyWindow)
+	slices.SortFunc(drugs, func(a, b *entity.Drug) int { return strings.Compare(a.Name, b.Name) })
+
+	plan := &entity.PerioperativePlan{
+		PatientID:    patientID,
+		SurgeryDate:  surgery,
+		Drugs:        make([]entity.PerioperativeDrugPlan, 0, len(drugs)),
+		Timeline:     []entity.PerioperativeEvent{{Date: surgery, Kind: entity.PerioperativeEventSurgery}},
+		FlaggedDoses: make([]entity.PerioperativeDose, 0),
+	}
+	for _, d := range drugs {
+		dp := buildPerioperativeDrugPlan(d, rules[d.ID], logs, surgery, today)
+		if !slices.Contains(active, d.ID) && !withinIntervals(dp, today, 2) {
+			continue
+		}
+		plan.Drugs = append(plan.Drugs, dp)
+		plan.Timeline = append(plan.Timeline, perioperativeEvents(dp)...)
+		for _, dose := range dp.ScheduledDoses {
+			if dose.InHoldWindow {
+				plan.FlaggedDoses = append(plan.FlaggedDoses, dose)
+			}
+		}
+	}
+	// Stable, so the surgery comes first on its day and each drug's events keep their order.
+	slices.SortStableFunc(plan.Timeline, func(a, b entity.PerioperativeEvent) int { return a.Date.Compare(b.Date) })
+	return plan, nil
+}
+
+// buildPerioperativeDrugPlan applies a rule (nil when none matched) to one drug's logs. The dosing
+// interval observed between the last two administrations replaces the rule's default when it is
+// within a factor of two of it, so e.g. infliximab every 4 weeks is held for 4 weeks, not 8.
+func buildPerioperativeDrugPlan(d *entity.Drug, rule *entity.DrugPerioperativeRule, logs []*entity.TherapyLog, surgery, today time.Time) entity.PerioperativeDrugPlan {
+	dp := entity.PerioperativeDrugPlan{
+		Drug:           entity.InteractionDrug{ID: d.ID, Name: d.Name, ATCCode: d.ATCCode},
+		Action:         entity.PerioperativeNoRule,
+		ScheduledDoses: make([]entity.PerioperativeDose, 0),
+	}
+	given := administeredDays(logs, d.ID, today)
+	if len(given) > 0 {
+		dp.LastDoseAt = &given[0]
+	}
+
+	horizon := surgery
+	var lastBy, resume time.Time
+	if rule != nil {
+		dp.RuleID = &rule.ID
+		dp.Guidance = rule.Guidance
+		dp.DosingIntervalDays, dp.IntervalSource = rule.DosingIntervalDays, entity.IntervalSourceRule
+		if len(given) > 1 {
+			gap := int(given[0].Sub(given[1]).Hours() / 24)
+			if gap*2 >= rule.DosingIntervalDays && gap <= rule.DosingIntervalDays*2 {
+				dp.DosingIntervalDays, dp.IntervalSource = gap, entity.IntervalSourceObserved
+			}
+		}
+
+		dp.Action = entity.PerioperativeContinue
+		if rule.HoldIntervals > 0 {
+			dp.Action = entity.PerioperativeHold
+			dp.HoldDays = int(math.Ceil(rule.HoldIntervals * float64(dp.DosingIntervalDays)))
+			lastBy = surgery.AddDate(0, 0, -dp.HoldDays)
+			resume = surgery.AddDate(0, 0, rule.ResumeAfterDays)
+			dp.LastDoseBy, dp.ResumeOn = &lastBy, &resume
+			horizon = resume
+			if last := dp.LastDoseAt; last != nil && last.After(lastBy) && !last.After(surgery) {
+				earliest := last.AddDate(0, 0, dp.HoldDays)
+				dp.EarliestSurgeryDate = &earliest
+			}
+		}
+	}
+
+	for _, dose := range scheduledDoses(logs, d, today, horizon) {
+		if dp.Action == entity.PerioperativeHold {
+			dose.InHoldWindow = dose.Date.After(lastBy) && (dose.Date.Before(resume) || dose.Date.Equal(surgery))
+		}
+		dp.ScheduledDoses = append(dp.ScheduledDoses, dose)
+	}
+	return dp
+}
+
+// administeredDays returns the days a drug was given up to today, most recent first, one per day.
+func administeredDays(logs []*entity.TherapyLog, drugID uuid.UUID, today time.Time) []time.Time {
+	var days []time.Time
+	for _, l := range logs {
+		if l.DrugID != drugID || l.Status != entity.TherapyStatusCompleted || l.AdministeredAt == nil {
+			continue
+		}
+		if d := calendarDay(*l.AdministeredAt); !d.After(today) && !slices.ContainsFunc(days, d.Equal) {
+			days = append(days, d)
+		}
+	}
+	slices.SortFunc(days, func(a, b time.Time) int { return b.Compare(a) })
+	return days
+}
+
+// scheduledDoses collects the planned doses of a drug from today to horizon: scheduled logs and the
+// next_scheduled date recorded on completed ones, one per day, earliest first. A completed log's
+// next_scheduled is only a forecast and is dropped once a dose of the drug is scheduled after it was
+// given, so a rescheduled dose is not reported twice.
+func scheduledDoses(logs []*entity.TherapyLog, d *entity.Drug, today, horizon time.Time) []entity.PerioperativeDose {
+	var planned []time.Time
+	for _, l := range logs {
+		if l.DrugID == d.ID && l.Status == entity.TherapyStatusScheduled {
+			if at := scheduledAt(l); at != nil {
+				planned = append(planned, calendarDay(*at))
+			}
+		}
+	}
+
+	var doses []entity.PerioperativeDose
+	for _, l := range logs {
+		if l.DrugID != d.ID {
+			continue
+		}
+		var at *time.Time
+		switch l.Status {
+		case entity.TherapyStatusScheduled:
+			at = scheduledAt(l)
+		case entity.TherapyStatusCompleted:
+			at = l.NextScheduled
+			if at != nil && l.AdministeredAt != nil {
+				given := calendarDay(*l.AdministeredAt)
+				if slices.ContainsFunc(planned, given.Before) {
+					continue
+				}
+			}
+		default:
+			continue
+		}
+		if at == nil {
+			continue
+		}
+		date := calendarDay(*at)
+		if date.Before(today) || date.After(horizon) || slices.ContainsFunc(doses, func(x entity.PerioperativeDose) bool { return x.Date.Equal(date) }) {
+			continue
+		}
+		doses = append(doses, entity.PerioperativeDose{LogID: l.ID, DrugID: d.ID, DrugName: d.Name, Date: date})
+	}
+	slices.SortFunc(doses, func(a, b entity.PerioperativeDose) int { return a.Date.Compare(b.Date) })
+	return doses
+}
+
+// scheduledAt is the planned date of a scheduled log: next_scheduled, else administered_at.
+func scheduledAt(l *entity.TherapyLog) *time.Time {
+	if l.NextScheduled != nil {
+		return l.NextScheduled
+	}
+	return l.AdministeredAt
+}
+
+// withinIntervals reports whether the last dose of a drug with a rule was given at most n dosing
+// intervals before today.
+func withinIntervals(dp entity.PerioperativeDrugPlan, today time.Time, n int) bool {
+	return dp.RuleID != nil && dp.LastDoseAt != nil && !dp.LastDoseAt.AddDate(0, 0, n*dp.DosingIntervalDays).Before(today)
+}
+
+func perioperativeEvents(dp entity.PerioperativeDrugPlan) []entity.PerioperativeEvent {
+	drugID := dp.Drug.ID
+	event := func(date time.Time, kind string) entity.PerioperativeEvent {
+		return entity.PerioperativeEvent{Date: date, Kind: kind, DrugID: &drugID, DrugName: dp.Drug.Name}
+	}
+	var out []entity.PerioperativeEvent
+	if dp.LastDoseAt != nil {
+		out = append(out, event(*dp.LastDoseAt, entity.PerioperativeEventLastDose))
+	}
+	if dp.LastDoseBy != nil {
+		out = append(out, event(*dp.LastDoseBy, entity.PerioperativeEventLastDoseBy))
+	}
+	for _, dose := range dp.ScheduledDoses {
+		e := event(dose.Date, entity.PerioperativeEventScheduledDose)
+		e.LogID = &dose.LogID
+		e.InHoldWindow = dose.InHoldWindow
+		out = append(out, e)
+	}
+	if dp.ResumeOn != nil {
+		out = append(out, event(*dp.ResumeOn, entity.PerioperativeEventResume))
+	}
+	return out
+}
+
+// calendarDay truncates t to midnight UTC of its UTC date.
+func calendarDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
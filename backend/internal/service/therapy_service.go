@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,18 +16,29 @@ import (
 	"github.com/medical-app/backend/pkg/validator"
 )
 
+const (
+	// activeTherapyWindow is how long after its last administration a drug counts as current
+	// for interaction checks; biologics dosed every 4–12 weeks stay active between doses.
+	activeTherapyWindow = 90 * 24 * time.Hour
+	// activeTherapyLookup bounds the history scanned for current therapies.
+	activeTherapyLookup = 200
+)
+
 type TherapyService struct {
 	repo        repository.TherapyLogRepository
 	patientRepo repository.PatientRepository
+	drugs       *DrugService
 }
 
 type TherapyDeps struct {
 	Repo        repository.TherapyLogRepository
 	PatientRepo repository.PatientRepository
+	// Drugs checks new logs for interactions with the patient's current therapies; optional.
+	Drugs *DrugService
 }
 
 func NewTherapyService(d TherapyDeps) *TherapyService {
-	return &TherapyService{repo: d.Repo, patientRepo: d.PatientRepo}
+	return &TherapyService{repo: d.Repo, patientRepo: d.PatientRepo, drugs: d.Drugs}
 }
 
 // CreateLogForUser resolves/creates a patient record for the given userID, then creates the therapy log.
@@ -129,9 +142,51 @@ func (s *TherapyService) CreateLog(ctx context.Context, req entity.TherapyLogCre
 	if err := s.repo.Create(ctx, logEntry); err != nil {
 		return nil, err
 	}
+	logEntry.Interactions = s.checkInteractions(ctx, logEntry, now)
 	return logEntry, nil
 }
 
+// checkInteractions returns the interactions of a new log's drug with the patient's active therapies.
+// The log is already saved, so a failed check is logged rather than failing the request.
+func (s *TherapyService) checkInteractions(ctx context.Context, logEntry *entity.TherapyLog, now time.Time) []entity.DrugInteraction {
+	if s.drugs == nil || logEntry.Status == entity.TherapyStatusCancelled || logEntry.Status == entity.TherapyStatusMissed {
+		return nil
+	}
+	logs, err := s.repo.ListByPatient(ctx, logEntry.PatientID, activeTherapyLookup)
+	if err != nil {
+		log.Printf("[Therapy] interaction check for log %s: %v", logEntry.ID, err)
+		return nil
+	}
+	interactions, err := s.drugs.InteractionsWith(ctx, logEntry.DrugID, activeTherapyDrugIDs(logs, logEntry.ID, now, activeTherapyWindow))
+	if err != nil {
+		log.Printf("[Therapy] interaction check for log %s: %v", logEntry.ID, err)
+		return nil
+	}
+	return interactions
+}
+
+// activeTherapyDrugIDs returns the drugs of scheduled or completed logs dated within window
+// before now or later, skipping the log being checked.
+func activeTherapyDrugIDs(logs []*entity.TherapyLog, exclude uuid.UUID, now time.Time, window time.Duration) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, l := range logs {
+		if l.ID == exclude || (l.Status != entity.TherapyStatusScheduled && l.Status != entity.TherapyStatusCompleted) {
+			continue
+		}
+		at := l.AdministeredAt
+		if l.Status == entity.TherapyStatusScheduled && l.NextScheduled != nil {
+			at = l.NextScheduled
+		}
+		if at == nil || now.Sub(*at) > window {
+			continue
+		}
+		if !slices.Contains(ids, l.DrugID) {
+			ids = append(ids, l.DrugID)
+		}
+	}
+	return ids
+}
+
 func (s *TherapyService) ListByPatient(ctx context.Context, patientID uuid.UUID, limit int) ([]*entity.TherapyLog, error) {
 	return s.repo.ListByPatient(ctx, patientID, limit)
 }
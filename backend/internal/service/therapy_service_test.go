@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/medical-app/backend/internal/entity"
+	"github.com/medical-app/backend/pkg/validator"
+)
+
+type fakeTherapyRepo struct {
+	logs []*entity.TherapyLog
+}
+
+func (r *fakeTherapyRepo) Create(ctx context.Context, l *entity.TherapyLog) error {
+	r.logs = append(r.logs, l)
+	return nil
+}
+func (r *fakeTherapyRepo) ListByPatient(ctx context.Context, patientID uuid.UUID, limit int) ([]*entity.TherapyLog, error) {
+	var out []*entity.TherapyLog
+	for _, l := range r.logs {
+		if l.PatientID == patientID {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+func (r *fakeTherapyRepo) DeleteByID(ctx context.Context, patientID uuid.UUID, logID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func TestCreateLogWarnsAboutConcurrentBiologics(t *testing.T) {
+	drugs, _ := newInteractionTestService()
+	patientID := uuid.New()
+	given := time.Now().UTC().AddDate(0, 0, -14)
+	old := time.Now().UTC().AddDate(-1, 0, 0)
+	repo := &fakeTherapyRepo{logs: []*entity.TherapyLog{
+		{ID: uuid.New(), PatientID: patientID, DrugID: testAdalimumab.ID, Status: entity.TherapyStatusCompleted, AdministeredAt: &given},
+		// Stopped a year ago: no longer a current therapy.
+		{ID: uuid.New(), PatientID: patientID, DrugID: testTocilizumab.ID, Status: entity.TherapyStatusCompleted, AdministeredAt: &old},
+	}}
+	svc := NewTherapyService(TherapyDeps{Repo: repo, Drugs: drugs})
+
+	created, err := svc.CreateLog(context.Background(), entity.TherapyLogCreate{
+		PatientID: patientID, DrugID: testInfliximab.ID, Dosage: "300", DosageUnit: "mg",
+	})
+	if err != nil {
+		t.Fatalf("CreateLog() error = %v", err)
+	}
+	if len(created.Interactions) != 1 {
+		t.Fatalf("interactions = %+v, want one with adalimumab", created.Interactions)
+	}
+	w := created.Interactions[0]
+	if w.Severity != entity.InteractionMajor || w.DrugA.ID != testInfliximab.ID || w.DrugB.ID != testAdalimumab.ID {
+		t.Fatalf("interaction = %+v", w)
+	}
+
+	// A repeat dose is not checked against earlier doses of the same drug, only against the infliximab just added.
+	again, err := svc.CreateLog(context.Background(), entity.TherapyLogCreate{
+		PatientID: patientID, DrugID: testAdalimumab.ID, Dosage: "40", DosageUnit: "mg", Status: entity.TherapyStatusCompleted,
+	})
+	if err != nil {
+		t.Fatalf("CreateLog() error = %v", err)
+	}
+	if len(again.Interactions) != 1 || again.Interactions[0].DrugB.ID != testInfliximab.ID {
+		t.Fatalf("repeat dose interactions = %+v, want only the infliximab one", again.Interactions)
+	}
+}
+
+type fakePerioperativeRepo struct {
+	rules []*entity.DrugPerioperativeRule
+}
+
+func (r *fakePerioperativeRepo) Upsert(ctx context.Context, rule *entity.DrugPerioperativeRule) (*entity.DrugPerioperativeRule, error) {
+	for i, prev := range r.rules {
+		sameDrug := rule.DrugID != nil && prev.DrugID != nil && *prev.DrugID == *rule.DrugID
+		if sameDrug || (rule.DrugID == nil && prev.DrugID == nil && prev.ATCCode == rule.ATCCode) {
+			rule.ID = prev.ID
+			r.rules[i] = rule
+			return prev, nil
+		}
+	}
+	r.rules = append(r.rules, rule)
+	return nil, nil
+}
+func (r *fakePerioperativeRepo) List(ctx context.Context) ([]*entity.DrugPerioperativeRule, error) {
+	return r.rules, nil
+}
+
+func TestPerioperativePlan(t *testing.T) {
+	adalimumabID := testAdalimumab.ID
+	periop := &fakePerioperativeRepo{rules: []*entity.DrugPerioperativeRule{
+		{ID: uuid.New(), ATCCode: "L04AB", DosingIntervalDays: 56, HoldIntervals: 1, ResumeAfterDays: 14},
+		{ID: uuid.New(), DrugID: &adalimumabID, DosingIntervalDays: 14, HoldIntervals: 1, ResumeAfterDays: 14},
+		{ID: uuid.New(), ATCCode: "L04AX03", DosingIntervalDays: 7},
+	}}
+	drugs := NewDrugService(DrugDeps{
+		Repo:       newFakeDrugRepo(testAdalimumab, testInfliximab, testTocilizumab, testMethotrexate, testIbuprofen),
+		PeriopRepo: periop,
+	})
+
+	today := calendarDay(time.Now())
+	at := func(days int) *time.Time {
+		t := today.AddDate(0, 0, days).Add(10 * time.Hour)
+		return &t
+	}
+	patientID := uuid.New()
+	entry := func(drug *entity.Drug, status string, administered, next *time.Time) *entity.TherapyLog {
+		return &entity.TherapyLog{ID: uuid.New(), PatientID: patientID, DrugID: drug.ID, Status: status, AdministeredAt: administered, NextScheduled: next}
+	}
+	repo := &fakeTherapyRepo{logs: []*entity.TherapyLog{
+		entry(testAdalimumab, entity.TherapyStatusCompleted, at(-28), nil),
+		// The forecast on day 34 is superseded by the doses scheduled since.
+		entry(testAdalimumab, entity.TherapyStatusCompleted, at(-14), at(34)),
+		entry(testAdalimumab, entity.TherapyStatusScheduled, at(6), at(6)),
+		entry(testAdalimumab, entity.TherapyStatusScheduled, at(13), at(13)),
+		// Every 4 weeks rather than the default 8, last given too close to the operation.
+		entry(testInfliximab, entity.TherapyStatusCompleted, at(-33), nil),
+		entry(testInfliximab, entity.TherapyStatusCompleted, at(-5), nil),
+		entry(testMethotrexate, entity.TherapyStatusCompleted, at(-3), nil),
+		entry(testMethotrexate, entity.TherapyStatusScheduled, at(20), at(20)),
+		entry(testIbuprofen, entity.TherapyStatusCompleted, at(-1), nil),
+		// Stopped long ago and has no rule keeping it current.
+		entry(testTocilizumab, entity.TherapyStatusCompleted, at(-300), nil),
+	}}
+	svc := NewTherapyService(TherapyDeps{Repo: repo, Drugs: drugs})
+
+	plan, err := svc.PerioperativePlan(context.Background(), patientID, today.AddDate(0, 0, 20))
+	if err != nil {
+		t.Fatalf("PerioperativePlan() error = %v", err)
+	}
+	if len(plan.Drugs) != 4 {
+		t.Fatalf("drugs = %+v, want adalimumab, ibuprofen, infliximab, methotrexate", plan.Drugs)
+	}
+	ada, nsaid, ifx, mtx := plan.Drugs[0], plan.Drugs[1], plan.Drugs[2], plan.Drugs[3]
+
+	if ada.Action != entity.PerioperativeHold || ada.HoldDays != 14 || !ada.LastDoseBy.Equal(today.AddDate(0, 0, 6)) || !ada.ResumeOn.Equal(today.AddDate(0, 0, 34)) {
+		t.Fatalf("adalimumab plan = %+v", ada)
+	}
+	if len(ada.ScheduledDoses) != 2 || ada.ScheduledDoses[0].InHoldWindow || !ada.ScheduledDoses[1].InHoldWindow {
+		t.Fatalf("adalimumab doses = %+v, want only the one on day 13 flagged", ada.ScheduledDoses)
+	}
+	if ada.EarliestSurgeryDate != nil {
+		t.Fatalf("adalimumab earliest surgery = %v, want none", ada.EarliestSurgeryDate)
+	}
+
+	if ifx.DosingIntervalDays != 28 || ifx.IntervalSource != entity.IntervalSourceObserved || ifx.EarliestSurgeryDate == nil || !ifx.EarliestSurgeryDate.Equal(today.AddDate(0, 0, 23)) {
+		t.Fatalf("infliximab plan = %+v", ifx)
+	}
+	if mtx.Action != entity.PerioperativeContinue || mtx.ResumeOn != nil || len(mtx.ScheduledDoses) != 1 || mtx.ScheduledDoses[0].InHoldWindow {
+		t.Fatalf("methotrexate plan = %+v", mtx)
+	}
+	if nsaid.Action != entity.PerioperativeNoRule || nsaid.RuleID != nil {
+		t.Fatalf("ibuprofen plan = %+v", nsaid)
+	}
+
+	if len(plan.FlaggedDoses) != 1 || plan.FlaggedDoses[0].DrugID != testAdalimumab.ID {
+		t.Fatalf("flagged doses = %+v", plan.FlaggedDoses)
+	}
+	for i := 1; i < len(plan.Timeline); i++ {
+		if plan.Timeline[i].Date.Before(plan.Timeline[i-1].Date) {
+			t.Fatalf("timeline not sorted at %d: %+v", i, plan.Timeline)
+		}
+	}
+
+	var ve validator.ValidationErrors
+	if _, err := svc.PerioperativePlan(context.Background(), patientID, today.AddDate(0, 0, -1)); !errors.As(err, &ve) {
+		t.Fatalf("PerioperativePlan(past date) error = %v, want validation error", err)
+	}
+}
+
+func TestPerioperativePlanDropsSupersededForecast(t *testing.T) {
+	periop := &fakePerioperativeRepo{rules: []*entity.DrugPerioperativeRule{
+		{ID: uuid.New(), ATCCode: "L04AB", DosingIntervalDays: 28, HoldIntervals: 1, ResumeAfterDays: 14},
+	}}
+	drugs := NewDrugService(DrugDeps{Repo: newFakeDrugRepo(testAdalimumab, testInfliximab), PeriopRepo: periop})
+
+	today := calendarDay(time.Now())
+	at := func(days int) *time.Time {
+		t := today.AddDate(0, 0, days).Add(10 * time.Hour)
+		return &t
+	}
+	patientID := uuid.New()
+	repo := &fakeTherapyRepo{logs: []*entity.TherapyLog{
+		// Forecast for day 5, then rescheduled to day 40, after the operation window.
+		{ID: uuid.New(), PatientID: patientID, DrugID: testAdalimumab.ID, Status: entity.TherapyStatusCompleted, AdministeredAt: at(-23), NextScheduled: at(5)},
+		{ID: uuid.New(), PatientID: patientID, DrugID: testAdalimumab.ID, Status: entity.TherapyStatusScheduled, NextScheduled: at(40)},
+		// Nothing scheduled since, so the forecast stands.
+		{ID: uuid.New(), PatientID: patientID, DrugID: testInfliximab.ID, Status: entity.TherapyStatusCompleted, AdministeredAt: at(-23), NextScheduled: at(5)},
+	}}
+	svc := NewTherapyService(TherapyDeps{Repo: repo, Drugs: drugs})
+
+	plan, err := svc.PerioperativePlan(context.Background(), patientID, today.AddDate(0, 0, 20))
+	if err != nil {
+		t.Fatalf("PerioperativePlan() error = %v", err)
+	}
+	if len(plan.FlaggedDoses) != 1 || plan.FlaggedDoses[0].DrugID != testInfliximab.ID {
+		t.Fatalf("flagged doses = %+v, want only the infliximab forecast", plan.FlaggedDoses)
+	}
+	if ada := plan.Drugs[0]; len(ada.ScheduledDoses) != 0 {
+		t.Fatalf("adalimumab doses = %+v, want the superseded forecast dropped", ada.ScheduledDoses)
+	}
+}
+
+func TestSetPerioperativeRuleReturnsReplacedRule(t *testing.T) {
+	periop := &fakePerioperativeRepo{}
+	svc := NewDrugService(DrugDeps{Repo: newFakeDrugRepo(testAdalimumab), PeriopRepo: periop})
+	ctx := context.Background()
+	req := entity.DrugPerioperativeRuleSet{ATCCode: "l04ab", DosingIntervalDays: 14, HoldIntervals: 1, ResumeAfterDays: 14, Guidance: "Пропустить одну дозу"}
+
+	old, rule, err := svc.SetPerioperativeRule(ctx, uuid.New(), req)
+	if err != nil || old != nil || rule.ATCCode != "L04AB" {
+		t.Fatalf("SetPerioperativeRule(new) = %+v, %+v, %v", old, rule, err)
+	}
+	req.HoldIntervals = 2
+	old, replaced, err := svc.SetPerioperativeRule(ctx, uuid.New(), req)
+	if err != nil || old == nil || old.HoldIntervals != 1 || replaced.ID != rule.ID || len(periop.rules) != 1 {
+		t.Fatalf("SetPerioperativeRule(replace) old = %+v, rule = %+v, err = %v", old, replaced, err)
+	}
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_survey_responses_status;
+
+ALTER TABLE survey_responses
+    DROP COLUMN IF EXISTS override_reason,
+    DROP COLUMN IF EXISTS category_override,
+    DROP COLUMN IF EXISTS category;
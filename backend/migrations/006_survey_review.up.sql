@@ -0,0 +1,10 @@
+-- ============================================
+-- SURVEY REVIEW (clinician review workflow)
+-- ============================================
+
+ALTER TABLE survey_responses
+    ADD COLUMN category VARCHAR(50),  -- category computed by the scoring engine
+    ADD COLUMN category_override VARCHAR(50),  -- category set by the reviewing clinician
+    ADD COLUMN override_reason TEXT;
+
+CREATE INDEX idx_survey_responses_status ON survey_responses(status);
@@ -0,0 +1,54 @@
+-- ============================================
+-- CLINICAL ALERTS (rule-based, over medical indices)
+-- ============================================
+
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    index_type VARCHAR(50) NOT NULL,
+    rule_type VARCHAR(30) NOT NULL,  -- 'threshold', 'delta', 'category_change'
+    direction VARCHAR(10) NOT NULL DEFAULT 'rise',  -- 'rise', 'fall', 'any'
+    threshold DECIMAL(10,2),  -- absolute value for 'threshold', minimum change for 'delta'
+    category_order JSONB,  -- ordered categories (least to most severe) for 'category_change'
+    severity VARCHAR(20) NOT NULL DEFAULT 'warning',  -- 'info', 'warning', 'critical'
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_alert_rules_index_type ON alert_rules(index_type) WHERE is_active;
+
+CREATE TABLE alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rule_id UUID NOT NULL REFERENCES alert_rules(id),
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    doctor_id UUID REFERENCES users(id),
+    medical_index_id UUID REFERENCES medical_indices(id),
+    index_type VARCHAR(50) NOT NULL,
+    previous_value DECIMAL(10,2),
+    current_value DECIMAL(10,2) NOT NULL,
+    previous_category VARCHAR(50),
+    current_category VARCHAR(50),
+    severity VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',  -- 'open', 'acknowledged', 'resolved'
+    acknowledged_by UUID REFERENCES users(id),
+    acknowledged_at TIMESTAMPTZ,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMPTZ,
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_alerts_doctor_status ON alerts(doctor_id, status, created_at DESC);
+CREATE INDEX idx_alerts_patient ON alerts(patient_id);
+
+-- Default rules
+INSERT INTO alert_rules (id, index_type, rule_type, direction, threshold, category_order, severity, description) VALUES
+    ('a1e70000-0000-0000-0000-000000000001', 'DAS28_CRP', 'delta', 'rise', 1.2, NULL, 'critical',
+     'Клинически значимое ухудшение DAS28-CRP (рост более 1.2 между оценками)'),
+    ('a1e70000-0000-0000-0000-000000000002', 'DAS28_CRP', 'threshold', 'rise', 5.1, NULL, 'warning',
+     'DAS28-CRP перешёл в зону высокой активности (> 5.1)'),
+    ('a1e70000-0000-0000-0000-000000000003', 'RCRI', 'category_change', 'rise', NULL,
+     '["class_i", "class_ii", "class_iii", "class_iv"]', 'critical',
+     'Повышение класса RCRI между оценками');
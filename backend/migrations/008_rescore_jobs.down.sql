@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rescore_job_items;
+DROP TABLE IF EXISTS rescore_jobs;
@@ -0,0 +1,39 @@
+-- ============================================
+-- RESCORE JOBS (re-score stored responses after a template/engine change)
+-- ============================================
+
+CREATE TABLE rescore_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL REFERENCES survey_templates(id),
+    template_version INT,
+    requested_by UUID REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- 'pending', 'running', 'ready', 'applying', 'applied', 'failed'
+    total_count INT NOT NULL DEFAULT 0,
+    changed_count INT NOT NULL DEFAULT 0,
+    category_changed_count INT NOT NULL DEFAULT 0,
+    report JSONB,  -- category transition counts
+    error TEXT,
+    confirmed_by UUID REFERENCES users(id),
+    confirmed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_rescore_jobs_template ON rescore_jobs(template_id, created_at DESC);
+
+-- Only responses whose result differs are stored; old_* columns keep the original values.
+CREATE TABLE rescore_job_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_id UUID NOT NULL REFERENCES rescore_jobs(id) ON DELETE CASCADE,
+    survey_response_id UUID NOT NULL REFERENCES survey_responses(id),
+    old_score DECIMAL(10,2),
+    new_score DECIMAL(10,2) NOT NULL,
+    old_category VARCHAR(50),
+    new_category VARCHAR(50) NOT NULL,
+    old_breakdown JSONB,
+    new_breakdown JSONB,
+    category_changed BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_rescore_job_items_job ON rescore_job_items(job_id);
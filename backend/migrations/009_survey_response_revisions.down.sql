@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_survey_response_revisions_immutable ON survey_response_revisions;
+DROP FUNCTION IF EXISTS survey_response_revisions_immutable();
+DROP TABLE IF EXISTS survey_response_revisions;
+
+ALTER TABLE survey_responses
+    DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,37 @@
+-- ============================================
+-- SURVEY RESPONSE REVISIONS (amendments)
+-- ============================================
+
+ALTER TABLE survey_responses
+    ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;  -- current revision, bumped on every amendment
+
+-- One row per amendment; together with the original answers (old_* of revision 2)
+-- the rows form the full history of a response.
+CREATE TABLE survey_response_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    survey_response_id UUID NOT NULL REFERENCES survey_responses(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,  -- revision produced by this amendment (2, 3, ...)
+    amended_by UUID NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    old_responses JSONB NOT NULL,
+    new_responses JSONB NOT NULL,
+    old_score DECIMAL(10,2),
+    new_score DECIMAL(10,2) NOT NULL,
+    old_category VARCHAR(50),
+    new_category VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (survey_response_id, revision)
+);
+
+CREATE INDEX idx_survey_response_revisions_response ON survey_response_revisions(survey_response_id);
+
+-- Revisions are append-only.
+CREATE OR REPLACE FUNCTION survey_response_revisions_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'survey_response_revisions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_survey_response_revisions_immutable
+    BEFORE UPDATE ON survey_response_revisions
+    FOR EACH ROW EXECUTE FUNCTION survey_response_revisions_immutable();
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS ai_jobs;
+
+ALTER TABLE ai_advice
+    DROP COLUMN IF EXISTS ai_status;
+
+ALTER TABLE survey_responses
+    DROP COLUMN IF EXISTS ai_status;
@@ -0,0 +1,31 @@
+-- ============================================
+-- AI JOBS (asynchronous LLM generation queue)
+-- ============================================
+
+-- NULL = no AI enrichment requested, otherwise pending / done / failed
+ALTER TABLE survey_responses
+    ADD COLUMN ai_status VARCHAR(20);
+
+ALTER TABLE ai_advice
+    ADD COLUMN ai_status VARCHAR(20);
+
+CREATE TABLE ai_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(40) NOT NULL,  -- 'survey_interpretation', 'patient_advice'
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- 'pending', 'running', 'done', 'failed'
+    user_id UUID REFERENCES users(id),  -- requester, may poll the job
+    survey_response_id UUID REFERENCES survey_responses(id) ON DELETE CASCADE,
+    ai_advice_id UUID REFERENCES ai_advice(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    result TEXT,
+    error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_ai_jobs_pending ON ai_jobs(run_after) WHERE status = 'pending';
+CREATE INDEX idx_ai_jobs_running ON ai_jobs(locked_at) WHERE status = 'running';
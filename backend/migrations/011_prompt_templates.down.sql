@@ -0,0 +1,5 @@
+ALTER TABLE ai_advice
+    DROP COLUMN IF EXISTS prompt_version,
+    DROP COLUMN IF EXISTS prompt_template_id;
+
+DROP TABLE IF EXISTS prompt_templates;
@@ -0,0 +1,50 @@
+-- ============================================
+-- PROMPT TEMPLATES (versioned LLM system prompts)
+-- ============================================
+
+CREATE TABLE prompt_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(50) NOT NULL,  -- 'patient_advice', 'survey_interpretation', 'therapy_recommendation', 'drug_interaction'
+    survey_code VARCHAR(50),  -- NULL = default for all surveys, otherwise an override for one survey
+    version INTEGER NOT NULL,
+    system_prompt TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',  -- 'draft', 'approved', 'rejected', 'retired'
+    notes TEXT,
+    created_by UUID REFERENCES users(id),
+    reviewed_by UUID REFERENCES users(id),
+    review_comment TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+-- Versions are numbered per key and survey scope.
+CREATE UNIQUE INDEX idx_prompt_templates_version ON prompt_templates(key, COALESCE(survey_code, ''), version);
+-- At most one approved (active) version per key and survey scope.
+CREATE UNIQUE INDEX idx_prompt_templates_active ON prompt_templates(key, COALESCE(survey_code, '')) WHERE status = 'approved';
+
+INSERT INTO prompt_templates (key, version, system_prompt, status, notes, reviewed_at) VALUES
+    ('patient_advice', 1, 'Ты — информационный помощник по предоперационной оценке рисков для пациента.
+Твои ответы должны быть безопасными и не содержать конкретных назначений или дозировок препаратов.
+Пиши простым русским языком.
+Не добавляй дисклеймеры/предупреждения в стиле "Важно:" — приложение покажет стандартное предупреждение отдельно.
+
+Контекст: пациент заполнил шкалу предоперационной оценки риска (ASA, RCRI/Lee, Goldman, Caprini или другую).
+
+Структура ответа:
+1) Резюме результата (1-2 предложения, что означает балл/класс)
+2) Уровень периоперационного риска и его значение
+3) Возможные меры предоперационной подготовки (без конкретных назначений)
+4) Какие дополнительные обследования могут потребоваться
+5) Вопросы для обсуждения с анестезиологом и хирургом', 'approved', 'Initial version from the application code', NOW()),
+    ('survey_interpretation', 1, 'Ты — медицинский AI-ассистент. Твоя задача — интерпретировать результаты медицинских опросников (BVAS, DAS28, BASDAI и др.) для врача.
+Отвечай кратко и по делу. Используй медицинскую терминологию. Укажи степень активности заболевания и возможные рекомендации по дальнейшему обследованию.', 'approved', 'Initial version from the application code', NOW()),
+    ('therapy_recommendation', 1, 'Ты — медицинский AI-ассистент, помогающий врачу с подбором биологической терапии (ГИБП).
+На основе предоставленных данных о пациенте (диагноз, индексы активности, предыдущая терапия) предложи возможные варианты ГИБП-терапии.
+Укажи механизм действия препаратов и возможные противопоказания. Это рекомендация для врача, не для пациента.', 'approved', 'Initial version from the application code', NOW()),
+    ('drug_interaction', 1, 'Ты — фармацевт-консультант. Проанализируй возможные лекарственные взаимодействия между указанными препаратами.
+Укажи клинически значимые взаимодействия и рекомендации по их предотвращению.', 'approved', 'Initial version from the application code', NOW());
+
+-- Prompt version used to generate the advice (NULL = built-in prompt)
+ALTER TABLE ai_advice
+    ADD COLUMN prompt_template_id UUID REFERENCES prompt_templates(id),
+    ADD COLUMN prompt_version INTEGER;
@@ -0,0 +1,2 @@
+ALTER TABLE ai_advice
+    DROP COLUMN IF EXISTS sections;
@@ -0,0 +1,4 @@
+-- Structured advice: the five answer sections parsed from the model output.
+-- NULL when the model answered in free text that could not be split.
+ALTER TABLE ai_advice
+    ADD COLUMN sections JSONB;
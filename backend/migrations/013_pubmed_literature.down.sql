@@ -0,0 +1,5 @@
+ALTER TABLE ai_advice
+    DROP COLUMN IF EXISTS citations;
+
+DROP TABLE IF EXISTS pubmed_queries;
+DROP TABLE IF EXISTS pubmed_abstracts;
@@ -0,0 +1,24 @@
+-- ============================================
+-- PUBMED LITERATURE CACHE (evidence for patient advice)
+-- ============================================
+
+-- Abstracts fetched with EFetch; published abstracts do not change, so rows are kept indefinitely.
+CREATE TABLE pubmed_abstracts (
+    pmid VARCHAR(20) PRIMARY KEY,
+    title TEXT NOT NULL,
+    abstract TEXT NOT NULL DEFAULT '',
+    journal TEXT,
+    pub_year VARCHAR(4),
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ESearch results per query term, refreshed after a TTL so new literature is picked up.
+CREATE TABLE pubmed_queries (
+    term TEXT PRIMARY KEY,
+    pmids TEXT[] NOT NULL DEFAULT '{}',
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- PMIDs of the abstracts included in the prompt; NULL for fallback text.
+ALTER TABLE ai_advice
+    ADD COLUMN citations TEXT[];
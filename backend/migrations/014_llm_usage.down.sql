@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS llm_quotas;
+DROP TABLE IF EXISTS llm_usage;
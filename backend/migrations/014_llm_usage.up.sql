@@ -0,0 +1,43 @@
+-- ============================================
+-- LLM USAGE ACCOUNTING & DAILY QUOTAS
+-- ============================================
+
+-- One row per completion call (streamed or not), including failed calls.
+CREATE TABLE llm_usage (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    survey_code VARCHAR(50),
+    purpose VARCHAR(50),  -- prompt key: 'patient_advice', 'survey_interpretation', ...
+    model VARCHAR(100) NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,  -- 'ok', 'error'
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_usage_user_day ON llm_usage(user_id, created_at);
+CREATE INDEX idx_llm_usage_created ON llm_usage(created_at);
+
+-- Daily limits per role, optionally overridden per user. NULL limit = unlimited.
+CREATE TABLE llm_quotas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    daily_tokens INTEGER,
+    daily_requests INTEGER,
+    updated_by UUID REFERENCES users(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((role_id IS NULL) <> (user_id IS NULL)),
+    CHECK (daily_tokens IS NULL OR daily_tokens >= 0),
+    CHECK (daily_requests IS NULL OR daily_requests >= 0)
+);
+
+CREATE UNIQUE INDEX idx_llm_quotas_role ON llm_quotas(role_id) WHERE role_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_llm_quotas_user ON llm_quotas(user_id) WHERE user_id IS NOT NULL;
+
+-- Patients generate advice for themselves; clinicians review many patients.
+INSERT INTO llm_quotas (role_id, daily_tokens, daily_requests) VALUES
+    ('44444444-4444-4444-4444-444444444444', 30000, 20),
+    ('33333333-3333-3333-3333-333333333333', 100000, 100),
+    ('22222222-2222-2222-2222-222222222222', 200000, 200);
@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission_id = 'abababab-abab-abab-abab-abababababab';
+DELETE FROM permissions WHERE id = 'abababab-abab-abab-abab-abababababab';
+
+ALTER TABLE ai_jobs DROP COLUMN IF EXISTS clinical_insight_id;
+
+DROP TABLE IF EXISTS clinical_insights;
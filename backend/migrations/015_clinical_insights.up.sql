@@ -0,0 +1,30 @@
+-- ============================================
+-- CLINICAL INSIGHTS (clinician-facing AI output, kept apart from patient advice)
+-- ============================================
+
+CREATE TABLE clinical_insights (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id),
+    kind VARCHAR(30) NOT NULL,  -- 'interpretation', 'therapy'
+    insight_text TEXT NOT NULL,
+    interaction_text TEXT,  -- drug interaction review of the current therapy (kind = 'therapy')
+    context JSONB NOT NULL,  -- diagnosis, latest indices and therapy history the text is based on
+    ai_status VARCHAR(20),
+    prompt_template_id UUID REFERENCES prompt_templates(id),
+    prompt_version INTEGER,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_clinical_insights_patient ON clinical_insights(patient_id, created_at DESC);
+
+ALTER TABLE ai_jobs
+    ADD COLUMN clinical_insight_id UUID REFERENCES clinical_insights(id) ON DELETE CASCADE;
+
+INSERT INTO permissions (id, name, description) VALUES
+    ('abababab-abab-abab-abab-abababababab', 'ai:clinical', 'Request clinician-level AI interpretations');
+
+-- Clinician-facing output: doctors and admins only.
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+    ('11111111-1111-1111-1111-111111111111', 'abababab-abab-abab-abab-abababababab'),
+    ('22222222-2222-2222-2222-222222222222', 'abababab-abab-abab-abab-abababababab');
@@ -0,0 +1,14 @@
+-- ============================================
+-- ADVICE FOLLOW-UP THREADS (patient questions about one advice record)
+-- ============================================
+
+CREATE TABLE ai_advice_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    advice_id UUID NOT NULL REFERENCES ai_advice(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,  -- 'user', 'assistant'
+    content TEXT NOT NULL,
+    ai_status VARCHAR(20),  -- assistant replies: 'done', or 'failed' when the fallback reply was stored
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_advice_messages_advice ON ai_advice_messages(advice_id, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ai_advice_feedback;
+
+ALTER TABLE ai_advice
+    DROP COLUMN IF EXISTS model;
@@ -0,0 +1,24 @@
+-- ============================================
+-- ADVICE FEEDBACK (patient ratings, clinician flags) & QUALITY REPORTING
+-- ============================================
+
+-- Model that generated the advice text (NULL = rule-based fallback)
+ALTER TABLE ai_advice
+    ADD COLUMN model VARCHAR(100);
+
+CREATE TABLE ai_advice_feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    advice_id UUID NOT NULL REFERENCES ai_advice(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,  -- 'rating' (patient), 'flag' (clinician)
+    rating SMALLINT CHECK (rating BETWEEN 1 AND 5),
+    reason VARCHAR(20),  -- flags: 'inaccurate', 'unsafe'
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((kind = 'rating') = (rating IS NOT NULL)),
+    CHECK ((kind = 'flag') = (reason IS NOT NULL))
+);
+
+-- One rating per patient and one flag per clinician for each advice record; resubmitting replaces it.
+CREATE UNIQUE INDEX idx_ai_advice_feedback_unique ON ai_advice_feedback(advice_id, user_id, kind);
@@ -0,0 +1,24 @@
+-- ============================================
+-- PATIENT ADVICE CACHE
+-- ============================================
+
+-- Generated advice for identical scored answers, reused instead of a new LLM call.
+-- cache_key is a SHA-256 over survey code, template version, prompt version and text,
+-- model and the canonical answers; only advice requested without a patient comment is cached.
+CREATE TABLE ai_advice_cache (
+    cache_key CHAR(64) PRIMARY KEY,
+    survey_code VARCHAR(50) NOT NULL,
+    template_version INTEGER NOT NULL,
+    prompt_template_id UUID REFERENCES prompt_templates(id) ON DELETE CASCADE,
+    prompt_version INTEGER NOT NULL DEFAULT 0,
+    model VARCHAR(100) NOT NULL,
+    advice_text TEXT NOT NULL,
+    sections JSONB,
+    citations TEXT[],
+    hits INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_ai_advice_cache_survey ON ai_advice_cache(survey_code);
+CREATE INDEX idx_ai_advice_cache_expires ON ai_advice_cache(expires_at);
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE role_id = '55555555-5555-5555-5555-555555555555';
+DELETE FROM roles WHERE id = '55555555-5555-5555-5555-555555555555';
@@ -0,0 +1,7 @@
+-- Pharmacists maintain the drug catalogue.
+INSERT INTO roles (id, name, description) VALUES
+    ('55555555-5555-5555-5555-555555555555', 'pharmacist', 'Pharmacist - maintains the drug catalog');
+
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+    ('55555555-5555-5555-5555-555555555555', 'eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeea'),
+    ('55555555-5555-5555-5555-555555555555', 'eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeeb');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_drugs_atc_code;
+DROP INDEX IF EXISTS idx_drugs_search_trgm;
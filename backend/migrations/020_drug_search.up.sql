@@ -0,0 +1,9 @@
+-- Fuzzy drug search: trigram index over the combined names. The expression must match
+-- drugSearchDocument in the repository for the index to be used.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_drugs_search_trgm ON drugs USING GIN (
+    LOWER(name || ' ' || COALESCE(international_name, '') || ' ' || COALESCE(trade_name, '')) gin_trgm_ops
+);
+
+CREATE INDEX idx_drugs_atc_code ON drugs(atc_code text_pattern_ops);
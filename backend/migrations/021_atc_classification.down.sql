@@ -0,0 +1 @@
+DROP TABLE IF EXISTS atc_classes;
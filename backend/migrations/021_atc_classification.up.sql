@@ -0,0 +1,110 @@
+-- ATC (Anatomical Therapeutic Chemical) classification reference. A code's ancestors are its
+-- prefixes of length 1, 3, 4 and 5, so drugs.atc_code needs no foreign key: a class contains
+-- every drug whose code starts with the class code. Only the main groups and the branches used
+-- by the catalogue are seeded.
+CREATE TABLE atc_classes (
+    code VARCHAR(7) PRIMARY KEY,
+    level SMALLINT NOT NULL CHECK (level BETWEEN 1 AND 5),
+    parent_code VARCHAR(7) REFERENCES atc_classes(code),
+    name_ru VARCHAR(255) NOT NULL,
+    name_en VARCHAR(255) NOT NULL,
+    CHECK ((level = 1) = (parent_code IS NULL))
+);
+
+CREATE INDEX idx_atc_classes_parent ON atc_classes(parent_code);
+
+INSERT INTO atc_classes (code, level, parent_code, name_ru, name_en) VALUES
+-- Level 1: anatomical main groups
+('A', 1, NULL, 'Пищеварительный тракт и обмен веществ', 'Alimentary tract and metabolism'),
+('B', 1, NULL, 'Кровь и система кроветворения', 'Blood and blood forming organs'),
+('C', 1, NULL, 'Сердечно-сосудистая система', 'Cardiovascular system'),
+('D', 1, NULL, 'Дерматологические препараты', 'Dermatologicals'),
+('G', 1, NULL, 'Мочеполовая система и половые гормоны', 'Genito urinary system and sex hormones'),
+('H', 1, NULL, 'Гормональные препараты для системного применения (кроме половых гормонов и инсулинов)', 'Systemic hormonal preparations, excluding sex hormones and insulins'),
+('J', 1, NULL, 'Противомикробные препараты для системного применения', 'Antiinfectives for systemic use'),
+('L', 1, NULL, 'Противоопухолевые препараты и иммуномодуляторы', 'Antineoplastic and immunomodulating agents'),
+('M', 1, NULL, 'Костно-мышечная система', 'Musculo-skeletal system'),
+('N', 1, NULL, 'Нервная система', 'Nervous system'),
+('P', 1, NULL, 'Противопаразитарные препараты, инсектициды и репелленты', 'Antiparasitic products, insecticides and repellents'),
+('R', 1, NULL, 'Дыхательная система', 'Respiratory system'),
+('S', 1, NULL, 'Органы чувств', 'Sensory organs'),
+('V', 1, NULL, 'Прочие препараты', 'Various'),
+
+-- A07EC: aminosalicylates
+('A07', 2, 'A', 'Противодиарейные, кишечные противовоспалительные и противомикробные препараты', 'Antidiarrheals, intestinal antiinflammatory/antiinfective agents'),
+('A07E', 3, 'A07', 'Кишечные противовоспалительные препараты', 'Intestinal antiinflammatory agents'),
+('A07EC', 4, 'A07E', 'Аминосалициловая кислота и аналогичные препараты', 'Aminosalicylic acid and similar agents'),
+('A07EC01', 5, 'A07EC', 'Сульфасалазин', 'sulfasalazine'),
+
+-- B01: antithrombotics
+('B01', 2, 'B', 'Антитромботические средства', 'Antithrombotic agents'),
+('B01A', 3, 'B01', 'Антитромботические средства', 'Antithrombotic agents'),
+('B01AA', 4, 'B01A', 'Антагонисты витамина K', 'Vitamin K antagonists'),
+('B01AA03', 5, 'B01AA', 'Варфарин', 'warfarin'),
+('B01AC', 4, 'B01A', 'Антиагреганты, кроме гепарина', 'Platelet aggregation inhibitors excl. heparin'),
+('B01AC06', 5, 'B01AC', 'Ацетилсалициловая кислота', 'acetylsalicylic acid'),
+
+-- H02AB: systemic glucocorticoids
+('H02', 2, 'H', 'Кортикостероиды для системного применения', 'Corticosteroids for systemic use'),
+('H02A', 3, 'H02', 'Кортикостероиды для системного применения', 'Corticosteroids for systemic use, plain'),
+('H02AB', 4, 'H02A', 'Глюкокортикоиды', 'Glucocorticoids'),
+('H02AB04', 5, 'H02AB', 'Метилпреднизолон', 'methylprednisolone'),
+('H02AB06', 5, 'H02AB', 'Преднизолон', 'prednisolone'),
+
+-- L01: antineoplastic agents
+('L01', 2, 'L', 'Противоопухолевые препараты', 'Antineoplastic agents'),
+('L01B', 3, 'L01', 'Антиметаболиты', 'Antimetabolites'),
+('L01BA', 4, 'L01B', 'Аналоги фолиевой кислоты', 'Folic acid analogues'),
+('L01BA01', 5, 'L01BA', 'Метотрексат', 'methotrexate'),
+('L01F', 3, 'L01', 'Моноклональные антитела и конъюгаты антитело-препарат', 'Monoclonal antibodies and antibody drug conjugates'),
+('L01FA', 4, 'L01F', 'Ингибиторы CD20', 'CD20 (Clusters of Differentiation 20) inhibitors'),
+('L01FA01', 5, 'L01FA', 'Ритуксимаб', 'rituximab'),
+('L01X', 3, 'L01', 'Прочие противоопухолевые препараты', 'Other antineoplastic agents'),
+('L01XC', 4, 'L01X', 'Моноклональные антитела (до 2022 г., перенесены в L01F)', 'Monoclonal antibodies (until 2022, moved to L01F)'),
+('L01XC02', 5, 'L01XC', 'Ритуксимаб', 'rituximab'),
+
+-- L04: immunosuppressants
+('L04', 2, 'L', 'Иммунодепрессанты', 'Immunosuppressants'),
+('L04A', 3, 'L04', 'Иммунодепрессанты', 'Immunosuppressants'),
+('L04AA', 4, 'L04A', 'Селективные иммунодепрессанты', 'Selective immunosuppressants'),
+('L04AA13', 5, 'L04AA', 'Лефлуномид', 'leflunomide'),
+('L04AA24', 5, 'L04AA', 'Абатацепт', 'abatacept'),
+('L04AA26', 5, 'L04AA', 'Белимумаб', 'belimumab'),
+('L04AA29', 5, 'L04AA', 'Тофацитиниб', 'tofacitinib'),
+('L04AA37', 5, 'L04AA', 'Барицитиниб', 'baricitinib'),
+('L04AA44', 5, 'L04AA', 'Упадацитиниб', 'upadacitinib'),
+('L04AB', 4, 'L04A', 'Ингибиторы фактора некроза опухоли альфа (ФНО-альфа)', 'Tumor necrosis factor alpha (TNF-alpha) inhibitors'),
+('L04AB01', 5, 'L04AB', 'Этанерцепт', 'etanercept'),
+('L04AB02', 5, 'L04AB', 'Инфликсимаб', 'infliximab'),
+('L04AB04', 5, 'L04AB', 'Адалимумаб', 'adalimumab'),
+('L04AB05', 5, 'L04AB', 'Цертолизумаба пэгол', 'certolizumab pegol'),
+('L04AB06', 5, 'L04AB', 'Голимумаб', 'golimumab'),
+('L04AC', 4, 'L04A', 'Ингибиторы интерлейкина', 'Interleukin inhibitors'),
+('L04AC03', 5, 'L04AC', 'Анакинра', 'anakinra'),
+('L04AC05', 5, 'L04AC', 'Устекинумаб', 'ustekinumab'),
+('L04AC07', 5, 'L04AC', 'Тоцилизумаб', 'tocilizumab'),
+('L04AC10', 5, 'L04AC', 'Секукинумаб', 'secukinumab'),
+('L04AC13', 5, 'L04AC', 'Иксекизумаб', 'ixekizumab'),
+('L04AC14', 5, 'L04AC', 'Сарилумаб', 'sarilumab'),
+('L04AC16', 5, 'L04AC', 'Гуселькумаб', 'guselkumab'),
+('L04AD', 4, 'L04A', 'Ингибиторы кальциневрина', 'Calcineurin inhibitors'),
+('L04AD01', 5, 'L04AD', 'Циклоспорин', 'ciclosporin'),
+('L04AX', 4, 'L04A', 'Прочие иммунодепрессанты', 'Other immunosuppressants'),
+('L04AX01', 5, 'L04AX', 'Азатиоприн', 'azathioprine'),
+('L04AX03', 5, 'L04AX', 'Метотрексат', 'methotrexate'),
+
+-- M01A: NSAIDs
+('M01', 2, 'M', 'Противовоспалительные и противоревматические препараты', 'Antiinflammatory and antirheumatic products'),
+('M01A', 3, 'M01', 'Нестероидные противовоспалительные и противоревматические препараты', 'Antiinflammatory and antirheumatic products, non-steroids'),
+('M01AB', 4, 'M01A', 'Производные уксусной кислоты и родственные соединения', 'Acetic acid derivatives and related substances'),
+('M01AB05', 5, 'M01AB', 'Диклофенак', 'diclofenac'),
+('M01AE', 4, 'M01A', 'Производные пропионовой кислоты', 'Propionic acid derivatives'),
+('M01AE01', 5, 'M01AE', 'Ибупрофен', 'ibuprofen'),
+('M01AH', 4, 'M01A', 'Коксибы', 'Coxibs'),
+('M01AH01', 5, 'M01AH', 'Целекоксиб', 'celecoxib'),
+
+-- P01BA: aminoquinolines
+('P01', 2, 'P', 'Противопротозойные препараты', 'Antiprotozoals'),
+('P01B', 3, 'P01', 'Противомалярийные препараты', 'Antimalarials'),
+('P01BA', 4, 'P01B', 'Аминохинолины', 'Aminoquinolines'),
+('P01BA02', 5, 'P01BA', 'Гидроксихлорохин', 'hydroxychloroquine');
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS drug_interaction_rules;
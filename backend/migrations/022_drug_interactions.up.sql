@@ -0,0 +1,74 @@
+-- Deterministic interaction rules. Each side names either one catalogue drug or an ATC class
+-- (a drug belongs to every class its atc_code starts with).
+CREATE TABLE drug_interaction_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    drug_a_id UUID REFERENCES drugs(id),
+    atc_code_a VARCHAR(7),
+    drug_b_id UUID REFERENCES drugs(id),
+    atc_code_b VARCHAR(7),
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('contraindicated', 'major', 'moderate', 'minor')),
+    mechanism TEXT NOT NULL,
+    management TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((drug_a_id IS NULL) <> (atc_code_a IS NULL)),
+    CHECK ((drug_b_id IS NULL) <> (atc_code_b IS NULL))
+);
+
+-- Biologic DMARDs: TNF-alpha inhibitors, interleukin inhibitors, abatacept, belimumab, rituximab
+-- (current and pre-2022 code). Any two of them together is a major interaction.
+WITH biologics(code) AS (
+    VALUES ('L04AB'), ('L04AC'), ('L04AA24'), ('L04AA26'), ('L01FA01'), ('L01XC02')
+)
+INSERT INTO drug_interaction_rules (atc_code_a, atc_code_b, severity, mechanism, management)
+SELECT a.code, b.code, 'major',
+    'Сочетание двух генно-инженерных биологических препаратов: суммарная иммуносупрессия без доказанного дополнительного эффекта, рост риска тяжёлых и оппортунистических инфекций.',
+    'Не назначать одновременно. При переключении препарата соблюдать интервал отмены предыдущего; при вынужденном совместном применении — решение ревматолога и контроль инфекций.'
+FROM biologics a JOIN biologics b ON a.code <= b.code;
+
+-- JAK inhibitors with biologics or with each other.
+WITH jak(code) AS (
+    VALUES ('L04AA29'), ('L04AA37'), ('L04AA44')
+), biologics(code) AS (
+    VALUES ('L04AB'), ('L04AC'), ('L04AA24'), ('L04AA26'), ('L01FA01'), ('L01XC02')
+)
+INSERT INTO drug_interaction_rules (atc_code_a, atc_code_b, severity, mechanism, management)
+SELECT j.code, b.code, 'contraindicated',
+    'Ингибитор JAK в сочетании с ГИБП: выраженная иммуносупрессия; комбинация не изучена и не рекомендована инструкциями.',
+    'Не сочетать. Перед началом ингибитора JAK отменить ГИБП с учётом периода его выведения.'
+FROM jak j CROSS JOIN biologics b
+UNION ALL
+SELECT a.code, b.code, 'contraindicated',
+    'Два ингибитора JAK: дублирование механизма действия, риск цитопений и инфекций.',
+    'Не сочетать; назначается только один ингибитор JAK.'
+FROM jak a JOIN jak b ON a.code < b.code;
+
+INSERT INTO drug_interaction_rules (atc_code_a, atc_code_b, severity, mechanism, management) VALUES
+('L04AA29', 'L04AD', 'major',
+    'Ингибитор JAK с ингибитором кальциневрина (циклоспорин): усиление иммуносупрессии.',
+    'Избегать сочетания; при необходимости — минимальные дозы и контроль инфекций.'),
+('L04AA37', 'L04AD', 'major',
+    'Ингибитор JAK с ингибитором кальциневрина (циклоспорин): усиление иммуносупрессии.',
+    'Избегать сочетания; при необходимости — минимальные дозы и контроль инфекций.'),
+('L04AA44', 'L04AD', 'major',
+    'Ингибитор JAK с ингибитором кальциневрина (циклоспорин): усиление иммуносупрессии.',
+    'Избегать сочетания; при необходимости — минимальные дозы и контроль инфекций.'),
+('L04AX03', 'L04AA13', 'major',
+    'Метотрексат и лефлуномид: суммирование гепатотоксичности и миелосупрессии.',
+    'Допустимо только под контролем АЛТ/АСТ и общего анализа крови каждые 4 недели в первые полгода.'),
+('L01BA01', 'L04AA13', 'major',
+    'Метотрексат и лефлуномид: суммирование гепатотоксичности и миелосупрессии.',
+    'Допустимо только под контролем АЛТ/АСТ и общего анализа крови каждые 4 недели в первые полгода.'),
+('L04AX03', 'M01A', 'moderate',
+    'НПВП снижают почечный клиренс метотрексата и повышают его токсичность.',
+    'При низких «ревматологических» дозах сочетание допустимо; контроль креатинина и анализа крови, избегать НПВП в день приёма метотрексата.'),
+('L01BA01', 'M01A', 'moderate',
+    'НПВП снижают почечный клиренс метотрексата и повышают его токсичность.',
+    'При низких «ревматологических» дозах сочетание допустимо; контроль креатинина и анализа крови, избегать НПВП в день приёма метотрексата.'),
+('B01AA', 'M01A', 'major',
+    'Антагонисты витамина K и НПВП: повреждение слизистой ЖКТ и нарушение функции тромбоцитов, риск кровотечения.',
+    'Избегать сочетания; при необходимости — кратко, с ингибитором протонной помпы и контролем МНО.'),
+('H02AB', 'M01A', 'moderate',
+    'Глюкокортикоиды и НПВП: аддитивный риск язв и кровотечений из ЖКТ.',
+    'Назначить гастропротекцию (ингибитор протонной помпы), использовать минимальные эффективные дозы.');
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS drug_perioperative_rules;
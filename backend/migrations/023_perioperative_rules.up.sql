@@ -0,0 +1,52 @@
+-- Perioperative management of antirheumatic drugs, after the 2022 ACR/AAHKS guideline for elective
+-- total hip and knee arthroplasty: biologics are held so that surgery falls at the end of a dosing
+-- interval (the last dose at least hold_intervals * dosing_interval_days before surgery) and restarted
+-- once the wound has healed, about 14 days after surgery; conventional DMARDs are continued.
+-- A rule names either one catalogue drug or an ATC class; the most specific match wins.
+CREATE TABLE drug_perioperative_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    drug_id UUID REFERENCES drugs(id),
+    atc_code VARCHAR(7),
+    dosing_interval_days INT NOT NULL CHECK (dosing_interval_days > 0),
+    hold_intervals NUMERIC(4,1) NOT NULL CHECK (hold_intervals >= 0),
+    resume_after_days INT NOT NULL DEFAULT 0 CHECK (resume_after_days >= 0),
+    guidance TEXT NOT NULL,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((drug_id IS NULL) <> (atc_code IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_perioperative_rules_drug ON drug_perioperative_rules(drug_id) WHERE drug_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_perioperative_rules_atc ON drug_perioperative_rules(atc_code) WHERE atc_code IS NOT NULL;
+
+INSERT INTO drug_perioperative_rules (atc_code, dosing_interval_days, hold_intervals, resume_after_days, guidance) VALUES
+-- TNF-alpha inhibitors
+('L04AB01', 7, 1, 14, 'Этанерцепт еженедельно: операция на 2-й неделе после последней инъекции. Возобновить после заживления раны (обычно через 14 дней), при отсутствии инфекции и после снятия швов.'),
+('L04AB02', 56, 1, 14, 'Инфликсимаб каждые 4, 6 или 8 недель: операция на 5-й, 7-й или 9-й неделе после последней инфузии. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AB04', 14, 1, 14, 'Адалимумаб каждые 2 недели: операция на 3-й неделе после последней инъекции. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AB05', 14, 1, 14, 'Цертолизумаба пэгол каждые 2 или 4 недели: операция на 3-й или 5-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AB06', 28, 1, 14, 'Голимумаб п/к ежемесячно: операция на 5-й неделе (в/в каждые 8 недель — на 9-й). Возобновить после заживления раны (обычно через 14 дней).'),
+-- Interleukin inhibitors
+('L04AC03', 1, 1, 14, 'Анакинра ежедневно: операция на следующий день после последней инъекции. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC05', 84, 1, 14, 'Устекинумаб каждые 12 недель: операция на 13-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC07', 28, 1, 14, 'Тоцилизумаб в/в каждые 4 недели: операция на 5-й неделе (п/к еженедельно — на 2-й). Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC10', 28, 1, 14, 'Секукинумаб каждые 4 недели: операция на 5-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC13', 28, 1, 14, 'Иксекизумаб каждые 4 недели: операция на 5-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC14', 14, 1, 14, 'Сарилумаб каждые 2 недели: операция на 3-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AC16', 56, 1, 14, 'Гуселькумаб каждые 8 недель: операция на 9-й неделе. Возобновить после заживления раны (обычно через 14 дней).'),
+-- Other biologics
+('L04AA24', 28, 1, 14, 'Абатацепт в/в ежемесячно: операция на 5-й неделе (п/к еженедельно — на 2-й). Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AA26', 28, 1, 14, 'Белимумаб в/в ежемесячно: операция на 5-й неделе (п/к еженедельно — на 2-й). Возобновить после заживления раны (обычно через 14 дней).'),
+('L01FA01', 180, 1, 14, 'Ритуксимаб каждые 6 месяцев: операция на 7-м месяце после последнего курса. Возобновить после заживления раны (обычно через 14 дней).'),
+('L01XC02', 180, 1, 14, 'Ритуксимаб каждые 6 месяцев: операция на 7-м месяце после последнего курса. Возобновить после заживления раны (обычно через 14 дней).'),
+-- JAK inhibitors
+('L04AA29', 1, 3, 14, 'Тофацитиниб: отменить за 3 дня до операции. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AA37', 1, 3, 14, 'Барицитиниб: отменить за 3 дня до операции. Возобновить после заживления раны (обычно через 14 дней).'),
+('L04AA44', 1, 3, 14, 'Упадацитиниб: отменить за 3 дня до операции. Возобновить после заживления раны (обычно через 14 дней).'),
+-- Conventional DMARDs and glucocorticoids are continued
+('L04AX03', 7, 0, 0, 'Метотрексат: продолжать приём в обычной дозе.'),
+('L01BA01', 7, 0, 0, 'Метотрексат: продолжать приём в обычной дозе.'),
+('L04AA13', 1, 0, 0, 'Лефлуномид: продолжать приём в обычной дозе.'),
+('P01BA02', 1, 0, 0, 'Гидроксихлорохин: продолжать приём в обычной дозе.'),
+('A07EC01', 1, 0, 0, 'Сульфасалазин: продолжать приём в обычной дозе.'),
+('H02AB', 1, 0, 0, 'Глюкокортикоиды: продолжать текущую суточную дозу; «стрессовые» дозы рутинно не назначаются.');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rescore_jobs_active;
+ALTER TABLE rescore_jobs DROP COLUMN IF EXISTS updated_at;
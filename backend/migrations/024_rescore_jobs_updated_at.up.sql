@@ -0,0 +1,7 @@
+-- updated_at is touched by every status change and progress update of a rescore job, so a job
+-- left running or applying by a crashed process can be told apart from a slow one and failed on
+-- startup instead of blocking new jobs for its template forever.
+ALTER TABLE rescore_jobs ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_rescore_jobs_active ON rescore_jobs(updated_at)
+    WHERE status IN ('pending', 'running', 'applying');
@@ -0,0 +1,5 @@
+DELETE FROM alert_rules
+WHERE id IN ('a1e70000-0000-0000-0000-000000000004', 'a1e70000-0000-0000-0000-000000000005');
+
+UPDATE alert_rules SET is_active = true
+WHERE id IN ('a1e70000-0000-0000-0000-000000000001', 'a1e70000-0000-0000-0000-000000000002');
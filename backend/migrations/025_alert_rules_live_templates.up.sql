@@ -0,0 +1,14 @@
+-- DAS28-CRP is not among the live templates, so its default rules never fire; seed category
+-- rules for the live GOLDMAN and CAPRINI templates instead. Category orders use the codes
+-- returned by the scoring engine (CalculateScore), not the display labels of the templates'
+-- interpretation_rules.
+UPDATE alert_rules SET is_active = false
+WHERE id IN ('a1e70000-0000-0000-0000-000000000001', 'a1e70000-0000-0000-0000-000000000002');
+
+INSERT INTO alert_rules (id, index_type, rule_type, direction, threshold, category_order, severity, description) VALUES
+    ('a1e70000-0000-0000-0000-000000000004', 'GOLDMAN', 'category_change', 'rise', NULL,
+     '["class_i", "class_ii", "class_iii", "class_iv"]', 'warning',
+     'Повышение класса Goldman между оценками'),
+    ('a1e70000-0000-0000-0000-000000000005', 'CAPRINI', 'category_change', 'rise', NULL,
+     '["very_low", "low", "moderate", "high"]', 'warning',
+     'Повышение категории риска ВТЭ по Caprini между оценками');
@@ -0,0 +1,14 @@
+-- At most one non-terminal job per template; the check in RescoreService.Start alone races with
+-- a concurrent request. Older duplicates left by such races are failed first.
+UPDATE rescore_jobs j SET status = 'failed', error = 'superseded by a newer job for the template',
+    finished_at = NOW(), updated_at = NOW()
+WHERE status IN ('pending', 'running', 'ready', 'applying')
+  AND EXISTS (
+    SELECT 1 FROM rescore_jobs n
+    WHERE n.template_id = j.template_id
+      AND n.status IN ('pending', 'running', 'ready', 'applying')
+      AND (n.created_at, n.id) > (j.created_at, j.id)
+  );
+
+CREATE UNIQUE INDEX idx_rescore_jobs_one_active ON rescore_jobs(template_id)
+    WHERE status IN ('pending', 'running', 'ready', 'applying');
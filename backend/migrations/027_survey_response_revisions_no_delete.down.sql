@@ -0,0 +1,10 @@
+DROP TRIGGER trg_survey_response_revisions_immutable ON survey_response_revisions;
+
+CREATE TRIGGER trg_survey_response_revisions_immutable
+    BEFORE UPDATE ON survey_response_revisions
+    FOR EACH ROW EXECUTE FUNCTION survey_response_revisions_immutable();
+
+ALTER TABLE survey_response_revisions
+    DROP CONSTRAINT survey_response_revisions_survey_response_id_fkey,
+    ADD CONSTRAINT survey_response_revisions_survey_response_id_fkey
+        FOREIGN KEY (survey_response_id) REFERENCES survey_responses(id) ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+-- Revisions are the audit trail of amendments: block DELETE as well as UPDATE, and keep a
+-- response with revisions from being deleted (it cascaded the history away before).
+ALTER TABLE survey_response_revisions
+    DROP CONSTRAINT survey_response_revisions_survey_response_id_fkey,
+    ADD CONSTRAINT survey_response_revisions_survey_response_id_fkey
+        FOREIGN KEY (survey_response_id) REFERENCES survey_responses(id) ON DELETE RESTRICT;
+
+DROP TRIGGER trg_survey_response_revisions_immutable ON survey_response_revisions;
+
+CREATE TRIGGER trg_survey_response_revisions_immutable
+    BEFORE UPDATE OR DELETE ON survey_response_revisions
+    FOR EACH ROW EXECUTE FUNCTION survey_response_revisions_immutable();
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_drugs_name_lower;
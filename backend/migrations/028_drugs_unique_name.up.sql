@@ -0,0 +1,11 @@
+-- Drug names are unique ignoring case: bulk imports match entries by name, and a duplicate made
+-- them update whichever row came first. Existing duplicates cannot be deleted (therapy logs and
+-- rules refer to them), so every copy but the oldest is renamed after its id and deactivated.
+UPDATE drugs d SET name = LEFT(d.name, 200) || ' (' || d.id || ')', is_active = false, updated_at = NOW()
+WHERE EXISTS (
+    SELECT 1 FROM drugs o
+    WHERE LOWER(o.name) = LOWER(d.name)
+      AND (COALESCE(o.created_at, '-infinity'), o.id) < (COALESCE(d.created_at, '-infinity'), d.id)
+);
+
+CREATE UNIQUE INDEX idx_drugs_name_lower ON drugs(LOWER(name));
@@ -1,6 +1,11 @@
 package response
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -52,6 +57,14 @@ func Created(c *fiber.Ctx, data interface{}) error {
 	})
 }
 
+// Accepted sends a 202 accepted response for work that continues in the background
+func Accepted(c *fiber.Ctx, data interface{}) error {
+	return c.Status(fiber.StatusAccepted).JSON(Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
 // NoContent sends a 204 no content response
 func NoContent(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
@@ -105,6 +118,16 @@ func InternalError(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", message)
 }
 
+// TooManyRequests sends a 429 with a Retry-After header (whole seconds, rounded up).
+func TooManyRequests(c *fiber.Ctx, message string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return ErrorWithDetails(c, fiber.StatusTooManyRequests, "TOO_MANY_REQUESTS", message, fmt.Sprintf("retry after %d seconds", seconds))
+}
+
 func ValidationError(c *fiber.Ctx, details string) error {
 	return ErrorWithDetails(c, fiber.StatusUnprocessableEntity, "VALIDATION_ERROR", "Validation failed", details)
 }
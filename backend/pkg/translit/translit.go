@@ -0,0 +1,105 @@
+// Package translit converts search queries between Cyrillic and Latin script. The rules are tuned
+// for drug names (Russian names follow the Latin INN: "ц" for "c" before e/i/y, "кс" for "x"),
+// not for a reversible standard such as GOST 7.79; fuzzy matching absorbs the remaining differences.
+package translit
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var cyrToLat = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// latDigraphs are tried before single letters, longest first.
+var latDigraphs = []digraph{
+	{"shch", "щ"},
+	{"sh", "ш"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ph", "ф"}, {"th", "т"},
+	{"ch", "х"}, {"ck", "к"}, {"qu", "кв"},
+	{"ya", "я"}, {"yu", "ю"}, {"yo", "е"}, {"ye", "е"},
+}
+
+var latToCyr = map[rune]string{
+	'a': "а", 'b': "б", 'd': "д", 'e': "е", 'f': "ф", 'g': "г", 'h': "х", 'i': "и",
+	'j': "дж", 'k': "к", 'l': "л", 'm': "м", 'n': "н", 'o': "о", 'p': "п", 'q': "к",
+	'r': "р", 's': "с", 't': "т", 'u': "у", 'v': "в", 'w': "в", 'x': "кс", 'y': "и",
+	'z': "з",
+}
+
+// ToLatin transliterates Cyrillic letters of a lower-cased string; other characters are kept.
+func ToLatin(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == 'к' && i+1 < len(runes) && runes[i+1] == 'с' {
+			b.WriteByte('x')
+			i++
+			continue
+		}
+		if lat, ok := cyrToLat[runes[i]]; ok {
+			b.WriteString(lat)
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
+
+// ToCyrillic transliterates Latin letters of a lower-cased string; other characters are kept.
+func ToCyrillic(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if d, ok := matchDigraph(s[i:]); ok {
+			b.WriteString(d.cyr)
+			i += len(d.lat)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch cyr, ok := latToCyr[r]; {
+		case r == 'c' && i < len(s) && strings.IndexByte("eiy", s[i]) >= 0:
+			// Soft "c" before e, i, y as in Tocilizumab -> Тоцилизумаб, hard otherwise.
+			b.WriteString("ц")
+		case r == 'c':
+			b.WriteString("к")
+		case ok:
+			b.WriteString(cyr)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+type digraph struct{ lat, cyr string }
+
+func matchDigraph(s string) (digraph, bool) {
+	for _, d := range latDigraphs {
+		if strings.HasPrefix(s, d.lat) {
+			return d, true
+		}
+	}
+	return digraph{}, false
+}
+
+// Variants returns the lower-cased, space-normalized query followed by its transliterations into
+// the other script, without duplicates. A query in one script yields two variants; an empty one none.
+func Variants(query string) []string {
+	q := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if q == "" {
+		return nil
+	}
+	out := []string{q}
+	for _, v := range []string{ToLatin(q), ToCyrillic(q)} {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package translit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToLatin(t *testing.T) {
+	tests := map[string]string{
+		"адалимумаб":  "adalimumab",
+		"хумира":      "humira",
+		"тоцилизумаб": "tocilizumab",
+		"инфликсимаб": "infliximab",
+		"l04ab04":     "l04ab04",
+	}
+	for in, want := range tests {
+		if got := ToLatin(in); got != want {
+			t.Errorf("ToLatin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestToCyrillic(t *testing.T) {
+	tests := map[string]string{
+		"adalimumab":   "адалимумаб",
+		"humira":       "хумира",
+		"tocilizumab":  "тоцилизумаб",
+		"certolizumab": "цертолизумаб",
+		"secukinumab":  "секукинумаб",
+		"infliximab":   "инфликсимаб",
+		"olumiant 4":   "олумиант 4",
+	}
+	for in, want := range tests {
+		if got := ToCyrillic(in); got != want {
+			t.Errorf("ToCyrillic(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	if got := Variants("  Хумира  40 "); !slices.Equal(got, []string{"хумира 40", "humira 40"}) {
+		t.Errorf("Variants(cyrillic) = %q", got)
+	}
+	if got := Variants("Adalimumab"); !slices.Equal(got, []string{"adalimumab", "адалимумаб"}) {
+		t.Errorf("Variants(latin) = %q", got)
+	}
+	if got := Variants(" "); got != nil {
+		t.Errorf("Variants(blank) = %q, want nil", got)
+	}
+}
@@ -1,10 +1,18 @@
-import { useMemo } from 'react';
-import { View, Text, StyleSheet, ActivityIndicator, TouchableOpacity, Alert, FlatList } from 'react-native';
+import { useMemo, useState } from 'react';
+import { View, Text, StyleSheet, ActivityIndicator, TouchableOpacity, FlatList, Linking } from 'react-native';
 import { SafeAreaView } from 'react-native-safe-area-context';
 import { useQuery } from '@tanstack/react-query';
 import FontAwesome from '@expo/vector-icons/FontAwesome';
 import { aiApi } from '../../src/api/client';
-import type { AIAdviceResult } from '../../src/types';
+import type { AdviceSections, AIAdviceResult } from '../../src/types';
+
+const SECTION_TITLES: { key: keyof AdviceSections; title: string }[] = [
+  { key: 'summary', title: 'Резюме' },
+  { key: 'risk_level', title: 'Уровень риска' },
+  { key: 'preparation', title: 'Подготовка' },
+  { key: 'tests', title: 'Обследования' },
+  { key: 'questions', title: 'Вопросы' },
+];
 
 function formatRuDate(iso: string) {
   const d = new Date(iso);
@@ -19,6 +27,7 @@ export default function AdviceHistoryScreen() {
   });
 
   const items = useMemo(() => data || [], [data]);
+  const [expandedId, setExpandedId] = useState<string | null>(null);
 
   if (isLoading) {
     return (
@@ -63,27 +72,51 @@ export default function AdviceHistoryScreen() {
           data={items}
           keyExtractor={(it) => it.id}
           contentContainerStyle={styles.list}
-          renderItem={({ item }) => (
-            <TouchableOpacity
-              style={styles.card}
-              onPress={() =>
-                Alert.alert(
-                  `${item.survey_code} • ${formatRuDate(item.created_at)}`,
-                  `${item.advice_text}\n\n${item.disclaimer}`,
-                  [{ text: 'OK' }]
-                )
-              }
-            >
-              <View style={styles.cardTop}>
-                <Text style={styles.cardTitle}>{item.survey_code}</Text>
-                <Text style={styles.cardDate}>{formatRuDate(item.created_at)}</Text>
-              </View>
-              {!!item.user_text && <Text style={styles.userText}>Комментарий: {item.user_text}</Text>}
-              <Text style={styles.preview} numberOfLines={3}>
-                {item.advice_text}
-              </Text>
-            </TouchableOpacity>
-          )}
+          renderItem={({ item }) => {
+            const expanded = expandedId === item.id;
+            const sections = item.sections;
+            return (
+              <TouchableOpacity
+                style={styles.card}
+                onPress={() => setExpandedId(expanded ? null : item.id)}
+              >
+                <View style={styles.cardTop}>
+                  <Text style={styles.cardTitle}>{item.survey_code}</Text>
+                  <Text style={styles.cardDate}>{formatRuDate(item.created_at)}</Text>
+                </View>
+                {!!item.user_text && <Text style={styles.userText}>Комментарий: {item.user_text}</Text>}
+                {!expanded ? (
+                  <Text style={styles.preview} numberOfLines={3}>
+                    {sections?.summary || item.advice_text}
+                  </Text>
+                ) : sections ? (
+                  SECTION_TITLES.filter(({ key }) => !!sections[key]).map(({ key, title }) => (
+                    <View key={key} style={styles.section}>
+                      <Text style={styles.sectionTitle}>{title}</Text>
+                      <Text style={styles.sectionText}>{sections[key]}</Text>
+                    </View>
+                  ))
+                ) : (
+                  <Text style={styles.preview}>{item.advice_text}</Text>
+                )}
+                {expanded && !!item.citations?.length && (
+                  <View style={styles.section}>
+                    <Text style={styles.sectionTitle}>Источники</Text>
+                    {item.citations.map((pmid) => (
+                      <Text
+                        key={pmid}
+                        style={styles.citation}
+                        onPress={() => Linking.openURL(`https://pubmed.ncbi.nlm.nih.gov/${pmid}/`)}
+                      >
+                        PubMed PMID {pmid}
+                      </Text>
+                    ))}
+                  </View>
+                )}
+                {expanded && <Text style={styles.disclaimer}>{item.disclaimer}</Text>}
+              </TouchableOpacity>
+            );
+          }}
         />
       )}
     </SafeAreaView>
@@ -146,6 +179,33 @@ const styles = StyleSheet.create({
     color: '#111827',
     lineHeight: 18,
   },
+  section: {
+    marginTop: 10,
+  },
+  sectionTitle: {
+    fontSize: 12,
+    fontWeight: '700',
+    color: '#2563eb',
+    textTransform: 'uppercase',
+  },
+  sectionText: {
+    marginTop: 4,
+    fontSize: 13,
+    color: '#111827',
+    lineHeight: 18,
+  },
+  citation: {
+    marginTop: 4,
+    fontSize: 13,
+    color: '#2563eb',
+    textDecorationLine: 'underline',
+  },
+  disclaimer: {
+    marginTop: 12,
+    fontSize: 11,
+    color: '#6b7280',
+    lineHeight: 16,
+  },
   empty: {
     flex: 1,
     justifyContent: 'center',
@@ -97,7 +97,29 @@ export const authApi = {
   me: () => api.get('/auth/me'),
 };
 
-import type { SurveyTemplate, SurveyAnswer, SurveyResult, AIAdviceResult } from '../types';
+import type { SurveyTemplate, SurveyAnswer, SurveyResult, AIAdviceResult, AdviceThread, AdviceFeedback, AIJob } from '../types';
+
+const AI_JOB_POLL_MS = 1500;
+const AI_JOB_TIMEOUT_MS = 90000;
+
+// Advice is generated by a background job; poll it and swap in the generated text when ready.
+// On failure or timeout the fallback text returned by the server is kept.
+const waitForAdvice = async (advice: AIAdviceResult): Promise<AIAdviceResult> => {
+  if (advice.ai_status !== 'pending' || !advice.ai_job_id) return advice;
+
+  const deadline = Date.now() + AI_JOB_TIMEOUT_MS;
+  while (Date.now() < deadline) {
+    await new Promise((resolve) => setTimeout(resolve, AI_JOB_POLL_MS));
+    const job = await aiApi.getJob(advice.ai_job_id);
+    if (job.status === 'done') {
+      return { ...advice, advice_text: job.result || advice.advice_text, ai_status: 'done' };
+    }
+    if (job.status === 'failed') {
+      return { ...advice, ai_status: 'failed' };
+    }
+  }
+  return advice;
+};
 
 // Surveys API
 export const surveysApi = {
@@ -118,7 +140,7 @@ export const surveysApi = {
 
   createAdvice: async (code: string, answers: SurveyAnswer[], text: string): Promise<AIAdviceResult> => {
     const res = await api.post(`/surveys/${code}/advice`, { answers, text });
-    return res.data.data || res.data;
+    return waitForAdvice(res.data.data || res.data);
   },
 
   getResponse: (id: string) => api.get(`/surveys/responses/${id}`),
@@ -132,6 +154,26 @@ export const aiApi = {
     const res = await api.get('/ai/advice', { params: { limit, offset } });
     return res.data.data || res.data;
   },
+
+  getThread: async (adviceId: string): Promise<AdviceThread> => {
+    const res = await api.get(`/ai/advice/${adviceId}/messages`);
+    return res.data.data || res.data;
+  },
+
+  askInThread: async (adviceId: string, text: string): Promise<AdviceThread> => {
+    const res = await api.post(`/ai/advice/${adviceId}/messages`, { text });
+    return res.data.data || res.data;
+  },
+
+  rateAdvice: async (adviceId: string, rating: number, comment?: string): Promise<AdviceFeedback> => {
+    const res = await api.post(`/ai/advice/${adviceId}/rating`, { rating, comment });
+    return res.data.data || res.data;
+  },
+
+  getJob: async (id: string): Promise<AIJob> => {
+    const res = await api.get(`/ai/jobs/${id}`);
+    return res.data.data || res.data;
+  },
 };
 
 // Drugs API
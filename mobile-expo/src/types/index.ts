@@ -67,17 +67,69 @@ export interface SurveyResult {
   breakdown?: Record<string, unknown>;
 }
 
+export interface AdviceSections {
+  summary: string;
+  risk_level: string;
+  preparation: string;
+  tests: string;
+  questions: string;
+}
+
 export interface AIAdviceResult {
   id: string;
   survey_code: string;
   user_text?: string;
   advice_text: string;
+  sections?: AdviceSections;
+  citations?: string[];
   disclaimer: string;
   score?: number;
   category?: string;
+  ai_status?: 'pending' | 'done' | 'failed';
+  ai_job_id?: string;
+  // true when the text was reused from identical earlier answers
+  cached?: boolean;
+  created_at: string;
+}
+
+export interface AdviceMessage {
+  id: string;
+  advice_id: string;
+  role: 'user' | 'assistant';
+  content: string;
+  ai_status?: 'done' | 'failed';
   created_at: string;
 }
 
+export interface AdviceThread {
+  advice_id: string;
+  messages: AdviceMessage[];
+  turns_left: number;
+  disclaimer: string;
+}
+
+export interface AdviceFeedback {
+  id: string;
+  advice_id: string;
+  kind: 'rating' | 'flag';
+  rating?: number;
+  reason?: 'inaccurate' | 'unsafe';
+  comment?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AIJob {
+  id: string;
+  kind: string;
+  status: 'pending' | 'running' | 'done' | 'failed';
+  result?: string;
+  error?: string;
+  attempts: number;
+  created_at: string;
+  finished_at?: string;
+}
+
 export interface SurveyResponse {
   id: string;
   template_id: string;
@@ -113,6 +165,17 @@ export interface TherapyLog {
   next_scheduled?: string;
   notes?: string;
   created_at: string;
+  // Interactions with the patient's current therapies, returned when the log is created
+  interactions?: DrugInteraction[];
+}
+
+export interface DrugInteraction {
+  rule_id: string;
+  severity: 'contraindicated' | 'major' | 'moderate' | 'minor';
+  drug_a: { id: string; name: string; atc_code?: string };
+  drug_b: { id: string; name: string; atc_code?: string };
+  mechanism: string;
+  management: string;
 }
 
 // API Response wrapper